@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/tests"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	BlockTestJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "output the results as JSON",
+	}
+	BlockTestRunFlag = cli.StringFlag{
+		Name:  "run",
+		Usage: "run only the tests whose name matches the regular expression",
+	}
+)
+
+var blockTestCommand = cli.Command{
+	Action:    blockTestCmd,
+	Name:      "blocktest",
+	Usage:     "executes the given blockchain tests through the staged sync",
+	ArgsUsage: "<file|dir>...",
+	Flags: []cli.Flag{
+		BlockTestJSONFlag,
+		BlockTestRunFlag,
+	},
+	Description: `
+The blocktest command inserts the blocks of the JSON blockchain tests with the stages of
+the staged sync (Headers, Bodies, Senders, Execution, HashState, IntermediateHashes...)
+into an in-memory database. The directories are searched for .json files recursively.
+It reports whether every test passes, with the mismatching accounts and storage items
+when the final state differs from the post state of the test.`,
+}
+
+// BlocktestResult contains the outcome of a blockchain test run in stages
+type BlocktestResult struct {
+	Name          string   `json:"name"`
+	File          string   `json:"file"`
+	Pass          bool     `json:"pass"`
+	Skipped       bool     `json:"skipped,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	PostStateDiff []string `json:"postStateDiff,omitempty"`
+}
+
+func blockTestCmd(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		return errors.New("path-to-test argument required")
+	}
+	// Configure the go-ethereum logger
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	var run *regexp.Regexp
+	if pattern := ctx.String(BlockTestRunFlag.Name); pattern != "" {
+		var err error
+		if run, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid --%s: %w", BlockTestRunFlag.Name, err)
+		}
+	}
+	var files []string
+	for _, arg := range ctx.Args() {
+		err := filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			// The files given as arguments are run whatever their extension
+			if !info.IsDir() && (path == arg || strings.HasSuffix(path, ".json")) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg := vm.Config{EVMInterpreter: ctx.GlobalString(EVMInterpreterFlag.Name)}
+	jsonOutput := ctx.Bool(BlockTestJSONFlag.Name)
+	var results []BlocktestResult
+	var passed, failed, skipped int
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var blockTests map[string]*tests.BlockTest
+		if err = json.Unmarshal(src, &blockTests); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		names := make([]string, 0, len(blockTests))
+		for name := range blockTests {
+			if run == nil || run.MatchString(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result := BlocktestResult{Name: name, File: file}
+			diff, err := blockTests[name].RunInStages(cfg)
+			var unsupported tests.UnsupportedForkError
+			switch {
+			case errors.As(err, &unsupported):
+				result.Skipped, result.Error = true, err.Error()
+				skipped++
+			case err != nil:
+				result.Error, result.PostStateDiff = err.Error(), diff
+				failed++
+			default:
+				result.Pass = true
+				passed++
+			}
+			results = append(results, result)
+			if !jsonOutput {
+				printBlocktestResult(result)
+			}
+		}
+	}
+
+	if jsonOutput {
+		out, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		fmt.Printf("%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d blockchain tests failed", failed, passed+failed)
+	}
+	return nil
+}
+
+func printBlocktestResult(result BlocktestResult) {
+	switch {
+	case result.Skipped:
+		fmt.Printf("SKIP %s (%s): %s\n", result.Name, result.File, result.Error)
+	case result.Pass:
+		fmt.Printf("PASS %s (%s)\n", result.Name, result.File)
+	default:
+		fmt.Printf("FAIL %s (%s): %s\n", result.Name, result.File, result.Error)
+		for _, line := range result.PostStateDiff {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
@@ -187,6 +187,7 @@ func init() {
 		EVMInterpreterFlag,
 	}
 	app.Commands = []cli.Command{
+		blockTestCommand,
 		compileCommand,
 		disasmCommand,
 		runCommand,
@@ -6,24 +6,24 @@ import (
 )
 
 var (
-	filesDir   string // Directory when the files should be stored
-	bufferSize int    // Size of buffer in MiB
-	natSetting string // NAT setting
-	port       int    // Listening port
+	filesDir    string   // Directory when the files should be stored
+	bufferSize  int      // Size of buffer in MiB
+	sentryAddrs []string // Addresses of the sentries to connect to
+	chaindata   string   // Path to the database, if bodies need to be downloaded
 )
 
 func init() {
 	downloadCmd.Flags().StringVar(&filesDir, "filesdir", "", "path to directory where files will be stored")
 	downloadCmd.Flags().IntVar(&bufferSize, "buffersize", 512, "size o the buffer in MiB")
-	downloadCmd.Flags().StringVar(&natSetting, "nat", "any", "NAT port mapping mechanism (any|none|upnp|pmp|extip:<IP>)")
-	downloadCmd.Flags().IntVar(&port, "port", 30303, "p2p port number")
+	downloadCmd.Flags().StringSliceVar(&sentryAddrs, "sentry.addr", []string{"localhost:9091"}, "comma separated sentry addresses '<host>:<port>,<host>:<port>'")
+	downloadCmd.Flags().StringVar(&chaindata, "chaindata", "", "path to the database; if set, headers are inserted into the database and bodies are downloaded for them")
 	rootCmd.AddCommand(downloadCmd)
 }
 
 var downloadCmd = &cobra.Command{
 	Use:   "download",
-	Short: "Download headers backwards",
+	Short: "Download headers backwards, using peers of one or more sentries",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return download.Download(natSetting, filesDir, port)
+		return download.Download(filesDir, sentryAddrs, chaindata)
 	},
 }
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"github.com/ledgerwatch/turbo-geth/cmd/headers/download"
+	"github.com/spf13/cobra"
+)
+
+var (
+	natSetting string // NAT setting
+	port       int    // Listening port
+	sentryAddr string // Address of the sentry gRPC API
+)
+
+func init() {
+	sentryCmd.Flags().StringVar(&natSetting, "nat", "any", "NAT port mapping mechanism (any|none|upnp|pmp|extip:<IP>)")
+	sentryCmd.Flags().IntVar(&port, "port", 30303, "p2p port number")
+	sentryCmd.Flags().StringVar(&sentryAddr, "sentry.api.addr", "localhost:9091", "address '<host>:<port>' on which the sentry serves gRPC API for the core node")
+	rootCmd.AddCommand(sentryCmd)
+}
+
+var sentryCmd = &cobra.Command{
+	Use:   "sentry",
+	Short: "Run p2p sentry for the downloader",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return download.Sentry(natSetting, port, sentryAddr)
+	},
+}
@@ -1,33 +1,58 @@
 package download
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"net"
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/ledgerwatch/turbo-geth/common"
-	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/forkid"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/crypto"
 	"github.com/ledgerwatch/turbo-geth/eth"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/metrics"
 	"github.com/ledgerwatch/turbo-geth/p2p"
 	"github.com/ledgerwatch/turbo-geth/p2p/dnsdisc"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
 	"github.com/ledgerwatch/turbo-geth/p2p/nat"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
-	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
+	"google.golang.org/grpc"
 )
 
+const (
+	// inboundBufferSize is the number of inbound messages (and, separately, peer events) buffered by the sentry
+	// for the core node. When the buffer is full (the core node is not connected or too slow), new messages are dropped
+	inboundBufferSize = 1024
+	// peerBackOff is the time (in seconds) after sending a request to a peer, during which no further requests
+	// are sent to it, unless it responds
+	peerBackOff = 5
+)
+
+var messageIdToCode = map[remote.MessageId]uint64{
+	remote.MessageId_NewBlockHashes:  eth.NewBlockHashesMsg,
+	remote.MessageId_NewBlock:        eth.NewBlockMsg,
+	remote.MessageId_BlockHeaders:    eth.BlockHeadersMsg,
+	remote.MessageId_BlockBodies:     eth.BlockBodiesMsg,
+	remote.MessageId_GetBlockHeaders: eth.GetBlockHeadersMsg,
+	remote.MessageId_GetBlockBodies:  eth.GetBlockBodiesMsg,
+}
+
 func nodeKey() *ecdsa.PrivateKey {
 	keyfile := "nodekey"
 	if key, err := crypto.LoadECDSA(keyfile); err == nil {
@@ -44,70 +69,59 @@ func nodeKey() *ecdsa.PrivateKey {
 	return key
 }
 
-// SentryMsg declares ID fields necessary for communicating with the sentry
-type SentryMsg struct {
-	sentryId  int
-	requestId int
-}
-
-// NewBlockFromSentry is a type of message sent from sentry to the downloader as a result of NewBlockMsg
-type NewBlockFromSentry struct {
-	SentryMsg
-	eth.NewBlockData
-}
-
-type NewBlockHashFromSentry struct {
-	SentryMsg
-	eth.NewBlockHashesData
-}
+// SentryServerImpl runs the p2p layer (eth protocol) on behalf of the core node, and is controlled by it via gRPC.
+// Inbound messages and peer events are buffered and streamed to the core node, outbound messages are sent
+// to the peers chosen by the sentry. The p2p server is started after the core node provides its status
+type SentryServerImpl struct {
+	remote.UnimplementedSENTRYServer // must be embedded to have forward compatible implementations.
 
-type BlockHeadersFromSentry struct {
-	SentryMsg
-	headers []*types.Header
+	natSetting    string
+	port          int
+	lock          sync.RWMutex
+	statusData    *remote.StatusData
+	p2pServer     *p2p.Server
+	peerHeightMap sync.Map
+	peerRwMap     sync.Map
+	peerTimeMap   sync.Map
+	peerMap       sync.Map
+	receiveCh     chan *remote.InboundMessage
+	peerEventCh   chan *remote.PeerEvent
 }
 
-type PenaltyMsg struct {
-	SentryMsg
-	penalty headerdownload.Penalty
+func NewSentryServer(natSetting string, port int) *SentryServerImpl {
+	return &SentryServerImpl{
+		natSetting:  natSetting,
+		port:        port,
+		receiveCh:   make(chan *remote.InboundMessage, inboundBufferSize),
+		peerEventCh: make(chan *remote.PeerEvent, inboundBufferSize),
+	}
 }
 
-func makeP2PServer(
-	natSetting string,
-	port int,
-	peerHeightMap *sync.Map,
-	peerTimeMap *sync.Map,
-	peerRwMap *sync.Map,
-	protocols []string,
-	newBlockCh chan NewBlockFromSentry,
-	newBlockHashCh chan NewBlockHashFromSentry,
-	headersCh chan BlockHeadersFromSentry,
-	penaltyCh chan PenaltyMsg,
-) (*p2p.Server, error) {
-	client := dnsdisc.NewClient(dnsdisc.Config{})
-
-	dns := params.KnownDNSNetwork(params.MainnetGenesisHash, "all")
-	dialCandidates, err := client.NewIterator(dns)
-	if err != nil {
-		return nil, fmt.Errorf("create discovery candidates: %v", err)
+func (ss *SentryServerImpl) makeP2PServer(genesisHash common.Hash) (*p2p.Server, error) {
+	var dialCandidates enode.Iterator
+	if dns := params.KnownDNSNetwork(genesisHash, "all"); dns != "" {
+		client := dnsdisc.NewClient(dnsdisc.Config{})
+		var err error
+		if dialCandidates, err = client.NewIterator(dns); err != nil {
+			return nil, fmt.Errorf("create discovery candidates: %v", err)
+		}
 	}
 
-	genesis := core.DefaultGenesisBlock()
 	serverKey := nodeKey()
 	p2pConfig := p2p.Config{}
-	natif, err := nat.Parse(natSetting)
+	natif, err := nat.Parse(ss.natSetting)
 	if err != nil {
-		return nil, fmt.Errorf("invalid nat option %s: %v", natSetting, err)
+		return nil, fmt.Errorf("invalid nat option %s: %v", ss.natSetting, err)
 	}
 	p2pConfig.NAT = natif
 	p2pConfig.PrivateKey = serverKey
-	p2pConfig.Name = "header downloader"
+	p2pConfig.Name = "sentry"
 	p2pConfig.Logger = log.New()
 	p2pConfig.MaxPeers = 100
-	p2pConfig.Protocols = []p2p.Protocol{}
 	p2pConfig.NodeDatabase = "downloader_nodes"
-	p2pConfig.ListenAddr = fmt.Sprintf(":%d", port)
-	pMap := map[string]p2p.Protocol{
-		eth.ProtocolName: {
+	p2pConfig.ListenAddr = fmt.Sprintf(":%d", ss.port)
+	p2pConfig.Protocols = []p2p.Protocol{
+		{
 			Name:           eth.ProtocolName,
 			Version:        eth.ProtocolVersions[0],
 			Length:         eth.ProtocolLengths[eth.ProtocolVersions[0]],
@@ -115,36 +129,25 @@ func makeP2PServer(
 			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
 				peerID := peer.ID().String()
 				log.Info(fmt.Sprintf("[%s] Start with peer", peerID))
-				peerRwMap.Store(peerID, rw)
-				if err := runPeer(
-					peerHeightMap,
-					peerTimeMap,
-					peer,
-					rw,
-					eth.ProtocolVersions[0],
-					eth.DefaultConfig.NetworkID,
-					genesis.Difficulty,
-					params.MainnetGenesisHash,
-					params.MainnetChainConfig,
-					0, /* head */
-					newBlockCh,
-					newBlockHashCh,
-					headersCh,
-					penaltyCh,
-				); err != nil {
+				if err := ss.handshake(peer, rw); err != nil {
+					log.Info(fmt.Sprintf("[%s] Handshake failed: %v", peerID, err))
+					return err
+				}
+				ss.peerRwMap.Store(peerID, rw)
+				ss.peerMap.Store(peerID, peer)
+				ss.sendPeerEvent(&remote.PeerEvent{EventId: remote.PeerEventId_Connect, PeerId: peerID})
+				if err := ss.runPeer(peerID, rw); err != nil {
 					log.Info(fmt.Sprintf("[%s] Error while running peer: %v", peerID, err))
 				}
-				peerHeightMap.Delete(peerID)
-				peerTimeMap.Delete(peerID)
-				peerRwMap.Delete(peerID)
+				ss.peerHeightMap.Delete(peerID)
+				ss.peerTimeMap.Delete(peerID)
+				ss.peerRwMap.Delete(peerID)
+				ss.peerMap.Delete(peerID)
+				ss.sendPeerEvent(&remote.PeerEvent{EventId: remote.PeerEventId_Disconnect, PeerId: peerID})
 				return nil
 			},
 		},
 	}
-
-	for _, protocolName := range protocols {
-		p2pConfig.Protocols = append(p2pConfig.Protocols, pMap[protocolName])
-	}
 	return &p2p.Server{Config: p2pConfig}, nil
 }
 
@@ -152,78 +155,104 @@ func errResp(code int, format string, v ...interface{}) error {
 	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
 }
 
-func runPeer(
-	peerMap *sync.Map,
-	peerTimeMap *sync.Map,
-	peer *p2p.Peer,
-	rw p2p.MsgReadWriter,
-	version uint,
-	networkID uint64,
-	td *big.Int,
-	genesisHash common.Hash,
-	chainConfig *params.ChainConfig,
-	head uint64,
-	newBlockCh chan NewBlockFromSentry,
-	newBlockHashCh chan NewBlockHashFromSentry,
-	headersCh chan BlockHeadersFromSentry,
-	_ chan PenaltyMsg,
-) error {
-	peerID := peer.ID().String()
-	forkId := forkid.NewID(chainConfig, genesisHash, head)
+func (ss *SentryServerImpl) getStatus() *remote.StatusData {
+	ss.lock.RLock()
+	defer ss.lock.RUnlock()
+	return ss.statusData
+}
+
+func (ss *SentryServerImpl) handshake(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+	status := ss.getStatus()
+	if status == nil || status.ForkData == nil {
+		return fmt.Errorf("status of the core node is not known yet")
+	}
+	version := eth.ProtocolVersions[0]
+	networkID := status.NetworkId
+	td := new(big.Int).SetBytes(status.TotalDifficulty)
+	genesisHash := common.BytesToHash(status.ForkData.Genesis)
+	forks := status.ForkData.Forks
 	// Send handshake message
 	if err := p2p.Send(rw, eth.StatusMsg, &eth.StatusData{
 		ProtocolVersion: uint32(version),
 		NetworkID:       networkID,
 		TD:              td,
-		Head:            genesisHash, // For now we always start unsyched
+		Head:            common.BytesToHash(status.BestHash),
 		Genesis:         genesisHash,
-		ForkID:          forkId,
+		ForkID:          forkid.NewIDFromForks(forks, genesisHash, status.MaxBlock),
 	}); err != nil {
-		return fmt.Errorf("handshake to peer %s: %v", peerID, err)
+		return fmt.Errorf("handshake to peer %s: %v", peer.ID(), err)
 	}
 	// Read handshake message
 	msg, err := rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	defer msg.Discard()
 
 	if msg.Code != eth.StatusMsg {
-		msg.Discard()
 		return errResp(eth.ErrNoStatusMsg, "first msg has code %x (!= %x)", msg.Code, eth.StatusMsg)
 	}
 	if msg.Size > eth.ProtocolMaxMsgSize {
-		msg.Discard()
 		return errResp(eth.ErrMsgTooLarge, "message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize)
 	}
 	// Decode the handshake and make sure everything matches
-	var status eth.StatusData
-	if err = msg.Decode(&status); err != nil {
-		msg.Discard()
+	var reply eth.StatusData
+	if err = msg.Decode(&reply); err != nil {
 		return errResp(eth.ErrDecode, "decode message %v: %v", msg, err)
 	}
-	msg.Discard()
-	if status.NetworkID != networkID {
-		return errResp(eth.ErrNetworkIDMismatch, "network id does not match: theirs %d, ours %d", status.NetworkID, networkID)
+	if reply.NetworkID != networkID {
+		return errResp(eth.ErrNetworkIDMismatch, "network id does not match: theirs %d, ours %d", reply.NetworkID, networkID)
 	}
-	if uint(status.ProtocolVersion) != version {
-		return errResp(eth.ErrProtocolVersionMismatch, "version does not match: theirs %d, ours %d", status.ProtocolVersion, version)
+	if uint(reply.ProtocolVersion) != version {
+		return errResp(eth.ErrProtocolVersionMismatch, "version does not match: theirs %d, ours %d", reply.ProtocolVersion, version)
 	}
-	if status.Genesis != genesisHash {
-		return errResp(eth.ErrGenesisMismatch, "genesis hash does not match: theirs %x, ours %x", status.Genesis, genesisHash)
+	if reply.Genesis != genesisHash {
+		return errResp(eth.ErrGenesisMismatch, "genesis hash does not match: theirs %x, ours %x", reply.Genesis, genesisHash)
 	}
-	forkFilter := forkid.NewFilter(chainConfig, genesisHash, head)
-	if err = forkFilter(status.ForkID); err != nil {
+	forkFilter := forkid.NewFilterFromForks(forks, genesisHash, status.MaxBlock)
+	if err = forkFilter(reply.ForkID); err != nil {
 		return errResp(eth.ErrForkIDRejected, "%v", err)
 	}
-	log.Info(fmt.Sprintf("[%s] Received status message OK", peerID), "name", peer.Name())
+	log.Info(fmt.Sprintf("[%s] Received status message OK", peer.ID()), "name", peer.Name())
+	return nil
+}
 
+// updateHeight records that the peer has announced the block with given height, and informs the core node
+// when the highest known block of the peer increases
+func (ss *SentryServerImpl) updateHeight(peerID string, height uint64) {
+	x, _ := ss.peerHeightMap.Load(peerID)
+	highestBlock, _ := x.(uint64)
+	if height <= highestBlock {
+		return
+	}
+	ss.peerHeightMap.Store(peerID, height)
+	ss.sendPeerEvent(&remote.PeerEvent{EventId: remote.PeerEventId_MinBlock, PeerId: peerID, BlockHeight: height})
+}
+
+func (ss *SentryServerImpl) forward(peerID string, id remote.MessageId, data []byte) {
+	select {
+	case ss.receiveCh <- &remote.InboundMessage{Id: id, Data: data, PeerId: peerID}:
+	default:
+		log.Warn(fmt.Sprintf("[%s] Inbound message %s dropped, core node is not receiving", peerID, id))
+	}
+}
+
+func (ss *SentryServerImpl) sendPeerEvent(event *remote.PeerEvent) {
+	select {
+	case ss.peerEventCh <- event:
+	default:
+		log.Debug(fmt.Sprintf("[%s] Peer event %s dropped, core node is not receiving", event.PeerId, event.EventId))
+	}
+}
+
+func (ss *SentryServerImpl) runPeer(peerID string, rw p2p.MsgReadWriter) error {
 	for {
-		msg, err = rw.ReadMsg()
+		msg, err := rw.ReadMsg()
 		if err != nil {
 			return fmt.Errorf("reading message: %v", err)
 		}
 		// Peer responded or sent message - reset the "back off" timer
-		peerTimeMap.Store(peerID, time.Now().Unix())
+		ss.peerTimeMap.Store(peerID, time.Now().Unix())
 		if msg.Size > eth.ProtocolMaxMsgSize {
 			msg.Discard()
 			return errResp(eth.ErrMsgTooLarge, "message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize)
@@ -238,121 +267,57 @@ func runPeer(
 			if err = msg.Decode(&query); err != nil {
 				return errResp(eth.ErrDecode, "decoding GetBlockHeadersMsg %v: %v", msg, err)
 			}
-			log.Info(fmt.Sprintf("[%s] GetBlockHeaderMsg{hash=%x, number=%d, amount=%d, skip=%d, reverse=%t}", peerID, query.Origin.Hash, query.Origin.Number, query.Amount, query.Skip, query.Reverse))
+			log.Trace(fmt.Sprintf("[%s] GetBlockHeaderMsg{hash=%x, number=%d, amount=%d, skip=%d, reverse=%t}", peerID, query.Origin.Hash, query.Origin.Number, query.Amount, query.Skip, query.Reverse))
 			var headers []*types.Header
 			if err = p2p.Send(rw, eth.BlockHeadersMsg, headers); err != nil {
 				return fmt.Errorf("send empty headers reply: %v", err)
 			}
 		case eth.BlockHeadersMsg:
-			var headers []*types.Header
-			if err = msg.Decode(&headers); err != nil {
-				return errResp(eth.ErrDecode, "decoding BlockHeadersMsg %v: %v", msg, err)
-			}
-			var hashesStr strings.Builder
-			for _, header := range headers {
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hash := header.Hash()
-				hashesStr.WriteString(fmt.Sprintf("%x-%x(%d)", hash[:4], hash[28:], header.Number.Uint64()))
-			}
-			log.Info(fmt.Sprintf("[%s] BlockHeadersMsg{%s}", peerID, hashesStr.String()))
-			headersCh <- BlockHeadersFromSentry{SentryMsg: SentryMsg{sentryId: 0, requestId: 0}, headers: headers}
-		case eth.GetBlockBodiesMsg:
-			// Decode the retrieval message
-			msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-			if _, err = msgStream.List(); err != nil {
-				return fmt.Errorf("getting list from RLP stream for GetBlockBodiesMsg: %v", err)
+			b, err := ioutil.ReadAll(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("reading BlockHeadersMsg payload: %v", err)
 			}
-			// Gather blocks until the fetch or network limits is reached
-			var hash common.Hash
-			var hashesStr strings.Builder
-			for {
-				// Retrieve the hash of the next block
-				if err = msgStream.Decode(&hash); errors.Is(err, rlp.EOL) {
-					break
-				} else if err != nil {
-					return errResp(eth.ErrDecode, "decode hash for GetBlockBodiesMsg %v: %v", msg, err)
-				}
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
-			}
-			log.Info(fmt.Sprintf("[%s] GetBlockBodiesMsg {%s}", peerID, hashesStr.String()))
+			ss.forward(peerID, remote.MessageId_BlockHeaders, b)
 		case eth.BlockBodiesMsg:
-			log.Info(fmt.Sprintf("[%s] BlockBodiesMsg", peerID))
-		case eth.GetNodeDataMsg:
-			log.Info(fmt.Sprintf("[%s] GetNodeData", peerID))
-		case eth.GetReceiptsMsg:
-			log.Info(fmt.Sprintf("[%s] GetReceiptsMsg", peerID))
-		case eth.ReceiptsMsg:
-			log.Info(fmt.Sprintf("[%s] ReceiptsMsg", peerID))
+			b, err := ioutil.ReadAll(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("reading BlockBodiesMsg payload: %v", err)
+			}
+			ss.forward(peerID, remote.MessageId_BlockBodies, b)
 		case eth.NewBlockHashesMsg:
+			b, err := ioutil.ReadAll(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("reading NewBlockHashesMsg payload: %v", err)
+			}
 			var announces eth.NewBlockHashesData
-			if err = msg.Decode(&announces); err != nil {
+			if err = rlp.DecodeBytes(b, &announces); err != nil {
 				return errResp(eth.ErrDecode, "decode NewBlockHashesData %v: %v", msg, err)
 			}
-			x, _ := peerMap.Load(peerID)
-			highestBlock, _ := x.(uint64)
-			var numStr strings.Builder
 			for _, announce := range announces {
-				if numStr.Len() > 0 {
-					numStr.WriteString(",")
-				}
-				numStr.WriteString(fmt.Sprintf("%d", announce.Number))
-				if announce.Number > highestBlock {
-					highestBlock = announce.Number
-				}
+				ss.updateHeight(peerID, announce.Number)
 			}
-			peerMap.Store(peerID, highestBlock)
-			log.Info(fmt.Sprintf("[%s] NewBlockHashesMsg {%s}", peerID, numStr.String()))
-			newBlockHashCh <- NewBlockHashFromSentry{SentryMsg: SentryMsg{sentryId: 0, requestId: 0}, NewBlockHashesData: announces}
+			ss.forward(peerID, remote.MessageId_NewBlockHashes, b)
 		case eth.NewBlockMsg:
+			b, err := ioutil.ReadAll(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("reading NewBlockMsg payload: %v", err)
+			}
 			var request eth.NewBlockData
-			if err = msg.Decode(&request); err != nil {
+			if err = rlp.DecodeBytes(b, &request); err != nil {
 				return errResp(eth.ErrDecode, "decode NewBlockMsg %v: %v", msg, err)
 			}
-			blockNum := request.Block.NumberU64()
-			x, _ := peerMap.Load(peerID)
-			highestBlock, _ := x.(uint64)
-			if blockNum > highestBlock {
-				highestBlock = blockNum
-				peerMap.Store(peerID, highestBlock)
-			}
-			log.Info(fmt.Sprintf("[%s] NewBlockMsg{blockNumber: %d}", peerID, blockNum))
-			newBlockCh <- NewBlockFromSentry{SentryMsg: SentryMsg{sentryId: 0, requestId: 0}, NewBlockData: request}
-		case eth.NewPooledTransactionHashesMsg:
-			var hashes []common.Hash
-			if err := msg.Decode(&hashes); err != nil {
-				return errResp(eth.ErrDecode, "decode NewPooledTransactionHashesMsg %v: %v", msg, err)
-			}
-			var hashesStr strings.Builder
-			for _, hash := range hashes {
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
-			}
-			//log.Info(fmt.Sprintf("[%s] NewPooledTransactionHashesMsg {%s}", peerID, hashesStr.String()))
-		case eth.GetPooledTransactionsMsg:
-			//log.Info(fmt.Sprintf("[%s] GetPooledTransactionsMsg", peerID)
-		case eth.TransactionMsg:
-			var txs []*types.Transaction
-			if err := msg.Decode(&txs); err != nil {
-				return errResp(eth.ErrDecode, "decode TransactionMsg %v: %v", msg, err)
-			}
-			var hashesStr strings.Builder
-			for _, tx := range txs {
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hash := tx.Hash()
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
-			}
-			//log.Info(fmt.Sprintf("[%s] TransactionMsg {%s}", peerID, hashesStr.String()))
-		case eth.PooledTransactionsMsg:
-			//log.Info(fmt.Sprintf("[%s] PooledTransactionsMsg", peerID)
+			ss.updateHeight(peerID, request.Block.NumberU64())
+			ss.forward(peerID, remote.MessageId_NewBlock, b)
+		case eth.GetBlockBodiesMsg:
+			log.Trace(fmt.Sprintf("[%s] GetBlockBodiesMsg", peerID))
+		case eth.GetNodeDataMsg:
+			log.Trace(fmt.Sprintf("[%s] GetNodeData", peerID))
+		case eth.GetReceiptsMsg:
+			log.Trace(fmt.Sprintf("[%s] GetReceiptsMsg", peerID))
+		case eth.ReceiptsMsg:
+			log.Trace(fmt.Sprintf("[%s] ReceiptsMsg", peerID))
+		case eth.NewPooledTransactionHashesMsg, eth.GetPooledTransactionsMsg, eth.TransactionMsg, eth.PooledTransactionsMsg:
+			// Transaction propagation is not handled by the sentry yet
 		default:
 			log.Error(fmt.Sprintf("[%s] Unknown message code: %d", peerID, msg.Code))
 		}
@@ -360,6 +325,130 @@ func runPeer(
 	}
 }
 
+func (ss *SentryServerImpl) SetStatus(_ context.Context, statusData *remote.StatusData) (*remote.SetStatusReply, error) {
+	if statusData.ForkData == nil {
+		return &remote.SetStatusReply{}, fmt.Errorf("fork data is missing from the status")
+	}
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	if ss.statusData != nil && ss.p2pServer != nil && !bytes.Equal(ss.statusData.ForkData.Genesis, statusData.ForkData.Genesis) {
+		return &remote.SetStatusReply{}, fmt.Errorf("genesis hash cannot be changed, sentry needs to be restarted")
+	}
+	ss.statusData = statusData
+	if ss.p2pServer == nil {
+		srv, err := ss.makeP2PServer(common.BytesToHash(statusData.ForkData.Genesis))
+		if err != nil {
+			return &remote.SetStatusReply{}, err
+		}
+		if err = srv.Start(); err != nil {
+			return &remote.SetStatusReply{}, fmt.Errorf("could not start p2p server: %w", err)
+		}
+		ss.p2pServer = srv
+		log.Info("Started p2p server", "genesis", common.BytesToHash(statusData.ForkData.Genesis))
+	}
+	return &remote.SetStatusReply{}, nil
+}
+
+func (ss *SentryServerImpl) PenalizePeer(_ context.Context, req *remote.PenalizePeerRequest) (*remote.PenalizePeerReply, error) {
+	log.Warn(fmt.Sprintf("[%s] Received penalty %s", req.PeerId, req.Penalty))
+	if x, ok := ss.peerMap.Load(req.PeerId); ok {
+		// For now, all penalties lead to disconnection
+		x.(*p2p.Peer).Disconnect(p2p.DiscUselessPeer)
+	}
+	return &remote.PenalizePeerReply{}, nil
+}
+
+func (ss *SentryServerImpl) send(peerID string, outreq *remote.OutboundMessageData) error {
+	code, ok := messageIdToCode[outreq.Id]
+	if !ok {
+		return fmt.Errorf("unsupported message id %s", outreq.Id)
+	}
+	rwRaw, ok := ss.peerRwMap.Load(peerID)
+	if !ok {
+		return fmt.Errorf("peer %s not found", peerID)
+	}
+	if err := p2p.Send(rwRaw.(p2p.MsgReadWriter), code, rlp.RawValue(outreq.Data)); err != nil {
+		return fmt.Errorf("failed to send to peer %s: %w", peerID, err)
+	}
+	return nil
+}
+
+func (ss *SentryServerImpl) SendMessageByMinBlock(_ context.Context, inreq *remote.SendMessageByMinBlockRequest) (*remote.SentPeers, error) {
+	if inreq.Data == nil {
+		return &remote.SentPeers{}, fmt.Errorf("message data is missing")
+	}
+	// Choose a peer that we can send this request to
+	var peerID string
+	var found bool
+	now := time.Now().Unix()
+	ss.peerHeightMap.Range(func(key, value interface{}) bool {
+		valUint, _ := value.(uint64)
+		if valUint >= inreq.MinBlock {
+			timeRaw, _ := ss.peerTimeMap.Load(key)
+			t, _ := timeRaw.(int64)
+			// We give the peer a pause before sending another request, unless it responded
+			if t <= now {
+				peerID = key.(string)
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	if !found {
+		return &remote.SentPeers{}, nil
+	}
+	if err := ss.send(peerID, inreq.Data); err != nil {
+		return &remote.SentPeers{}, err
+	}
+	ss.peerTimeMap.Store(peerID, now+peerBackOff)
+	return &remote.SentPeers{Peers: []string{peerID}}, nil
+}
+
+func (ss *SentryServerImpl) SendMessageById(_ context.Context, inreq *remote.SendMessageByIdRequest) (*remote.SentPeers, error) {
+	if inreq.Data == nil {
+		return &remote.SentPeers{}, fmt.Errorf("message data is missing")
+	}
+	if err := ss.send(inreq.PeerId, inreq.Data); err != nil {
+		return &remote.SentPeers{}, err
+	}
+	return &remote.SentPeers{Peers: []string{inreq.PeerId}}, nil
+}
+
+func (ss *SentryServerImpl) ReceiveMessages(_ *remote.ReceiveMessagesRequest, server remote.SENTRY_ReceiveMessagesServer) error {
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case msg := <-ss.receiveCh:
+			if err := server.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (ss *SentryServerImpl) ReceivePeerEvents(_ *remote.ReceivePeerEventsRequest, server remote.SENTRY_ReceivePeerEventsServer) error {
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case event := <-ss.peerEventCh:
+			if err := server.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (ss *SentryServerImpl) Close() {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	if ss.p2pServer != nil {
+		ss.p2pServer.Stop()
+	}
+}
+
 func rootContext() context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
@@ -378,73 +467,46 @@ func rootContext() context.Context {
 	return ctx
 }
 
-func Download(natSetting string, filesDir string, port int) error {
+// Sentry runs the sentry process: gRPC server for the core node listening on sentryAddr, and the p2p server
+// which is started as soon as the core node sets the status
+func Sentry(natSetting string, port int, sentryAddr string) error {
 	ctx := rootContext()
-	newBlockCh := make(chan NewBlockFromSentry)
-	newBlockHashCh := make(chan NewBlockHashFromSentry)
-	penaltyCh := make(chan PenaltyMsg)
-	reqHeadersCh := make(chan headerdownload.HeaderRequest)
-	headersCh := make(chan BlockHeadersFromSentry)
-	var peerHeightMap, peerRwMap, peerTimeMap sync.Map
-	server, err := makeP2PServer(natSetting, port, &peerHeightMap, &peerTimeMap, &peerRwMap, []string{eth.ProtocolName}, newBlockCh, newBlockHashCh, headersCh, penaltyCh)
+	lis, err := net.Listen("tcp", sentryAddr)
 	if err != nil {
-		return err
+		return fmt.Errorf("could not create sentry listener: %w, addr=%s", err, sentryAddr)
 	}
-	// Add protocol
-	if err = server.Start(); err != nil {
-		return fmt.Errorf("could not start server: %w", err)
+	var (
+		streamInterceptors []grpc.StreamServerInterceptor
+		unaryInterceptors  []grpc.UnaryServerInterceptor
+	)
+	if metrics.Enabled {
+		streamInterceptors = append(streamInterceptors, grpc_prometheus.StreamServerInterceptor)
+		unaryInterceptors = append(unaryInterceptors, grpc_prometheus.UnaryServerInterceptor)
+	}
+	streamInterceptors = append(streamInterceptors, grpc_recovery.StreamServerInterceptor())
+	unaryInterceptors = append(unaryInterceptors, grpc_recovery.UnaryServerInterceptor())
+	grpcServer := grpc.NewServer(
+		grpc.NumStreamWorkers(20),  // reduce amount of goroutines
+		grpc.WriteBufferSize(1024), // reduce buffers to save mem
+		grpc.ReadBufferSize(1024),
+		grpc.MaxConcurrentStreams(40), // to force clients reduce concurency level
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
+	)
+	sentryServer := NewSentryServer(natSetting, port)
+	defer sentryServer.Close()
+	remote.RegisterSENTRYServer(grpcServer, sentryServer)
+	if metrics.Enabled {
+		grpc_prometheus.Register(grpcServer)
 	}
-	go Downloader(ctx, filesDir, newBlockCh, newBlockHashCh, headersCh, penaltyCh, reqHeadersCh)
-
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case req := <-penaltyCh:
-				log.Warn(fmt.Sprintf("Received penalty %s for peer %d req %d", req.penalty, req.SentryMsg.sentryId, req.SentryMsg.requestId))
-			case req := <-reqHeadersCh:
-				// Choose a peer that we can send this request to
-				var peerID string
-				var found bool
-				peerHeightMap.Range(func(key, value interface{}) bool {
-					valUint, _ := value.(uint64)
-					if valUint >= req.Number {
-						peerID = key.(string)
-						timeRaw, _ := peerTimeMap.Load(peerID)
-						t, _ := timeRaw.(int64)
-						// If request is large, we give 5 second pause to the peer before sending another request, unless it responded
-						if req.Length == 1 || t <= time.Now().Unix() {
-							found = true
-							return false
-						}
-					}
-					return true
-				})
-				if !found {
-					//log.Warn(fmt.Sprintf("Could not find suitable peer to send GetBlockHeadersData request for block %d", req.Number))
-				} else {
-					log.Info(fmt.Sprintf("Sending req for hash %x, blocknumber %d, length %d to peer %s\n", req.Hash, req.Number, req.Length, peerID))
-					rwRaw, _ := peerRwMap.Load(peerID)
-					rw, _ := rwRaw.(p2p.MsgReadWriter)
-					if rw == nil {
-						log.Error(fmt.Sprintf("Could not find rw for peer %s", peerID))
-					} else {
-						if err := p2p.Send(rw, eth.GetBlockHeadersMsg, &eth.GetBlockHeadersData{
-							Amount:  uint64(req.Length),
-							Reverse: true,
-							Skip:    0,
-							Origin:  eth.HashOrNumber{Hash: req.Hash},
-						}); err != nil {
-							log.Error(fmt.Sprintf("Failed to send to peer %s: %v", peerID, err))
-						}
-						peerTimeMap.Store(peerID, time.Now().Unix()+5)
-					}
-				}
-			}
+		if err1 := grpcServer.Serve(lis); err1 != nil && !errors.Is(err1, grpc.ErrServerStopped) {
+			log.Error("Sentry server fail", "err", err1)
 		}
 	}()
+	log.Info("Sentry started, waiting for the core node to set the status", "addr", sentryAddr)
 
 	<-ctx.Done()
+	grpcServer.GracefulStop()
 	return nil
 }
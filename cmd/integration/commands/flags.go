@@ -16,6 +16,9 @@ var (
 	reset              bool
 	bucket             string
 	datadir            string
+	diffEVM            string
+	diffStop           bool
+	executionWorkers   int
 )
 
 func must(err error) {
@@ -66,3 +69,12 @@ func withDatadir(cmd *cobra.Command) {
 func withHDD(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&hdd, "hdd", false, "optimizations valuable for HDD")
 }
+
+func withDiffEVM(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&diffEVM, "vm.evm.diff", "", "external EVM configuration to re-execute every transaction with, logging the divergences from the built-in interpreter")
+	cmd.Flags().BoolVar(&diffStop, "vm.evm.diff.stop", false, "stop on the first divergence of --vm.evm.diff instead of logging it")
+}
+
+func withExecutionWorkers(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&executionWorkers, "execution.workers", 0, "goroutines executing the transactions of a block speculatively, 0 or 1 to execute them in order")
+}
@@ -7,8 +7,11 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/cmd/utils"
 
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
 	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
 	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/vm"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
@@ -125,6 +128,8 @@ func init() {
 	withBlock(cmdStageExec)
 	withUnwind(cmdStageExec)
 	withHDD(cmdStageExec)
+	withDiffEVM(cmdStageExec)
+	withExecutionWorkers(cmdStageExec)
 
 	rootCmd.AddCommand(cmdStageExec)
 
@@ -196,7 +201,7 @@ func stageSenders(ctx context.Context) error {
 		Now:             time.Now(),
 	}
 
-	return stagedsync.SpawnRecoverSendersStage(cfg, stage3, db, params.MainnetChainConfig, block, datadir, ch)
+	return stagedsync.SpawnRecoverSendersStage(cfg, stage3, db, bc.Config(), block, datadir, ch)
 }
 
 func stageExec(ctx context.Context) error {
@@ -222,9 +227,9 @@ func stageExec(ctx context.Context) error {
 	ch := ctx.Done()
 	if unwind > 0 {
 		u := &stagedsync.UnwindState{Stage: stages.Execution, UnwindPoint: stage4.BlockNumber - unwind}
-		return stagedsync.UnwindExecutionStage(u, stage4, db, false)
+		return stagedsync.UnwindExecutionStage(u, stage4, db, false, nil, nil)
 	}
-	return stagedsync.SpawnExecuteBlocksStage(stage4, db, bc.Config(), bc, bc.GetVMConfig(), block, ch, sm.Receipts, hdd, nil)
+	return stagedsync.SpawnExecuteBlocksStage(stage4, db, bc.Config(), bc, bc.GetVMConfig(), block, ch, sm.Receipts, hdd, nil, nil, nil)
 }
 
 func stageIHash(ctx context.Context) error {
@@ -369,7 +374,7 @@ func newSync(quitCh <-chan struct{}, db ethdb.Database, tx ethdb.Database, hook
 	st, err := stagedsync.New(
 		stagedsync.DefaultStages(),
 		stagedsync.DefaultUnwindOrder(),
-	).Prepare(nil, chainConfig, bc, bc.GetVMConfig(), db, tx, "integration_test", sm, "", false, quitCh, nil, nil, func() error { return nil }, hook)
+	).Prepare(nil, chainConfig, bc, bc.GetVMConfig(), db, tx, "integration_test", sm, "", false, quitCh, nil, nil, func() error { return nil }, hook, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -392,10 +397,21 @@ func newSync(quitCh <-chan struct{}, db ethdb.Database, tx ethdb.Database, hook
 	return bc, st, progress
 }
 
+// newBlockChain uses the chain config stored with the genesis, mainnet if there is none, and the consensus
+// engine of the chain: clique for the proof-of-authority chains, ethash without the seal checks otherwise
 func newBlockChain(db ethdb.Database) (*params.ChainConfig, *core.BlockChain, error) {
-	blockchain, err1 := core.NewBlockChain(db, nil, params.MainnetChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	chainConfig := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if chainConfig == nil {
+		chainConfig = params.MainnetChainConfig
+	}
+	var engine consensus.Engine = ethash.NewFaker()
+	if chainConfig.Clique != nil {
+		engine = clique.New(chainConfig.Clique, db)
+	}
+	vmConfig := vm.Config{DiffEVMInterpreter: diffEVM, DiffStop: diffStop, ExecutionWorkers: executionWorkers}
+	blockchain, err1 := core.NewBlockChain(db, nil, chainConfig, engine, vmConfig, nil, nil)
 	if err1 != nil {
 		return nil, nil, err1
 	}
-	return params.MainnetChainConfig, blockchain, nil
+	return chainConfig, blockchain, nil
 }
@@ -0,0 +1,698 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/holiman/uint256"
+	"github.com/spf13/cobra"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/migrations"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+var (
+	fuzzSeed     int64
+	fuzzCount    int
+	fuzzReplay   string
+	fuzzGenerate int
+)
+
+var stateStagesFuzz = &cobra.Command{
+	Use: "state_stages_fuzz",
+	Short: `Move all StateStages (which happen after senders) forward and unwind them by random numbers of blocks.
+			The numbers are picked from "--seed": up to "--unwind_every" blocks forward, then up to "--unwind" blocks back.
+			After every move checks the hashed state against the plain state, the trie root against the header
+			and the history indices against the changesets.
+			The cycles run in one transaction which is rolled back: reset_state isn't needed to re-run the test.
+			A failing scenario is shrunk to a minimal one, printed as the "--scenario" to reproduce it with.
+			With "--generate" runs on a generated chain in a temporary database instead of "--chaindata".
+		`,
+	Example: "go run ./cmd/integration state_stages_fuzz --chaindata=... --seed=1 --cycles=100 --unwind=5000 --unwind_every=20000 --block=2000000",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := utils.RootContext()
+		if err := fuzzStateStages(ctx); err != nil {
+			log.Error("Error", "err", err)
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	stateStagesFuzz.Flags().StringVar(&chaindata, "chaindata", "", "path to the db, not needed with --generate")
+	must(stateStagesFuzz.MarkFlagDirname("chaindata"))
+	withUnwind(stateStagesFuzz)
+	withUnwindEvery(stateStagesFuzz)
+	withBlock(stateStagesFuzz)
+	withHDD(stateStagesFuzz)
+	withDatadir(stateStagesFuzz)
+	stateStagesFuzz.Flags().Int64Var(&fuzzSeed, "seed", 0, "seed of the random cycles, the current time if 0")
+	stateStagesFuzz.Flags().IntVar(&fuzzCount, "cycles", 100, "number of the random cycles")
+	stateStagesFuzz.Flags().StringVar(&fuzzReplay, "scenario", "", "cycles to run instead of the random ones, as printed for a failed scenario: forward:unwind,...")
+	stateStagesFuzz.Flags().IntVar(&fuzzGenerate, "generate", 0, "generate a chain of this many blocks to run on")
+
+	rootCmd.AddCommand(stateStagesFuzz)
+}
+
+func fuzzStateStages(ctx context.Context) error {
+	var db *ethdb.ObjectDatabase
+	if fuzzGenerate > 0 {
+		dir, err := ioutil.TempDir("", "state_stages_fuzz")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		db = ethdb.MustOpen(dir)
+		if err = generateFuzzChain(db, fuzzGenerate, datadir); err != nil {
+			return err
+		}
+	} else {
+		if chaindata == "" {
+			return fmt.Errorf("--chaindata or --generate is required")
+		}
+		db = ethdb.MustOpen(chaindata)
+	}
+	defer db.Close()
+	core.UsePlainStateExecution = true
+
+	var scenario fuzzCycles
+	if fuzzReplay != "" {
+		var err error
+		if scenario, err = parseFuzzCycles(fuzzReplay); err != nil {
+			return err
+		}
+	} else {
+		if fuzzSeed == 0 {
+			fuzzSeed = time.Now().UnixNano()
+		}
+		if unwindEvery == 0 {
+			return fmt.Errorf("--unwind_every is required")
+		}
+		log.Info("Random cycles", "seed", fuzzSeed)
+		scenario = randomFuzzCycles(rand.New(rand.NewSource(fuzzSeed)), fuzzCount, unwindEvery, unwind)
+	}
+
+	failed, err := runFuzzCycles(ctx, db, scenario, block)
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	log.Error("Scenario failed, shrinking", "cycle", failed, "err", err)
+	scenario = shrinkFuzzCycles(scenario, failed, func(candidate fuzzCycles) (int, bool) {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		failed, err := runFuzzCycles(ctx, db, candidate, block)
+		return failed, err != nil && ctx.Err() == nil
+	})
+	failed, err = runFuzzCycles(ctx, db, scenario, block)
+	if err == nil {
+		return fmt.Errorf("shrunk scenario %s doesn't fail anymore", scenario)
+	}
+	fmt.Printf("Minimal failing scenario: --scenario=%s\n", scenario)
+	return fmt.Errorf("cycle %d of --scenario=%s: %w", failed, scenario, err)
+}
+
+// fuzzCycle moves the stages forward by the given number of blocks, then unwinds them by the other one
+type fuzzCycle struct {
+	forward uint64
+	unwind  uint64
+}
+
+type fuzzCycles []fuzzCycle
+
+func (s fuzzCycles) String() string {
+	parts := make([]string, len(s))
+	for i, c := range s {
+		parts[i] = fmt.Sprintf("%d:%d", c.forward, c.unwind)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseFuzzCycles(s string) (fuzzCycles, error) {
+	var cycles fuzzCycles
+	for _, part := range strings.Split(s, ",") {
+		steps := strings.Split(part, ":")
+		if len(steps) != 2 {
+			return nil, fmt.Errorf("cycle %q is not forward:unwind", part)
+		}
+		forward, err := strconv.ParseUint(steps[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cycle %q: %w", part, err)
+		}
+		unwind, err := strconv.ParseUint(steps[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cycle %q: %w", part, err)
+		}
+		cycles = append(cycles, fuzzCycle{forward, unwind})
+	}
+	return cycles, nil
+}
+
+// randomFuzzCycles picks the steps uniformly, and more often the smallest and the largest ones,
+// where the off-by-one errors are
+func randomFuzzCycles(rnd *rand.Rand, n int, maxForward, maxUnwind uint64) fuzzCycles {
+	steps := func(min, max uint64) uint64 {
+		if max <= min {
+			return min
+		}
+		switch rnd.Intn(4) {
+		case 0:
+			if steps := min + uint64(rnd.Intn(3)); steps < max {
+				return steps
+			}
+			return max
+		case 1:
+			return max
+		default:
+			return min + uint64(rnd.Int63n(int64(max-min+1)))
+		}
+	}
+	cycles := make(fuzzCycles, n)
+	for i := range cycles {
+		cycles[i] = fuzzCycle{forward: steps(1, maxForward), unwind: steps(0, maxUnwind)}
+	}
+	return cycles
+}
+
+// shrinkFuzzCycles drops the cycles after the failed one, then the cycles and the parts of the steps the scenario
+// keeps failing without, and merges the cycles without unwinds into the next ones, for as long as it can.
+// fails runs a scenario and returns the failed cycle.
+func shrinkFuzzCycles(scenario fuzzCycles, failed int, fails func(fuzzCycles) (int, bool)) fuzzCycles {
+	scenario = append(fuzzCycles{}, scenario[:failed+1]...)
+	try := func(candidate fuzzCycles) bool {
+		if failed, ok := fails(candidate); ok {
+			scenario = candidate[:failed+1]
+			return true
+		}
+		return false
+	}
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := 0; i < len(scenario) && len(scenario) > 1; i++ {
+			candidate := append(append(fuzzCycles{}, scenario[:i]...), scenario[i+1:]...)
+			if try(candidate) {
+				shrunk = true
+				i--
+			}
+		}
+		for i := 0; i+1 < len(scenario); i++ {
+			if scenario[i].unwind != 0 {
+				continue
+			}
+			candidate := append(append(fuzzCycles{}, scenario[:i]...), scenario[i+1:]...)
+			candidate[i].forward += scenario[i].forward
+			if try(candidate) {
+				shrunk = true
+				i--
+			}
+		}
+		for i := 0; i < len(scenario); i++ {
+			for _, unwind := range smallerSteps(scenario[i].unwind, 0) {
+				candidate := append(fuzzCycles{}, scenario...)
+				candidate[i].unwind = unwind
+				if try(candidate) {
+					shrunk = true
+					break
+				}
+			}
+			if i >= len(scenario) {
+				break
+			}
+			for _, forward := range smallerSteps(scenario[i].forward, 1) {
+				candidate := append(fuzzCycles{}, scenario...)
+				candidate[i].forward = forward
+				if try(candidate) {
+					shrunk = true
+					break
+				}
+			}
+			if i >= len(scenario) {
+				break
+			}
+		}
+	}
+	return scenario
+}
+
+// smallerSteps - the candidates to replace the steps with, from the smallest
+func smallerSteps(steps, min uint64) []uint64 {
+	var smaller []uint64
+	for _, s := range []uint64{min, min + (steps-min)/2, steps - 1} {
+		if s >= min && s < steps && (len(smaller) == 0 || s > smaller[len(smaller)-1]) {
+			smaller = append(smaller, s)
+		}
+	}
+	return smaller
+}
+
+// runFuzzCycles runs the cycles in a transaction which is rolled back, so every run starts at the same state.
+// It returns the failed cycle along with the error.
+func runFuzzCycles(ctx context.Context, db *ethdb.ObjectDatabase, cycles fuzzCycles, stopAt uint64) (int, error) {
+	sm, err := ethdb.GetStorageModeFromDB(db)
+	if err != nil {
+		return 0, err
+	}
+
+	expectedAccountChanges := make(map[uint64][]byte)
+	expectedStorageChanges := make(map[uint64][]byte)
+	changeSetHook := func(blockNum uint64, csw *state.ChangeSetWriter) {
+		accountChanges, err := csw.GetAccountChanges()
+		if err != nil {
+			panic(err)
+		}
+		expectedAccountChanges[blockNum], err = changeset.EncodeAccountsPlain(accountChanges)
+		if err != nil {
+			panic(err)
+		}
+
+		storageChanges, err := csw.GetStorageChanges()
+		if err != nil {
+			panic(err)
+		}
+		if storageChanges.Len() > 0 {
+			expectedStorageChanges[blockNum], err = changeset.EncodeStoragePlain(storageChanges)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ch := ctx.Done()
+	bc, st, progress := newSync(ch, db, tx, changeSetHook)
+	defer bc.Stop()
+	st.DisableStages(stages.Headers, stages.BlockHashes, stages.Bodies, stages.Senders)
+
+	if senders := progress(stages.Senders).BlockNumber; stopAt == 0 || stopAt > senders {
+		stopAt = senders
+	}
+	var execTo uint64
+	st.MockExecFunc(stages.Execution, func(stageState *stagedsync.StageState, unwinder stagedsync.Unwinder) error {
+		if execTo <= stageState.BlockNumber {
+			stageState.Done()
+			return nil
+		}
+		if err := stagedsync.SpawnExecuteBlocksStage(stageState, tx, bc.Config(), bc, bc.GetVMConfig(), execTo, ch, sm.Receipts, hdd, changeSetHook, nil, nil); err != nil {
+			return fmt.Errorf("spawnExecuteBlocksStage: %w", err)
+		}
+		return nil
+	})
+	run := func() error {
+		if err := st.SetCurrentStage(stages.Headers); err != nil {
+			return err
+		}
+		return st.Run(db, tx)
+	}
+
+	for i, c := range cycles {
+		if err := common.Stopped(ch); err != nil {
+			return i, err
+		}
+		from := progress(stages.Execution).BlockNumber
+		execTo = from + c.forward
+		if execTo > stopAt {
+			execTo = stopAt
+		}
+		if execTo < from {
+			execTo = from
+		}
+		log.Info("Fuzz cycle", "cycle", i, "from", from, "forward", execTo-from, "unwind", c.unwind)
+		if err := run(); err != nil {
+			return i, err
+		}
+		to := progress(stages.Execution).BlockNumber
+		if to != execTo {
+			return i, fmt.Errorf("execution at block %d after moving forward to %d", to, execTo)
+		}
+		for blockN := range expectedAccountChanges {
+			if err := checkChangeSet(tx, blockN, expectedAccountChanges[blockN], expectedStorageChanges[blockN]); err != nil {
+				return i, err
+			}
+			delete(expectedAccountChanges, blockN)
+			delete(expectedStorageChanges, blockN)
+		}
+		changed, err := readChangedKeys(tx, from, to)
+		if err != nil {
+			return i, err
+		}
+		if err := checkStateInvariants(tx, to, changed, sm.History); err != nil {
+			return i, fmt.Errorf("after moving forward from %d to %d: %w", from, to, err)
+		}
+
+		// The stages at block 0 start over as on the initial sync, so they aren't unwound to the genesis
+		unwindTo := uint64(1)
+		if c.unwind < to {
+			unwindTo = to - c.unwind
+		}
+		if c.unwind == 0 || unwindTo >= to {
+			continue
+		}
+		if changed, err = readChangedKeys(tx, unwindTo, to); err != nil {
+			return i, err
+		}
+		if err := st.UnwindTo(unwindTo, tx); err != nil {
+			return i, err
+		}
+		execTo = unwindTo
+		if err := run(); err != nil {
+			return i, err
+		}
+		if at := progress(stages.Execution).BlockNumber; at != unwindTo {
+			return i, fmt.Errorf("execution at block %d after unwinding to %d", at, unwindTo)
+		}
+		if err := checkStateInvariants(tx, unwindTo, changed, sm.History); err != nil {
+			return i, fmt.Errorf("after unwinding from %d to %d: %w", to, unwindTo, err)
+		}
+	}
+	return len(cycles), nil
+}
+
+// changedKeys - the accounts and the storage items changed in the blocks, by the changesets
+type changedKeys struct {
+	accounts map[uint64][][]byte // address
+	storage  map[uint64][][]byte // address + incarnation + key
+}
+
+func readChangedKeys(db ethdb.Getter, from, to uint64) (*changedKeys, error) {
+	changed := &changedKeys{accounts: make(map[uint64][][]byte), storage: make(map[uint64][][]byte)}
+	for _, bucket := range []string{dbutils.PlainAccountChangeSetBucket, dbutils.PlainStorageChangeSetBucket} {
+		keys := changed.accounts
+		if bucket == dbutils.PlainStorageChangeSetBucket {
+			keys = changed.storage
+		}
+		walker := changeset.Mapper[bucket].WalkerAdapter
+		if err := db.Walk(bucket, dbutils.EncodeTimestamp(from+1), 0, func(k, v []byte) (bool, error) {
+			blockNum, _ := dbutils.DecodeTimestamp(k)
+			if blockNum > to {
+				return false, nil
+			}
+			return true, walker(v).Walk(func(key, _ []byte) error {
+				keys[blockNum] = append(keys[blockNum], common.CopyBytes(key))
+				return nil
+			})
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// checkStateInvariants checks the state of the stages at the block: the hashed state of the changed keys
+// against the plain state, the trie root against the header, and the history indices against the changesets
+// of the changed keys, which are either in the history before the block or unwound
+func checkStateInvariants(db ethdb.Database, blockNum uint64, changed *changedKeys, history bool) error {
+	for _, keys := range []map[uint64][][]byte{changed.accounts, changed.storage} {
+		for _, blockKeys := range keys {
+			for _, key := range blockKeys {
+				if err := checkHashedState(db, key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	loader := trie.NewFlatDBTrieLoader(dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
+	if err := loader.Reset(trie.NewRetainList(0), nil /* HashCollector */, false); err != nil {
+		return err
+	}
+	root, err := loader.CalcTrieRoot(db, nil)
+	if err != nil {
+		return err
+	}
+	header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, blockNum), blockNum)
+	if header == nil {
+		return fmt.Errorf("no canonical header %d", blockNum)
+	}
+	if root != header.Root {
+		return fmt.Errorf("trie root %x, expected (from header %d): %x", root, blockNum, header.Root)
+	}
+
+	if !history {
+		return nil
+	}
+	if err := checkHistoryIndex(db, dbutils.AccountsHistoryBucket, changed.accounts, blockNum); err != nil {
+		return err
+	}
+	return checkHistoryIndex(db, dbutils.StorageHistoryBucket, changed.storage, blockNum)
+}
+
+// checkHashedState compares the value of the plain state key with the one of the hashed key, and the code
+// of the account in both buckets
+func checkHashedState(db ethdb.Getter, key []byte) error {
+	var hashedKey []byte
+	if len(key) == common.AddressLength {
+		addrHash, err := common.HashData(key)
+		if err != nil {
+			return err
+		}
+		hashedKey = addrHash[:]
+	} else {
+		address, incarnation, storageKey := dbutils.PlainParseCompositeStorageKey(key)
+		addrHash, err := common.HashData(address[:])
+		if err != nil {
+			return err
+		}
+		keyHash, err := common.HashData(storageKey[:])
+		if err != nil {
+			return err
+		}
+		hashedKey = dbutils.GenerateCompositeStorageKey(addrHash, incarnation, keyHash)
+	}
+	plain, err := getOrNil(db, dbutils.PlainStateBucket, key)
+	if err != nil {
+		return err
+	}
+	hashed, err := getOrNil(db, dbutils.CurrentStateBucket, hashedKey)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(plain, hashed) {
+		return fmt.Errorf("plain state of %x: %x, hashed state of %x: %x", key, plain, hashedKey, hashed)
+	}
+	if len(key) != common.AddressLength || plain == nil {
+		return nil
+	}
+
+	var acc accounts.Account
+	if err = acc.DecodeForStorage(plain); err != nil {
+		return err
+	}
+	if acc.Incarnation == 0 {
+		return nil
+	}
+	plainCode, err := getOrNil(db, dbutils.PlainContractCodeBucket, dbutils.PlainGenerateStoragePrefix(key, acc.Incarnation))
+	if err != nil {
+		return err
+	}
+	hashedCode, err := getOrNil(db, dbutils.ContractCodeBucket, dbutils.GenerateStoragePrefix(hashedKey, acc.Incarnation))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(plainCode, hashedCode) {
+		return fmt.Errorf("plain code hash of %x: %x, hashed: %x", key, plainCode, hashedCode)
+	}
+	return nil
+}
+
+func getOrNil(db ethdb.Getter, bucket string, key []byte) ([]byte, error) {
+	v, err := db.Get(bucket, key)
+	if err != nil && !errors.Is(err, ethdb.ErrKeyNotFound) {
+		return nil, err
+	}
+	return v, nil
+}
+
+// checkHistoryIndex checks that the index of every key has the blocks up to blockNum in which the key changed,
+// and none after it
+func checkHistoryIndex(db ethdb.Getter, indexBucket string, keys map[uint64][][]byte, blockNum uint64) error {
+	index := func(key []byte, from uint64) (*roaring.Bitmap, error) {
+		chunk, err := db.GetIndexChunk(indexBucket, key, from)
+		if err != nil {
+			if errors.Is(err, ethdb.ErrKeyNotFound) {
+				return roaring.New(), nil
+			}
+			return nil, err
+		}
+		bm := roaring.New()
+		return bm, bm.UnmarshalBinary(chunk)
+	}
+	for changedAt, blockKeys := range keys {
+		for _, key := range blockKeys {
+			bm, err := index(key, changedAt)
+			if err != nil {
+				return err
+			}
+			found, ok := bitmapdb.SeekInBitmap(bm, changedAt)
+			if changedAt <= blockNum && (!ok || found != changedAt) {
+				return fmt.Errorf("%s of %x doesn't have block %d of the changeset", indexBucket, key, changedAt)
+			}
+			if bm, err = index(key, blockNum+1); err != nil {
+				return err
+			}
+			if found, ok = bitmapdb.SeekInBitmap(bm, blockNum+1); ok {
+				return fmt.Errorf("%s of %x has block %d after the unwind to %d", indexBucket, key, found, blockNum)
+			}
+		}
+	}
+	return nil
+}
+
+// generateFuzzChain writes a generated chain to the empty database with the stages before Execution done.
+// It is called before core.UsePlainStateExecution is set: the genesis is committed to the hashed state too,
+// which the roots of the blocks are calculated from.
+// The blocks move ether between a small set of accounts, write the storage of contracts which are created
+// and destroyed along the chain, so the unwinds hit all the buckets of the state.
+func generateFuzzChain(db *ethdb.ObjectDatabase, n int, datadir string) error {
+	if err := migrations.NewMigrator().Apply(db, datadir); err != nil {
+		return err
+	}
+	if err := ethdb.SetStorageModeIfNotExist(db, ethdb.StorageMode{History: true, Receipts: true, TxIndex: true}); err != nil {
+		return err
+	}
+
+	var keys []*ecdsa.PrivateKey
+	gspec := &core.Genesis{Config: params.AllEthashProtocolChanges, Alloc: core.GenesisAlloc{}}
+	for i := 0; i < 8; i++ {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("state_stages_fuzz %d", i))))
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		gspec.Alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	genesis, _, err := gspec.Commit(db, false /* history */)
+	if err != nil {
+		return err
+	}
+
+	// SSTORE(NUMBER, 1) without the call data, SELFDESTRUCT(CALLER) with it
+	runtimeCode := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 9, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.NUMBER), byte(vm.SSTORE), byte(vm.STOP),
+		byte(vm.JUMPDEST), byte(vm.CALLER), byte(vm.SELFDESTRUCT),
+	}
+	initCode := append([]byte{
+		byte(vm.PUSH1), byte(len(runtimeCode)), byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(runtimeCode)), byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}, runtimeCode...)
+
+	signer := types.MakeSigner(gspec.Config, big.NewInt(1))
+	genDb := ethdb.NewMemDatabase()
+	defer genDb.Close()
+	var contracts []common.Address
+	var genErr error
+	blocks, _, err := core.GenerateChain(gspec.Config, gspec.MustCommit(genDb), ethash.NewFaker(), genDb, n, func(i int, gen *core.BlockGen) {
+		tx := func(key *ecdsa.PrivateKey, to *common.Address, value uint64, data []byte) {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			var tx *types.Transaction
+			if to == nil {
+				tx = types.NewContractCreation(gen.TxNonce(from), uint256.NewInt(), 200000, uint256.NewInt().SetUint64(1), data)
+			} else {
+				tx = types.NewTransaction(gen.TxNonce(from), *to, uint256.NewInt().SetUint64(value), 100000, uint256.NewInt().SetUint64(1), data)
+			}
+			signed, err := types.SignTx(tx, signer, key)
+			if err != nil {
+				genErr = err
+				return
+			}
+			gen.AddTx(signed)
+		}
+		// the recipients change every few blocks, the senders - in every block
+		to := common.BytesToAddress([]byte{byte(i * 7 % 16)})
+		tx(keys[i%4], &to, uint64(i+1), nil)
+		for j, contract := range contracts {
+			if (i+j)%3 == 0 {
+				tx(keys[4+j%2], &contract, 0, nil)
+			}
+		}
+		if i%10 == 0 {
+			contracts = append(contracts, crypto.CreateAddress(crypto.PubkeyToAddress(keys[6].PublicKey), gen.TxNonce(crypto.PubkeyToAddress(keys[6].PublicKey))))
+			tx(keys[6], nil, 0, initCode)
+		}
+		if i%10 == 7 && len(contracts) > 1 {
+			tx(keys[7], &contracts[0], 0, []byte{1})
+			contracts = contracts[1:]
+		}
+	}, false /* intermediateHashes */)
+	if err != nil {
+		return err
+	}
+	if genErr != nil {
+		return genErr
+	}
+
+	chainConfig, bc, err := newBlockChain(db)
+	if err != nil {
+		return err
+	}
+	defer bc.Stop()
+	headers := make([]*types.Header, len(blocks))
+	for i, b := range blocks {
+		headers[i] = b.Header()
+	}
+	if _, _, err = stagedsync.InsertHeaderChain(db, headers, chainConfig, ethash.NewFaker(), 1); err != nil {
+		return err
+	}
+	last := uint64(len(blocks))
+	if err = stages.SaveStageProgress(db, stages.Headers, last, nil); err != nil {
+		return err
+	}
+	if err = stagedsync.SpawnBlockHashStage(&stagedsync.StageState{Stage: stages.BlockHashes}, db, datadir, nil); err != nil {
+		return err
+	}
+	if _, err = bc.InsertBodyChain(context.Background(), blocks); err != nil {
+		return err
+	}
+	if err = stages.SaveStageProgress(db, stages.Bodies, last, nil); err != nil {
+		return err
+	}
+	cfg := stagedsync.Stage3Config{
+		BatchSize:       10000,
+		BlockSize:       4096,
+		BufferSize:      (4096 * 10 / 20) * 10000,
+		NumOfGoroutines: runtime.NumCPU(),
+		ReadChLen:       4,
+		Now:             time.Now(),
+	}
+	if err = stagedsync.SpawnRecoverSendersStage(cfg, &stagedsync.StageState{Stage: stages.Senders}, db, chainConfig, 0, datadir, nil); err != nil {
+		return err
+	}
+	log.Info("Generated chain", "blocks", last, "genesis", genesis.Hash())
+	return nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestStateStagesFuzz(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_stages_fuzz")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db := ethdb.MustOpen(dir)
+	defer db.Close()
+	require.NoError(t, generateFuzzChain(db, 100, dir))
+
+	defer func(usePlainStateExecution bool) { core.UsePlainStateExecution = usePlainStateExecution }(core.UsePlainStateExecution)
+	core.UsePlainStateExecution = true
+	cycles := randomFuzzCycles(rand.New(rand.NewSource(1)), 30, 25, 30)
+	failed, err := runFuzzCycles(context.Background(), db, cycles, 0)
+	require.NoError(t, err, "cycle %d of --scenario=%s", failed, cycles)
+
+	// the cycles ran in a transaction which was rolled back
+	failed, err = runFuzzCycles(context.Background(), db, fuzzCycles{{100, 99}, {100, 0}}, 0)
+	require.NoError(t, err, "cycle %d", failed)
+}
+
+func TestShrinkFuzzCycles(t *testing.T) {
+	// fails when the stages are unwound by 5 blocks or more after reaching block 10
+	fails := func(cycles fuzzCycles) (int, bool) {
+		var at uint64
+		for i, c := range cycles {
+			at += c.forward
+			if at >= 10 && c.unwind >= 5 {
+				return i, true
+			}
+			if c.unwind < at {
+				at -= c.unwind
+			} else {
+				at = 0
+			}
+		}
+		return 0, false
+	}
+	scenario, err := parseFuzzCycles("3:1,4:0,7:2,6:8,9:9")
+	require.NoError(t, err)
+	failed, ok := fails(scenario)
+	require.True(t, ok)
+	shrunk := shrinkFuzzCycles(scenario, failed, fails)
+	require.Equal(t, "10:5", shrunk.String())
+}
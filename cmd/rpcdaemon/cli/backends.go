@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/node"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+// maxRequestContentLength - as in the rpc package, the requests are kept to be sent to another backend
+const maxRequestContentLength = 1024 * 1024 * 5
+
+// Backends - the turbo-geth nodes the daemon reads from. Every request is served by one of them: the healthy one
+// with the highest synced head, so the block numbers the request reads, like the latest one, are those of that
+// node. If the node goes away during the request, the request is served again by the next one.
+type Backends struct {
+	nodes []*backendNode
+}
+
+type backendNode struct {
+	addr    string
+	remote  ethdb.KV // the connection to the node, read by the health checks
+	kv      ethdb.KV
+	eth     ethdb.Backend
+	head    uint64 // progress of stages.Finish at the last health check
+	healthy int32
+
+	lock     sync.Mutex
+	requests map[*backendRequest]struct{} // in flight
+
+	rpc, ws http.Handler
+}
+
+// backendRequest - a request served by a node. The APIs don't pass the context of the request to the database,
+// so the failures can't be told apart by the request: the node going away fails all the requests in flight on it.
+type backendRequest struct {
+	failed int32
+}
+
+// OpenBackends connects to the nodes of the comma separated --private.api.addr and checks their health
+func OpenBackends(cfg Flags) (*Backends, error) {
+	b := &Backends{}
+	for _, addr := range strings.Split(cfg.PrivateApiAddr, ",") {
+		addr = strings.TrimSpace(addr)
+		kv, eth, err := ethdb.NewRemote().Path(addr).WithToken(cfg.PrivateApiToken).Open(cfg.TLSCertfile, cfg.TLSKeyFile, cfg.TLSCACert)
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+		node := &backendNode{addr: addr, remote: kv, requests: map[*backendRequest]struct{}{}}
+		node.kv = &backendKV{KV: kv, node: node}
+		node.eth = &backendEth{Backend: eth, node: node}
+		b.nodes = append(b.nodes, node)
+	}
+	b.checkHealth(time.Second)
+	return b, nil
+}
+
+func (b *Backends) Close() {
+	for _, node := range b.nodes {
+		node.kv.Close()
+	}
+}
+
+// registerAPIs creates the rpc server of every node, the transactions sent through any of them go to all the nodes
+func (b *Backends) registerAPIs(cfg Flags, apiList func(db ethdb.KV, eth ethdb.Backend) []rpc.API) error {
+	for i, n := range b.nodes {
+		srv := rpc.NewServer()
+		if err := node.RegisterApisFromWhitelist(apiList(n.kv, &fanOutEth{Backend: n.eth, nodes: b.nodes, own: i}), cfg.API, srv, false); err != nil {
+			return fmt.Errorf("could not start register RPC apis: %w", err)
+		}
+		n.rpc = srv
+		if cfg.WebsocketEnabled {
+			n.ws = srv.WebsocketHandler([]string{"*"})
+		}
+	}
+	return nil
+}
+
+// checkHealth reads the head of every node, the nodes which don't reply within the timeout are not used
+// until they do
+func (b *Backends) checkHealth(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, node := range b.nodes {
+		wg.Add(1)
+		go func(node *backendNode) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			var head uint64
+			if err := node.remote.View(ctx, func(tx ethdb.Tx) error {
+				var err error
+				head, _, err = stages.GetStageProgress(txReader{tx}, stages.Finish)
+				return err
+			}); err != nil {
+				node.unhealthy(err)
+				return
+			}
+			atomic.StoreUint64(&node.head, head)
+			if atomic.CompareAndSwapInt32(&node.healthy, 0, 1) {
+				log.Info("Backend is available", "addr", node.addr, "head", head)
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
+// CheckHealthEvery checks the health of the nodes until the context is cancelled
+func (b *Backends) CheckHealthEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.checkHealth(interval)
+		}
+	}
+}
+
+// fail takes the node out of the routing if it is unavailable, the requests in flight on it are served again
+func (n *backendNode) fail(err error) {
+	if !isUnavailable(err) {
+		return
+	}
+	n.lock.Lock()
+	for req := range n.requests {
+		atomic.StoreInt32(&req.failed, 1)
+	}
+	n.lock.Unlock()
+	n.unhealthy(err)
+}
+
+// unhealthy takes the node out of the routing until its health check succeeds
+func (n *backendNode) unhealthy(err error) {
+	if atomic.CompareAndSwapInt32(&n.healthy, 1, 0) {
+		log.Warn("Backend is not available", "addr", n.addr, "err", err)
+	}
+}
+
+// serve serves the request by the node, it returns false if the node went away in the meantime
+func (n *backendNode) serve(w http.ResponseWriter, r *http.Request) bool {
+	req := &backendRequest{}
+	n.lock.Lock()
+	n.requests[req] = struct{}{}
+	n.lock.Unlock()
+	defer func() {
+		n.lock.Lock()
+		delete(n.requests, req)
+		n.lock.Unlock()
+	}()
+	n.rpc.ServeHTTP(w, r)
+	return atomic.LoadInt32(&req.failed) == 0
+}
+
+func isUnavailable(err error) bool {
+	var s interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &s) && s.GRPCStatus().Code() == codes.Unavailable
+}
+
+// order - the nodes to serve a request, in the order they are tried: the healthy ones from the highest head,
+// then the others, in case their health check is late
+func (b *Backends) order() []*backendNode {
+	nodes := make([]*backendNode, len(b.nodes))
+	copy(nodes, b.nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		iHealthy, jHealthy := atomic.LoadInt32(&nodes[i].healthy) == 1, atomic.LoadInt32(&nodes[j].healthy) == 1
+		if iHealthy != jHealthy {
+			return iHealthy
+		}
+		return iHealthy && atomic.LoadUint64(&nodes[i].head) > atomic.LoadUint64(&nodes[j].head)
+	})
+	return nodes
+}
+
+func (b *Backends) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestContentLength))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	nodes := b.order()
+	for i, node := range nodes {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp := &bufferedResponse{header: http.Header{}}
+		if !node.serve(resp, r) && i < len(nodes)-1 {
+			log.Debug("Backend failed, sending the request to the next one", "addr", node.addr)
+			continue
+		}
+		resp.writeTo(w)
+		return
+	}
+}
+
+// WebsocketHandler - the connections are served by the node with the highest head at the time they are opened.
+// They are not moved to another node when theirs goes away: the subscriptions live on the connection, and the
+// requests on it are not kept to be sent again. The client has to reconnect, and gets a node which is available.
+func (b *Backends) WebsocketHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.order()[0].ws.ServeHTTP(w, r)
+	})
+}
+
+// bufferedResponse keeps the response until it is known that the node didn't go away while serving the request
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header         { return r.header }
+func (r *bufferedResponse) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *bufferedResponse) WriteHeader(status int)      { r.status = status }
+
+func (r *bufferedResponse) writeTo(w http.ResponseWriter) {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+	_, _ = w.Write(r.body.Bytes())
+}
+
+// txReader - the reader of the stage progress in the transaction of the health check
+type txReader struct {
+	ethdb.Tx
+}
+
+func (r txReader) Has(bucket string, key []byte) (bool, error) {
+	v, err := r.Get(bucket, key)
+	return v != nil, err
+}
+
+// backendKV takes the node out of the routing when it is not available
+type backendKV struct {
+	ethdb.KV
+	node *backendNode
+}
+
+func (kv *backendKV) View(ctx context.Context, f func(tx ethdb.Tx) error) error {
+	err := kv.KV.View(ctx, f)
+	if err != nil {
+		kv.node.fail(err)
+	}
+	return err
+}
+
+// backendEth takes the node out of the routing when it is not available
+type backendEth struct {
+	ethdb.Backend
+	node *backendNode
+}
+
+func (eth *backendEth) NetVersion() (uint64, error) {
+	v, err := eth.Backend.NetVersion()
+	if err != nil {
+		eth.node.fail(err)
+	}
+	return v, err
+}
+
+func (eth *backendEth) SyncStatus() (*ethdb.SyncStatus, error) {
+	s, err := eth.Backend.SyncStatus()
+	if err != nil {
+		eth.node.fail(err)
+	}
+	return s, err
+}
+
+func (eth *backendEth) PoolContent() (pending, queued []ethdb.PoolTransaction, err error) {
+	if pending, queued, err = eth.Backend.PoolContent(); err != nil {
+		eth.node.fail(err)
+	}
+	return pending, queued, err
+}
+
+func (eth *backendEth) PoolStatus() (pending, queued uint64, err error) {
+	if pending, queued, err = eth.Backend.PoolStatus(); err != nil {
+		eth.node.fail(err)
+	}
+	return pending, queued, err
+}
+
+func (eth *backendEth) PoolInspect() (pending, queued []ethdb.PoolInspectItem, err error) {
+	if pending, queued, err = eth.Backend.PoolInspect(); err != nil {
+		eth.node.fail(err)
+	}
+	return pending, queued, err
+}
+
+// fanOutEth sends the transactions to all the nodes, so they are not lost when the node serving the request goes
+// away. The failures are not reported to the routing: the transaction is not sent again with the request. If the
+// request is served again anyway, because its node went away in the meantime, the nodes already know the
+// transaction, and that is not an error. The request can't be told from the client sending the transaction again,
+// so that isn't an error either.
+type fanOutEth struct {
+	ethdb.Backend
+	nodes []*backendNode
+	own   int // the node serving the request
+}
+
+func (eth *fanOutEth) AddLocal(signedTx []byte) ([]byte, error) {
+	hashes := make([][]byte, len(eth.nodes))
+	errs := make([]error, len(eth.nodes))
+	var wg sync.WaitGroup
+	for i, node := range eth.nodes {
+		wg.Add(1)
+		go func(i int, node *backendNode) {
+			defer wg.Done()
+			hashes[i], errs[i] = node.eth.(*backendEth).Backend.AddLocal(signedTx)
+			if errs[i] != nil {
+				log.Debug("Backend didn't accept the transaction", "addr", node.addr, "err", errs[i])
+			}
+		}(i, node)
+	}
+	wg.Wait()
+	if errs[eth.own] == nil {
+		return hashes[eth.own], nil
+	}
+	for i := range eth.nodes {
+		if errs[i] == nil {
+			return hashes[i], nil
+		}
+	}
+	for i := range eth.nodes {
+		if isAlreadyKnown(errs[i]) {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(signedTx); err != nil {
+				return hashes[eth.own], err
+			}
+			return tx.Hash().Bytes(), nil
+		}
+	}
+	return hashes[eth.own], errs[eth.own]
+}
+
+// isAlreadyKnown - the error of the pool for the transactions it already has, as the message of the gRPC status
+// when it comes from the remote backend
+func isAlreadyKnown(err error) bool {
+	return err != nil && status.Convert(err).Message() == core.ErrAlreadyKnown.Error()
+}
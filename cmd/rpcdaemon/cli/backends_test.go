@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/common/u256"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+// testAPI - the head of the node serving the request, and the transactions
+type testAPI struct {
+	db  ethdb.KV
+	eth ethdb.Backend
+}
+
+func (api *testAPI) Head(_ context.Context) (uint64, error) {
+	head, _, err := stages.GetStageProgress(ethdb.NewObjectDatabase(api.db), stages.Finish)
+	return head, err
+}
+
+func (api *testAPI) Send(_ context.Context, tx hexutil.Bytes) (common.Hash, error) {
+	hash, err := api.eth.AddLocal(tx)
+	return common.BytesToHash(hash), err
+}
+
+// testEth - the transaction pool of the node, which accepts the transactions until it is stopped
+type testEth struct {
+	ethdb.Backend
+	lock    sync.Mutex
+	stopped bool
+	txs     map[common.Hash]struct{}
+	onAdd   func() // called when a transaction is accepted
+}
+
+func (eth *testEth) AddLocal(encoded []byte) ([]byte, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encoded); err != nil {
+		return nil, err
+	}
+	eth.lock.Lock()
+	defer eth.lock.Unlock()
+	if eth.stopped {
+		return nil, errors.New("stopped")
+	}
+	if _, ok := eth.txs[tx.Hash()]; ok {
+		return common.Hash{}.Bytes(), core.ErrAlreadyKnown
+	}
+	eth.txs[tx.Hash()] = struct{}{}
+	if eth.onAdd != nil {
+		eth.onAdd()
+	}
+	return tx.Hash().Bytes(), nil
+}
+
+func (eth *testEth) stop() {
+	eth.lock.Lock()
+	defer eth.lock.Unlock()
+	eth.stopped = true
+}
+
+func (eth *testEth) count() int {
+	eth.lock.Lock()
+	defer eth.lock.Unlock()
+	return len(eth.txs)
+}
+
+var testKey, _ = crypto.GenerateKey()
+
+func testTx(t *testing.T, nonce uint64) (hexutil.Bytes, common.Hash) {
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{1}, u256.Num1, params.TxGas, u256.Num1, nil), types.HomesteadSigner{}, testKey)
+	require.NoError(t, err)
+	encoded, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return encoded, tx.Hash()
+}
+
+// startTestNode serves the database at the given head on a local port
+func startTestNode(t *testing.T, head uint64) (string, *grpc.Server, func()) {
+	kv := ethdb.NewLMDB().InMem().MustOpen()
+	require.NoError(t, stages.SaveStageProgress(ethdb.NewObjectDatabase(kv), stages.Finish, head, nil))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	remote.RegisterKVServer(grpcServer, remotedbserver.NewKvServer(kv))
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	return lis.Addr().String(), grpcServer, func() {
+		grpcServer.Stop()
+		kv.Close()
+	}
+}
+
+// startTestBackends serves the APIs of the nodes at the heads 5 and 7
+func startTestBackends(t *testing.T) (*Backends, []*testEth, []*grpc.Server, *rpc.Client, func()) {
+	addr1, server1, stop1 := startTestNode(t, 5)
+	addr2, server2, stop2 := startTestNode(t, 7)
+	cfg := Flags{PrivateApiAddr: addr1 + "," + addr2, API: []string{"test"}}
+	backends, err := OpenBackends(cfg)
+	require.NoError(t, err)
+	eths := []*testEth{{txs: map[common.Hash]struct{}{}}, {txs: map[common.Hash]struct{}{}}}
+	for i, node := range backends.nodes {
+		node.eth = &backendEth{Backend: eths[i], node: node}
+	}
+	require.NoError(t, backends.registerAPIs(cfg, func(db ethdb.KV, eth ethdb.Backend) []rpc.API {
+		return []rpc.API{{Namespace: "test", Public: true, Service: &testAPI{db: db, eth: eth}, Version: "1.0"}}
+	}))
+	server := httptest.NewServer(backends)
+	client, err := rpc.Dial(server.URL)
+	require.NoError(t, err)
+	return backends, eths, []*grpc.Server{server1, server2}, client, func() {
+		client.Close()
+		server.Close()
+		backends.Close()
+		stop2()
+		stop1()
+	}
+}
+
+func TestBackendsFailover(t *testing.T) {
+	backends, eths, servers, client, stop := startTestBackends(t)
+	defer stop()
+
+	// the node with the highest head serves the requests
+	var head uint64
+	require.NoError(t, client.Call(&head, "test_head"))
+	require.Equal(t, uint64(7), head)
+
+	// the transactions go to all the nodes
+	var hash common.Hash
+	tx1, hash1 := testTx(t, 0)
+	require.NoError(t, client.Call(&hash, "test_send", tx1))
+	require.Equal(t, hash1, hash)
+	require.Equal(t, 1, eths[0].count())
+	require.Equal(t, 1, eths[1].count())
+
+	// the request to the node which went away is served by the other one
+	servers[1].Stop()
+	eths[1].stop()
+	require.NoError(t, client.Call(&head, "test_head"))
+	require.Equal(t, uint64(5), head)
+	require.Equal(t, backends.nodes[0].addr, backends.order()[0].addr)
+	backends.checkHealth(time.Second)
+	require.Equal(t, int32(0), atomic.LoadInt32(&backends.nodes[1].healthy))
+
+	tx2, hash2 := testTx(t, 1)
+	require.NoError(t, client.Call(&hash, "test_send", tx2))
+	require.Equal(t, hash2, hash)
+	require.Equal(t, 2, eths[0].count())
+
+	// the transaction is accepted by another node, if the one serving the request doesn't
+	fanOut := &fanOutEth{Backend: backends.nodes[1].eth, nodes: backends.nodes, own: 1}
+	tx3, hash3 := testTx(t, 2)
+	h, err := fanOut.AddLocal(tx3)
+	require.NoError(t, err)
+	require.Equal(t, hash3.Bytes(), h)
+	require.Equal(t, 3, eths[0].count())
+}
+
+func TestBackendsFailoverDuringSend(t *testing.T) {
+	backends, eths, _, client, stop := startTestBackends(t)
+	defer stop()
+
+	// the node serving the request goes away after it accepted the transaction, the request is served again by the
+	// other node, which got the transaction too
+	var failed int32
+	eths[1].onAdd = func() {
+		if atomic.CompareAndSwapInt32(&failed, 0, 1) {
+			backends.nodes[1].fail(status.Error(codes.Unavailable, "node went away"))
+		}
+	}
+	var hash common.Hash
+	tx, txHash := testTx(t, 0)
+	require.NoError(t, client.Call(&hash, "test_send", tx))
+	require.Equal(t, txHash, hash)
+	require.Equal(t, int32(1), atomic.LoadInt32(&failed))
+	require.Equal(t, int32(0), atomic.LoadInt32(&backends.nodes[1].healthy))
+	require.Equal(t, 1, eths[0].count())
+	require.Equal(t, 1, eths[1].count())
+
+	// the transaction sent again by the client is known to all the nodes, which is not an error either
+	hash = common.Hash{}
+	require.NoError(t, client.Call(&hash, "test_send", tx))
+	require.Equal(t, txHash, hash)
+}
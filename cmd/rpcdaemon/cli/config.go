@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ledgerwatch/turbo-geth/cmd/utils"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
@@ -15,20 +16,22 @@ import (
 )
 
 type Flags struct {
-	PrivateApiAddr    string
-	Chaindata         string
-	HttpListenAddress string
-	TLSCertfile       string
-	TLSCACert         string
-	TLSKeyFile        string
-	HttpPort          int
-	HttpCORSDomain    []string
-	HttpVirtualHost   []string
-	API               []string
-	Gascap            uint64
-	MaxTraces         uint64
-	TraceType         string
-	WebsocketEnabled  bool
+	PrivateApiAddr      string
+	PrivateApiToken     string
+	Chaindata           string
+	HttpListenAddress   string
+	TLSCertfile         string
+	TLSCACert           string
+	TLSKeyFile          string
+	HttpPort            int
+	HttpCORSDomain      []string
+	HttpVirtualHost     []string
+	API                 []string
+	Gascap              uint64
+	MaxTraces           uint64
+	TraceType           string
+	WebsocketEnabled    bool
+	HealthCheckInterval time.Duration
 }
 
 var rootCmd = &cobra.Command{
@@ -50,7 +53,9 @@ func RootCommand() (*cobra.Command, *Flags) {
 	utils.CobraFlags(rootCmd, append(debug.Flags, utils.MetricFlags...))
 
 	cfg := &Flags{}
-	rootCmd.PersistentFlags().StringVar(&cfg.PrivateApiAddr, "private.api.addr", "127.0.0.1:9090", "private api network address, for example: 127.0.0.1:9090, empty string means not to start the listener. do not expose to public network. serves remote database interface")
+	rootCmd.PersistentFlags().StringVar(&cfg.PrivateApiAddr, "private.api.addr", "127.0.0.1:9090", "private api network address, for example: 127.0.0.1:9090, or a comma separated list of the addresses of several nodes, empty string means not to start the listener. do not expose to public network. serves remote database interface")
+	rootCmd.PersistentFlags().StringVar(&cfg.PrivateApiToken, "private.api.token", "", "token which identifies the daemon to the private api, if the node has a policy of its clients")
+	rootCmd.PersistentFlags().DurationVar(&cfg.HealthCheckInterval, "private.api.healthcheck", time.Second, "how often the head of each node is read, if there are several of them in --private.api.addr")
 	rootCmd.PersistentFlags().StringVar(&cfg.Chaindata, "chaindata", "", "path to the database")
 	rootCmd.PersistentFlags().StringVar(&cfg.HttpListenAddress, "http.addr", node.DefaultHTTPHost, "HTTP-RPC server listening interface")
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSCertfile, "tls.cert", "", "certificate for client side TLS handshake")
@@ -81,7 +86,7 @@ func OpenDB(cfg Flags) (ethdb.KV, ethdb.Backend, error) {
 			err = errOpen
 		}
 	} else if cfg.PrivateApiAddr != "" {
-		db, txPool, err = ethdb.NewRemote().Path(cfg.PrivateApiAddr).Open(cfg.TLSCertfile, cfg.TLSKeyFile, cfg.TLSCACert)
+		db, txPool, err = ethdb.NewRemote().Path(cfg.PrivateApiAddr).WithToken(cfg.PrivateApiToken).Open(cfg.TLSCertfile, cfg.TLSKeyFile, cfg.TLSCACert)
 		if err != nil {
 			return nil, nil, fmt.Errorf("could not connect to remoteDb: %w", err)
 		}
@@ -98,20 +103,35 @@ func OpenDB(cfg Flags) (ethdb.KV, ethdb.Backend, error) {
 
 func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API) error {
 	// register apis and create handler stack
-	httpEndpoint := fmt.Sprintf("%s:%d", cfg.HttpListenAddress, cfg.HttpPort)
-
 	srv := rpc.NewServer()
 	if err := node.RegisterApisFromWhitelist(rpcAPI, cfg.API, srv, false); err != nil {
 		return fmt.Errorf("could not start register RPC apis: %w", err)
 	}
 
-	var err error
-
-	httpHandler := node.NewHTTPHandlerStack(srv, cfg.HttpCORSDomain, cfg.HttpVirtualHost)
 	var wsHandler http.Handler
 	if cfg.WebsocketEnabled {
 		wsHandler = srv.WebsocketHandler([]string{"*"})
 	}
+	return serveRpc(ctx, cfg, srv, wsHandler)
+}
+
+// StartRpcServerWithBackends serves the apis of every node, apiList is called for each of them, see Backends
+func StartRpcServerWithBackends(ctx context.Context, cfg Flags, backends *Backends, apiList func(db ethdb.KV, eth ethdb.Backend) []rpc.API) error {
+	if err := backends.registerAPIs(cfg, apiList); err != nil {
+		return err
+	}
+	go backends.CheckHealthEvery(ctx, cfg.HealthCheckInterval)
+
+	var wsHandler http.Handler
+	if cfg.WebsocketEnabled {
+		wsHandler = backends.WebsocketHandler()
+	}
+	return serveRpc(ctx, cfg, backends, wsHandler)
+}
+
+func serveRpc(ctx context.Context, cfg Flags, srv http.Handler, wsHandler http.Handler) error {
+	httpEndpoint := fmt.Sprintf("%s:%d", cfg.HttpListenAddress, cfg.HttpPort)
+	httpHandler := node.NewHTTPHandlerStack(srv, cfg.HttpCORSDomain, cfg.HttpVirtualHost)
 
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if cfg.WebsocketEnabled && r.Method == "GET" {
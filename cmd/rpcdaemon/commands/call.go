@@ -20,7 +20,21 @@ import (
 )
 
 func (api *APIImpl) Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]ethapi.Account) (hexutil.Bytes, error) {
-	result, err := transactions.DoCall(ctx, args, api.db, api.dbReader, blockNrOrHash, overrides, api.GasCap)
+	var result *core.ExecutionResult
+	var err error
+	if blockNrOrHash.BlockNumber != nil && *blockNrOrHash.BlockNumber == rpc.PendingBlockNumber {
+		if api.ethBackend == nil {
+			// We're running in --chaindata mode or otherwise cannot get the transaction pool
+			return nil, fmt.Errorf(NotAvailableChainData, "eth_call on the pending block")
+		}
+		pending, err1 := pendingTransactions(api.ethBackend)
+		if err1 != nil {
+			return nil, err1
+		}
+		result, err = transactions.DoCallPending(ctx, args, api.db, api.dbReader, pending, overrides, api.GasCap)
+	} else {
+		result, err = transactions.DoCall(ctx, args, api.db, api.dbReader, blockNrOrHash, overrides, api.GasCap)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +47,14 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHas
 	return result.Return(), result.Err
 }
 
+// CallBundle implements eth_callBundle. It simulates an ordered bundle of signed
+// transactions (raw hex encoded) or unsigned calls (call arguments) on top of the
+// state of the given block, or of its parent if onParent is set, and reports the
+// outcome of each transaction.
+func (api *APIImpl) CallBundle(ctx context.Context, txs []transactions.BundleTransaction, blockNrOrHash rpc.BlockNumberOrHash, onParent *bool, blockOverrides *transactions.BlockOverrides) (*transactions.BundleResult, error) {
+	return transactions.DoCallBundle(ctx, txs, api.db, api.dbReader, blockNrOrHash, onParent != nil && *onParent, blockOverrides, api.GasCap)
+}
+
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
 func (api *APIImpl) EstimateGas(ctx context.Context, args ethapi.CallArgs) (hexutil.Uint64, error) {
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+func TestCallBundle(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		// increments slot 0 and returns its new value
+		counter = common.HexToAddress("0xc1")
+		// reverts with Error("boom")
+		reverter = common.HexToAddress("0xc2")
+		// returns the gas left after the GAS opcode
+		gasLeft = common.HexToAddress("0xc3")
+		gasCap  = uint64(100000)
+	)
+	reason := append(common.FromHex("08c379a0"), common.LeftPadBytes([]byte{0x20}, 32)...)
+	reason = append(reason, common.LeftPadBytes([]byte{4}, 32)...)
+	reason = append(reason, common.RightPadBytes([]byte("boom"), 32)...)
+	reverterCode := []byte{
+		byte(vm.PUSH1), byte(len(reason)), byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(reason)), byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}
+	gspec := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: core.GenesisAlloc{
+			address: {Balance: big.NewInt(params.Ether)},
+			counter: {Balance: new(big.Int), Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(5))}, Code: []byte{
+				byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+			}},
+			reverter: {Balance: new(big.Int), Code: append(reverterCode, reason...)},
+			gasLeft: {Balance: new(big.Int), Code: []byte{
+				byte(vm.GAS), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+			}},
+		},
+	}
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	genesis, _, err := gspec.Commit(db, true /* history */)
+	require.NoError(t, err)
+	api := NewAPI(db.KV(), db, nil, gasCap)
+	genesisNr := rpc.BlockNumberOrHashWithNumber(0)
+
+	signer := types.MakeSigner(gspec.Config, big.NewInt(1))
+	signTx := func(nonce uint64, to common.Address) transactions.BundleTransaction {
+		tx, err1 := types.SignTx(types.NewTransaction(nonce, to, uint256.NewInt(), 100000, uint256.NewInt().SetUint64(1), nil), signer, key)
+		require.NoError(t, err1)
+		return transactions.BundleTransaction{Tx: tx}
+	}
+
+	// the transactions are executed in order on top of the genesis state, in the context of the block after it.
+	// The reverted transaction is reported in its result, and doesn't stop the bundle
+	bundle := []transactions.BundleTransaction{signTx(0, counter), signTx(1, reverter), signTx(2, counter)}
+	res, err := api.CallBundle(context.Background(), bundle, genesisNr, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(0), res.StateBlockNumber)
+	require.Equal(t, big.NewInt(1), res.BlockNumber.ToInt())
+	require.Equal(t, genesis.Coinbase(), res.Coinbase)
+	require.Len(t, res.Results, 3)
+
+	var totalGas uint64
+	for i, txRes := range res.Results {
+		require.Equal(t, bundle[i].Tx.Hash(), *txRes.TxHash, "tx %d", i)
+		require.Equal(t, address, txRes.From, "tx %d", i)
+		require.Equal(t, bundle[i].Tx.To(), txRes.To, "tx %d", i)
+		require.NotZero(t, txRes.GasUsed, "tx %d", i)
+		// the gas price is 1, so the coinbase gets the gas used
+		require.Equal(t, uint64(txRes.GasUsed), txRes.CoinbaseDiff.ToInt().Uint64(), "tx %d", i)
+		totalGas += uint64(txRes.GasUsed)
+	}
+	require.Equal(t, hexutil.Uint64(totalGas), res.TotalGasUsed)
+	require.Equal(t, totalGas, res.CoinbaseDiff.ToInt().Uint64())
+
+	require.Empty(t, res.Results[0].Error)
+	require.Equal(t, common.BigToHash(big.NewInt(6)).Bytes(), []byte(res.Results[0].ReturnData))
+	require.Equal(t, vm.ErrExecutionReverted.Error(), res.Results[1].Error)
+	require.Equal(t, "boom", res.Results[1].RevertReason)
+	require.Equal(t, reason, []byte(res.Results[1].ReturnData))
+	require.Empty(t, res.Results[2].Error)
+	require.Equal(t, common.BigToHash(big.NewInt(7)).Bytes(), []byte(res.Results[2].ReturnData))
+
+	// the bundle is not persisted, so the next one starts from the same state
+	res, err = api.CallBundle(context.Background(), bundle[:1], genesisNr, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(big.NewInt(6)).Bytes(), []byte(res.Results[0].ReturnData))
+
+	// a transaction failing the consensus checks fails the whole bundle
+	_, err = api.CallBundle(context.Background(), bundle[2:], genesisNr, nil, nil)
+	require.Error(t, err)
+
+	// the genesis has no parent state to apply the bundle on
+	onParent := true
+	_, err = api.CallBundle(context.Background(), bundle, genesisNr, &onParent, nil)
+	require.Error(t, err)
+
+	// the calls without gas get the gas left in the block, limited by the gas cap
+	gas := hexutil.Uint64(60000)
+	calls := []transactions.BundleTransaction{
+		{Call: &ethapi.CallArgs{To: &gasLeft}},
+		{Call: &ethapi.CallArgs{To: &gasLeft, Gas: &gas}},
+	}
+	res, err = api.CallBundle(context.Background(), calls, genesisNr, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Results, 2)
+	require.Equal(t, gasCap-params.TxGas-vm.GasQuickStep, new(big.Int).SetBytes(res.Results[0].ReturnData).Uint64())
+	require.Equal(t, uint64(gas)-params.TxGas-vm.GasQuickStep, new(big.Int).SetBytes(res.Results[1].ReturnData).Uint64())
+}
@@ -92,6 +92,8 @@ func APIList(db ethdb.KV, eth ethdb.Backend, cfg cli.Flags, customApiList []rpc.
 	dbgAPIImpl := NewPrivateDebugAPI(db, dbReader)
 	traceAPIImpl := NewTraceAPI(db, dbReader, &cfg)
 	web3Impl := NewWeb3APIImpl()
+	txPoolImpl := NewTxPoolAPI(eth)
+	tgImpl := NewTgAPI(eth)
 
 	for _, enabledAPI := range cfg.API {
 		switch enabledAPI {
@@ -130,6 +132,20 @@ func APIList(db ethdb.KV, eth ethdb.Backend, cfg cli.Flags, customApiList []rpc.
 				Service:   TraceAPI(traceAPIImpl),
 				Version:   "1.0",
 			})
+		case "txpool":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "txpool",
+				Public:    true,
+				Service:   TxPoolAPI(txPoolImpl),
+				Version:   "1.0",
+			})
+		case "tg":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "tg",
+				Public:    true,
+				Service:   TgAPI(tgImpl),
+				Version:   "1.0",
+			})
 		}
 	}
 
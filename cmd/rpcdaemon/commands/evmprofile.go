@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth/tracers"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+// defaultProfileTopContracts is the number of contracts reported by EvmProfile by default
+const defaultProfileTopContracts = 100
+
+// EvmProfile re-executes the blocks fromBlock..toBlock (inclusive) and returns the opcode, gas
+// and storage access statistics aggregated over all their transactions, see tracers.Profiler.
+func (api *PrivateDebugAPIImpl) EvmProfile(ctx context.Context, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber, topContracts *int) (*tracers.ProfileResult, error) {
+	from, err := getBlockNumber(fromBlock, api.dbReader)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getBlockNumber(toBlock, api.dbReader)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("fromBlock %d is after toBlock %d", from, to)
+	}
+	top := defaultProfileTopContracts
+	if topContracts != nil {
+		top = *topContracts
+	}
+
+	genesisHash := rawdb.ReadCanonicalHash(api.dbReader, 0)
+	chainConfig := rawdb.ReadChainConfig(api.dbReader, genesisHash)
+	if chainConfig == nil {
+		return nil, fmt.Errorf("chain config not found for genesis %x", genesisHash)
+	}
+	profiler := tracers.NewProfiler()
+	blocks, err := transactions.ProfileBlocks(ctx, api.db, api.dbReader, chainConfig, from, to, profiler)
+	if err != nil {
+		return nil, err
+	}
+	result := profiler.Result(top)
+	result.Blocks = blocks
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+// TgAPI the interface for the tg_ RPC commands
+type TgAPI interface {
+	SyncStatus(_ context.Context) (*ethdb.SyncStatus, error)
+}
+
+// TgAPIImpl data structure to store things needed for tg_ commands
+type TgAPIImpl struct {
+	ethBackend ethdb.Backend
+}
+
+// NewTgAPI returns TgAPIImpl instance
+func NewTgAPI(eth ethdb.Backend) *TgAPIImpl {
+	return &TgAPIImpl{
+		ethBackend: eth,
+	}
+}
+
+// SyncStatus implements RPC call for tg_syncStatus
+func (api *TgAPIImpl) SyncStatus(_ context.Context) (*ethdb.SyncStatus, error) {
+	if api.ethBackend == nil {
+		// We're running in --chaindata mode or otherwise cannot get the backend
+		return nil, fmt.Errorf(NotAvailableChainData, "tg_syncStatus")
+	}
+	return api.ethBackend.SyncStatus()
+}
@@ -5,9 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
+	"github.com/RoaringBitmap/roaring"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/common/hexutil"
@@ -285,45 +288,30 @@ func (api *TraceAPIImpl) Filter(ctx context.Context, req TraceFilterRequest) (Pa
 func retrieveHistory(tx ethdb.Tx, addr *common.Address, fromBlock uint64, toBlock uint64) ([]uint64, error) {
 	addrBytes := addr.Bytes()
 	ca := tx.Cursor(dbutils.AccountsHistoryBucket).Prefix(addrBytes)
-	var blockNumbers []uint64
+	blocks := roaring.New()
 
 	for k, v, err := ca.First(); k != nil; k, v, err = ca.Next() {
 		if err != nil {
 			return nil, err
 		}
 
-		numbers, _, err := dbutils.WrapHistoryIndex(v).Decode()
-		if err != nil {
+		chunk := roaring.New()
+		if err := chunk.UnmarshalBinary(v); err != nil {
 			return nil, err
 		}
-
-		blockNumbers = append(blockNumbers, numbers...)
+		blocks.Or(chunk)
 	}
 
 	// cleanup for invalid blocks
-	start := -1
-	end := -1
-	for i, b := range blockNumbers {
-		if b >= fromBlock && b <= toBlock && start == -1 {
-			start = i
-			continue
-		}
-
-		if b > toBlock {
-			end = i
-			break
-		}
-	}
-
-	if start == -1 {
-		return []uint64{}, nil
+	blocks.RemoveRange(0, fromBlock)
+	if toBlock < math.MaxUint32 {
+		blocks.RemoveRange(toBlock+1, uint64(math.MaxUint32)+1)
 	}
-
-	if end == -1 {
-		return blockNumbers[start:], nil
+	blockNumbers := make([]uint64, 0, blocks.GetCardinality())
+	for _, b := range blocks.ToArray() {
+		blockNumbers = append(blockNumbers, uint64(b))
 	}
-	// Remove dublicates
-	return blockNumbers[start:end], nil
+	return blockNumbers, nil
 }
 
 func isAddressInFilter(addr *common.Address, filter []*common.Address) bool {
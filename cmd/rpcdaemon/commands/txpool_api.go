@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// TxPoolAPI the interface for the txpool_ RPC commands
+type TxPoolAPI interface {
+	Content(ctx context.Context) (map[string]map[string]map[string]*RPCTransaction, error)
+	Status(ctx context.Context) (map[string]hexutil.Uint, error)
+	Inspect(ctx context.Context) (map[string]map[string]map[string]string, error)
+}
+
+// TxPoolAPIImpl data structure to store things needed for txpool_ commands
+type TxPoolAPIImpl struct {
+	ethBackend ethdb.Backend
+}
+
+// NewTxPoolAPI returns TxPoolAPIImpl instance
+func NewTxPoolAPI(eth ethdb.Backend) *TxPoolAPIImpl {
+	return &TxPoolAPIImpl{
+		ethBackend: eth,
+	}
+}
+
+// Content implements RPC call for txpool_content
+func (api *TxPoolAPIImpl) Content(_ context.Context) (map[string]map[string]map[string]*RPCTransaction, error) {
+	if api.ethBackend == nil {
+		// We're running in --chaindata mode or otherwise cannot get the backend
+		return nil, fmt.Errorf(NotAvailableChainData, "txpool_content")
+	}
+
+	pending, queued, err := api.ethBackend.PoolContent()
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]map[string]map[string]*RPCTransaction{
+		"pending": make(map[string]map[string]*RPCTransaction),
+		"queued":  make(map[string]map[string]*RPCTransaction),
+	}
+	flatten := func(txs []ethdb.PoolTransaction, dump map[string]map[string]*RPCTransaction) error {
+		for _, poolTx := range txs {
+			tx := new(types.Transaction)
+			if err := rlp.DecodeBytes(poolTx.RlpTx, tx); err != nil {
+				return err
+			}
+			account := poolTx.Sender.Hex()
+			if _, ok := dump[account]; !ok {
+				dump[account] = make(map[string]*RPCTransaction)
+			}
+			rpcTx := newRPCTransaction(tx, common.Hash{}, 0, 0)
+			rpcTx.From = poolTx.Sender
+			dump[account][fmt.Sprintf("%d", tx.Nonce())] = rpcTx
+		}
+		return nil
+	}
+	if err := flatten(pending, content["pending"]); err != nil {
+		return nil, err
+	}
+	if err := flatten(queued, content["queued"]); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// Status implements RPC call for txpool_status
+func (api *TxPoolAPIImpl) Status(_ context.Context) (map[string]hexutil.Uint, error) {
+	if api.ethBackend == nil {
+		// We're running in --chaindata mode or otherwise cannot get the backend
+		return nil, fmt.Errorf(NotAvailableChainData, "txpool_status")
+	}
+
+	pending, queued, err := api.ethBackend.PoolStatus()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]hexutil.Uint{
+		"pending": hexutil.Uint(pending),
+		"queued":  hexutil.Uint(queued),
+	}, nil
+}
+
+// Inspect implements RPC call for txpool_inspect
+func (api *TxPoolAPIImpl) Inspect(_ context.Context) (map[string]map[string]map[string]string, error) {
+	if api.ethBackend == nil {
+		// We're running in --chaindata mode or otherwise cannot get the backend
+		return nil, fmt.Errorf(NotAvailableChainData, "txpool_inspect")
+	}
+
+	pending, queued, err := api.ethBackend.PoolInspect()
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]map[string]map[string]string{
+		"pending": make(map[string]map[string]string),
+		"queued":  make(map[string]map[string]string),
+	}
+	flatten := func(items []ethdb.PoolInspectItem, dump map[string]map[string]string) {
+		for _, item := range items {
+			account := item.Sender.Hex()
+			if _, ok := dump[account]; !ok {
+				dump[account] = make(map[string]string)
+			}
+			dump[account][fmt.Sprintf("%d", item.Nonce)] = item.Summary
+		}
+	}
+	flatten(pending, content["pending"])
+	flatten(queued, content["queued"])
+	return content, nil
+}
+
+// pendingTransactions returns the pending transactions of the transaction pool of the backend,
+// grouped by sender and sorted by nonce
+func pendingTransactions(eth ethdb.Backend) (map[common.Address]types.Transactions, error) {
+	pending, _, err := eth.PoolContent()
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make(map[common.Address]types.Transactions)
+	for _, poolTx := range pending {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(poolTx.RlpTx, tx); err != nil {
+			return nil, err
+		}
+		txs[poolTx.Sender] = append(txs[poolTx.Sender], tx)
+	}
+	return txs, nil
+}
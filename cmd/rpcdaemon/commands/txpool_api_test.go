@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// testPool - the transaction pool of the backend, with the pending and queued transactions given by the test
+type testPool struct {
+	ethdb.Backend
+	pending, queued []ethdb.PoolTransaction
+}
+
+func (p *testPool) PoolContent() (pending, queued []ethdb.PoolTransaction, err error) {
+	return p.pending, p.queued, nil
+}
+
+func (p *testPool) PoolStatus() (pending, queued uint64, err error) {
+	return uint64(len(p.pending)), uint64(len(p.queued)), nil
+}
+
+func (p *testPool) PoolInspect() (pending, queued []ethdb.PoolInspectItem, err error) {
+	inspect := func(txs []ethdb.PoolTransaction) (items []ethdb.PoolInspectItem) {
+		for _, poolTx := range txs {
+			tx := new(types.Transaction)
+			if err := rlp.DecodeBytes(poolTx.RlpTx, tx); err != nil {
+				panic(err)
+			}
+			items = append(items, ethdb.PoolInspectItem{Sender: poolTx.Sender, Nonce: tx.Nonce(), Summary: tx.To().Hex()})
+		}
+		return items
+	}
+	return inspect(p.pending), inspect(p.queued), nil
+}
+
+func poolTransaction(t *testing.T, sender common.Address, nonce uint64) ethdb.PoolTransaction {
+	tx := types.NewTransaction(nonce, common.HexToAddress("0xc1"), uint256.NewInt(), 21000, uint256.NewInt().SetUint64(1), nil)
+	rlpTx, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+	return ethdb.PoolTransaction{Sender: sender, RlpTx: rlpTx}
+}
+
+func TestTxPoolAPI(t *testing.T) {
+	sender1, sender2 := common.HexToAddress("0xa1"), common.HexToAddress("0xa2")
+	pool := &testPool{
+		pending: []ethdb.PoolTransaction{poolTransaction(t, sender1, 0), poolTransaction(t, sender1, 1), poolTransaction(t, sender2, 0)},
+		queued:  []ethdb.PoolTransaction{poolTransaction(t, sender2, 5)},
+	}
+	api := NewTxPoolAPI(pool)
+
+	status, err := api.Status(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]hexutil.Uint{"pending": 3, "queued": 1}, status)
+
+	content, err := api.Content(context.Background())
+	require.NoError(t, err)
+	require.Len(t, content["pending"], 2)
+	require.Len(t, content["pending"][sender1.Hex()], 2)
+	require.Equal(t, sender1, content["pending"][sender1.Hex()]["1"].From)
+	require.Equal(t, hexutil.Uint64(1), content["pending"][sender1.Hex()]["1"].Nonce)
+	require.Len(t, content["queued"], 1)
+	require.Equal(t, sender2, content["queued"][sender2.Hex()]["5"].From)
+
+	inspect, err := api.Inspect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]map[string]string{
+		"pending": {
+			sender1.Hex(): {"0": "0x00000000000000000000000000000000000000C1", "1": "0x00000000000000000000000000000000000000C1"},
+			sender2.Hex(): {"0": "0x00000000000000000000000000000000000000C1"},
+		},
+		"queued": {
+			sender2.Hex(): {"5": "0x00000000000000000000000000000000000000C1"},
+		},
+	}, inspect)
+
+	// the pending transactions to execute the call on are grouped by sender, in the order of the pool
+	pending, err := pendingTransactions(pool)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, []uint64{0, 1}, []uint64{pending[sender1][0].Nonce(), pending[sender1][1].Nonce()})
+
+	// the pool is not available without the backend
+	_, err = NewTxPoolAPI(nil).Status(context.Background())
+	require.Error(t, err)
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"os"
+	"strings"
 
 	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rpc"
 
 	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/commands"
@@ -18,6 +21,18 @@ var (
 func main() {
 	cmd, cfg := cli.RootCommand()
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if cfg.Chaindata == "" && strings.Contains(cfg.PrivateApiAddr, ",") {
+			backends, err := cli.OpenBackends(*cfg)
+			if err != nil {
+				log.Error("Could not connect to remoteDb", "error", err)
+				return nil
+			}
+			defer backends.Close()
+			return cli.StartRpcServerWithBackends(cmd.Context(), *cfg, backends, func(db ethdb.KV, eth ethdb.Backend) []rpc.API {
+				return commands.APIList(db, eth, *cfg, nil)
+			})
+		}
+
 		db, backend, err := cli.OpenDB(*cfg)
 		if err != nil {
 			log.Error("Could not connect to remoteDb", "error", err)
@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"runtime"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/state/stateless"
+	"github.com/spf13/cobra"
+)
+
+var executionWorkers int
+
+func init() {
+	withBlock(checkParallelExecCmd)
+	withChaindata(checkParallelExecCmd)
+	checkParallelExecCmd.Flags().IntVar(&executionWorkers, "workers", runtime.NumCPU(), "goroutines executing the transactions of a block speculatively")
+	rootCmd.AddCommand(checkParallelExecCmd)
+}
+
+var checkParallelExecCmd = &cobra.Command{
+	Use:   "checkParallelExec",
+	Short: "Re-executes historical blocks in order and in parallel, and checks that the receipts and the changesets match",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return stateless.CheckParallelExecution(genesis, block, chaindata, executionWorkers)
+	},
+}
@@ -0,0 +1,149 @@
+package stateless
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// changeSetCollector keeps the changesets of a block in memory, for the comparison
+type changeSetCollector struct {
+	*state.ChangeSetWriter
+}
+
+func (changeSetCollector) WriteChangeSets() error { return nil }
+func (changeSetCollector) WriteHistory() error    { return nil }
+
+// CheckParallelExecution re-executes historical blocks twice, with the transactions applied in order and
+// executed speculatively on the given number of goroutines, and checks that the receipts and the changesets match.
+func CheckParallelExecution(genesis *core.Genesis, blockNum uint64, chaindata string, workers int) error {
+	startTime := time.Now()
+	sigs := make(chan os.Signal, 1)
+	interruptCh := make(chan bool, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigs
+		interruptCh <- true
+	}()
+
+	chainDb := ethdb.MustOpen(chaindata)
+	defer chainDb.Close()
+
+	chainConfig := genesis.Config
+	engine := ethash.NewFaker()
+	txCacher := core.NewTxSenderCacher(runtime.NumCPU())
+	bc, err := core.NewBlockChain(chainDb, nil, chainConfig, engine, vm.Config{}, nil, txCacher)
+	if err != nil {
+		return err
+	}
+	defer bc.Stop()
+
+	execute := func(block *types.Block, vmConfig vm.Config) (types.Receipts, []byte, []byte, error) {
+		csw := changeSetCollector{state.NewChangeSetWriterPlain(block.NumberU64() - 1)}
+		dbstate := state.NewPlainDBState(chainDb.KV(), block.NumberU64()-1)
+		receipts, err := core.ExecuteBlockEphemerally(chainConfig, &vmConfig, bc, engine, block, dbstate, csw)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		accountChanges, err := csw.GetAccountChanges()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		accounts, err := changeset.EncodeAccountsPlain(accountChanges)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		storageChanges, err := csw.GetStorageChanges()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var storage []byte
+		if storageChanges.Len() > 0 {
+			if storage, err = changeset.EncodeStoragePlain(storageChanges); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		return receipts, accounts, storage, nil
+	}
+
+	interrupt := false
+	var sequentialTime, parallelTime time.Duration
+	for !interrupt {
+		block := bc.GetBlockByNumber(blockNum)
+		if block == nil {
+			break
+		}
+
+		start := time.Now()
+		receipts, accounts, storage, err := execute(block, vm.Config{})
+		if err != nil {
+			return fmt.Errorf("sequential execution of block %d: %w", blockNum, err)
+		}
+		sequentialTime += time.Since(start)
+		start = time.Now()
+		parallelReceipts, parallelAccounts, parallelStorage, err := execute(block, vm.Config{ExecutionWorkers: workers})
+		if err != nil {
+			return fmt.Errorf("parallel execution of block %d: %w", blockNum, err)
+		}
+		parallelTime += time.Since(start)
+
+		if !reflect.DeepEqual(receipts, parallelReceipts) {
+			for i := range receipts {
+				if !reflect.DeepEqual(receipts[i], parallelReceipts[i]) {
+					fmt.Printf("Mismatched receipt of tx %x in block %d\nSequential: %+v\nParallel: %+v\n", receipts[i].TxHash, blockNum, receipts[i], parallelReceipts[i])
+				}
+			}
+			return fmt.Errorf("mismatched receipts in block %d", blockNum)
+		}
+		if !bytes.Equal(accounts, parallelAccounts) {
+			fmt.Printf("Mismatched account changes in block %d\nSequential: ======================\n", blockNum)
+			printChanges(changeset.AccountChangeSetPlainBytes(accounts).Walk)
+			fmt.Printf("Parallel: ==========================\n")
+			printChanges(changeset.AccountChangeSetPlainBytes(parallelAccounts).Walk)
+			return fmt.Errorf("mismatched account changes in block %d", blockNum)
+		}
+		if !bytes.Equal(storage, parallelStorage) {
+			fmt.Printf("Mismatched storage changes in block %d\nSequential: ======================\n", blockNum)
+			printChanges(changeset.StorageChangeSetPlainBytes(storage).Walk)
+			fmt.Printf("Parallel: ==========================\n")
+			printChanges(changeset.StorageChangeSetPlainBytes(parallelStorage).Walk)
+			return fmt.Errorf("mismatched storage changes in block %d", blockNum)
+		}
+
+		blockNum++
+		if blockNum%1000 == 0 {
+			log.Info("Checked", "blocks", blockNum, "sequential", sequentialTime, "parallel", parallelTime)
+		}
+
+		// Check for interrupts
+		select {
+		case interrupt = <-interruptCh:
+			fmt.Println("interrupted, please wait for cleanup...")
+		default:
+		}
+	}
+	log.Info("Checked", "blocks", blockNum, "next time specify --block", blockNum, "sequential", sequentialTime, "parallel", parallelTime, "duration", time.Since(startTime))
+	return nil
+}
+
+func printChanges(walk func(func(k, v []byte) error) error) {
+	_ = walk(func(k, v []byte) error {
+		fmt.Printf("0x%x: %x\n", k, v)
+		return nil
+	})
+}
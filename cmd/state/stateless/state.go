@@ -19,8 +19,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/common/debug"
 	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
@@ -180,6 +182,30 @@ func (r *StateGrowth1Reporter) interrupt(ctx context.Context, i int, startTime t
 	return false
 }
 
+// decodeHistory returns the blocks of a history index chunk and whether the value was empty before
+// the change in each of them. The index only keeps the blocks, the values are read from the changesets.
+func decodeHistory(tx ethdb.Tx, v []byte, csBucket string, find func(changeSet []byte) ([]byte, error)) ([]uint64, []bool, error) {
+	index := roaring.New()
+	if err := index.UnmarshalBinary(v); err != nil {
+		return nil, nil, err
+	}
+	blockNums := make([]uint64, 0, index.GetCardinality())
+	created := make([]bool, 0, index.GetCardinality())
+	for _, blockNum := range index.ToArray() {
+		changeSet, err := tx.Get(csBucket, dbutils.EncodeTimestamp(uint64(blockNum)))
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := find(changeSet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("finding the change of block %d: %w", blockNum, err)
+		}
+		blockNums = append(blockNums, uint64(blockNum))
+		created = append(created, len(value) == 0)
+	}
+	return blockNums, created, nil
+}
+
 func (r *StateGrowth1Reporter) StateGrowth1(ctx context.Context) {
 	startTime := time.Now()
 
@@ -208,8 +234,9 @@ func (r *StateGrowth1Reporter) StateGrowth1(ctx context.Context) {
 				return err
 			}
 			address := k[:common.AddressLength]
-			hi := dbutils.WrapHistoryIndex(v)
-			blockNums, created, err1 := hi.Decode()
+			blockNums, created, err1 := decodeHistory(tx, v, dbutils.PlainAccountChangeSetBucket, func(changeSet []byte) ([]byte, error) {
+				return changeset.AccountChangeSetPlainBytes(changeSet).Find(address)
+			})
 			if err1 != nil {
 				return err1
 			}
@@ -333,8 +360,9 @@ func (r *StateGrowth2Reporter) StateGrowth2(ctx context.Context) {
 			}
 			address := k[:common.AddressLength]
 			location := k[common.AddressLength : common.AddressLength+common.HashLength]
-			hi := dbutils.WrapHistoryIndex(v)
-			blockNums, created, err1 := hi.Decode()
+			blockNums, created, err1 := decodeHistory(tx, v, dbutils.PlainStorageChangeSetBucket, func(changeSet []byte) ([]byte, error) {
+				return changeset.StorageChangeSetPlainBytes(changeSet).FindWithoutIncarnation(address, location)
+			})
 			if err1 != nil {
 				return err1
 			}
@@ -251,6 +251,7 @@ func makeTokenBalances() {
 			math.MaxUint64/2, // gaslimit
 			uint256.NewInt().SetUint64(100000),
 			common.FromHex(fmt.Sprintf("0x70a08231000000000000000000000000%x", common.HexToAddress("0xe477292f1b3268687a29376116b0ed27a9c76170"))),
+			nil,   // accessList
 			false, // checkNonce
 		)
 
@@ -477,6 +478,7 @@ func makeTokenAllowances() {
 				common.HexToAddress("0xe477292f1b3268687a29376116b0ed27a9c76170"),
 				common.HexToAddress("0xe477292f1b3268687a29376116b0ed25a9c76170"),
 			)),
+			nil,   // accessList
 			false, // checkNonce
 		)
 		{
@@ -8,23 +8,23 @@ import (
 	"os"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
+
 	"github.com/ledgerwatch/turbo-geth/common"
-	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 )
 
 func IndexStats(chaindata string, indexBucket string, statsFile string) error {
 	db := ethdb.MustOpen(chaindata)
 	startTime := time.Now()
-	lenOfKey := common.HashLength
-	if strings.HasPrefix(indexBucket, dbutils.StorageHistoryBucket) {
-		lenOfKey = common.HashLength*2 + common.IncarnationLength
-	}
 
-	more1index := 0
+	var (
+		keys, chunks, blocks, size uint64
+		maxChunk                   int
+	)
+	more1chunk := 0
 	more10index := make(map[string]uint64)
 	more50index := make(map[string]uint64)
 	more100index := make(map[string]uint64)
@@ -32,50 +32,66 @@ func IndexStats(chaindata string, indexBucket string, statsFile string) error {
 	more500index := make(map[string]uint64)
 	more1000index := make(map[string]uint64)
 
-	prevKey := []byte{}
-	count := uint64(1)
-	added := false
+	var prevKey []byte
+	count := uint64(0) // blocks of the current key
+	chunksOfKey := 0
+	// flush accounts the blocks of the previous key, which are spread over all its chunks
+	flush := func() {
+		if prevKey == nil {
+			return
+		}
+		if chunksOfKey > 1 {
+			more1chunk++
+		}
+		for _, more := range []struct {
+			index map[string]uint64
+			limit uint64
+		}{
+			{more10index, 10}, {more50index, 50}, {more100index, 100}, {more200index, 200}, {more500index, 500}, {more1000index, 1000},
+		} {
+			if count > more.limit {
+				more.index[string(prevKey)] = count
+			}
+		}
+	}
 	i := uint64(0)
 	if err := db.Walk(indexBucket, []byte{}, 0, func(k, v []byte) (b bool, e error) {
 		if i%100_000 == 0 {
 			fmt.Printf("Processed %dK, %s\n", i/1000, time.Since(startTime))
 		}
-		if bytes.Equal(k[:lenOfKey], prevKey) {
-			count++
-			if count > 1 && !added {
-				more1index++
-				added = true
-			}
-			if count > 10 {
-				more10index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-			if count > 50 {
-				more50index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-			if count > 100 {
-				more100index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-			if count > 200 {
-				more200index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-			if count > 500 {
-				more500index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-			if count > 1000 {
-				more1000index[string(common.CopyBytes(k[:lenOfKey]))] = count
-			}
-		} else {
-			added = false
-			count = 1
-			prevKey = common.CopyBytes(k[:common.HashLength])
+		i++
+		index := roaring.New()
+		if err := index.UnmarshalBinary(v); err != nil {
+			return false, fmt.Errorf("decoding the chunk %x: %w", k, err)
+		}
+		// The key of a chunk is the key of the state followed by the last block of the chunk
+		key := k[:len(k)-8]
+		if !bytes.Equal(key, prevKey) {
+			flush()
+			prevKey = common.CopyBytes(key)
+			count = 0
+			chunksOfKey = 0
+			keys++
+		}
+		count += index.GetCardinality()
+		chunksOfKey++
+		chunks++
+		blocks += index.GetCardinality()
+		size += uint64(len(v))
+		if len(v) > maxChunk {
+			maxChunk = len(v)
 		}
-
 		return true, nil
 	}); err != nil {
 		return err
 	}
+	flush()
 
-	fmt.Println("more1", more1index)
+	fmt.Println("keys", keys, "chunks", chunks, "blocks", blocks)
+	if chunks > 0 {
+		fmt.Println("chunk size: total", common.StorageSize(size), "avg", size/chunks, "max", maxChunk)
+	}
+	fmt.Println("more1chunk", more1chunk)
 	fmt.Println("more10", len(more10index))
 	fmt.Println("more50", len(more50index))
 	fmt.Println("more100", len(more100index))
@@ -91,25 +107,18 @@ func IndexStats(chaindata string, indexBucket string, statsFile string) error {
 		defer f.Close() //nolint
 		save10 := make([]struct {
 			Address      string
-			Hash         string
+			Key          string
 			NumOfIndexes uint64
 		}, 0, len(more10index))
-		for hash, v := range more10index {
-			p, innerErr := db.Get(dbutils.PreimagePrefix, []byte(hash)[:common.HashLength])
-			if innerErr != nil {
-				return innerErr
-			}
-			if len(p) == 0 {
-				p = make([]byte, 20)
-			}
+		for key, v := range more10index {
 			save10 = append(save10, struct {
 				Address      string
-				Hash         string
+				Key          string
 				NumOfIndexes uint64
 			}{
-				Address:      common.BytesToAddress(p).String(),
+				Address:      common.BytesToAddress([]byte(key)[:common.AddressLength]).String(),
 				NumOfIndexes: v,
-				Hash:         common.Bytes2Hex([]byte(hash)),
+				Key:          common.Bytes2Hex([]byte(key)),
 			})
 
 		}
@@ -118,16 +127,20 @@ func IndexStats(chaindata string, indexBucket string, statsFile string) error {
 		})
 
 		csvWriter := csv.NewWriter(f)
-		err = csvWriter.Write([]string{"hash", "address", "num"})
+		err = csvWriter.Write([]string{"key", "address", "blocks"})
 		if err != nil {
 			return err
 		}
 		for _, v := range save10 {
-			err = csvWriter.Write([]string{v.Hash, v.Address, strconv.FormatUint(v.NumOfIndexes, 10)})
+			err = csvWriter.Write([]string{v.Key, v.Address, strconv.FormatUint(v.NumOfIndexes, 10)})
 			if err != nil {
 				return err
 			}
 		}
+		csvWriter.Flush()
+		if err = csvWriter.Error(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/changeset"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
 )
 
 func CheckIndex(chaindata string, changeSetBucket string, indexBucket string) error {
@@ -39,8 +42,11 @@ func CheckIndex(chaindata string, changeSetBucket string, indexBucket string) er
 				return innerErr
 			}
 
-			index := dbutils.WrapHistoryIndex(indexBytes)
-			if findVal, _, ok := index.Search(blockNum); !ok {
+			index := roaring.New()
+			if innerErr = index.UnmarshalBinary(indexBytes); innerErr != nil {
+				return innerErr
+			}
+			if findVal, ok := bitmapdb.SeekInBitmap(index, blockNum); !ok || findVal != blockNum {
 				return fmt.Errorf("%v,%v,%v", blockNum, findVal, common.Bytes2Hex(key))
 			}
 			return nil
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	cfgAddressFlag = cli.StringFlag{
+		Name:  "address",
+		Usage: "Address of the contract to analyse",
+	}
+	cfgBlockFlag = cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Block at which the code of the contract is taken (default = last executed block)",
+	}
+	cfgAllFlag = cli.BoolFlag{
+		Name:  "all",
+		Usage: "Analyse every contract code in the database, one JSON object per line",
+	}
+	cfgFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format: json or dot (dot is only available for a single contract)",
+		Value: "json",
+	}
+	cfgMaxStepsFlag = cli.IntFlag{
+		Name:  "maxsteps",
+		Usage: "Give up the analysis of a code after this many steps (0 = no limit)",
+		Value: 1000000,
+	}
+)
+
+var cfgCommand = cli.Command{
+	Action:    cfgCmd,
+	Name:      "cfg",
+	Usage:     "Builds the control flow graphs of contracts with the abstract interpreter and reports how many jumps it resolves",
+	ArgsUsage: "",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		cfgAddressFlag,
+		cfgBlockFlag,
+		cfgAllFlag,
+		cfgFormatFlag,
+		cfgMaxStepsFlag,
+	},
+	Description: `
+The cfg command runs the abstract interpretation of EVM bytecode (core/vm/absint_stackset.go)
+either on the code of one contract (--address, optionally at --block) or on every code of the
+database (--all). For each code it reports the number of reachable jumps, which of them could
+not be resolved to static destinations, the bounds of the stack height and analysis failures.`,
+}
+
+// cfgReport is the JSON representation of the analysis of one code
+type cfgReport struct {
+	Address  *common.Address `json:"address,omitempty"`
+	Block    *uint64         `json:"block,omitempty"`
+	CodeHash common.Hash     `json:"codeHash"`
+	*vm.CfgAnalysis
+}
+
+func cfgCmd(ctx *cli.Context) error {
+	format := ctx.String(cfgFormatFlag.Name)
+	if format != "json" && format != "dot" {
+		return fmt.Errorf("unknown format %q, expected json or dot", format)
+	}
+	all := ctx.Bool(cfgAllFlag.Name)
+	if all == ctx.IsSet(cfgAddressFlag.Name) {
+		return errors.New("exactly one of --address and --all is required")
+	}
+	if all && format == "dot" {
+		return errors.New("dot format is only available for a single contract")
+	}
+
+	kv, err := ethdb.NewLMDB().Path(filepath.Join(ctx.String(utils.DataDirFlag.Name), "tg", "chaindata")).ReadOnly().Open()
+	if err != nil {
+		return err
+	}
+	db := ethdb.NewObjectDatabase(kv)
+	defer db.Close()
+
+	maxSteps := ctx.Int(cfgMaxStepsFlag.Name)
+	if all {
+		return cfgAll(db, maxSteps)
+	}
+
+	address := common.HexToAddress(ctx.String(cfgAddressFlag.Name))
+	block := ctx.Uint64(cfgBlockFlag.Name)
+	if !ctx.IsSet(cfgBlockFlag.Name) {
+		if block, _, err = stages.GetStageProgress(db, stages.Execution); err != nil {
+			return err
+		}
+	}
+	reader := state.NewPlainDBState(kv, block)
+	account, err := reader.ReadAccountData(address)
+	if err != nil {
+		return err
+	}
+	if account == nil || account.IsEmptyCodeHash() {
+		return fmt.Errorf("no code at address %x at block %d", address, block)
+	}
+	code, err := reader.ReadAccountCode(address, account.CodeHash)
+	if err != nil {
+		return err
+	}
+
+	analysis := vm.AbsIntCfg(code, maxSteps)
+	if format == "dot" {
+		fmt.Println(analysis.Dot())
+		return nil
+	}
+	out, err := json.MarshalIndent(cfgReport{Address: &address, Block: &block, CodeHash: account.CodeHash, CfgAnalysis: analysis}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// cfgAll analyses every code in the CodeBucket, prints the report of each of them and logs the totals
+func cfgAll(db ethdb.Database, maxSteps int) error {
+	var codes, failed, jumps, resolvedJumps, fullyResolved int
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	enc := json.NewEncoder(os.Stdout)
+
+	if err := db.Walk(dbutils.CodeBucket, nil, 0, func(k, v []byte) (bool, error) {
+		analysis := vm.AbsIntCfg(v, maxSteps)
+		if err := enc.Encode(cfgReport{CodeHash: common.BytesToHash(k), CfgAnalysis: analysis}); err != nil {
+			return false, err
+		}
+		codes++
+		if analysis.Error != "" {
+			failed++
+		} else {
+			jumps += analysis.Jumps
+			resolvedJumps += analysis.ResolvedJumps
+			if len(analysis.UnresolvedJumps) == 0 {
+				fullyResolved++
+			}
+		}
+		select {
+		default:
+		case <-logEvery.C:
+			log.Info("Analysing codes", "done", codes, "failed", failed)
+		}
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	log.Info("Analysed codes", "total", codes, "failed", failed, "fully resolved", fullyResolved, "jumps", jumps, "resolved jumps", resolvedJumps)
+	return nil
+}
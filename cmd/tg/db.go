@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+
+	"github.com/urfave/cli"
+)
+
+var dbCommand = cli.Command{
+	Name:  "db",
+	Usage: "Inspects and maintains the chain database",
+	Subcommands: []cli.Command{
+		dbStatCommand,
+		dbCompactCommand,
+	},
+}
+
+var (
+	dbCompactFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Database directory to copy, e.g. <datadir>/tg/chaindata",
+	}
+	dbCompactToFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Directory to write the compacted copy to, it must not have a database",
+	}
+)
+
+var dbCompactCommand = cli.Command{
+	Action: dbCompactCmd,
+	Name:   "compact",
+	Usage:  "Copies the database with compaction, bucket by bucket, and verifies the copy",
+	Flags: []cli.Flag{
+		dbCompactFromFlag,
+		dbCompactToFlag,
+	},
+	Description: `
+The compact command copies the database of --from to --to without free pages: after much data is
+removed, the freelist slows down every commit. The entries of every bucket are appended in order,
+with the DupSort and comparator configs of the bucket, then the number of the entries and their
+checksum are compared with the source. The source is not changed, the node must be stopped while it
+is copied. The command can also clone a database to another disk.`,
+}
+
+var dbStatCommand = cli.Command{
+	Action: dbStatCmd,
+	Name:   "stat",
+	Usage:  "Prints the health of the LMDB environment of the chain database",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+	},
+	Description: `
+The stat command prints the size of the database file and of its freelist, and the reader table shared
+by all the processes using the database. The pages freed by the writes are reused by the later writes,
+unless a read transaction which began before they were freed is still open: such pages are pinned, and
+the file grows instead. A large pinned size along with a reader lagging many transactions behind points
+to a long-lived reader, for example of the rpcdaemon. The reader slots of the dead processes are cleared.`,
+}
+
+func dbStatCmd(ctx *cli.Context) error {
+	kv, err := ethdb.NewLMDB().Path(filepath.Join(ctx.String(utils.DataDirFlag.Name), "tg", "chaindata")).ReadOnly().Open()
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	stat, err := kv.(*ethdb.LmdbKV).Stat(context.Background())
+	if err != nil {
+		return err
+	}
+	size := func(pages uint64) common.StorageSize {
+		return common.StorageSize(pages * stat.PageSize)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Map size\t%s\n", common.StorageSize(stat.MapSize))
+	fmt.Fprintf(w, "Used\t%s\t%d pages of %d bytes\n", size(stat.UsedPages), stat.UsedPages, stat.PageSize)
+	fmt.Fprintf(w, "Free\t%s\t%d pages\n", size(stat.FreePages), stat.FreePages)
+	fmt.Fprintf(w, "Not reusable yet\t%s\t%d pages\n", size(stat.PinnedPages), stat.PinnedPages)
+	fmt.Fprintf(w, "Last transaction\t%d\n", stat.LastTxnID)
+	fmt.Fprintf(w, "Reader slots\t%d of %d\t%d active, %d stale cleared\n", len(stat.Readers), stat.MaxReaders, stat.ActiveReaders(), stat.StaleReaders)
+	fmt.Fprintf(w, "Oldest reader lag\t%d transactions\n", stat.OldestReaderLag())
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if len(stat.Readers) == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Fprintln(w, "PID\tTHREAD\tTXN\tLAG")
+	for _, r := range stat.Readers {
+		if r.TxnID == 0 {
+			fmt.Fprintf(w, "%d\t%s\t-\t-\n", r.Pid, r.Thread)
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", r.Pid, r.Thread, r.TxnID, stat.LastTxnID-r.TxnID)
+	}
+	return w.Flush()
+}
+
+func dbCompactCmd(ctx *cli.Context) error {
+	from, to := ctx.String(dbCompactFromFlag.Name), ctx.String(dbCompactToFlag.Name)
+	if from == "" || to == "" {
+		return fmt.Errorf("--%s and --%s are required", dbCompactFromFlag.Name, dbCompactToFlag.Name)
+	}
+	if _, err := os.Stat(filepath.Join(to, "data.mdb")); err == nil {
+		return fmt.Errorf("%s already has a database", to)
+	}
+
+	rootCtx := utils.RootContext()
+	sourceKV, err := ethdb.NewLMDB().Path(from).ReadOnly().Open()
+	if err != nil {
+		return err
+	}
+	defer sourceKV.Close()
+	destKV, err := ethdb.NewLMDB().Path(to).Open()
+	if err != nil {
+		return err
+	}
+	defer destKV.Close()
+	source, dest := sourceKV.(*ethdb.LmdbKV), destKV.(*ethdb.LmdbKV)
+
+	start := time.Now()
+	copied, err := source.CopyTo(rootCtx, dest)
+	if err != nil {
+		return err
+	}
+	log.Info("Copied, verifying", "buckets", len(copied), "took", time.Since(start))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "BUCKET\tENTRIES\tCHECKSUM\t")
+	var mismatches int
+	for _, c := range copied {
+		checksum, err := dest.Checksum(rootCtx, c.Bucket)
+		if err != nil {
+			return err
+		}
+		status := "OK"
+		if checksum != c {
+			status = fmt.Sprintf("MISMATCH: %d entries, %x", checksum.Entries, checksum.Checksum)
+			mismatches++
+		}
+		fmt.Fprintf(w, "%s\t%d\t%x\t%s\n", c.Bucket, c.Entries, c.Checksum, status)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d buckets of the copy differ from the source", mismatches, len(copied))
+	}
+
+	sourceFile, err := os.Stat(filepath.Join(from, "data.mdb"))
+	if err != nil {
+		return err
+	}
+	destFile, err := os.Stat(filepath.Join(to, "data.mdb"))
+	if err != nil {
+		return err
+	}
+	log.Info("Compacted", "from", common.StorageSize(sourceFile.Size()), "to", common.StorageSize(destFile.Size()), "took", time.Since(start))
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/eth/tracers"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	profileFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block to profile",
+		Value: 1,
+	}
+	profileToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block to profile (default = last executed block)",
+	}
+	profileFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format: json or csv",
+		Value: "json",
+	}
+	profileTopFlag = cli.IntFlag{
+		Name:  "top",
+		Usage: "Number of contracts consuming the most gas to report",
+		Value: 100,
+	}
+	profileOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the profile to (default = standard output)",
+	}
+)
+
+var evmProfileCommand = cli.Command{
+	Action: evmProfileCmd,
+	Name:   "evmprofile",
+	Usage:  "Re-executes a range of blocks and reports the opcode, gas and storage access statistics",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		profileFromFlag,
+		profileToFlag,
+		profileFormatFlag,
+		profileTopFlag,
+		profileOutputFlag,
+	},
+	Description: `
+The evmprofile command re-executes the blocks --from..--to (inclusive) on top of the historical
+state and aggregates, over all their transactions, the number of executions, the gas and the time
+of each opcode, the cold and warm SLOAD/SSTORE accesses and the contracts consuming the most gas.
+The same profile is available from rpcdaemon as debug_evmProfile.`,
+}
+
+func evmProfileCmd(ctx *cli.Context) error {
+	format := ctx.String(profileFormatFlag.Name)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q, expected json or csv", format)
+	}
+
+	kv, err := ethdb.NewLMDB().Path(filepath.Join(ctx.String(utils.DataDirFlag.Name), "tg", "chaindata")).ReadOnly().Open()
+	if err != nil {
+		return err
+	}
+	db := ethdb.NewObjectDatabase(kv)
+	defer db.Close()
+
+	from, to := ctx.Uint64(profileFromFlag.Name), ctx.Uint64(profileToFlag.Name)
+	if !ctx.IsSet(profileToFlag.Name) {
+		if to, _, err = stages.GetStageProgress(db, stages.Execution); err != nil {
+			return err
+		}
+	}
+	if from > to {
+		return errors.New("--from is after --to")
+	}
+
+	var out io.Writer = os.Stdout
+	if path := ctx.String(profileOutputFlag.Name); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	chainConfig := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if chainConfig == nil {
+		return errors.New("chain config not found in the database")
+	}
+	profiler := tracers.NewProfiler()
+	blocks, err := transactions.ProfileBlocks(context.Background(), kv, db, chainConfig, from, to, profiler)
+	if err != nil {
+		return err
+	}
+	result := profiler.Result(ctx.Int(profileTopFlag.Name))
+	result.Blocks = blocks
+	log.Info("Profiled blocks", "from", from, "to", to, "txs", result.Txs, "gas", result.Gas)
+
+	if format == "csv" {
+		return result.WriteCSV(out)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/internal/flags"
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/turbo/node"
 
@@ -21,6 +23,13 @@ var (
 func main() {
 	// creating a turbo-api app with all defaults
 	app := turbocli.MakeApp(runTurboGeth, turbocli.DefaultFlags)
+	app.Commands = []cli.Command{
+		cfgCommand,
+		dbCommand,
+		evmProfileCommand,
+		replayCommand,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -32,6 +41,13 @@ func runTurboGeth(ctx *cli.Context) {
 		stagedsync.DefaultStages(),
 		stagedsync.DefaultUnwindOrder(),
 	)
+	if ctx.GlobalBool(utils.ReceiptsSyncFlag.Name) {
+		// receipts are downloaded from peers, no blocks are executed
+		sync = stagedsync.New(
+			stagedsync.ReceiptsStages(),
+			stagedsync.ReceiptsUnwindOrder(),
+		)
+	}
 
 	// initializing the node and providing the current git commit there
 	tg := node.New(ctx, sync, node.Params{GitCommit: gitCommit})
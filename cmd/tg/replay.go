@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/utils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	replayBlockFlag = cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Block to re-execute",
+	}
+	replayToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block of the range to re-execute (default = --block)",
+	}
+	replayOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the state diffs to (default = standard output)",
+	}
+)
+
+var replayCommand = cli.Command{
+	Action: replayCmd,
+	Name:   "replay",
+	Usage:  "Re-executes historical blocks and reports the state changed by each transaction",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		replayBlockFlag,
+		replayToFlag,
+		replayOutputFlag,
+	},
+	Description: `
+The replay command re-executes the blocks --block..--to (inclusive), each on top of the historical
+state after its parent, and writes for every block a JSON document with the balances, nonces, code
+and storage before and after each transaction, the DAO fork and the block rewards. The changes of
+every block are cross-checked against its stored account and storage changesets, the mismatching
+entries are listed in changeSetMismatches and make the command fail once all blocks are written.`,
+}
+
+func replayCmd(ctx *cli.Context) error {
+	if !ctx.IsSet(replayBlockFlag.Name) {
+		return fmt.Errorf("--%s is required", replayBlockFlag.Name)
+	}
+	from, to := ctx.Uint64(replayBlockFlag.Name), ctx.Uint64(replayBlockFlag.Name)
+	if ctx.IsSet(replayToFlag.Name) {
+		to = ctx.Uint64(replayToFlag.Name)
+	}
+	if from > to {
+		return errors.New("--block is after --to")
+	}
+
+	kv, err := ethdb.NewLMDB().Path(filepath.Join(ctx.String(utils.DataDirFlag.Name), "tg", "chaindata")).ReadOnly().Open()
+	if err != nil {
+		return err
+	}
+	db := ethdb.NewObjectDatabase(kv)
+	defer db.Close()
+
+	sm, err := ethdb.GetStorageModeFromDB(db)
+	if err != nil {
+		return err
+	}
+	if !sm.History {
+		return errors.New("the database has no history, the historical state cannot be read")
+	}
+	chainConfig := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if chainConfig == nil {
+		return errors.New("chain config not found in the database")
+	}
+
+	var out io.Writer = os.Stdout
+	if path := ctx.String(replayOutputFlag.Name); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	var mismatchingBlocks int
+	for blockNum := from; blockNum <= to; blockNum++ {
+		replayed, err := transactions.ReplayBlock(kv, db, chainConfig, blockNum)
+		if err != nil {
+			return err
+		}
+		if len(replayed.ChangeSetMismatches) > 0 {
+			log.Warn("Changesets mismatch", "block", blockNum, "mismatches", len(replayed.ChangeSetMismatches))
+			mismatchingBlocks++
+		}
+		if err = enc.Encode(replayed); err != nil {
+			return err
+		}
+	}
+	if mismatchingBlocks > 0 {
+		return fmt.Errorf("the stored changesets of %d of %d blocks differ from the re-execution", mismatchingBlocks, to-from+1)
+	}
+	return nil
+}
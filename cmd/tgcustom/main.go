@@ -5,14 +5,17 @@ import (
 	"os"
 
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/turbo/node"
 
 	turbocli "github.com/ledgerwatch/turbo-geth/turbo/cli"
 
 	"github.com/urfave/cli"
+	"golang.org/x/crypto/sha3"
 )
 
 // defining a custom command-line flag, a string
@@ -26,8 +29,29 @@ const (
 	customBucketName = "ch.torquem.demo.tgcustom.CUSTOM_BUCKET"
 )
 
+// defining a custom precompiled contract, computing the keccak512 hash of its input.
+// the chain config enables it in the genesis JSON file, e.g. from the genesis block on:
+// "precompiles": [{"name": "ch.torquem.demo.tgcustom.KECCAK512", "address": "0x0000000000000000000000000000000000000100", "block": 0}]
+const customPrecompileName = "ch.torquem.demo.tgcustom.KECCAK512"
+
+type keccak512 struct{}
+
+// RequiredGas returns the gas consumed by the precompile, priced like the KECCAK256 opcode
+func (keccak512) RequiredGas(input []byte) uint64 {
+	return params.Sha3Gas + params.Sha3WordGas*uint64((len(input)+31)/32)
+}
+
+func (keccak512) Run(input []byte) ([]byte, error) {
+	hasher := sha3.NewLegacyKeccak512()
+	hasher.Write(input) //nolint:errcheck
+	return hasher.Sum(nil), nil
+}
+
 // the regular main function
 func main() {
+	// registering our precompile, so that chain configs can enable it
+	vm.RegisterPrecompile(customPrecompileName, keccak512{})
+
 	// initializing turbo-geth application here and providing our custom flag
 	app := turbocli.MakeApp(runTurboGeth,
 		append(turbocli.DefaultFlags, flag), // always use DefaultFlags, but add a new one in the end.
@@ -314,6 +314,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolNoPersistFlag = cli.BoolFlag{
+		Name:  "txpool.nopersist",
+		Usage: "Disables persisting the whole transaction pool into the database to survive node restarts",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -410,11 +414,20 @@ var (
 		Name:  "hdd",
 		Usage: "Perform warm up loop during transaction replay stage to reduce the impact of high latency of HDD",
 	}
+	ReceiptsSyncFlag = cli.BoolFlag{
+		Name:  "sync.receipts",
+		Usage: "Download receipts from peers instead of executing blocks (no state, only blocks, transactions and receipts are kept)",
+	}
 	PrivateApiAddr = cli.StringFlag{
 		Name:  "private.api.addr",
 		Usage: "private api network address, for example: 127.0.0.1:9090, empty string means not to start the listener. do not expose to public network. serves remote database interface",
 		Value: "",
 	}
+	PrivateApiACL = cli.StringFlag{
+		Name:  "private.api.acl",
+		Usage: "JSON file with the clients of the private api: their certificate subjects or tokens, buckets, concurrent streams, transaction time and request rate. reloaded on SIGHUP. empty string means that any client can read all the buckets",
+		Value: "",
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -760,6 +773,37 @@ var (
 		Usage: "External EVM configuration (default = built-in interpreter)",
 		Value: "",
 	}
+	DiffEVMInterpreterFlag = cli.StringFlag{
+		Name:  "vm.evm.diff",
+		Usage: "External EVM configuration to re-execute every transaction with during the Execution stage, logging the divergences from the built-in interpreter",
+		Value: "",
+	}
+	DiffStopFlag = cli.BoolFlag{
+		Name:  "vm.evm.diff.stop",
+		Usage: "Stop the Execution stage on the first divergence of --vm.evm.diff instead of logging it",
+	}
+	StateDiffFileFlag = cli.StringFlag{
+		Name:  "statediff.file",
+		Usage: "Append the state diffs of the executed blocks, and the unwinds on reorgs, to the file (relative to the data directory)",
+		Value: "",
+	}
+	StateDiffStreamFlag = cli.BoolFlag{
+		Name:  "statediff.stream",
+		Usage: "Stream the state diffs of the executed blocks over the private API, resuming from the cursors of the subscribers if --statediff.file is set",
+	}
+	ExecutionCacheFlag = cli.IntFlag{
+		Name:  "execution.cache",
+		Usage: "Megabytes of memory for the accounts, the storage and the code sizes cached across the blocks by the Execution stage (0 to disable)",
+		Value: eth.DefaultConfig.ExecutionCache,
+	}
+	ExecutionWorkersFlag = cli.IntFlag{
+		Name:  "execution.workers",
+		Usage: "Goroutines executing the transactions of a block speculatively, on the state at the beginning of the block (0 or 1 to execute them in order)",
+	}
+	SentryAddrFlag = cli.StringFlag{
+		Name:  "sentry.addr",
+		Usage: "Comma separated addresses of the sentries to download the headers and the bodies through, instead of connecting to the peers from the node ('<host>:<port>,<host>:<port>')",
+	}
 
 	// LMDB flags
 	LMDBMapSizeFlag = cli.StringFlag{
@@ -990,6 +1034,7 @@ func splitAndTrim(input string) (ret []string) {
 // read-only interface to the databae
 func setPrivateApi(ctx *cli.Context, cfg *node.Config) {
 	cfg.PrivateApiAddr = ctx.GlobalString(PrivateApiAddr.Name)
+	cfg.PrivateApiACL = ctx.GlobalString(PrivateApiACL.Name)
 	if ctx.GlobalBool(TLSFlag.Name) {
 		certFile := ctx.GlobalString(TLSCertFlag.Name)
 		keyFile := ctx.GlobalString(TLSKeyFlag.Name)
@@ -1199,6 +1244,13 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NoDiscovery = true
 		cfg.DiscoveryV5 = false
 	}
+	if ctx.GlobalIsSet(SentryAddrFlag.Name) {
+		// the peers are connected to the sentries, not to the node
+		cfg.MaxPeers = 0
+		cfg.ListenAddr = ":0"
+		cfg.NoDiscovery = true
+		cfg.DiscoveryV5 = false
+	}
 }
 
 // SetNodeConfig applies node-related command line flags to the config.
@@ -1355,6 +1407,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolNoPersistFlag.Name) {
+		cfg.NoPersist = ctx.GlobalBool(TxPoolNoPersistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1578,6 +1633,27 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(EVMInterpreterFlag.Name) {
 		cfg.EVMInterpreter = ctx.GlobalString(EVMInterpreterFlag.Name)
 	}
+	if ctx.GlobalIsSet(DiffEVMInterpreterFlag.Name) {
+		cfg.DiffEVMInterpreter = ctx.GlobalString(DiffEVMInterpreterFlag.Name)
+	}
+	if ctx.GlobalIsSet(DiffStopFlag.Name) {
+		cfg.DiffStop = ctx.GlobalBool(DiffStopFlag.Name)
+	}
+	if ctx.GlobalIsSet(StateDiffFileFlag.Name) {
+		cfg.StateDiffFile = ctx.GlobalString(StateDiffFileFlag.Name)
+	}
+	if ctx.GlobalIsSet(StateDiffStreamFlag.Name) {
+		cfg.StateDiffStream = ctx.GlobalBool(StateDiffStreamFlag.Name)
+	}
+	if ctx.GlobalIsSet(ExecutionCacheFlag.Name) {
+		cfg.ExecutionCache = ctx.GlobalInt(ExecutionCacheFlag.Name)
+	}
+	if ctx.GlobalIsSet(ExecutionWorkersFlag.Name) {
+		cfg.ExecutionWorkers = ctx.GlobalInt(ExecutionWorkersFlag.Name)
+	}
+	if ctx.GlobalIsSet(SentryAddrFlag.Name) {
+		cfg.SentryAddrs = splitAndTrim(ctx.GlobalString(SentryAddrFlag.Name))
+	}
 	if ctx.GlobalIsSet(RPCGlobalGasCap.Name) {
 		cfg.RPCGasCap = ctx.GlobalUint64(RPCGlobalGasCap.Name)
 	}
@@ -64,13 +64,15 @@ var (
 	CurrentStateBucket     = "CST2"
 	CurrentStateBucketOld1 = "CST"
 
-	//key - address hash
-	//value - list of block where it's changed
-	AccountsHistoryBucket = "hAT"
+	//key - address + last block of the chunk (^uint64(0) for the last chunk)
+	//value - roaring bitmap - list of blocks where it's changed
+	AccountsHistoryBucket     = "hAT2"
+	AccountsHistoryBucketOld1 = "hAT"
 
-	//key - address hash
-	//value - list of block where it's changed
-	StorageHistoryBucket = "hST"
+	//key - address + storage key + last block of the chunk (^uint64(0) for the last chunk)
+	//value - roaring bitmap - list of blocks where it's changed
+	StorageHistoryBucket     = "hST2"
+	StorageHistoryBucketOld1 = "hST"
 
 	//key - contract code hash
 	//value - contract code
@@ -145,6 +147,11 @@ var (
 	// headFastBlockKey tracks the latest known incomplete block's hash during fast sync.
 	HeadFastBlockKey = "LastFast"
 
+	// TxPoolBucket keeps the snapshot of the transaction pool to survive node restarts
+	// key - sender address + nonce (uint64 big endian)
+	// value - origin flag (local or remote) + RLP encoded transaction
+	TxPoolBucket = "txPool"
+
 	// migrationName -> serialized SyncStageProgress and SyncStageUnwind buckets
 	// it stores stages progress to understand in which context was executed migration
 	// in case of bug-report developer can ask content of this bucket
@@ -210,6 +217,7 @@ var Buckets = []string{
 	HeadFastBlockKey,
 	HeadHeaderKey,
 	Migrations,
+	TxPoolBucket,
 }
 
 // DeprecatedBuckets - list of buckets which can be programmatically deleted - for example after migration
@@ -219,6 +227,8 @@ var DeprecatedBuckets = []string{
 	CurrentStateBucketOld1,
 	PlainStateBucketOld1,
 	IntermediateTrieHashBucketOld1,
+	AccountsHistoryBucketOld1,
+	StorageHistoryBucketOld1,
 }
 
 type CustomComparator string
@@ -11,6 +11,12 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+)
+
+var (
+	spilledFilesCounter = metrics.NewRegisteredCounter("etl/spilled/files", nil)
+	spilledBytesCounter = metrics.NewRegisteredCounter("etl/spilled/bytes", nil) // keys and values, before encoding
 )
 
 type dataProvider interface {
@@ -62,12 +68,16 @@ func FlushToDisk(encoder Encoder, currentKey []byte, b Buffer, datadir string) (
 	}()
 
 	encoder.Reset(w)
+	var spilled int64
 	for _, entry := range b.GetEntries() {
 		err = writeToDisk(encoder, entry.key, entry.value)
 		if err != nil {
 			return nil, fmt.Errorf("error writing entries to disk: %v", err)
 		}
+		spilled += int64(len(entry.key) + len(entry.value))
 	}
+	spilledFilesCounter.Inc(1)
+	spilledBytesCounter.Inc(spilled)
 
 	return &fileDataProvider{bufferFile, nil}, nil
 }
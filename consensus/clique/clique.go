@@ -430,6 +430,25 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 	return snap, err
 }
 
+// UnwindTo implements consensus.Unwinder, removing the voting snapshots of the
+// blocks after unwindPoint from memory and from the database. They are rebuilt
+// from the preceding snapshots when the headers are verified again.
+func (c *Clique) UnwindTo(unwindPoint uint64) error {
+	for _, hash := range c.recents.Keys() {
+		if s, ok := c.recents.Peek(hash); ok && s.(*Snapshot).Number > unwindPoint {
+			c.recents.Remove(hash)
+		}
+	}
+	deleted, err := deleteSnapshots(c.db, unwindPoint)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Info("Removed voting snapshots from disk", "after", unwindPoint, "count", deleted)
+	}
+	return nil
+}
+
 // VerifyUncles implements consensus.Engine, always returning an error for any
 // uncles as this consensus mechanism doesn't permit uncles.
 func (c *Clique) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
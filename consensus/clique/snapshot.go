@@ -112,6 +112,30 @@ func (s *Snapshot) store(db ethdb.Database) error {
 	return db.Put(dbutils.CliqueBucket, s.Hash[:], blob)
 }
 
+// deleteSnapshots removes the snapshots of the blocks after the given number from
+// the database, returning how many were removed.
+func deleteSnapshots(db ethdb.Database, after uint64) (int, error) {
+	var stale [][]byte
+	if err := db.Walk(dbutils.CliqueBucket, nil, 0, func(k, v []byte) (bool, error) {
+		snap := new(Snapshot)
+		if err := json.Unmarshal(v, snap); err != nil {
+			return false, err
+		}
+		if snap.Number > after {
+			stale = append(stale, common.CopyBytes(k))
+		}
+		return true, nil
+	}); err != nil {
+		return 0, err
+	}
+	for _, k := range stale {
+		if err := db.Delete(dbutils.CliqueBucket, k); err != nil {
+			return 0, err
+		}
+	}
+	return len(stale), nil
+}
+
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	cpy := &Snapshot{
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"errors"
+	"math/big"
 	"runtime"
 	"sort"
 	"testing"
@@ -515,3 +516,33 @@ func TestClique(t *testing.T) {
 		db.Close()
 	}
 }
+
+// Tests that unwinding removes the snapshots of the unwound blocks from memory
+// and from the database, keeping the older ones.
+func TestUnwindSnapshots(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	engine := New(params.AllCliqueProtocolChanges.Clique, db)
+
+	signers := []common.Address{common.HexToAddress("0x01")}
+	for _, number := range []uint64{0, 1024, 2048} {
+		snap := newSnapshot(engine.config, engine.signatures, number, common.BigToHash(new(big.Int).SetUint64(number+1)), signers)
+		if err := snap.store(db); err != nil {
+			t.Fatal(err)
+		}
+		engine.recents.Add(snap.Hash, snap)
+	}
+	if err := engine.UnwindTo(1024); err != nil {
+		t.Fatal(err)
+	}
+	for _, number := range []uint64{0, 1024, 2048} {
+		hash := common.BigToHash(new(big.Int).SetUint64(number + 1))
+		_, err := loadSnapshot(engine.config, engine.signatures, db, hash)
+		if stored := err == nil; stored != (number <= 1024) {
+			t.Errorf("snapshot %d: stored %t after the unwind to 1024 (%v)", number, stored, err)
+		}
+		if cached := engine.recents.Contains(hash); cached != (number <= 1024) {
+			t.Errorf("snapshot %d: cached %t after the unwind to 1024", number, cached)
+		}
+	}
+}
@@ -128,3 +128,13 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// Unwinder is a consensus engine persisting data derived from the headers, like
+// the voting snapshots of clique, which has to be removed when the staged sync
+// unwinds the headers.
+type Unwinder interface {
+	Engine
+
+	// UnwindTo removes the data derived from the headers after unwindPoint.
+	UnwindTo(unwindPoint uint64) error
+}
@@ -2140,6 +2140,8 @@ func ExecuteBlockEphemerally(
 ) (types.Receipts, error) {
 	defer blockExecutionTimer.UpdateSince(time.Now())
 
+	// The transactions are executed in parallel unless they are compared with another interpreter
+	parallel := vmConfig.ExecutionWorkers > 1 && vmConfig.DiffEVMInterpreter == "" && !vmConfig.Debug && len(block.Transactions()) > 1
 	ibs := state.New(stateReader)
 	header := block.Header()
 	var receipts types.Receipts
@@ -2150,13 +2152,26 @@ func ExecuteBlockEphemerally(
 		misc.ApplyDAOHardFork(ibs)
 	}
 	noop := state.NewNoopWriter()
-	for i, tx := range block.Transactions() {
-		ibs.Prepare(tx.Hash(), block.Hash(), i)
-		receipt, err := ApplyTransaction(chainConfig, chainContext, nil, gp, ibs, noop, header, tx, usedGas, *vmConfig)
-		if err != nil {
-			return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+	if parallel {
+		var err error
+		if receipts, err = applyTransactionsParallel(chainConfig, vmConfig, chainContext, block, ibs, stateReader, gp, usedGas); err != nil {
+			return nil, err
+		}
+	} else {
+		for i, tx := range block.Transactions() {
+			ibs.Prepare(tx.Hash(), block.Hash(), i)
+			var receipt *types.Receipt
+			var err error
+			if vmConfig.DiffEVMInterpreter != "" {
+				receipt, err = applyTransactionDiff(chainConfig, chainContext, nil, gp, ibs, header, block.Hash(), i, tx, usedGas, *vmConfig)
+			} else {
+				receipt, err = ApplyTransaction(chainConfig, chainContext, nil, gp, ibs, noop, header, tx, usedGas, *vmConfig)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+			}
+			receipts = append(receipts, receipt)
 		}
-		receipts = append(receipts, receipt)
 	}
 
 	if chainConfig.IsByzantium(header.Number) {
@@ -1,8 +1,13 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 )
 
@@ -15,15 +20,18 @@ type Backend interface {
 	Etherbase() (common.Address, error)
 	NetVersion() (uint64, error)
 	BloomIndexer() *ChainIndexer
+	SyncStatus() (*ethdb.SyncStatus, error)
 }
 
 func NewEthBackend(eth Backend) *EthBackend {
 	return &EthBackend{eth}
 }
 
+// AddLocal adds the transaction in the binary encoding (RLP for the legacy transactions, the EIP-2718 envelope
+// for the typed ones) to the pool, as a local one
 func (back *EthBackend) AddLocal(signedtx []byte) ([]byte, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(signedtx, tx); err != nil {
+	if err := tx.UnmarshalBinary(signedtx); err != nil {
 		return common.Hash{}.Bytes(), err
 	}
 
@@ -33,3 +41,70 @@ func (back *EthBackend) AddLocal(signedtx []byte) ([]byte, error) {
 func (back *EthBackend) BloomStatus() (uint64, uint64, common.Hash) {
 	return back.Backend.BloomIndexer().Sections()
 }
+
+func (back *EthBackend) PoolContent() ([]ethdb.PoolTransaction, []ethdb.PoolTransaction, error) {
+	pending, queued := back.TxPool().Content()
+
+	convert := func(content map[common.Address]types.Transactions) ([]ethdb.PoolTransaction, error) {
+		var out []ethdb.PoolTransaction
+		for _, sender := range sortedSenders(content) {
+			for _, tx := range content[sender] {
+				encoded, err := rlp.EncodeToBytes(tx)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, ethdb.PoolTransaction{Sender: sender, RlpTx: encoded})
+			}
+		}
+		return out, nil
+	}
+	pendingTxs, err := convert(pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	queuedTxs, err := convert(queued)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pendingTxs, queuedTxs, nil
+}
+
+func (back *EthBackend) PoolStatus() (uint64, uint64, error) {
+	pending, queued := back.TxPool().Stats()
+	return uint64(pending), uint64(queued), nil
+}
+
+func (back *EthBackend) PoolInspect() ([]ethdb.PoolInspectItem, []ethdb.PoolInspectItem, error) {
+	pending, queued := back.TxPool().Content()
+
+	convert := func(content map[common.Address]types.Transactions) []ethdb.PoolInspectItem {
+		var out []ethdb.PoolInspectItem
+		for _, sender := range sortedSenders(content) {
+			for _, tx := range content[sender] {
+				out = append(out, ethdb.PoolInspectItem{Sender: sender, Nonce: tx.Nonce(), Summary: PoolTxSummary(tx)})
+			}
+		}
+		return out
+	}
+	return convert(pending), convert(queued), nil
+}
+
+// PoolTxSummary flattens a transaction of the transaction pool into a human readable string,
+// as returned by txpool_inspect
+func PoolTxSummary(tx *types.Transaction) string {
+	if to := tx.To(); to != nil {
+		return fmt.Sprintf("%s: %v wei + %v gas × %v wei", to.Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+	}
+	return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+}
+
+func sortedSenders(content map[common.Address]types.Transactions) []common.Address {
+	senders := make([]common.Address, 0, len(content))
+	for sender := range content {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+	return senders
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// testBackend - the backend with the transaction pool only
+type testBackend struct {
+	Backend
+	pool *TxPool
+}
+
+func (b testBackend) TxPool() *TxPool { return b.pool }
+
+// TestEthBackendAddLocalTyped tests that the typed transactions are accepted in
+// their EIP-2718 envelope, like the legacy ones in RLP.
+func TestEthBackendAddLocalTyped(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := *params.TestChainConfig
+	config.BerlinBlock = big.NewInt(0)
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(testTxPoolConfig, &config, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, uint256.NewInt().SetUint64(1000000000))
+
+	signer := types.NewEIP2930Signer(config.ChainID)
+	accessListTx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+		ChainID:    *uint256.NewInt().SetUint64(config.ChainID.Uint64()),
+		Nonce:      0,
+		GasPrice:   *uint256.NewInt().SetUint64(1),
+		Gas:        100000,
+		To:         &common.Address{1},
+		AccessList: types.AccessList{{Address: common.Address{1}, StorageKeys: []common.Hash{{}}}},
+	}), signer, key)
+	if err != nil {
+		t.Fatalf("signing access list transaction: %v", err)
+	}
+	legacyTx, err := types.SignTx(types.NewTransaction(1, common.Address{1}, uint256.NewInt(), 100000, uint256.NewInt().SetUint64(1), nil), signer, key)
+	if err != nil {
+		t.Fatalf("signing legacy transaction: %v", err)
+	}
+
+	backend := NewEthBackend(testBackend{pool: pool})
+	for _, tx := range []*types.Transaction{accessListTx, legacyTx} {
+		encoded, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encoding transaction type %d: %v", tx.Type(), err)
+		}
+		hash, err := backend.AddLocal(encoded)
+		if err != nil {
+			t.Fatalf("adding transaction type %d: %v", tx.Type(), err)
+		}
+		if common.BytesToHash(hash) != tx.Hash() {
+			t.Fatalf("hash mismatch of transaction type %d: have %x, want %x", tx.Type(), hash, tx.Hash())
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+}
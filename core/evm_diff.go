@@ -0,0 +1,69 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+var evmDiffCounter = metrics.NewRegisteredCounter("chain/evm/diff", nil)
+
+// applyTransactionDiff applies the transaction like ApplyTransaction with the built-in interpreter, and
+// also executes it on a copy of the state with the interpreter configured by cfg.DiffEVMInterpreter.
+// The status, return data, gas used and state writes of the two executions are compared, a divergence
+// is logged, or returned as an error if cfg.DiffStop is set. Either way, the state is advanced with the
+// results of the built-in interpreter.
+func applyTransactionDiff(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, ibs *state.IntraBlockState, header *types.Header, blockHash common.Hash, txIndex int, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, error) {
+	diffCfg := cfg
+	diffCfg.EVMInterpreter, diffCfg.DiffEVMInterpreter = cfg.DiffEVMInterpreter, ""
+	cfg.EVMInterpreter, cfg.DiffEVMInterpreter = "", ""
+
+	diffIbs := ibs.Copy()
+	diffIbs.Prepare(tx.Hash(), blockHash, txIndex)
+	diffGp := new(GasPool).AddGas(gp.Gas())
+	diffWriter := state.NewRecordingWriter()
+	var diffUsedGas uint64
+	_, diffResult, diffErr := applyTransaction(config, bc, author, diffGp, diffIbs, diffWriter, header, tx, &diffUsedGas, diffCfg)
+
+	writer := state.NewRecordingWriter()
+	receipt, result, err := applyTransaction(config, bc, author, gp, ibs, writer, header, tx, usedGas, cfg)
+	if err != nil {
+		// The transaction is invalid, the block is rejected regardless of the other interpreter
+		return nil, err
+	}
+
+	var diff []string
+	if diffErr != nil {
+		diff = append(diff, fmt.Sprintf("error: none vs %v", diffErr))
+	} else {
+		if result.Failed() != diffResult.Failed() {
+			diff = append(diff, fmt.Sprintf("failed: %t vs %t", result.Failed(), diffResult.Failed()))
+		}
+		if !bytes.Equal(result.ReturnData, diffResult.ReturnData) {
+			diff = append(diff, fmt.Sprintf("return data: %x vs %x", result.ReturnData, diffResult.ReturnData))
+		}
+		if result.UsedGas != diffResult.UsedGas {
+			diff = append(diff, fmt.Sprintf("gas used: %d vs %d", result.UsedGas, diffResult.UsedGas))
+		}
+		diff = append(diff, writer.Diff(diffWriter)...)
+	}
+	if len(diff) == 0 {
+		return receipt, nil
+	}
+
+	evmDiffCounter.Inc(1)
+	if cfg.DiffStop {
+		return nil, fmt.Errorf("interpreter %s diverges from the built-in one in block %d, tx %x: %s",
+			diffCfg.EVMInterpreter, header.Number, tx.Hash(), strings.Join(diff, "; "))
+	}
+	log.Error("Interpreters diverge, built-in vs external", "block", header.Number, "tx", tx.Hash(), "diff", strings.Join(diff, "; "))
+	return receipt, nil
+}
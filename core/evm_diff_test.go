@@ -0,0 +1,104 @@
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// buildGoEVM builds the EVMC module of the built-in interpreter in testdata/goevm
+func buildGoEVM(t *testing.T, dir string) string {
+	evmcDir, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/ethereum/evmc/v7").Output()
+	if err != nil {
+		t.Fatalf("locating the EVMC headers: %v", err)
+	}
+	path := filepath.Join(dir, "goevm.so")
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", path, "./testdata/goevm")
+	cmd.Env = append(os.Environ(), "CGO_CFLAGS=-I"+filepath.Join(strings.TrimSpace(string(evmcDir)), "include"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the EVMC module: %v\n%s", err, out)
+	}
+	return path
+}
+
+func TestExecuteBlockDiff(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the EVMC module")
+	}
+	dir, err := ioutil.TempDir("", "goevm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goevm := buildGoEVM(t, dir)
+
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xcc")
+		gspec    = &Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				// SSTORE(0, 1), MSTORE(0, 42), RETURN(0, 32)
+				contract: {Balance: new(big.Int), Code: []byte{
+					byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+					byte(vm.PUSH1), 42, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+					byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+				}},
+			},
+		}
+		signer = types.MakeSigner(gspec.Config, big.NewInt(1))
+	)
+	genDb := ethdb.NewMemDatabase()
+	defer genDb.Close()
+	chain, _, err := GenerateChain(gspec.Config, gspec.MustCommit(genDb), ethash.NewFaker(), genDb, 1, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), contract, uint256.NewInt(), 100000, uint256.NewInt().SetUint64(1), nil), signer, key)
+		gen.AddTx(tx)
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := chain[0]
+
+	execute := func(vmConfig vm.Config) error {
+		db := ethdb.NewMemDatabase()
+		defer db.Close()
+		gspec.MustCommit(db)
+		blockchain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vmConfig, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer blockchain.Stop()
+		_, err = ExecuteBlockEphemerally(gspec.Config, &vmConfig, blockchain, ethash.NewFaker(), block, state.NewPlainStateReader(db), state.NewPlainStateWriter(db, db, block.NumberU64()))
+		return err
+	}
+
+	// The external interpreters run through the EVMC host
+	if err := execute(vm.Config{DiffEVMInterpreter: goevm, DiffStop: true}); err != nil {
+		t.Errorf("unexpected divergence from the same interpreter: %v", err)
+	}
+	err = execute(vm.Config{DiffEVMInterpreter: goevm + ",overcharge=1", DiffStop: true})
+	if err == nil || !strings.Contains(err.Error(), "gas used") || !strings.Contains(err.Error(), "account "+strings.ToLower(address.Hex()[2:])) {
+		t.Errorf("expected the gas and the sender balance to diverge, got %v", err)
+	}
+	// Without DiffStop, the block is executed with the results of the built-in interpreter
+	if err := execute(vm.Config{DiffEVMInterpreter: goevm + ",overcharge=1"}); err != nil {
+		t.Errorf("unexpected error when logging the divergences: %v", err)
+	}
+}
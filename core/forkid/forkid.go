@@ -67,12 +67,19 @@ type Filter func(id ID) error
 
 // NewID calculates the Ethereum fork ID from the chain config and head.
 func NewID(config *params.ChainConfig, genesis common.Hash, head uint64) ID {
+	return NewIDFromForks(GatherForks(config), genesis, head)
+}
+
+// NewIDFromForks calculates the Ethereum fork ID from the sorted list of fork block
+// numbers (as returned by GatherForks) and head. It is used by the processes which
+// don't have access to the chain config, for example, by the sentry.
+func NewIDFromForks(forks []uint64, genesis common.Hash, head uint64) ID {
 	// Calculate the starting checksum from the genesis hash
 	hash := crc32.ChecksumIEEE(genesis[:])
 
 	// Calculate the current fork checksum and the next fork block
 	var next uint64
-	for _, fork := range gatherForks(config) {
+	for _, fork := range forks {
 		if fork <= head {
 			// Fork already passed, checksum the previous hash and the fork number
 			hash = checksumUpdate(hash, fork)
@@ -96,6 +103,18 @@ func NewFilter(config *params.ChainConfig, genesis common.Hash, head uint64) Fil
 	)
 }
 
+// NewFilterFromForks creates a filter that returns if a fork ID should be rejected or not
+// based on the sorted list of fork block numbers (as returned by GatherForks) and head.
+func NewFilterFromForks(forks []uint64, genesis common.Hash, head uint64) Filter {
+	return newFilterFromForks(
+		forks,
+		genesis,
+		func() uint64 {
+			return head
+		},
+	)
+}
+
 // NewStaticFilter creates a filter at block zero.
 func NewStaticFilter(config *params.ChainConfig, genesis common.Hash) Filter {
 	head := func() uint64 { return 0 }
@@ -106,11 +125,12 @@ func NewStaticFilter(config *params.ChainConfig, genesis common.Hash) Filter {
 // instead of a chain. The reason is to allow testing it without having to simulate
 // an entire blockchain.
 func newFilter(config *params.ChainConfig, genesis common.Hash, headfn func() uint64) Filter {
+	return newFilterFromForks(GatherForks(config), genesis, headfn)
+}
+
+func newFilterFromForks(forks []uint64, genesis common.Hash, headfn func() uint64) Filter {
 	// Calculate the all the valid fork hash and fork next combos
-	var (
-		forks = gatherForks(config)
-		sums  = make([][4]byte, len(forks)+1) // 0th is the genesis
-	)
+	sums := make([][4]byte, len(forks)+1) // 0th is the genesis
 	hash := crc32.ChecksumIEEE(genesis[:])
 	sums[0] = checksumToBytes(hash)
 	for i, fork := range forks {
@@ -119,7 +139,7 @@ func newFilter(config *params.ChainConfig, genesis common.Hash, headfn func() ui
 	}
 	// Add two sentries to simplify the fork checks and don't require special
 	// casing the last one.
-	forks = append(forks, math.MaxUint64) // Last fork will never be passed
+	forks = append(forks[:len(forks):len(forks)], math.MaxUint64) // Last fork will never be passed
 
 	// Create a validator that will filter out incompatible chains
 	return func(id ID) error {
@@ -202,8 +222,8 @@ func checksumToBytes(hash uint32) [4]byte {
 	return blob
 }
 
-// gatherForks gathers all the known forks and creates a sorted list out of them.
-func gatherForks(config *params.ChainConfig) []uint64 {
+// GatherForks gathers all the known forks and creates a sorted list out of them.
+func GatherForks(config *params.ChainConfig) []uint64 {
 	// Gather all the fork block numbers via reflection
 	kind := reflect.TypeOf(params.ChainConfig{})
 	conf := reflect.ValueOf(config).Elem()
@@ -4,14 +4,18 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/changeset"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/common/etl"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
 	"github.com/ledgerwatch/turbo-geth/log"
 )
 
@@ -109,16 +113,26 @@ func (ig *IndexGenerator) Truncate(timestampTo uint64, changeSetBucket string) e
 			timestamp := binary.BigEndian.Uint64(k[keySize:]) // the last timestamp in the chunk
 			kStr := string(common.CopyBytes(k))
 			if timestamp > timestampTo {
-				historyEffects[kStr] = nil
+				// The last chunk may already hold the truncated part of a previous chunk
+				if _, ok := historyEffects[kStr]; !ok {
+					historyEffects[kStr] = nil
+				}
 				// truncate the chunk
-				index := dbutils.WrapHistoryIndex(v)
-				index = index.TruncateGreater(timestampTo)
-				if len(index) > 8 { // If the chunk is empty after truncation, it gets simply deleted
+				index := roaring.New()
+				if err := index.UnmarshalBinary(v); err != nil {
+					return false, err
+				}
+				index.RemoveRange(timestampTo+1, uint64(math.MaxUint32)+1)
+				if !index.IsEmpty() { // If the chunk is empty after truncation, it gets simply deleted
 					// Truncated chunk becomes "the last chunk" with the timestamp 0xffff....ffff
 					lastK := make([]byte, len(k))
 					copy(lastK, k[:keySize])
 					binary.BigEndian.PutUint64(lastK[keySize:], ^uint64(0))
-					historyEffects[string(lastK)] = common.CopyBytes(index)
+					buf, err := index.ToBytes()
+					if err != nil {
+						return false, err
+					}
+					historyEffects[string(lastK)] = buf
 				}
 			}
 			return true, nil
@@ -159,59 +173,43 @@ func (ig *IndexGenerator) DropIndex(bucket string) error {
 }
 
 func loadFunc(k []byte, value []byte, state etl.State, next etl.LoadNextFunc) error {
-	if len(value)%9 != 0 {
-		log.Error("Value must be a multiple of 9", "ln", len(value), "k", common.Bytes2Hex(k))
+	if len(value)%8 != 0 {
+		log.Error("Value must be a multiple of 8", "ln", len(value), "k", common.Bytes2Hex(k))
 		return errors.New("incorrect value")
 	}
-	k = common.CopyBytes(k)
-	currentChunkKey := dbutils.IndexChunkKey(k, ^uint64(0))
+	currentChunkKey := dbutils.CurrentChunkKey(k)
+	k = dbutils.CompositeKeyWithoutIncarnation(k)
 	indexBytes, err1 := state.Get(currentChunkKey)
 	if err1 != nil && !errors.Is(err1, ethdb.ErrKeyNotFound) {
 		return fmt.Errorf("find chunk failed: %w", err1)
 	}
-	currentIndex := dbutils.WrapHistoryIndex(indexBytes)
-
-	for i := 0; i < len(value); i += 9 {
-		b := binary.BigEndian.Uint64(value[i:])
-		vzero := value[i+8] == 1
-		blockNr := b
-		if err1 != nil && err1 != ethdb.ErrKeyNotFound {
-			return fmt.Errorf("find chunk failed: %w", err1)
-		}
-
-		if dbutils.CheckNewIndexChunk(currentIndex, blockNr) {
-			// Chunk overflow, need to write the "old" current chunk under its key derived from the last element
-			indexKey, err3 := currentIndex.Key(k)
-			if err3 != nil {
-				return err3
-			}
-			// Flush the old chunk
-			if err4 := next(k, indexKey, currentIndex); err4 != nil {
-				return err4
-			}
-			// Start a new chunk
-			currentIndex = dbutils.NewHistoryIndex()
+	// The blocks are merged into the last chunk, which is split again if it overflows
+	currentIndex := roaring.New()
+	if len(indexBytes) > 0 {
+		if err := currentIndex.UnmarshalBinary(indexBytes); err != nil {
+			return fmt.Errorf("decode chunk %x: %w", currentChunkKey, err)
 		}
-		currentIndex = currentIndex.Append(blockNr, vzero)
 	}
-
-	if err := next(k, currentChunkKey, currentIndex); err != nil {
-		return err
+	for i := 0; i < len(value); i += 8 {
+		currentIndex.Add(uint32(binary.BigEndian.Uint64(value[i:])))
 	}
 
-	return nil
+	return bitmapdb.WalkChunkWithKeys(k, currentIndex, bitmapdb.ChunkLimit, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+		buf, err := chunk.ToBytes()
+		if err != nil {
+			return err
+		}
+		return next(k, chunkKey, buf)
+	})
 }
 
 func getExtractFunc(bytes2walker func([]byte) changeset.Walker) etl.ExtractFunc { //nolint
 	return func(dbKey, dbValue []byte, next etl.ExtractNextFunc) error {
 		blockNum, _ := dbutils.DecodeTimestamp(dbKey)
-		return bytes2walker(dbValue).Walk(func(changesetKey, changesetValue []byte) error {
+		return bytes2walker(dbValue).Walk(func(changesetKey, _ []byte) error {
 			key := common.CopyBytes(changesetKey)
-			v := make([]byte, 9)
+			v := make([]byte, 8)
 			binary.BigEndian.PutUint64(v, blockNum)
-			if len(changesetValue) == 0 {
-				v[8] = 1
-			}
 			return next(dbKey, key, v)
 		})
 	}
@@ -9,12 +9,14 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/RoaringBitmap/roaring"
 
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/crypto"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
 	"github.com/ledgerwatch/turbo-geth/log"
 )
 
@@ -38,19 +40,13 @@ func TestIndexGenerator_GenerateIndex_SimpleCase(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			checkIndex(t, db, csInfo.IndexBucket, addrs[0], 0, expecedIndexes[string(addrs[0])][0])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[0], 999, expecedIndexes[string(addrs[0])][0])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[0], 1000, expecedIndexes[string(addrs[0])][1])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[0], 1999, expecedIndexes[string(addrs[0])][1])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[0], 2000, expecedIndexes[string(addrs[0])][2])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[1], 0, expecedIndexes[string(addrs[1])][0])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[1], 2000, expecedIndexes[string(addrs[1])][1])
-			checkIndex(t, db, csInfo.IndexBucket, addrs[2], 0, expecedIndexes[string(addrs[2])][0])
-
-			//check last chunk
-			lastChunkCheck(t, db, csInfo.IndexBucket, addrs[0], expecedIndexes[string(addrs[0])][2])
-			lastChunkCheck(t, db, csInfo.IndexBucket, addrs[1], expecedIndexes[string(addrs[1])][1])
-			lastChunkCheck(t, db, csInfo.IndexBucket, addrs[2], expecedIndexes[string(addrs[2])][0])
+			for _, addr := range addrs {
+				checkIndex(t, db, csInfo.IndexBucket, addr, expecedIndexes[string(addr)])
+			}
+			// The blocks of the first key don't fit into a single chunk
+			if chunks := countChunks(t, db, csInfo.IndexBucket, addrs[0]); chunks < 2 {
+				t.Fatalf("expected the index of %x in several chunks, got %d", addrs[0], chunks)
+			}
 		}
 	}
 
@@ -81,81 +77,23 @@ func TestIndexGenerator_Truncate(t *testing.T) {
 			return arr[:pos]
 		}
 
-		t.Run("truncate to 2050 "+string(csbucket), func(t *testing.T) {
-			expected[string(hashes[0])][2] = reduceSlice(expected[string(hashes[0])][2], 2050)
-			expected[string(hashes[1])][1] = reduceSlice(expected[string(hashes[1])][1], 2050)
-			expected[string(hashes[2])][0] = reduceSlice(expected[string(hashes[2])][0], 2050)
-
-			err = ig.Truncate(2050, csbucket)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			checkIndex(t, db, indexBucket, hashes[0], 2030, expected[string(hashes[0])][2])
-			checkIndex(t, db, indexBucket, hashes[1], 2030, expected[string(hashes[1])][1])
-			checkIndex(t, db, indexBucket, hashes[2], 2030, expected[string(hashes[2])][0])
-			checkIndex(t, db, indexBucket, hashes[0], 1999, expected[string(hashes[0])][1])
-			checkIndex(t, db, indexBucket, hashes[1], 999, expected[string(hashes[1])][0])
-		})
-
-		t.Run("truncate to 2000 "+string(csbucket), func(t *testing.T) {
-			expected[string(hashes[0])][2] = reduceSlice(expected[string(hashes[0])][2], 2000)
-			expected[string(hashes[1])][1] = reduceSlice(expected[string(hashes[1])][1], 2000)
-			expected[string(hashes[2])][0] = reduceSlice(expected[string(hashes[2])][0], 2000)
-
-			err = ig.Truncate(2000, csbucket)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			checkIndex(t, db, indexBucket, hashes[0], 2000, expected[string(hashes[0])][2])
-			checkIndex(t, db, indexBucket, hashes[1], 2000, expected[string(hashes[1])][1])
-			checkIndex(t, db, indexBucket, hashes[2], expected[string(hashes[2])][0][len(expected[string(hashes[2])][0])-1], expected[string(hashes[2])][0])
-		})
-
-		t.Run("truncate to 1999 "+string(csbucket), func(t *testing.T) {
-			err = ig.Truncate(1999, csbucket)
-			if err != nil {
-				t.Fatal(err)
-			}
-			checkIndex(t, db, indexBucket, hashes[0], 1999, expected[string(hashes[0])][1])
-			checkIndex(t, db, indexBucket, hashes[1], 1998, expected[string(hashes[1])][0])
-			checkIndex(t, db, indexBucket, hashes[2], 1998, expected[string(hashes[2])][0])
-			_, err = db.GetIndexChunk(csbucket, hashes[0], 2000)
-			if err != ethdb.ErrKeyNotFound {
-				t.Fatal()
-			}
-			_, err = db.GetIndexChunk(csbucket, hashes[1], 2000)
-			if err != ethdb.ErrKeyNotFound {
-				t.Fatal()
-			}
-		})
-
-		t.Run("truncate to 999 "+string(csbucket), func(t *testing.T) {
-			expected[string(hashes[1])][0] = reduceSlice(expected[string(hashes[1])][0], 999)
-			expected[string(hashes[2])][0] = reduceSlice(expected[string(hashes[2])][0], 999)
-
-			err = ig.Truncate(999, csbucket)
-			if err != nil {
-				t.Fatal(err)
-			}
-			checkIndex(t, db, indexBucket, hashes[0], 999, expected[string(hashes[0])][0])
-			checkIndex(t, db, indexBucket, hashes[1], 998, expected[string(hashes[1])][0])
-			checkIndex(t, db, indexBucket, hashes[2], 999, expected[string(hashes[2])][0])
-			_, err = db.GetIndexChunk(csbucket, hashes[0], 1000)
-			if err != ethdb.ErrKeyNotFound {
-				t.Fatal()
-			}
-			_, err = db.GetIndexChunk(csbucket, hashes[1], 1000)
-			if err != ethdb.ErrKeyNotFound {
-				t.Fatal()
-			}
-		})
+		for _, timestampTo := range []uint64{2050, 2000, 1999, 999, 0} {
+			timestampTo := timestampTo
+			t.Run(fmt.Sprintf("truncate to %d %s", timestampTo, csbucket), func(t *testing.T) {
+				if err = ig.Truncate(timestampTo, csbucket); err != nil {
+					t.Fatal(err)
+				}
+				for _, hash := range hashes {
+					expected[string(hash)] = reduceSlice(expected[string(hash)], timestampTo)
+					checkIndex(t, db, indexBucket, hash, expected[string(hash)])
+				}
+			})
+		}
 		db.Close()
 	}
 }
 
-func generateTestData(t *testing.T, db ethdb.Database, csBucket string, numOfBlocks int) ([][]byte, map[string][][]uint64) { //nolint
+func generateTestData(t *testing.T, db ethdb.Database, csBucket string, numOfBlocks int) ([][]byte, map[string][]uint64) { //nolint
 	csInfo, ok := changeset.Mapper[string(csBucket)]
 	if !ok {
 		t.Fatal("incorrect cs bucket")
@@ -182,44 +120,28 @@ func generateTestData(t *testing.T, db ethdb.Database, csBucket string, numOfBlo
 		}
 	}
 
-	expected1 := make([]dbutils.HistoryIndexBytes, 0)
-	expected1 = append(expected1, dbutils.NewHistoryIndex())
-	expected2 := make([]dbutils.HistoryIndexBytes, 0)
-	expected2 = append(expected2, dbutils.NewHistoryIndex())
-	expected3 := make([]dbutils.HistoryIndexBytes, 0)
-	expected3 = append(expected3, dbutils.NewHistoryIndex())
-
+	expected := make(map[string][]uint64)
 	for i := 0; i < numOfBlocks; i++ {
 		cs := csInfo.New()
 		err = cs.Add(addrs[0], []byte(strconv.Itoa(i)))
 		if err != nil {
 			t.Fatal(err)
 		}
-
-		if dbutils.CheckNewIndexChunk(expected1[len(expected1)-1], uint64(i)) {
-			expected1 = append(expected1, dbutils.NewHistoryIndex())
-		}
-		expected1[len(expected1)-1] = expected1[len(expected1)-1].Append(uint64(i), false)
+		expected[string(addrs[0])] = append(expected[string(addrs[0])], uint64(i))
 
 		if i%2 == 0 {
 			err = cs.Add(addrs[1], []byte(strconv.Itoa(i)))
 			if err != nil {
 				t.Fatal(err)
 			}
-			if dbutils.CheckNewIndexChunk(expected2[len(expected2)-1], uint64(i)) {
-				expected2 = append(expected2, dbutils.NewHistoryIndex())
-			}
-			expected2[len(expected2)-1] = expected2[len(expected2)-1].Append(uint64(i), false)
+			expected[string(addrs[1])] = append(expected[string(addrs[1])], uint64(i))
 		}
 		if i%3 == 0 {
 			err = cs.Add(addrs[2], []byte(strconv.Itoa(i)))
 			if err != nil {
 				t.Fatal(err)
 			}
-			if dbutils.CheckNewIndexChunk(expected3[len(expected3)-1], uint64(i)) {
-				expected3 = append(expected3, dbutils.NewHistoryIndex())
-			}
-			expected3[len(expected3)-1] = expected3[len(expected3)-1].Append(uint64(i), false)
+			expected[string(addrs[2])] = append(expected[string(addrs[2])], uint64(i))
 		}
 		v, err := csInfo.Encode(cs)
 		if err != nil {
@@ -230,70 +152,67 @@ func generateTestData(t *testing.T, db ethdb.Database, csBucket string, numOfBlo
 			t.Fatal(err)
 		}
 	}
-	res := make([][]uint64, len(expected1))
-	for i := range expected1 {
-		res[i], _, err = expected1[i].Decode()
-		if err != nil {
-			t.Fatal(err)
+	return addrs, expected
+}
+
+// checkIndex verifies that the chunks of the key hold the expected blocks, in order and within the size
+// limit, and that every block is found in the chunk returned by GetIndexChunk
+func checkIndex(t *testing.T, db ethdb.Database, bucket string, key []byte, expected []uint64) {
+	t.Helper()
+	k := dbutils.CompositeKeyWithoutIncarnation(key)
+	var blocks []uint64
+	if err := db.Walk(bucket, k, 8*len(k), func(chunkKey, v []byte) (bool, error) {
+		if len(v) > bitmapdb.ChunkLimit {
+			t.Errorf("chunk %x of %d bytes", chunkKey, len(v))
+		}
+		chunk := roaring.New()
+		if err := chunk.UnmarshalBinary(v); err != nil {
+			return false, err
+		}
+		if last := binary.BigEndian.Uint64(chunkKey[len(k):]); last != ^uint64(0) && last != uint64(chunk.Maximum()) {
+			t.Errorf("chunk %x ends with the block %d", chunkKey, chunk.Maximum())
 		}
+		for _, b := range chunk.ToArray() {
+			blocks = append(blocks, uint64(b))
+		}
+		return true, nil
+	}); err != nil {
+		t.Fatal(err)
 	}
-	res2 := make([][]uint64, len(expected2))
-	for i := range expected2 {
-		res2[i], _, err = expected2[i].Decode()
-		if err != nil {
-			t.Fatal(err)
+	if len(blocks) != len(expected) || (len(blocks) > 0 && !reflect.DeepEqual(blocks, expected)) {
+		t.Fatalf("index of %x: got %v, expected %v", key, blocks, expected)
+	}
+	if len(expected) > 0 {
+		if _, err := db.Get(bucket, dbutils.CurrentChunkKey(key)); err != nil {
+			t.Fatalf("last chunk of %x: %v", key, err)
 		}
-
 	}
-	res3 := make([][]uint64, len(expected3))
-	for i := range expected3 {
-		res3[i], _, err = expected3[i].Decode()
+	for _, blockNum := range expected {
+		v, err := db.GetIndexChunk(bucket, key, blockNum)
 		if err != nil {
+			t.Fatal(err, common.Bytes2Hex(key), blockNum)
+		}
+		chunk := roaring.New()
+		if err = chunk.UnmarshalBinary(v); err != nil {
 			t.Fatal(err)
 		}
-	}
-	return addrs, map[string][][]uint64{
-		string(addrs[0]): res,
-		string(addrs[1]): res2,
-		string(addrs[2]): res3,
-	}
-}
-
-func checkIndex(t *testing.T, db ethdb.Database, bucket string, addrHash []byte, chunkBlock uint64, expected []uint64) {
-	t.Helper()
-	b, err := db.GetIndexChunk(bucket, addrHash, chunkBlock)
-	if err != nil {
-		t.Fatal(err, common.Bytes2Hex(addrHash), chunkBlock)
-	}
-	val, _, err := dbutils.HistoryIndexBytes(b).Decode()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	if !reflect.DeepEqual(val, expected) {
-		fmt.Println("get", val)
-		fmt.Println("expected", expected)
-		t.Fatal()
+		if found, ok := bitmapdb.SeekInBitmap(chunk, blockNum); !ok || found != blockNum {
+			t.Fatalf("index of %x: block %d not found in its chunk, got %d", key, blockNum, found)
+		}
 	}
 }
 
-func lastChunkCheck(t *testing.T, db ethdb.Database, bucket string, key []byte, expected []uint64) {
+func countChunks(t *testing.T, db ethdb.Database, bucket string, key []byte) int {
 	t.Helper()
-	v, err := db.Get(bucket, dbutils.CurrentChunkKey(key))
-	if err != nil {
-		t.Fatal(err, dbutils.CurrentChunkKey(key))
-	}
-
-	val, _, err := dbutils.HistoryIndexBytes(v).Decode()
-	if err != nil {
+	k := dbutils.CompositeKeyWithoutIncarnation(key)
+	var chunks int
+	if err := db.Walk(bucket, k, 8*len(k), func(_, _ []byte) (bool, error) {
+		chunks++
+		return true, nil
+	}); err != nil {
 		t.Fatal(err)
 	}
-
-	if !reflect.DeepEqual(val, expected) {
-		fmt.Println(val)
-		fmt.Println(expected)
-		t.Fatal()
-	}
+	return chunks
 }
 
 func generateAddrs(numOfAddrs int, isPlain bool) ([][]byte, error) {
@@ -0,0 +1,316 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+var (
+	speculativeTxsCounter = metrics.NewRegisteredCounter("chain/execution/speculative", nil)
+	reexecutedTxsCounter  = metrics.NewRegisteredCounter("chain/execution/reexecuted", nil)
+)
+
+var errSenderNotReady = errors.New("nonce of the sender is not at the transaction yet")
+
+// speculation - a transaction executed on the state at the beginning of the block
+type speculation struct {
+	done    chan struct{}
+	reader  *state.SpeculativeReader
+	writer  *state.SpeculativeWriter
+	receipt *types.Receipt
+	payment *uint256.Int // to the coinbase, not applied to the state by the transaction
+	err     error
+}
+
+// applyTransactionsParallel applies the transactions of the block to ibs, as ApplyTransaction in order would.
+// The transactions are executed speculatively on vmConfig.ExecutionWorkers goroutines, on the state at the
+// beginning of the block, and their changes are applied in order, if the state they read was not changed by
+// the transactions before them. The others are executed again on ibs. The goroutines read the state, and the
+// headers for BLOCKHASH, through the calling goroutine, which owns the database transaction of stateReader.
+func applyTransactionsParallel(chainConfig *params.ChainConfig, vmConfig *vm.Config, chainContext ChainContext, block *types.Block, ibs *state.IntraBlockState, stateReader state.StateReader, gp *GasPool, usedGas *uint64) (types.Receipts, error) {
+	header := block.Header()
+	txs := block.Transactions()
+	speculations := make([]*speculation, len(txs))
+	for i := range speculations {
+		speculations[i] = &speculation{done: make(chan struct{})}
+	}
+	proxy := state.NewProxyStateReader(stateReader)
+	var wg sync.WaitGroup
+	quit := make(chan struct{})
+	defer func() {
+		close(quit)
+		// the speculations in progress read the state until they finish
+		stopped := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(stopped)
+		}()
+		for {
+			select {
+			case <-stopped:
+				return
+			case read := <-proxy.Requests():
+				read()
+			}
+		}
+	}()
+	next := int64(-1)
+	for w := 0; w < vmConfig.ExecutionWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt64(&next, 1)); i < len(txs); i = int(atomic.AddInt64(&next, 1)) {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				speculate(chainConfig, vmConfig, chainContext, block, i, proxy, speculations[i])
+				close(speculations[i].done)
+			}
+		}()
+	}
+
+	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
+	noop := state.NewNoopWriter()
+	receipts := make(types.Receipts, 0, len(txs))
+	for i, tx := range txs {
+		s := speculations[i]
+		for waiting := true; waiting; {
+			select {
+			case <-s.done:
+				waiting = false
+			case read := <-proxy.Requests():
+				read()
+			}
+		}
+		ibs.Prepare(tx.Hash(), block.Hash(), i)
+		if s.err == nil && gp.Gas() >= tx.Gas() && ibs.ApplySpeculative(s.reader, s.writer) {
+			if !s.payment.IsZero() {
+				ibs.AddBalance(header.Coinbase, s.payment)
+			}
+			for _, l := range s.receipt.Logs {
+				ibs.AddLog(l)
+			}
+			if err := ibs.FinalizeTx(ctx, noop); err != nil {
+				return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+			}
+			if err := gp.SubGas(s.receipt.GasUsed); err != nil {
+				return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+			}
+			*usedGas += s.receipt.GasUsed
+			s.receipt.CumulativeGasUsed = *usedGas
+			receipts = append(receipts, s.receipt)
+			speculativeTxsCounter.Inc(1)
+			continue
+		}
+		reexecutedTxsCounter.Inc(1)
+		receipt, err := ApplyTransaction(chainConfig, chainContext, nil, gp, ibs, noop, header, tx, usedGas, *vmConfig)
+		if err != nil {
+			return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// speculate executes the i-th transaction of the block on the state at the beginning of the block
+func speculate(chainConfig *params.ChainConfig, vmConfig *vm.Config, chainContext ChainContext, block *types.Block, i int, proxy *state.ProxyStateReader, s *speculation) {
+	tx := block.Transactions()[i]
+	header := block.Header()
+	s.reader = state.NewSpeculativeReader(proxy)
+	s.writer = state.NewSpeculativeWriter()
+	ibs := state.New(s.reader)
+	ibs.Prepare(tx.Hash(), block.Hash(), i)
+
+	msg, err := tx.AsMessage(types.MakeSigner(chainConfig, header.Number))
+	if err != nil {
+		s.err = err
+		return
+	}
+	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
+	evmContext := NewEVMContext(msg, header, chainContext, nil)
+	getHash := evmContext.GetHash
+	evmContext.GetHash = func(n uint64) (hash common.Hash) {
+		proxy.Do(func() { hash = getHash(n) })
+		return hash
+	}
+	cfg := *vmConfig
+	cfg.SkipAnalysis = SkipAnalysis(chainConfig, header.Number.Uint64())
+	coinbase := &deferredCoinbase{IntraBlockState: ibs, coinbase: evmContext.Coinbase}
+	if msg.CheckNonce() && coinbase.GetNonce(msg.From()) != msg.Nonce() {
+		// the preceding transactions of the sender are not applied to the state at the beginning of the block
+		s.err = errSenderNotReady
+		return
+	}
+	vmenv := vm.NewEVM(evmContext, coinbase, chainConfig, cfg)
+	result, err := ApplyMessage(vmenv, msg, new(GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		s.err = err
+		return
+	}
+	if s.err = ibs.FinalizeTx(ctx, s.writer); s.err != nil {
+		return
+	}
+	if s.err = ibs.Error(); s.err != nil {
+		return
+	}
+	s.payment = &coinbase.payment
+
+	// CumulativeGasUsed is set when the transaction is applied
+	s.receipt = types.NewReceipt(result.Failed(), 0)
+	s.receipt.Type = tx.Type()
+	s.receipt.TxHash = tx.Hash()
+	s.receipt.GasUsed = result.UsedGas
+	if msg.To() == nil {
+		s.receipt.ContractAddress = crypto.CreateAddress(vmenv.Context.Origin, tx.Nonce())
+	}
+	s.receipt.Logs = ibs.GetLogs(tx.Hash())
+	s.receipt.Bloom = types.CreateBloom(types.Receipts{s.receipt})
+}
+
+// deferredCoinbase holds back the payments to the coinbase of the block, so the speculative executions of
+// the transactions, which all pay the fees to it, don't conflict on its balance. The payments are applied
+// when the transaction is, unless the transaction reads the coinbase account: then they are applied to
+// the state, and the account is read as usual.
+type deferredCoinbase struct {
+	*state.IntraBlockState
+	coinbase  common.Address
+	observed  bool
+	payment   uint256.Int
+	snapshots map[int]uint256.Int // the payment at the snapshot, to revert it
+}
+
+func (c *deferredCoinbase) observe(address common.Address) {
+	if address != c.coinbase || c.observed {
+		return
+	}
+	c.observed = true
+	if !c.payment.IsZero() {
+		c.IntraBlockState.AddBalance(c.coinbase, &c.payment)
+		c.payment.Clear()
+	}
+}
+
+func (c *deferredCoinbase) AddBalance(address common.Address, amount *uint256.Int) {
+	// a zero payment touches the account
+	if address == c.coinbase && !c.observed && !amount.IsZero() {
+		c.payment.Add(&c.payment, amount)
+		return
+	}
+	c.observe(address)
+	c.IntraBlockState.AddBalance(address, amount)
+}
+
+func (c *deferredCoinbase) Snapshot() int {
+	id := c.IntraBlockState.Snapshot()
+	if !c.observed {
+		if c.snapshots == nil {
+			c.snapshots = make(map[int]uint256.Int)
+		}
+		c.snapshots[id] = c.payment
+	}
+	return id
+}
+
+func (c *deferredCoinbase) RevertToSnapshot(id int) {
+	c.IntraBlockState.RevertToSnapshot(id)
+	// the payments applied when the account was read after the snapshot are reverted too
+	if payment, ok := c.snapshots[id]; ok {
+		c.payment = payment
+		c.observed = false
+	}
+}
+
+func (c *deferredCoinbase) CreateAccount(address common.Address, contractCreation bool) {
+	c.observe(address)
+	c.IntraBlockState.CreateAccount(address, contractCreation)
+}
+
+func (c *deferredCoinbase) SubBalance(address common.Address, amount *uint256.Int) {
+	c.observe(address)
+	c.IntraBlockState.SubBalance(address, amount)
+}
+
+func (c *deferredCoinbase) GetBalance(address common.Address) *uint256.Int {
+	c.observe(address)
+	return c.IntraBlockState.GetBalance(address)
+}
+
+func (c *deferredCoinbase) GetNonce(address common.Address) uint64 {
+	c.observe(address)
+	return c.IntraBlockState.GetNonce(address)
+}
+
+func (c *deferredCoinbase) SetNonce(address common.Address, nonce uint64) {
+	c.observe(address)
+	c.IntraBlockState.SetNonce(address, nonce)
+}
+
+func (c *deferredCoinbase) GetCodeHash(address common.Address) common.Hash {
+	c.observe(address)
+	return c.IntraBlockState.GetCodeHash(address)
+}
+
+func (c *deferredCoinbase) GetCode(address common.Address) []byte {
+	c.observe(address)
+	return c.IntraBlockState.GetCode(address)
+}
+
+func (c *deferredCoinbase) SetCode(address common.Address, code []byte) {
+	c.observe(address)
+	c.IntraBlockState.SetCode(address, code)
+}
+
+func (c *deferredCoinbase) GetCodeSize(address common.Address) int {
+	c.observe(address)
+	return c.IntraBlockState.GetCodeSize(address)
+}
+
+func (c *deferredCoinbase) GetCommittedState(address common.Address, key *common.Hash, value *uint256.Int) {
+	c.observe(address)
+	c.IntraBlockState.GetCommittedState(address, key, value)
+}
+
+func (c *deferredCoinbase) GetState(address common.Address, key *common.Hash, value *uint256.Int) {
+	c.observe(address)
+	c.IntraBlockState.GetState(address, key, value)
+}
+
+func (c *deferredCoinbase) SetState(address common.Address, key *common.Hash, value uint256.Int) {
+	c.observe(address)
+	c.IntraBlockState.SetState(address, key, value)
+}
+
+func (c *deferredCoinbase) Suicide(address common.Address) bool {
+	c.observe(address)
+	return c.IntraBlockState.Suicide(address)
+}
+
+func (c *deferredCoinbase) HasSuicided(address common.Address) bool {
+	c.observe(address)
+	return c.IntraBlockState.HasSuicided(address)
+}
+
+func (c *deferredCoinbase) Exist(address common.Address) bool {
+	c.observe(address)
+	return c.IntraBlockState.Exist(address)
+}
+
+func (c *deferredCoinbase) Empty(address common.Address) bool {
+	c.observe(address)
+	return c.IntraBlockState.Empty(address)
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+func TestExecuteBlockParallel(t *testing.T) {
+	var (
+		keys     []*ecdsa.PrivateKey
+		coinbase = common.HexToAddress("0xcb")
+		// SSTORE(0, SLOAD(0) + 1)
+		counter = common.HexToAddress("0xc1")
+		// SSTORE(0, BALANCE(COINBASE))
+		coinbaseReader = common.HexToAddress("0xc2")
+		// SELFDESTRUCT(CALLER)
+		destructible = common.HexToAddress("0xc3")
+		gspec        = &Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: GenesisAlloc{
+				counter: {Balance: new(big.Int), Code: []byte{
+					byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				}},
+				coinbaseReader: {Balance: new(big.Int), Code: []byte{
+					byte(vm.COINBASE), byte(vm.BALANCE), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				}},
+				destructible: {Balance: big.NewInt(1000), Code: []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)}},
+			},
+		}
+		signer = types.MakeSigner(gspec.Config, big.NewInt(1))
+	)
+	for i := 0; i < 16; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys = append(keys, key)
+		gspec.Alloc[crypto.PubkeyToAddress(key.PublicKey)] = GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+
+	genDb := ethdb.NewMemDatabase()
+	defer genDb.Close()
+	chain, _, err := GenerateChain(gspec.Config, gspec.MustCommit(genDb), ethash.NewFaker(), genDb, 4, func(n int, gen *BlockGen) {
+		gen.SetCoinbase(coinbase)
+		tx := func(key *ecdsa.PrivateKey, to *common.Address, value uint64, data []byte) {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			var tx *types.Transaction
+			if to == nil {
+				tx = types.NewContractCreation(gen.TxNonce(from), uint256.NewInt(), 100000, uint256.NewInt().SetUint64(1), data)
+			} else {
+				tx = types.NewTransaction(gen.TxNonce(from), *to, uint256.NewInt().SetUint64(value), 100000, uint256.NewInt().SetUint64(1), data)
+			}
+			tx, err := types.SignTx(tx, signer, key)
+			require.NoError(t, err)
+			gen.AddTx(tx)
+		}
+		for i, key := range keys {
+			switch {
+			case i >= 8:
+				// independent transfers to new accounts
+				fresh := common.BytesToAddress([]byte(fmt.Sprintf("fresh%d-%d", n, i)))
+				tx(key, &fresh, 1000, nil)
+			case i%2 == 0:
+				// the storage of the counter is changed by the preceding transactions
+				tx(key, &counter, 0, nil)
+			default:
+				// the balance of the recipient is changed by its own transaction
+				to := crypto.PubkeyToAddress(keys[i-1].PublicKey)
+				tx(key, &to, 1, nil)
+			}
+		}
+		switch n {
+		case 1:
+			tx(keys[0], &coinbaseReader, 0, nil)
+			tx(keys[1], nil, 0, []byte{byte(vm.STOP)})
+		case 2:
+			tx(keys[2], &destructible, 0, nil)
+			tx(keys[3], &destructible, 5, nil)
+		}
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+
+	execute := func(vmConfig vm.Config) (ethdb.Database, []types.Receipts) {
+		db := ethdb.NewMemDatabase()
+		gspec.MustCommit(db)
+		blockchain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vmConfig, nil, nil)
+		require.NoError(t, err)
+		defer blockchain.Stop()
+		var receipts []types.Receipts
+		for _, block := range chain {
+			blockReceipts, err := ExecuteBlockEphemerally(gspec.Config, &vmConfig, blockchain, ethash.NewFaker(), block, state.NewPlainStateReader(db), state.NewPlainStateWriter(db, db, block.NumberU64()))
+			require.NoError(t, err)
+			receipts = append(receipts, blockReceipts)
+		}
+		return db, receipts
+	}
+	sequentialDb, sequentialReceipts := execute(vm.Config{})
+	defer sequentialDb.Close()
+	parallelDb, parallelReceipts := execute(vm.Config{ExecutionWorkers: 4})
+	defer parallelDb.Close()
+
+	require.Equal(t, sequentialReceipts, parallelReceipts)
+	for _, bucket := range []string{dbutils.PlainStateBucket, dbutils.PlainAccountChangeSetBucket, dbutils.PlainStorageChangeSetBucket, dbutils.PlainContractCodeBucket, dbutils.IncarnationMapBucket} {
+		dump := func(db ethdb.Database) map[string]string {
+			entries := map[string]string{}
+			require.NoError(t, db.Walk(bucket, nil, 0, func(k, v []byte) (bool, error) {
+				entries[fmt.Sprintf("%x", k)] = fmt.Sprintf("%x", v)
+				return true, nil
+			}))
+			return entries
+		}
+		require.Equal(t, dump(sequentialDb), dump(parallelDb), bucket)
+	}
+}
+
+func TestSpeculativeTransferToNewAccount(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		fresh   = common.HexToAddress("0xf1")
+		gspec   = &Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.MakeSigner(gspec.Config, big.NewInt(1))
+	)
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	genesis := gspec.MustCommit(db)
+	chain, _, err := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(_ int, gen *BlockGen) {
+		tx, err1 := types.SignTx(types.NewTransaction(0, fresh, uint256.NewInt().SetUint64(1000), params.TxGas, uint256.NewInt().SetUint64(1), nil), signer, key)
+		require.NoError(t, err1)
+		gen.AddTx(tx)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	blockchain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	// the reads of the speculation are served by this goroutine, like in applyTransactionsParallel
+	proxy := state.NewProxyStateReader(state.NewPlainStateReader(db))
+	s := &speculation{done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		speculate(gspec.Config, &vm.Config{}, blockchain, chain[0], 0, proxy, s)
+	}()
+	for waiting := true; waiting; {
+		select {
+		case <-s.done:
+			waiting = false
+		case read := <-proxy.Requests():
+			read()
+		}
+	}
+	require.NoError(t, s.err)
+
+	// the new account receiving ether is not created as a contract, so the transaction doesn't have to be executed again
+	require.False(t, s.writer.NotApplicable)
+	ibs := state.New(state.NewPlainStateReader(db))
+	require.True(t, ibs.ApplySpeculative(s.reader, s.writer))
+	require.Equal(t, uint64(1000), ibs.GetBalance(fresh).Uint64())
+	require.Equal(t, uint64(1), ibs.GetNonce(address))
+}
@@ -24,11 +24,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/holiman/uint256"
 
 	"github.com/ledgerwatch/turbo-geth/common"
@@ -953,16 +955,26 @@ func (tds *TrieDbState) truncateHistory(timestampTo uint64, accountMap map[strin
 			timestamp := binary.BigEndian.Uint64(k[common.HashLength:]) // the last timestamp in the chunk
 			kStr := string(common.CopyBytes(k))
 			if timestamp > timestampTo {
-				accountHistoryEffects[kStr] = nil
+				// The last chunk may already hold the truncated part of a previous chunk
+				if _, ok := accountHistoryEffects[kStr]; !ok {
+					accountHistoryEffects[kStr] = nil
+				}
 				// truncate the chunk
-				index := dbutils.WrapHistoryIndex(v)
-				index = index.TruncateGreater(timestampTo)
-				if len(index) > 8 { // If the chunk is empty after truncation, it gets simply deleted
+				index := roaring.New()
+				if err := index.UnmarshalBinary(v); err != nil {
+					return false, err
+				}
+				index.RemoveRange(timestampTo+1, uint64(math.MaxUint32)+1)
+				if !index.IsEmpty() { // If the chunk is empty after truncation, it gets simply deleted
 					// Truncated chunk becomes "the last chunk" with the timestamp 0xffff....ffff
 					lastK := make([]byte, len(k))
 					copy(lastK, k[:common.HashLength])
 					binary.BigEndian.PutUint64(lastK[common.HashLength:], ^uint64(0))
-					accountHistoryEffects[string(lastK)] = common.CopyBytes(index)
+					buf, err := index.ToBytes()
+					if err != nil {
+						return false, err
+					}
+					accountHistoryEffects[string(lastK)] = buf
 				}
 			}
 			return true, nil
@@ -980,15 +992,26 @@ func (tds *TrieDbState) truncateHistory(timestampTo uint64, accountMap map[strin
 			timestamp := binary.BigEndian.Uint64(k[2*common.HashLength:]) // the last timestamp in the chunk
 			kStr := string(common.CopyBytes(k))
 			if timestamp > timestampTo {
-				storageHistoryEffects[kStr] = nil
-				index := dbutils.WrapHistoryIndex(v)
-				index = index.TruncateGreater(timestampTo)
-				if len(index) > 8 { // If the chunk is empty after truncation, it gets simply deleted
+				// The last chunk may already hold the truncated part of a previous chunk
+				if _, ok := storageHistoryEffects[kStr]; !ok {
+					storageHistoryEffects[kStr] = nil
+				}
+				// truncate the chunk
+				index := roaring.New()
+				if err := index.UnmarshalBinary(v); err != nil {
+					return false, err
+				}
+				index.RemoveRange(timestampTo+1, uint64(math.MaxUint32)+1)
+				if !index.IsEmpty() { // If the chunk is empty after truncation, it gets simply deleted
 					// Truncated chunk becomes "the last chunk" with the timestamp 0xffff....ffff
 					lastK := make([]byte, len(k))
 					copy(lastK, k[:2*common.HashLength])
 					binary.BigEndian.PutUint64(lastK[2*common.HashLength:], ^uint64(0))
-					storageHistoryEffects[string(lastK)] = common.CopyBytes(index)
+					buf, err := index.ToBytes()
+					if err != nil {
+						return false, err
+					}
+					storageHistoryEffects[string(lastK)] = buf
 				}
 			}
 			return true, nil
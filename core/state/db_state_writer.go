@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/VictoriaMetrics/fastcache"
 	"github.com/holiman/uint256"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
 	"github.com/ledgerwatch/turbo-geth/turbo/trie"
 )
 
@@ -258,34 +260,28 @@ func (dsw *DbStateWriter) WriteHistory() error {
 
 func writeIndex(blocknum uint64, changes *changeset.ChangeSet, bucket string, changeDb ethdb.GetterPutter) error {
 	for _, change := range changes.Changes {
+		k := dbutils.CompositeKeyWithoutIncarnation(change.Key)
 		currentChunkKey := dbutils.CurrentChunkKey(change.Key)
 		indexBytes, err := changeDb.Get(bucket, currentChunkKey)
 		if err != nil && err != ethdb.ErrKeyNotFound {
 			return fmt.Errorf("find chunk failed: %w", err)
 		}
 
-		var index dbutils.HistoryIndexBytes
-		if len(indexBytes) == 0 {
-			index = dbutils.NewHistoryIndex()
-		} else if dbutils.CheckNewIndexChunk(indexBytes, blocknum) {
-			// Chunk overflow, need to write the "old" current chunk under its key derived from the last element
-			index = dbutils.WrapHistoryIndex(indexBytes)
-			indexKey, err := index.Key(change.Key)
-			if err != nil {
-				return err
+		index := roaring.New()
+		if len(indexBytes) > 0 {
+			if err = index.UnmarshalBinary(indexBytes); err != nil {
+				return fmt.Errorf("decode chunk %x: %w", currentChunkKey, err)
 			}
-			// Flush the old chunk
-			if err := changeDb.Put(bucket, indexKey, index); err != nil {
+		}
+		index.Add(uint32(blocknum))
+		// Chunk overflow splits the current chunk, the "old" part is written under the key derived from its last element
+		if err = bitmapdb.WalkChunkWithKeys(k, index, bitmapdb.ChunkLimit, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+			buf, err := chunk.ToBytes()
+			if err != nil {
 				return err
 			}
-			// Start a new chunk
-			index = dbutils.NewHistoryIndex()
-		} else {
-			index = dbutils.WrapHistoryIndex(indexBytes)
-		}
-		index = index.Append(blocknum, len(change.Value) == 0)
-
-		if err := changeDb.Put(bucket, currentChunkKey, index); err != nil {
+			return changeDb.Put(bucket, chunkKey, buf)
+		}); err != nil {
 			return err
 		}
 	}
@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/RoaringBitmap/roaring"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/changeset"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/bitmapdb"
 )
 
 //MaxChangesetsSearch -
@@ -72,16 +75,14 @@ func FindByHistory(tx ethdb.Tx, storage bool, key []byte, timestamp uint64) ([]b
 			return nil, ethdb.ErrKeyNotFound
 		}
 	}
-	index := dbutils.WrapHistoryIndex(v)
+	index := roaring.New()
+	if err := index.UnmarshalBinary(v); err != nil {
+		return nil, fmt.Errorf("decoding the history index of %x: %w", key, err)
+	}
 
-	changeSetBlock, set, ok := index.Search(timestamp)
+	changeSetBlock, ok := bitmapdb.SeekInBitmap(index, timestamp)
 	var data []byte
 	if ok {
-		// set == true if this change was from empty record (non-existent account) to non-empty
-		// In such case, we do not need to examine changeSet and return empty data
-		if set && !storage {
-			return []byte{}, nil
-		}
 		csBucket := dbutils.ChangeSetByIndexBucket(storage)
 		csKey := dbutils.EncodeTimestamp(changeSetBlock)
 		changeSetData, err := tx.Get(csBucket, csKey)
@@ -107,6 +108,10 @@ func FindByHistory(tx ethdb.Tx, storage bool, key []byte, timestamp uint64) ([]b
 
 	//restore codehash
 	if !storage {
+		// The change was from empty record (non-existent account) to non-empty
+		if len(data) == 0 {
+			return []byte{}, nil
+		}
 		var acc accounts.Account
 		if err := acc.DecodeForStorage(data); err != nil {
 			return nil, err
@@ -392,33 +397,36 @@ func walkAsOfThinAccounts(db ethdb.KV, bucket string, hBucket string, startkey [
 }
 
 func findInHistory(hK, hV []byte, timestamp uint64, csGetter func([]byte) ([]byte, error), adapter func(v []byte) changeset.Walker) ([]byte, bool, error) {
-	index := dbutils.WrapHistoryIndex(hV)
-	if changeSetBlock, set, ok := index.Search(timestamp); ok {
-		// set == true if this change was from empty record (non-existent account) to non-empty
-		// In such case, we do not need to examine changeSet and simply skip the record
-		if !set {
-			// Extract value from the changeSet
-			csKey := dbutils.EncodeTimestamp(changeSetBlock)
-			changeSetData, err := csGetter(csKey)
-			if err != nil {
-				return nil, false, err
-			}
-			if changeSetData == nil {
-				return nil, false, fmt.Errorf("could not find ChangeSet record for index entry %d (query timestamp %d) key %s, csKey %s", changeSetBlock, timestamp, common.Bytes2Hex(hK), common.Bytes2Hex(csKey))
-			}
-
-			data, err2 := adapter(changeSetData).Find(hK)
-			if err2 != nil {
-				return nil, false, fmt.Errorf("could not find key %x in the ChangeSet record for index entry %d (query timestamp %d): %v",
-					hK,
-					changeSetBlock,
-					timestamp,
-					err2,
-				)
-			}
-			return data, true, nil
+	index := roaring.New()
+	if err := index.UnmarshalBinary(hV); err != nil {
+		return nil, false, fmt.Errorf("decoding the history index of %x: %w", hK, err)
+	}
+	if changeSetBlock, ok := bitmapdb.SeekInBitmap(index, timestamp); ok {
+		// Extract value from the changeSet
+		csKey := dbutils.EncodeTimestamp(changeSetBlock)
+		changeSetData, err := csGetter(csKey)
+		if err != nil {
+			return nil, false, err
+		}
+		if changeSetData == nil {
+			return nil, false, fmt.Errorf("could not find ChangeSet record for index entry %d (query timestamp %d) key %s, csKey %s", changeSetBlock, timestamp, common.Bytes2Hex(hK), common.Bytes2Hex(csKey))
 		}
-		return nil, true, nil
+
+		data, err2 := adapter(changeSetData).Find(hK)
+		if err2 != nil {
+			return nil, false, fmt.Errorf("could not find key %x in the ChangeSet record for index entry %d (query timestamp %d): %v",
+				hK,
+				changeSetBlock,
+				timestamp,
+				err2,
+			)
+		}
+		// Empty value if this change was from empty record (non-existent account) to non-empty
+		// In such case the record is simply skipped
+		if len(data) == 0 {
+			return nil, true, nil
+		}
+		return data, true, nil
 	}
 	return nil, false, nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
@@ -71,13 +72,11 @@ func TestMutation_DeleteTimestamp(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	index := dbutils.WrapHistoryIndex(indexBytes)
-
-	parsed, _, innerErr := index.Decode()
-	if innerErr != nil {
+	index := roaring.New()
+	if innerErr = index.UnmarshalBinary(indexBytes); innerErr != nil {
 		t.Fatal(innerErr)
 	}
-	if parsed[0] != 1 {
+	if index.Minimum() != 1 {
 		t.Fatal("incorrect block num")
 	}
 
@@ -135,13 +134,12 @@ func TestMutationCommitThinHistory(t *testing.T) {
 			t.Fatal("error on get account", i, err)
 		}
 
-		index := dbutils.WrapHistoryIndex(indexBytes)
-		parsedIndex, _, err := index.Decode()
-		if err != nil {
+		index := roaring.New()
+		if err = index.UnmarshalBinary(indexBytes); err != nil {
 			t.Fatal("error on get account", i, err)
 		}
 
-		if parsedIndex[0] != 1 && index.Len() != 1 {
+		if index.Minimum() != 1 && index.GetCardinality() != 1 {
 			t.Fatal("incorrect history index")
 		}
 
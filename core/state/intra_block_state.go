@@ -90,6 +90,9 @@ type IntraBlockState struct {
 
 	preimages map[common.Hash][]byte
 
+	// Per-transaction access list
+	accessList *accessList
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -108,6 +111,7 @@ func New(stateReader StateReader) *IntraBlockState {
 		nilAccounts:       make(map[common.Address]struct{}),
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
+		accessList:        newAccessList(),
 		journal:           newJournal(),
 	}
 }
@@ -161,6 +165,12 @@ func (sdb *IntraBlockState) Copy() *IntraBlockState {
 	for hash, preimage := range sdb.preimages {
 		ibs.preimages[hash] = preimage
 	}
+	// Do we need to copy the access list? In practice: No. At the start of a
+	// transaction, the access list is empty. In practice, we only ever copy state
+	// _between_ transactions/blocks, never in the middle of a transaction.
+	// However, it doesn't cost us much to copy an empty list, so we do it anyway
+	// to not blow up if we ever decide copy it in the middle of a transaction
+	ibs.accessList = sdb.accessList.Copy()
 	return ibs
 }
 
@@ -909,6 +919,80 @@ func (sdb *IntraBlockState) Prepare(thash, bhash common.Hash, ti int) {
 	sdb.thash = thash
 	sdb.bhash = bhash
 	sdb.txIndex = ti
+	sdb.accessList = newAccessList()
+}
+
+// PrepareAccessList handles the preparatory steps for executing a state transition with
+// regards to both EIP-2929 and EIP-2930:
+//
+// - Add sender to access list (2929)
+// - Add destination to access list (2929)
+// - Add precompiles to access list (2929)
+// - Add the contents of the optional tx access list (2930)
+//
+// This method should only be called if Berlin/2929+2930 is applicable at the current number.
+func (sdb *IntraBlockState) PrepareAccessList(sender common.Address, dst *common.Address, precompiles []common.Address, list types.AccessList) {
+	sdb.AddAddressToAccessList(sender)
+	if dst != nil {
+		sdb.AddAddressToAccessList(*dst)
+		// If it's a create-tx, the destination will be added inside evm.create
+	}
+	for _, addr := range precompiles {
+		sdb.AddAddressToAccessList(addr)
+	}
+	for _, el := range list {
+		sdb.AddAddressToAccessList(el.Address)
+		for _, key := range el.StorageKeys {
+			sdb.AddSlotToAccessList(el.Address, key)
+		}
+	}
+}
+
+// AddAddressToAccessList adds the given address to the access list
+func (sdb *IntraBlockState) AddAddressToAccessList(addr common.Address) {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if sdb.accessList.AddAddress(addr) {
+		sdb.journal.append(accessListAddAccountChange{&addr})
+	}
+}
+
+// AddSlotToAccessList adds the given (address, slot)-tuple to the access list
+func (sdb *IntraBlockState) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	addrMod, slotMod := sdb.accessList.AddSlot(addr, slot)
+	if addrMod {
+		// In practice, this should not happen, since there is no way to enter the
+		// scope of 'address' without having the 'address' become already added
+		// to the access list (via call-variant, create, etc).
+		// Better safe than sorry, though
+		sdb.journal.append(accessListAddAccountChange{&addr})
+	}
+	if slotMod {
+		sdb.journal.append(accessListAddSlotChange{
+			address: &addr,
+			slot:    &slot,
+		})
+	}
+}
+
+// AddressInAccessList returns true if the given address is in the access list.
+func (sdb *IntraBlockState) AddressInAccessList(addr common.Address) bool {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	return sdb.accessList.ContainsAddress(addr)
+}
+
+// SlotInAccessList returns true if the given (address, slot)-tuple is in the access list.
+func (sdb *IntraBlockState) SlotInAccessList(addr common.Address, slot common.Hash) (addressPresent bool, slotPresent bool) {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	return sdb.accessList.Contains(addr, slot)
 }
 
 // no not lock
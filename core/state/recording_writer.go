@@ -0,0 +1,130 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+)
+
+type recordedStorageKey struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+// RecordingWriter is a StateWriter keeping the last write of every account, code and storage item in
+// memory, so that the effects of two executions of the same transaction can be compared
+type RecordingWriter struct {
+	accounts map[common.Address]*accounts.Account // nil value for the deleted accounts
+	code     map[common.Address]common.Hash
+	created  map[common.Address]struct{}
+	storage  map[recordedStorageKey]uint256.Int
+}
+
+func NewRecordingWriter() *RecordingWriter {
+	return &RecordingWriter{
+		accounts: make(map[common.Address]*accounts.Account),
+		code:     make(map[common.Address]common.Hash),
+		created:  make(map[common.Address]struct{}),
+		storage:  make(map[recordedStorageKey]uint256.Int),
+	}
+}
+
+func (w *RecordingWriter) UpdateAccountData(_ context.Context, address common.Address, original, account *accounts.Account) error {
+	w.accounts[address] = account.SelfCopy()
+	return nil
+}
+
+func (w *RecordingWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	w.code[address] = codeHash
+	return nil
+}
+
+func (w *RecordingWriter) DeleteAccount(_ context.Context, address common.Address, original *accounts.Account) error {
+	w.accounts[address] = nil
+	return nil
+}
+
+func (w *RecordingWriter) WriteAccountStorage(_ context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	w.storage[recordedStorageKey{address, incarnation, *key}] = *value
+	return nil
+}
+
+func (w *RecordingWriter) CreateContract(address common.Address) error {
+	w.created[address] = struct{}{}
+	return nil
+}
+
+// Diff describes the writes which are different in the other recording, one line per account, code
+// or storage item, sorted. It returns nil if the recordings are the same.
+func (w *RecordingWriter) Diff(other *RecordingWriter) []string {
+	var diff []string
+	for address, account := range w.accounts {
+		otherAccount, ok := other.accounts[address]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("account %x: %s vs not written", address, formatRecordedAccount(account)))
+		} else if !recordedAccountsEqual(account, otherAccount) {
+			diff = append(diff, fmt.Sprintf("account %x: %s vs %s", address, formatRecordedAccount(account), formatRecordedAccount(otherAccount)))
+		}
+	}
+	for address, account := range other.accounts {
+		if _, ok := w.accounts[address]; !ok {
+			diff = append(diff, fmt.Sprintf("account %x: not written vs %s", address, formatRecordedAccount(account)))
+		}
+	}
+	for address, codeHash := range w.code {
+		if otherCodeHash, ok := other.code[address]; !ok || otherCodeHash != codeHash {
+			diff = append(diff, fmt.Sprintf("code %x: %x vs %x", address, codeHash, otherCodeHash))
+		}
+	}
+	for address, codeHash := range other.code {
+		if _, ok := w.code[address]; !ok {
+			diff = append(diff, fmt.Sprintf("code %x: not written vs %x", address, codeHash))
+		}
+	}
+	for address := range w.created {
+		if _, ok := other.created[address]; !ok {
+			diff = append(diff, fmt.Sprintf("contract %x: created vs not created", address))
+		}
+	}
+	for address := range other.created {
+		if _, ok := w.created[address]; !ok {
+			diff = append(diff, fmt.Sprintf("contract %x: not created vs created", address))
+		}
+	}
+	for key, value := range w.storage {
+		value := value
+		if otherValue, ok := other.storage[key]; !ok {
+			diff = append(diff, fmt.Sprintf("storage %x/%d/%x: %s vs not written", key.address, key.incarnation, key.key, value.Hex()))
+		} else if !value.Eq(&otherValue) {
+			diff = append(diff, fmt.Sprintf("storage %x/%d/%x: %s vs %s", key.address, key.incarnation, key.key, value.Hex(), otherValue.Hex()))
+		}
+	}
+	for key, value := range other.storage {
+		value := value
+		if _, ok := w.storage[key]; !ok {
+			diff = append(diff, fmt.Sprintf("storage %x/%d/%x: not written vs %s", key.address, key.incarnation, key.key, value.Hex()))
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+func recordedAccountsEqual(a1, a2 *accounts.Account) bool {
+	if a1 == nil || a2 == nil {
+		return a1 == a2
+	}
+	return accountsEqual(a1, a2) && a1.Incarnation == a2.Incarnation
+}
+
+func formatRecordedAccount(a *accounts.Account) string {
+	if a == nil {
+		return "deleted"
+	}
+	return fmt.Sprintf("{nonce: %d, balance: %s, incarnation: %d, codeHash: %x}", a.Nonce, a.Balance.ToBig(), a.Incarnation, a.CodeHash)
+}
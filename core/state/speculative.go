@@ -0,0 +1,245 @@
+package state
+
+import (
+	"context"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+)
+
+// ProxyStateReader passes the reads of the goroutines executing the transactions speculatively to the goroutine
+// owning the StateReader, which serves them while it waits for the speculations. A database transaction must
+// only be used by the OS thread which began it, so the goroutines can't read from the write transaction of the
+// Execution stage, and read transactions of their own would not see the changes of the preceding blocks, which
+// are not committed yet.
+type ProxyStateReader struct {
+	reader   StateReader
+	requests chan func()
+}
+
+func NewProxyStateReader(reader StateReader) *ProxyStateReader {
+	return &ProxyStateReader{reader: reader, requests: make(chan func())}
+}
+
+// Requests returns the reads, to be called by the goroutine owning the reader
+func (r *ProxyStateReader) Requests() <-chan func() {
+	return r.requests
+}
+
+// Do calls f on the goroutine owning the reader, and waits for it to return
+func (r *ProxyStateReader) Do(f func()) {
+	done := make(chan struct{})
+	r.requests <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+}
+
+func (r *ProxyStateReader) ReadAccountData(address common.Address) (account *accounts.Account, err error) {
+	r.Do(func() { account, err = r.reader.ReadAccountData(address) })
+	return account, err
+}
+
+func (r *ProxyStateReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) (enc []byte, err error) {
+	r.Do(func() { enc, err = r.reader.ReadAccountStorage(address, incarnation, key) })
+	return enc, err
+}
+
+func (r *ProxyStateReader) ReadAccountCode(address common.Address, codeHash common.Hash) (code []byte, err error) {
+	r.Do(func() { code, err = r.reader.ReadAccountCode(address, codeHash) })
+	return code, err
+}
+
+func (r *ProxyStateReader) ReadAccountCodeSize(address common.Address, codeHash common.Hash) (size int, err error) {
+	r.Do(func() { size, err = r.reader.ReadAccountCodeSize(address, codeHash) })
+	return size, err
+}
+
+func (r *ProxyStateReader) ReadAccountIncarnation(address common.Address) (incarnation uint64, err error) {
+	r.Do(func() { incarnation, err = r.reader.ReadAccountIncarnation(address) })
+	return incarnation, err
+}
+
+type storageRead struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+// SpeculativeReader reads the state at the beginning of the block for a transaction executed speculatively,
+// before the transactions preceding it, and records the accounts and the storage items it read. The code
+// is not recorded: it is read by the code hash of the account.
+type SpeculativeReader struct {
+	reader   StateReader
+	accounts map[common.Address]*accounts.Account // nil if the account doesn't exist
+	storage  map[storageRead]uint256.Int
+}
+
+func NewSpeculativeReader(reader StateReader) *SpeculativeReader {
+	return &SpeculativeReader{
+		reader:   reader,
+		accounts: make(map[common.Address]*accounts.Account),
+		storage:  make(map[storageRead]uint256.Int),
+	}
+}
+
+func (r *SpeculativeReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	account, err := r.reader.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := r.accounts[address]; !ok {
+		var read *accounts.Account
+		if account != nil {
+			read = new(accounts.Account)
+			read.Copy(account)
+		}
+		r.accounts[address] = read
+	}
+	return account, nil
+}
+
+func (r *SpeculativeReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	enc, err := r.reader.ReadAccountStorage(address, incarnation, key)
+	if err != nil {
+		return nil, err
+	}
+	var value uint256.Int
+	value.SetBytes(enc)
+	r.storage[storageRead{address, incarnation, *key}] = value
+	return enc, nil
+}
+
+func (r *SpeculativeReader) ReadAccountCode(address common.Address, codeHash common.Hash) ([]byte, error) {
+	return r.reader.ReadAccountCode(address, codeHash)
+}
+
+func (r *SpeculativeReader) ReadAccountCodeSize(address common.Address, codeHash common.Hash) (int, error) {
+	return r.reader.ReadAccountCodeSize(address, codeHash)
+}
+
+func (r *SpeculativeReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return r.reader.ReadAccountIncarnation(address)
+}
+
+// SpeculativeWriter records the changes of a transaction executed speculatively, passed to FinalizeTx.
+// Only the changes of the balances, the nonces and the storage of the existing accounts, and the new accounts
+// receiving ether, can be applied by ApplySpeculative: the transactions creating contracts and deleting
+// accounts are executed again. The accounts receiving ether are not created as contracts, so CreateContract
+// is only called for the accounts created by CREATE and CREATE2, and for the contract creation transactions.
+type SpeculativeWriter struct {
+	accounts map[common.Address]*accounts.Account
+	storage  map[common.Address]map[common.Hash]uint256.Int
+	// NotApplicable is true if the transaction changed more than the balances, the nonces and the storage
+	NotApplicable bool
+}
+
+func NewSpeculativeWriter() *SpeculativeWriter {
+	return &SpeculativeWriter{
+		accounts: make(map[common.Address]*accounts.Account),
+		storage:  make(map[common.Address]map[common.Hash]uint256.Int),
+	}
+}
+
+func (w *SpeculativeWriter) UpdateAccountData(_ context.Context, address common.Address, original, account *accounts.Account) error {
+	if original.Incarnation != account.Incarnation || codeHashOf(original) != codeHashOf(account) {
+		w.NotApplicable = true
+	}
+	w.accounts[address] = new(accounts.Account)
+	w.accounts[address].Copy(account)
+	return nil
+}
+
+func (w *SpeculativeWriter) UpdateAccountCode(common.Address, uint64, common.Hash, []byte) error {
+	w.NotApplicable = true
+	return nil
+}
+
+func (w *SpeculativeWriter) DeleteAccount(context.Context, common.Address, *accounts.Account) error {
+	w.NotApplicable = true
+	return nil
+}
+
+func (w *SpeculativeWriter) WriteAccountStorage(_ context.Context, address common.Address, _ uint64, key *common.Hash, _, value *uint256.Int) error {
+	if w.storage[address] == nil {
+		w.storage[address] = make(map[common.Hash]uint256.Int)
+	}
+	w.storage[address][*key] = *value
+	return nil
+}
+
+func (w *SpeculativeWriter) CreateContract(common.Address) error {
+	w.NotApplicable = true
+	return nil
+}
+
+func codeHashOf(account *accounts.Account) common.Hash {
+	if account.CodeHash == (common.Hash{}) {
+		return emptyCodeHashH
+	}
+	return account.CodeHash
+}
+
+// ApplySpeculative applies the changes of a transaction executed speculatively, as the transaction would have
+// changed the state, if the accounts and the storage items it read were not changed by the transactions
+// applied before it. Otherwise, or if the changes can't be applied, it returns false and the transaction has
+// to be executed again. FinalizeTx is called after, as after the execution of the transaction.
+func (sdb *IntraBlockState) ApplySpeculative(r *SpeculativeReader, w *SpeculativeWriter) bool {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if w.NotApplicable {
+		return false
+	}
+	// The objects not loaded yet are in the state at the beginning of the block, which the transaction read
+	for address, read := range r.accounts {
+		so, loaded := sdb.stateObjects[address]
+		if !loaded {
+			continue
+		}
+		if so.deleted || read == nil || so.data.Nonce != read.Nonce || !so.data.Balance.Eq(&read.Balance) ||
+			so.data.Incarnation != read.Incarnation || codeHashOf(&so.data) != codeHashOf(read) {
+			return false
+		}
+	}
+	for item, read := range r.storage {
+		so, loaded := sdb.stateObjects[item.address]
+		if !loaded {
+			continue
+		}
+		var value uint256.Int
+		so.GetState(&item.key, &value)
+		if value != read {
+			return false
+		}
+	}
+
+	for address, account := range w.accounts {
+		address := address
+		so := sdb.getStateObject(address)
+		if so == nil || so.deleted {
+			so = sdb.createObject(address, nil /* previous */)
+		}
+		sdb.journal.append(touchChange{account: &address})
+		if !so.data.Balance.Eq(&account.Balance) {
+			so.SetBalance(&account.Balance)
+		}
+		if so.data.Nonce != account.Nonce {
+			so.SetNonce(account.Nonce)
+		}
+	}
+	for address, items := range w.storage {
+		address := address
+		so := sdb.stateObjects[address]
+		for key, value := range items {
+			var prev uint256.Int
+			so.GetState(&key, &prev)
+			sdb.journal.append(storageChange{account: &address, key: key, prevalue: prev})
+			so.setState(&key, value)
+		}
+	}
+	return true
+}
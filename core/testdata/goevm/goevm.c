@@ -0,0 +1,129 @@
+#include "shim.h"
+#include "_cgo_export.h"
+
+#include <stdlib.h>
+#include <string.h>
+
+static void destroy(struct evmc_vm* vm)
+{
+    free(vm);
+}
+
+static evmc_capabilities_flagset get_capabilities(struct evmc_vm* vm)
+{
+    (void)vm;
+    return EVMC_CAPABILITY_EVM1;
+}
+
+static enum evmc_set_option_result set_option(struct evmc_vm* instance, const char* name, const char* value)
+{
+    struct goevm* vm = (struct goevm*)instance;
+    if (strcmp(name, "overcharge") == 0)
+    {
+        char* end = NULL;
+        long long v = strtoll(value, &end, 0);
+        if (end == value || *end != 0 || v < 0)
+            return EVMC_SET_OPTION_INVALID_VALUE;
+        vm->overcharge = v;
+        return EVMC_SET_OPTION_SUCCESS;
+    }
+    return EVMC_SET_OPTION_INVALID_NAME;
+}
+
+static struct evmc_result execute(struct evmc_vm* instance, const struct evmc_host_interface* host,
+    struct evmc_host_context* context, enum evmc_revision rev, const struct evmc_message* msg,
+    const uint8_t* code, size_t code_size)
+{
+    return goevmExecute((struct goevm*)instance, (struct evmc_host_interface*)host, context, rev,
+        (struct evmc_message*)msg, (uint8_t*)code, code_size);
+}
+
+struct evmc_vm* evmc_create_goevm(void)
+{
+    struct evmc_vm init = {
+        .abi_version = EVMC_ABI_VERSION,
+        .name = "goevm",
+        .version = "0.0.0",
+        .destroy = destroy,
+        .execute = execute,
+        .get_capabilities = get_capabilities,
+        .set_option = set_option,
+    };
+    struct goevm* vm = calloc(1, sizeof(struct goevm));
+    memcpy(&vm->instance, &init, sizeof(init));
+    return &vm->instance;
+}
+
+static void release_result(const struct evmc_result* result)
+{
+    free((uint8_t*)result->output_data);
+}
+
+struct evmc_result goevm_result(enum evmc_status_code status_code, int64_t gas_left, const uint8_t* output_data, size_t output_size)
+{
+    struct evmc_result result = {.status_code = status_code, .gas_left = gas_left};
+    if (output_size > 0)
+    {
+        uint8_t* output = malloc(output_size);
+        memcpy(output, output_data, output_size);
+        result.output_data = output;
+        result.output_size = output_size;
+        result.release = release_result;
+    }
+    return result;
+}
+
+bool goevm_account_exists(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address)
+{
+    return host->account_exists(context, address);
+}
+
+evmc_bytes32 goevm_get_storage(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_bytes32* key)
+{
+    return host->get_storage(context, address, key);
+}
+
+enum evmc_storage_status goevm_set_storage(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_bytes32* key, const evmc_bytes32* value)
+{
+    return host->set_storage(context, address, key, value);
+}
+
+evmc_uint256be goevm_get_balance(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address)
+{
+    return host->get_balance(context, address);
+}
+
+size_t goevm_get_code_size(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address)
+{
+    return host->get_code_size(context, address);
+}
+
+evmc_bytes32 goevm_get_code_hash(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address)
+{
+    return host->get_code_hash(context, address);
+}
+
+size_t goevm_copy_code(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, size_t code_offset, uint8_t* buffer_data, size_t buffer_size)
+{
+    return host->copy_code(context, address, code_offset, buffer_data, buffer_size);
+}
+
+void goevm_selfdestruct(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_address* beneficiary)
+{
+    host->selfdestruct(context, address, beneficiary);
+}
+
+struct evmc_tx_context goevm_get_tx_context(const struct evmc_host_interface* host, struct evmc_host_context* context)
+{
+    return host->get_tx_context(context);
+}
+
+evmc_bytes32 goevm_get_block_hash(const struct evmc_host_interface* host, struct evmc_host_context* context, int64_t number)
+{
+    return host->get_block_hash(context, number);
+}
+
+void goevm_emit_log(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const uint8_t* data, size_t data_size, const evmc_bytes32 topics[], size_t topics_count)
+{
+    host->emit_log(context, address, data, data_size, topics, topics_count);
+}
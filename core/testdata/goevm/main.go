@@ -0,0 +1,261 @@
+// Command goevm is an EVMC module of the built-in Go interpreter. It stands in for an external EVM in the
+// tests of the EVMC host, e.g. the comparison of the interpreters with --vm.evm.diff, and is built with
+//
+//	CGO_CFLAGS=-I$(go list -m -f '{{.Dir}}' github.com/ethereum/evmc/v7)/include \
+//		go build -buildmode=c-shared -o goevm.so ./core/testdata/goevm
+//
+// The module executes one call frame at a time, accessing the state through the host. Nested calls and
+// contract creations need the host to transfer the value, and the revisions after Petersburg (as well as
+// Constantinople) need the committed storage for the gas metering, none of which EVMC provides, so such
+// frames fail and such revisions are rejected. With the "overcharge" option set, every frame uses the given
+// amount of gas on top of the interpreter, for the tests of the divergences.
+package main
+
+/*
+#include "shim.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"math/big"
+	"unsafe"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+func main() {}
+
+// errUnsupported aborts the frames making the state changes which the host doesn't provide
+var errUnsupported = errors.New("not supported by goevm")
+
+//export goevmExecute
+func goevmExecute(instance *C.struct_goevm, host *C.struct_evmc_host_interface, context *C.struct_evmc_host_context, rev C.enum_evmc_revision, msg *C.struct_evmc_message, code *C.uint8_t, codeSize C.size_t) C.struct_evmc_result {
+	chainConfig := revisionConfig(rev)
+	if chainConfig == nil {
+		return C.goevm_result(C.EVMC_REJECTED, 0, nil, 0)
+	}
+	ibs := &hostState{host: host, context: context}
+	txContext := C.goevm_get_tx_context(host, context)
+	evm := vm.NewEVM(vm.Context{
+		CanTransfer: func(ibs vm.IntraBlockState, addr common.Address, amount *uint256.Int) bool {
+			return ibs.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(vm.IntraBlockState, common.Address, common.Address, *uint256.Int) {
+			panic(errUnsupported)
+		},
+		GetHash: func(number uint64) common.Hash {
+			return goHash(C.goevm_get_block_hash(host, context, C.int64_t(number)))
+		},
+		Origin:      goAddress(txContext.tx_origin),
+		GasPrice:    goHash(txContext.tx_gas_price).Big(),
+		Coinbase:    goAddress(txContext.block_coinbase),
+		GasLimit:    uint64(txContext.block_gas_limit),
+		BlockNumber: big.NewInt(int64(txContext.block_number)),
+		Time:        big.NewInt(int64(txContext.block_timestamp)),
+		Difficulty:  goHash(txContext.block_difficulty).Big(),
+	}, ibs, chainConfig, vm.Config{})
+
+	value := goHash(msg.value)
+	contract := vm.NewContract(vm.AccountRef(goAddress(msg.sender)), vm.AccountRef(goAddress(msg.destination)), new(uint256.Int).SetBytes(value[:]), uint64(msg.gas), false /* skipAnalysis */)
+	contractCode := C.GoBytes(unsafe.Pointer(code), C.int(codeSize))
+	addr := contract.Address()
+	contract.SetCallCode(&addr, crypto.Keccak256Hash(contractCode), contractCode)
+	input := C.GoBytes(unsafe.Pointer(msg.input_data), C.int(msg.input_size))
+
+	ret, err := run(evm, contract, input, msg.flags&C.EVMC_STATIC != 0)
+	var status C.enum_evmc_status_code
+	switch {
+	case err == nil:
+		status = C.EVMC_SUCCESS
+	case errors.Is(err, vm.ErrExecutionReverted):
+		status = C.EVMC_REVERT
+	default:
+		// The failed frames use all the gas
+		return C.goevm_result(C.EVMC_FAILURE, 0, nil, 0)
+	}
+	gasLeft := int64(contract.Gas) - int64(instance.overcharge)
+	if gasLeft < 0 {
+		gasLeft = 0
+	}
+	var output *C.uint8_t
+	if len(ret) > 0 {
+		output = (*C.uint8_t)(unsafe.Pointer(&ret[0]))
+	}
+	return C.goevm_result(status, C.int64_t(gasLeft), output, C.size_t(len(ret)))
+}
+
+func run(evm *vm.EVM, contract *vm.Contract, input []byte, readOnly bool) (ret []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errUnsupported {
+				panic(r)
+			}
+			ret, err = nil, errUnsupported
+		}
+	}()
+	return evm.Interpreter().Run(contract, input, readOnly)
+}
+
+// revisionConfig returns the chain config with the forks of the given revision activated from the genesis,
+// or nil if the revision is not supported
+func revisionConfig(rev C.enum_evmc_revision) *params.ChainConfig {
+	zero := big.NewInt(0)
+	config := &params.ChainConfig{ChainID: big.NewInt(1)}
+	switch rev {
+	case C.EVMC_PETERSBURG:
+		config.ConstantinopleBlock, config.PetersburgBlock = zero, zero
+		fallthrough
+	case C.EVMC_BYZANTIUM:
+		config.ByzantiumBlock = zero
+		fallthrough
+	case C.EVMC_SPURIOUS_DRAGON:
+		config.EIP155Block, config.EIP158Block = zero, zero
+		fallthrough
+	case C.EVMC_TANGERINE_WHISTLE:
+		config.EIP150Block = zero
+		fallthrough
+	case C.EVMC_HOMESTEAD:
+		config.HomesteadBlock = zero
+	case C.EVMC_FRONTIER:
+	default:
+		return nil
+	}
+	return config
+}
+
+// hostState is the state of the interpreter, accessed through the host. The refunds are accounted by the
+// host, when the storage is set and the contract self-destructs
+type hostState struct {
+	host        *C.struct_evmc_host_interface
+	context     *C.struct_evmc_host_context
+	beneficiary common.Address
+}
+
+func (s *hostState) CreateAccount(common.Address, bool)      { panic(errUnsupported) }
+func (s *hostState) SubBalance(common.Address, *uint256.Int) { panic(errUnsupported) }
+func (s *hostState) GetNonce(common.Address) uint64          { panic(errUnsupported) }
+func (s *hostState) SetNonce(common.Address, uint64)         { panic(errUnsupported) }
+func (s *hostState) SetCode(common.Address, []byte)          { panic(errUnsupported) }
+func (s *hostState) RevertToSnapshot(int)                    { panic(errUnsupported) }
+func (s *hostState) Snapshot() int                           { panic(errUnsupported) }
+func (s *hostState) AddPreimage(common.Hash, []byte)         {}
+func (s *hostState) AddRefund(uint64)                        {}
+func (s *hostState) SubRefund(uint64)                        {}
+func (s *hostState) GetRefund() uint64                       { return 0 }
+func (s *hostState) HasSuicided(common.Address) bool         { return false }
+func (s *hostState) AddressInAccessList(common.Address) bool { panic(errUnsupported) }
+func (s *hostState) AddAddressToAccessList(common.Address)   { panic(errUnsupported) }
+func (s *hostState) AddSlotToAccessList(common.Address, common.Hash) {
+	panic(errUnsupported)
+}
+
+func (s *hostState) SlotInAccessList(common.Address, common.Hash) (bool, bool) {
+	panic(errUnsupported)
+}
+
+func (s *hostState) PrepareAccessList(common.Address, *common.Address, []common.Address, types.AccessList) {
+	panic(errUnsupported)
+}
+
+func (s *hostState) GetCommittedState(common.Address, *common.Hash, *uint256.Int) {
+	panic(errUnsupported)
+}
+
+// AddBalance is only called by SELFDESTRUCT, right before Suicide
+func (s *hostState) AddBalance(addr common.Address, _ *uint256.Int) {
+	s.beneficiary = addr
+}
+
+func (s *hostState) Suicide(addr common.Address) bool {
+	address, beneficiary := cAddress(addr), cAddress(s.beneficiary)
+	C.goevm_selfdestruct(s.host, s.context, &address, &beneficiary)
+	return true
+}
+
+func (s *hostState) GetBalance(addr common.Address) *uint256.Int {
+	address := cAddress(addr)
+	balance := goHash(C.goevm_get_balance(s.host, s.context, &address))
+	return new(uint256.Int).SetBytes(balance[:])
+}
+
+func (s *hostState) GetCodeHash(addr common.Address) common.Hash {
+	address := cAddress(addr)
+	return goHash(C.goevm_get_code_hash(s.host, s.context, &address))
+}
+
+func (s *hostState) GetCodeSize(addr common.Address) int {
+	address := cAddress(addr)
+	return int(C.goevm_get_code_size(s.host, s.context, &address))
+}
+
+func (s *hostState) GetCode(addr common.Address) []byte {
+	address := cAddress(addr)
+	code := make([]byte, C.goevm_get_code_size(s.host, s.context, &address))
+	if len(code) == 0 {
+		return nil
+	}
+	n := C.goevm_copy_code(s.host, s.context, &address, 0, (*C.uint8_t)(unsafe.Pointer(&code[0])), C.size_t(len(code)))
+	return code[:n]
+}
+
+func (s *hostState) GetState(addr common.Address, key *common.Hash, value *uint256.Int) {
+	address, k := cAddress(addr), cHash(*key)
+	v := goHash(C.goevm_get_storage(s.host, s.context, &address, &k))
+	value.SetBytes(v[:])
+}
+
+func (s *hostState) SetState(addr common.Address, key *common.Hash, value uint256.Int) {
+	address, k, v := cAddress(addr), cHash(*key), cHash(value.Bytes32())
+	C.goevm_set_storage(s.host, s.context, &address, &k, &v)
+}
+
+// Exist and Empty are called before and after EIP-158 respectively, when the host reports the existing and
+// the non-empty accounts
+func (s *hostState) Exist(addr common.Address) bool {
+	address := cAddress(addr)
+	return bool(C.goevm_account_exists(s.host, s.context, &address))
+}
+
+func (s *hostState) Empty(addr common.Address) bool {
+	return !s.Exist(addr)
+}
+
+func (s *hostState) AddLog(l *types.Log) {
+	address := cAddress(l.Address)
+	var data *C.uint8_t
+	if len(l.Data) > 0 {
+		data = (*C.uint8_t)(unsafe.Pointer(&l.Data[0]))
+	}
+	var topics *C.evmc_bytes32
+	cTopics := make([]C.evmc_bytes32, len(l.Topics))
+	for i, topic := range l.Topics {
+		cTopics[i] = cHash(topic)
+	}
+	if len(cTopics) > 0 {
+		topics = &cTopics[0]
+	}
+	C.goevm_emit_log(s.host, s.context, &address, data, C.size_t(len(l.Data)), topics, C.size_t(len(cTopics)))
+}
+
+func goAddress(addr C.evmc_address) common.Address {
+	return *(*common.Address)(unsafe.Pointer(&addr.bytes))
+}
+
+func cAddress(addr common.Address) C.evmc_address {
+	return C.evmc_address{bytes: *(*[common.AddressLength]C.uint8_t)(unsafe.Pointer(&addr))}
+}
+
+func goHash(hash C.evmc_bytes32) common.Hash {
+	return *(*common.Hash)(unsafe.Pointer(&hash.bytes))
+}
+
+func cHash(hash common.Hash) C.evmc_bytes32 {
+	return C.evmc_bytes32{bytes: *(*[common.HashLength]C.uint8_t)(unsafe.Pointer(&hash))}
+}
@@ -0,0 +1,31 @@
+#ifndef GOEVM_SHIM_H
+#define GOEVM_SHIM_H
+
+#include <evmc/evmc.h>
+
+/// The EVMC VM instance of the Go interpreter.
+struct goevm
+{
+    struct evmc_vm instance;  ///< The base struct.
+    int64_t overcharge;       ///< The extra gas used by every call frame.
+};
+
+struct evmc_vm* evmc_create_goevm(void);
+
+/// Copies the output into a result which releases it.
+struct evmc_result goevm_result(enum evmc_status_code status_code, int64_t gas_left, const uint8_t* output_data, size_t output_size);
+
+/// The host functions, callable from Go.
+bool goevm_account_exists(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address);
+evmc_bytes32 goevm_get_storage(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_bytes32* key);
+enum evmc_storage_status goevm_set_storage(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_bytes32* key, const evmc_bytes32* value);
+evmc_uint256be goevm_get_balance(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address);
+size_t goevm_get_code_size(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address);
+evmc_bytes32 goevm_get_code_hash(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address);
+size_t goevm_copy_code(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, size_t code_offset, uint8_t* buffer_data, size_t buffer_size);
+void goevm_selfdestruct(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const evmc_address* beneficiary);
+struct evmc_tx_context goevm_get_tx_context(const struct evmc_host_interface* host, struct evmc_host_context* context);
+evmc_bytes32 goevm_get_block_hash(const struct evmc_host_interface* host, struct evmc_host_context* context, int64_t number);
+void goevm_emit_log(const struct evmc_host_interface* host, struct evmc_host_context* context, const evmc_address* address, const uint8_t* data, size_t data_size, const evmc_bytes32 topics[], size_t topics_count);
+
+#endif
@@ -129,6 +129,8 @@ type TxPoolConfig struct {
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	NoPersist bool             // Whether persisting the whole pool into the database should be disabled
+	Repersist time.Duration    // Time interval to persist the pool into the database, if it changed
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -147,6 +149,7 @@ type TxPoolConfig struct {
 var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
+	Repersist: 10 * time.Second,
 
 	PriceLimit: 1,
 	PriceBump:  10,
@@ -167,6 +170,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Repersist <= 0 {
+		log.Warn("Sanitizing invalid txpool persist time", "provided", conf.Repersist, "updated", DefaultTxPoolConfig.Repersist)
+		conf.Repersist = DefaultTxPoolConfig.Repersist
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
@@ -219,13 +226,15 @@ type TxPool struct {
 	mu           sync.RWMutex
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
+	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 
 	pendingNonces *txNoncer              // Pending state tracking virtual nonces
 	currentState  *state.IntraBlockState // Current state in the blockchain head
 	currentMaxGas uint64                 // Current gas limit for transaction caps
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	journal  *txJournal  // Journal of local transaction to back up to disk
+	snapshot *txSnapshot // Snapshot of all transactions to back up to the database
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -260,7 +269,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chaindb *et
 		config:         config,
 		chainconfig:    chainconfig,
 		chaindb:        chaindb,
-		signer:         types.NewEIP155Signer(chainconfig.ChainID),
+		signer:         types.NewEIP2930Signer(chainconfig.ChainID),
 		pending:        make(map[common.Address]*txList),
 		queue:          make(map[common.Address]*txList),
 		beats:          make(map[common.Address]time.Time),
@@ -303,6 +312,14 @@ func (pool *TxPool) Start(gasLimit uint64, headNumber uint64) error {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If persisting is enabled, replay the pool snapshot from the database
+	if !pool.config.NoPersist && pool.chaindb != nil {
+		pool.snapshot = newTxSnapshot(pool.chaindb)
+
+		if err := pool.snapshot.load(pool.AddLocals, pool.AddRemotesSync); err != nil {
+			log.Warn("Failed to load transaction pool snapshot", "err", err)
+		}
+	}
 
 	pool.wg.Add(1)
 	go pool.loop()
@@ -321,14 +338,17 @@ func (pool *TxPool) loop() {
 
 	var (
 		prevPending, prevQueued, prevStales int
+		persistedChanges                    uint64
 		// Start the stats reporting and transaction eviction tickers
 		report  = time.NewTicker(statsReportInterval)
 		evict   = time.NewTicker(evictionInterval)
 		journal = time.NewTicker(pool.config.Rejournal)
+		persist = time.NewTicker(pool.config.Repersist)
 	)
 	defer report.Stop()
 	defer evict.Stop()
 	defer journal.Stop()
+	defer persist.Stop()
 
 	for {
 		select {
@@ -378,17 +398,42 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Persist the pool into the database if transactions were added or removed
+		case <-persist.C:
+			if pool.snapshot != nil {
+				if changes := pool.all.Changes(); changes != persistedChanges {
+					pool.persist()
+					persistedChanges = changes
+				}
+			}
 		}
 	}
 }
 
+// persist writes the current content of the pool into the database snapshot.
+// The pool is not locked while writing, so its writers don't wait for the database.
+func (pool *TxPool) persist() {
+	pending, queued := pool.Content()
+
+	pool.mu.Lock()
+	locals := pool.locals.flatten()
+	pool.mu.Unlock()
+
+	if err := pool.snapshot.save(locals, pending, queued); err != nil {
+		log.Warn("Failed to persist transaction pool snapshot", "err", err)
+	}
+}
+
 func (pool *TxPool) resetHead(blockGasLimit uint64, blockNumber uint64) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 	pool.currentState = state.New(state.NewPlainStateReader(pool.chaindb))
 	pool.pendingNonces = newTxNoncer(pool.currentState)
 	pool.currentMaxGas = blockGasLimit
-	pool.istanbul = pool.chainconfig.IsIstanbul(big.NewInt(int64(blockNumber + 1)))
+	next := big.NewInt(int64(blockNumber + 1))
+	pool.istanbul = pool.chainconfig.IsIstanbul(next)
+	pool.eip2718 = pool.chainconfig.IsBerlin(next)
 }
 
 func (pool *TxPool) ResetHead(blockGasLimit uint64, blockNumber uint64) {
@@ -410,6 +455,9 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.snapshot != nil {
+		pool.persist()
+	}
 
 	pool.isStarted = false
 
@@ -535,6 +583,10 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	// Accept only legacy transactions until EIP-2718/2930 activates.
+	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
+		return ErrTxTypeNotSupported
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
@@ -568,7 +620,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrInsufficientFunds
 	}
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
 		return err
 	}
@@ -1520,9 +1572,10 @@ func (as *accountSet) merge(other *accountSet) {
 // peeking into the pool in Backend.Get without having to acquire the widely scoped
 // Backend.mu mutex.
 type txLookup struct {
-	all   map[common.Hash]*types.Transaction
-	slots int
-	lock  sync.RWMutex
+	all     map[common.Hash]*types.Transaction
+	slots   int
+	changes uint64 // additions and removals, to tell if the pool needs to be persisted
+	lock    sync.RWMutex
 }
 
 // newTxLookup returns a new txLookup structure.
@@ -1577,6 +1630,7 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	slotsGauge.Update(int64(t.slots))
 
 	t.all[tx.Hash()] = tx
+	t.changes++
 }
 
 // Remove removes a transaction from the lookup.
@@ -1588,6 +1642,15 @@ func (t *txLookup) Remove(hash common.Hash) {
 	slotsGauge.Update(int64(t.slots))
 
 	delete(t.all, hash)
+	t.changes++
+}
+
+// Changes returns the number of additions and removals of transactions so far.
+func (t *txLookup) Changes() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.changes
 }
 
 // numSlots calculates the number of slots needed for a single transaction.
@@ -19,6 +19,7 @@ package core
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -31,6 +32,7 @@ import (
 	"github.com/holiman/uint256"
 
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/common/u256"
 	"github.com/ledgerwatch/turbo-geth/core/state"
 	"github.com/ledgerwatch/turbo-geth/core/types"
@@ -47,6 +49,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.NoPersist = true
 	testTxPoolConfig.StartOnInit = true
 }
 
@@ -1836,6 +1839,163 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	}
 }
 
+// TestTransactionPersistence tests that both local and remote transactions are
+// persisted into the database and replayed into the pool after a restart.
+func TestTransactionPersistence(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := testTxPoolConfig
+	config.NoPersist = false
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	stateWriter := state.NewPlainStateWriter(db, nil, 1)
+	ibs := state.New(state.NewPlainStateReader(db))
+	ibs.AddBalance(crypto.PubkeyToAddress(local.PublicKey), uint256.NewInt().SetUint64(1000000000))
+	ibs.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), uint256.NewInt().SetUint64(1000000000))
+	if err := ibs.CommitBlock(context.Background(), stateWriter); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a local and two remote transactions, one of the remotes being queued
+	if err := pool.AddLocal(pricedTransaction(0, 100000, u256.Num1, local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, u256.Num1, remote)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(2, 100000, u256.Num1, remote)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	txCacher.Close()
+	pool.Stop()
+
+	// Restart the pool on the same database and ensure everything was restored
+	txCacher = NewTxSenderCacher(runtime.NumCPU())
+	pool = NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	pending, queued := pool.Stats()
+	if pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != crypto.PubkeyToAddress(local.PublicKey) {
+		t.Fatalf("local accounts mismatched: have %v, want %v", locals, []common.Address{crypto.PubkeyToAddress(local.PublicKey)})
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// TestTransactionPersistenceOnChange tests that the pool is persisted soon after
+// it changes, and not only when it is stopped, so a crash loses little of it.
+func TestTransactionPersistenceOnChange(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := testTxPoolConfig
+	config.NoPersist = false
+	config.Repersist = 10 * time.Millisecond
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	key, _ := crypto.GenerateKey()
+	ibs := state.New(state.NewPlainStateReader(db))
+	ibs.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt().SetUint64(1000000000))
+	if err := ibs.CommitBlock(context.Background(), state.NewPlainStateWriter(db, nil, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, u256.Num1, key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+
+	persisted := func() int {
+		count := 0
+		if err := db.Walk(dbutils.TxPoolBucket, nil, 0, func(k, v []byte) (bool, error) {
+			count++
+			return true, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+	deadline := time.Now().Add(time.Second)
+	for persisted() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("transaction is not persisted while the pool is running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestTransactionPersistenceDiff tests that the snapshot only rewrites the
+// transactions which changed, and removes those which left the pool.
+func TestTransactionPersistenceDiff(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	tx0, tx1 := pricedTransaction(0, 100000, u256.Num1, key), pricedTransaction(1, 100000, u256.Num1, key)
+
+	persisted := func() map[uint64]byte {
+		origins := make(map[uint64]byte)
+		if err := db.Walk(dbutils.TxPoolBucket, nil, 0, func(k, v []byte) (bool, error) {
+			origins[binary.BigEndian.Uint64(k[common.AddressLength:])] = v[0]
+			return true, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return origins
+	}
+
+	snapshot := newTxSnapshot(db)
+	if err := snapshot.save(nil, map[common.Address]types.Transactions{addr: {tx0, tx1}}); err != nil {
+		t.Fatalf("saving snapshot: %v", err)
+	}
+	if origins := persisted(); len(origins) != 2 || origins[0] != txSnapshotRemote || origins[1] != txSnapshotRemote {
+		t.Fatalf("persisted transactions mismatch: have %v, want 2 remotes", origins)
+	}
+
+	// A new snapshot over the same database must pick up the previous one
+	snapshot = newTxSnapshot(db)
+	if err := snapshot.load(func(txs []*types.Transaction) []error { return make([]error, len(txs)) },
+		func(txs []*types.Transaction) []error { return make([]error, len(txs)) }); err != nil {
+		t.Fatalf("loading snapshot: %v", err)
+	}
+	if err := snapshot.save([]common.Address{addr}, map[common.Address]types.Transactions{addr: {tx1}}); err != nil {
+		t.Fatalf("saving snapshot: %v", err)
+	}
+	if origins := persisted(); len(origins) != 1 || origins[1] != txSnapshotLocal {
+		t.Fatalf("persisted transactions mismatch: have %v, want the local transaction 1", origins)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
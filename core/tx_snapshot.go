@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+const (
+	txSnapshotRemote byte = 0
+	txSnapshotLocal  byte = 1
+)
+
+// txSnapshot persists the whole content of the transaction pool (local and remote
+// transactions, pending and queued) into dbutils.TxPoolBucket. Unlike txJournal,
+// which only keeps local transactions in a flat file, the snapshot lets the pool
+// be fully restored after a node restart.
+//
+// Keys are sender address + nonce (uint64 big endian), values are the origin flag
+// (1 byte, local or remote) + RLP encoded transaction. Since keys are sorted by
+// sender and nonce, transactions are replayed into the pool in nonce order.
+type txSnapshot struct {
+	db    *ethdb.ObjectDatabase
+	saved map[string][]byte // entries in the database, so save only writes the difference
+}
+
+func newTxSnapshot(db *ethdb.ObjectDatabase) *txSnapshot {
+	return &txSnapshot{db: db, saved: map[string][]byte{}}
+}
+
+// load replays the persisted transactions into the pool. Transactions that were
+// local at the time of the snapshot are passed to addLocals, all the others - to addRemotes.
+func (snapshot *txSnapshot) load(addLocals, addRemotes func([]*types.Transaction) []error) error {
+	var locals, remotes types.Transactions
+	saved := map[string][]byte{}
+	if err := snapshot.db.Walk(dbutils.TxPoolBucket, nil, 0, func(k, v []byte) (bool, error) {
+		if len(k) != common.AddressLength+8 || len(v) < 1 {
+			return false, fmt.Errorf("invalid tx pool snapshot entry %x", k)
+		}
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(v[1:], tx); err != nil {
+			return false, fmt.Errorf("decoding tx pool snapshot entry %x: %w", k, err)
+		}
+		if v[0] == txSnapshotLocal {
+			locals = append(locals, tx)
+		} else {
+			remotes = append(remotes, tx)
+		}
+		saved[string(k)] = common.CopyBytes(v)
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	snapshot.saved = saved
+
+	dropped := 0
+	for _, err := range addLocals(locals) {
+		if err != nil {
+			log.Debug("Failed to add persisted local transaction", "err", err)
+			dropped++
+		}
+	}
+	for _, err := range addRemotes(remotes) {
+		if err != nil {
+			log.Debug("Failed to add persisted remote transaction", "err", err)
+			dropped++
+		}
+	}
+	log.Info("Loaded transaction pool snapshot", "locals", len(locals), "remotes", len(remotes), "dropped", dropped)
+	return nil
+}
+
+// save replaces the persisted snapshot with the given pool content (typically
+// pending and queued transactions) in a single database transaction. Only the
+// entries which differ from the previous save are written.
+func (snapshot *txSnapshot) save(locals []common.Address, contents ...map[common.Address]types.Transactions) error {
+	isLocal := make(map[common.Address]struct{}, len(locals))
+	for _, addr := range locals {
+		isLocal[addr] = struct{}{}
+	}
+	entries := make(map[string][]byte)
+	for _, content := range contents {
+		for addr, txs := range content {
+			origin := txSnapshotRemote
+			if _, ok := isLocal[addr]; ok {
+				origin = txSnapshotLocal
+			}
+			for _, txn := range txs {
+				k := make([]byte, common.AddressLength+8)
+				copy(k, addr[:])
+				binary.BigEndian.PutUint64(k[common.AddressLength:], txn.Nonce())
+				encoded, err := rlp.EncodeToBytes(txn)
+				if err != nil {
+					return err
+				}
+				entries[string(k)] = append([]byte{origin}, encoded...)
+			}
+		}
+	}
+
+	deleted, written := 0, 0
+	if err := snapshot.db.KV().Update(context.Background(), func(tx ethdb.Tx) error {
+		c := tx.Cursor(dbutils.TxPoolBucket)
+		for k := range snapshot.saved {
+			if _, ok := entries[k]; ok {
+				continue
+			}
+			if err := c.Delete([]byte(k)); err != nil {
+				return err
+			}
+			deleted++
+		}
+		for k, v := range entries {
+			if bytes.Equal(snapshot.saved[k], v) {
+				continue
+			}
+			if err := c.Put([]byte(k), v); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	snapshot.saved = entries
+	log.Debug("Persisted transaction pool snapshot", "transactions", len(entries), "written", written, "deleted", deleted)
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+)
+
+// AccessList is an EIP-2930 access list.
+type AccessList []AccessTuple
+
+// AccessTuple is the element type of an access list.
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// StorageKeys returns the total number of storage keys in the access list.
+func (al AccessList) StorageKeys() int {
+	sum := 0
+	for _, tuple := range al {
+		sum += len(tuple.StorageKeys)
+	}
+	return sum
+}
+
+// AccessListTx is the data of EIP-2930 access list transactions.
+type AccessListTx struct {
+	ChainID    uint256.Int     // destination chain ID
+	Nonce      uint64          // nonce of sender account
+	GasPrice   uint256.Int     // wei per gas
+	Gas        uint64          // gas limit
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      uint256.Int     // wei amount
+	Data       []byte          // contract invocation input data
+	AccessList AccessList      // EIP-2930 access list
+	V, R, S    uint256.Int     // signature values
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *AccessListTx) copy() TxData {
+	cpy := &AccessListTx{
+		ChainID:  tx.ChainID,
+		Nonce:    tx.Nonce,
+		GasPrice: tx.GasPrice,
+		Gas:      tx.Gas,
+		Value:    tx.Value,
+		Data:     common.CopyBytes(tx.Data),
+		V:        tx.V,
+		R:        tx.R,
+		S:        tx.S,
+	}
+	if tx.To != nil {
+		to := *tx.To
+		cpy.To = &to
+	}
+	if tx.AccessList != nil {
+		cpy.AccessList = make(AccessList, len(tx.AccessList))
+		for i, tuple := range tx.AccessList {
+			cpy.AccessList[i] = AccessTuple{
+				Address:     tuple.Address,
+				StorageKeys: append([]common.Hash(nil), tuple.StorageKeys...),
+			}
+		}
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+
+func (tx *AccessListTx) txType() byte           { return AccessListTxType }
+func (tx *AccessListTx) chainID() *uint256.Int  { return &tx.ChainID }
+func (tx *AccessListTx) accessList() AccessList { return tx.AccessList }
+func (tx *AccessListTx) data() []byte           { return tx.Data }
+func (tx *AccessListTx) gas() uint64            { return tx.Gas }
+func (tx *AccessListTx) gasPrice() *uint256.Int { return &tx.GasPrice }
+func (tx *AccessListTx) value() *uint256.Int    { return &tx.Value }
+func (tx *AccessListTx) nonce() uint64          { return tx.Nonce }
+func (tx *AccessListTx) to() *common.Address    { return tx.To }
+func (tx *AccessListTx) protected() bool        { return true }
+func (tx *AccessListTx) rawSignatureValues() (v, r, s *uint256.Int) {
+	return &tx.V, &tx.R, &tx.S
+}
+
+func (tx *AccessListTx) setSignatureValues(chainID, v, r, s *uint256.Int) {
+	tx.ChainID.Set(chainID)
+	tx.V.Set(v)
+	tx.R.Set(r)
+	tx.S.Set(s)
+}
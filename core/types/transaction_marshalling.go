@@ -0,0 +1,231 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+)
+
+// txJSON is the JSON representation of transactions.
+type txJSON struct {
+	Type hexutil.Uint64 `json:"type"`
+
+	// Common transaction fields:
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"input"`
+	V        *hexutil.Big    `json:"v"`
+	R        *hexutil.Big    `json:"r"`
+	S        *hexutil.Big    `json:"s"`
+	To       *common.Address `json:"to"`
+
+	// Access list transaction fields:
+	ChainID    *hexutil.Big `json:"chainId,omitempty"`
+	AccessList *AccessList  `json:"accessList,omitempty"`
+
+	// Only used for encoding:
+	Hash common.Hash `json:"hash"`
+}
+
+// MarshalJSON encodes the web3 RPC transaction format.
+func (tx *Transaction) MarshalJSON() ([]byte, error) {
+	var enc txJSON
+	// These are set for all tx types.
+	enc.Hash = tx.Hash()
+	enc.Type = hexutil.Uint64(tx.Type())
+
+	switch tx := tx.inner.(type) {
+	case *LegacyTx:
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice.ToBig())
+		enc.Value = (*hexutil.Big)(tx.Value.ToBig())
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V.ToBig())
+		enc.R = (*hexutil.Big)(tx.R.ToBig())
+		enc.S = (*hexutil.Big)(tx.S.ToBig())
+	case *AccessListTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID.ToBig())
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice.ToBig())
+		enc.Value = (*hexutil.Big)(tx.Value.ToBig())
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V.ToBig())
+		enc.R = (*hexutil.Big)(tx.R.ToBig())
+		enc.S = (*hexutil.Big)(tx.S.ToBig())
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON decodes the web3 RPC transaction format.
+func (tx *Transaction) UnmarshalJSON(input []byte) error {
+	var dec txJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	// Decode / verify fields according to transaction type.
+	var inner TxData
+	switch dec.Type {
+	case LegacyTxType:
+		var itx LegacyTx
+		inner = &itx
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.GasPrice == nil {
+			return errors.New("missing required field 'gasPrice' in transaction")
+		}
+		if err := setFromBig(&itx.GasPrice, dec.GasPrice); err != nil {
+			return err
+		}
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' in transaction")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		if err := setFromBig(&itx.Value, dec.Value); err != nil {
+			return err
+		}
+		if dec.Data == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Data
+		if err := setSignatureFromJSON(&dec, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			var V byte
+			if isProtectedV(&itx.V) {
+				chainID := deriveChainID(&itx.V).Uint64()
+				V = byte(itx.V.Uint64() - 35 - 2*chainID)
+			} else {
+				V = byte(itx.V.Uint64() - 27)
+			}
+			if !crypto.ValidateSignatureValues(V, &itx.R, &itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	case AccessListTxType:
+		var itx AccessListTx
+		inner = &itx
+		// Access list is optional for now.
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		if err := setFromBig(&itx.ChainID, dec.ChainID); err != nil {
+			return err
+		}
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.GasPrice == nil {
+			return errors.New("missing required field 'gasPrice' in transaction")
+		}
+		if err := setFromBig(&itx.GasPrice, dec.GasPrice); err != nil {
+			return err
+		}
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' in transaction")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		if err := setFromBig(&itx.Value, dec.Value); err != nil {
+			return err
+		}
+		if dec.Data == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Data
+		if err := setSignatureFromJSON(&dec, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			if !itx.V.IsUint64() || itx.V.Uint64() > 1 {
+				return ErrInvalidSig
+			}
+			if !crypto.ValidateSignatureValues(byte(itx.V.Uint64()), &itx.R, &itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	default:
+		return ErrTxTypeNotSupported
+	}
+
+	// Now set the inner transaction.
+	tx.setDecoded(inner, 0)
+	return nil
+}
+
+func setSignatureFromJSON(dec *txJSON, v, r, s *uint256.Int) error {
+	if dec.V == nil {
+		return errors.New("missing required field 'v' in transaction")
+	}
+	if err := setFromBig(v, dec.V); err != nil {
+		return err
+	}
+	if dec.R == nil {
+		return errors.New("missing required field 'r' in transaction")
+	}
+	if err := setFromBig(r, dec.R); err != nil {
+		return err
+	}
+	if dec.S == nil {
+		return errors.New("missing required field 's' in transaction")
+	}
+	return setFromBig(s, dec.S)
+}
+
+func setFromBig(dst *uint256.Int, src *hexutil.Big) error {
+	if overflow := dst.SetFromBig((*big.Int)(src)); overflow {
+		return errors.New("value exceeds 256 bits")
+	}
+	return nil
+}
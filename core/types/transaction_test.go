@@ -52,8 +52,25 @@ var (
 		HomesteadSigner{},
 		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a301"),
 	)
+
+	emptyEip2718Tx = NewTx(&AccessListTx{
+		ChainID:  *u256.Num1,
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    *uint256.NewInt().SetUint64(10),
+		Gas:      25000,
+		GasPrice: *uint256.NewInt().SetUint64(1),
+		Data:     common.FromHex("5544"),
+	})
+
+	signedEip2718Tx, _ = emptyEip2718Tx.WithSignature(
+		NewEIP2930Signer(common.Big1),
+		common.Hex2Bytes("c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b266032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d3752101"),
+	)
 )
 
+var testAddr = common.HexToAddress("b94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+
 func TestTransactionSigHash(t *testing.T) {
 	var homestead HomesteadSigner
 	if homestead.Hash(emptyTx) != common.HexToHash("c775b99e7ad12f50d819fcd602390467e28141316969f4b57f0626f74fe3b386") {
@@ -75,6 +92,113 @@ func TestTransactionEncode(t *testing.T) {
 	}
 }
 
+func TestEIP2718TransactionSigHash(t *testing.T) {
+	s := NewEIP2930Signer(common.Big1)
+	if s.Hash(emptyEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("empty EIP-2718 transaction hash mismatch, got %x", s.Hash(emptyEip2718Tx))
+	}
+	if s.Hash(signedEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("signed EIP-2718 transaction hash mismatch, got %x", s.Hash(signedEip2718Tx))
+	}
+}
+
+func TestEIP2718TransactionEncode(t *testing.T) {
+	// RLP representation
+	{
+		have, err := rlp.EncodeToBytes(signedEip2718Tx)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		want := common.FromHex("b86601f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+		if !bytes.Equal(have, want) {
+			t.Errorf("encoded RLP mismatch, got %x", have)
+		}
+	}
+	// Binary representation
+	{
+		have, err := signedEip2718Tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		want := common.FromHex("01f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+		if !bytes.Equal(have, want) {
+			t.Errorf("encoded binary mismatch, got %x", have)
+		}
+	}
+}
+
+func TestEIP2718TransactionDecode(t *testing.T) {
+	enc, err := signedEip2718Tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	var binTx Transaction
+	if err = binTx.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("binary decode error: %v", err)
+	}
+	rlpEnc, err := rlp.EncodeToBytes(signedEip2718Tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	rlpTx, err := decodeTx(rlpEnc)
+	if err != nil {
+		t.Fatalf("rlp decode error: %v", err)
+	}
+	for _, tx := range []*Transaction{&binTx, rlpTx} {
+		if tx.Type() != AccessListTxType {
+			t.Errorf("wrong transaction type, want %d, got %d", AccessListTxType, tx.Type())
+		}
+		if tx.Hash() != signedEip2718Tx.Hash() {
+			t.Errorf("decoded hash mismatch, want %x, got %x", signedEip2718Tx.Hash(), tx.Hash())
+		}
+	}
+	if err := binTx.UnmarshalBinary([]byte{0x05, 0xc0}); err != ErrTxTypeNotSupported {
+		t.Errorf("unexpected error for unknown tx type: %v", err)
+	}
+}
+
+func TestEIP2930Signer(t *testing.T) {
+	var (
+		key, keyAddr = defaultTestKey()
+		signer1      = NewEIP2930Signer(common.Big1)
+		signer2      = NewEIP2930Signer(common.Big2)
+		tx1          = NewTx(&AccessListTx{ChainID: *u256.Num1, Nonce: 1})
+	)
+	// A typed transaction carries its chain id, so it can't be signed for another chain.
+	if _, err := SignTx(tx1, signer2, key); err != ErrInvalidChainId {
+		t.Errorf("signing tx for another chain: want ErrInvalidChainId, got %v", err)
+	}
+	signed, err := SignTx(tx1, signer1, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if !signed.Protected() {
+		t.Error("access list transaction should be protected")
+	}
+	sender, err := Sender(signer1, signed)
+	if err != nil {
+		t.Fatalf("could not recover sender: %v", err)
+	}
+	if sender != keyAddr {
+		t.Errorf("wrong sender, want %x, got %x", keyAddr, sender)
+	}
+	if _, err := signer2.Sender(signed); err != ErrInvalidChainId {
+		t.Errorf("recovering with wrong chain id: want ErrInvalidChainId, got %v", err)
+	}
+	// Legacy transactions are still accepted by the EIP-2930 signer.
+	legacy, err := SignTx(NewTransaction(0, testAddr, u256.Num0, 21000, u256.Num1, nil), signer1, key)
+	if err != nil {
+		t.Fatalf("could not sign legacy transaction: %v", err)
+	}
+	if sender, err = Sender(signer1, legacy); err != nil || sender != keyAddr {
+		t.Errorf("legacy tx sender mismatch: %x, %v", sender, err)
+	}
+	// Pre-Berlin signers must reject typed transactions.
+	if _, err := NewEIP155Signer(common.Big1).Sender(signed); err != ErrTxTypeNotSupported {
+		t.Errorf("EIP155 signer on typed tx: want ErrTxTypeNotSupported, got %v", err)
+	}
+}
+
 func decodeTx(data []byte) (*Transaction, error) {
 	var tx Transaction
 	t, err := &tx, rlp.Decode(bytes.NewReader(data), &tx)
@@ -227,16 +351,29 @@ func TestTransactionJSON(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not generate key: %v", err)
 	}
-	signer := NewEIP155Signer(common.Big1)
+	signer := NewEIP2930Signer(common.Big1)
 
 	transactions := make([]*Transaction, 0, 50)
 	for i := uint64(0); i < 25; i++ {
 		var tx *Transaction
-		switch i % 2 {
+		switch i % 3 {
 		case 0:
 			tx = NewTransaction(i, common.Address{1}, u256.Num0, 1, u256.Num2, []byte("abcdef"))
 		case 1:
 			tx = NewContractCreation(i, u256.Num0, 1, u256.Num2, []byte("abcdef"))
+		case 2:
+			to := common.Address{2}
+			tx = NewTx(&AccessListTx{
+				ChainID:  *u256.Num1,
+				Nonce:    i,
+				To:       &to,
+				Gas:      123457,
+				GasPrice: *u256.Num2,
+				Data:     []byte("abcdef"),
+				AccessList: AccessList{
+					{Address: common.Address{3}, StorageKeys: []common.Hash{{4}}},
+				},
+			})
 		}
 		transactions = append(transactions, tx)
 
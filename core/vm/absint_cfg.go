@@ -0,0 +1,174 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/holiman/uint256"
+)
+
+// CfgAnalysis is the outcome of the abstract interpretation of a contract code, see AbsIntCfg
+type CfgAnalysis struct {
+	CodeSize        int    `json:"codeSize"`
+	Blocks          int    `json:"blocks"`
+	Edges           int    `json:"edges"`           // edges reachable from the entry point
+	Jumps           int    `json:"jumps"`           // reachable JUMP and JUMPI instructions
+	ResolvedJumps   int    `json:"resolvedJumps"`   // reachable jumps with only concrete, valid destinations
+	UnresolvedJumps []int  `json:"unresolvedJumps"` // program counters of the jumps which could not be resolved
+	MinStackHeight  int    `json:"minStackHeight"`  // lowest stack height at reachable instructions
+	MaxStackHeight  int    `json:"maxStackHeight"`  // highest stack height at reachable instructions
+	StackUnbounded  bool   `json:"stackUnbounded"`  // the stack grows beyond the modelled depth
+	Steps           int    `json:"steps"`           // number of edges processed until the fixpoint
+	Error           string `json:"error,omitempty"` // set when the analysis failed, the other counters are not filled then
+
+	program     *program
+	prevEdgeMap map[int]map[int]bool
+	badJumps    map[int]bool
+}
+
+// Dot returns the control flow graph of the basic blocks in the graphviz format
+func (a *CfgAnalysis) Dot() string {
+	if a.program == nil {
+		return ""
+	}
+	return cfgGraph(a.program, a.prevEdgeMap, a.badJumps).String()
+}
+
+// AbsIntCfg runs the same analysis as AbsIntCfgHarness, without printing anything and without stopping at
+// unresolved jumps, so that the results for many contracts can be collected. The analysis gives up after
+// maxSteps edges (0 means no limit).
+func AbsIntCfg(code []byte, maxSteps int) (result *CfgAnalysis) {
+	result = &CfgAnalysis{CodeSize: len(code), UnresolvedJumps: []int{}}
+	if len(code) == 0 {
+		return result
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	contract := NewContract(AccountRef{}, AccountRef{}, uint256.NewInt(), 0, true /* skipAnalysis */)
+	contract.Code = code
+	program := toProgram(contract)
+	result.program = program
+	result.Blocks = len(program.blocks)
+
+	D := make(map[int]*astate)
+	for pc := 0; pc < len(code); pc++ {
+		D[pc] = emptyState()
+	}
+	D[0] = botState()
+
+	prevEdgeMap := make(map[int]map[int]bool)
+	badJumps := make(map[int]bool)
+	result.prevEdgeMap = prevEdgeMap
+	result.badJumps = badJumps
+
+	resolution := resolve(program, 0, D[0])
+	if !resolution.resolved {
+		result.Error = "unable to resolve at pc=0"
+		return result
+	}
+	for _, e := range resolution.edges {
+		if prevEdgeMap[e.pc1] == nil {
+			prevEdgeMap[e.pc1] = make(map[int]bool)
+		}
+		prevEdgeMap[e.pc1][e.pc0] = true
+	}
+	workList := resolution.edges
+
+	for len(workList) > 0 {
+		if maxSteps > 0 && result.Steps >= maxSteps {
+			result.Error = fmt.Sprintf("no fixpoint after %d steps", maxSteps)
+			return result
+		}
+		var e edge
+		e, workList = workList[0], workList[1:]
+		result.Steps++
+
+		post1, err := post(D[e.pc0], e)
+		if err != nil {
+			result.Error = fmt.Sprintf("pc=%d: %v", e.pc0, err)
+			return result
+		}
+		if Leq(post1, D[e.pc1]) {
+			continue
+		}
+		D[e.pc1] = Lub(post1, D[e.pc1])
+
+		resolution := resolve(program, e.pc1, D[e.pc1])
+		if !resolution.resolved {
+			badJumps[resolution.badJump.pc] = true
+			continue
+		}
+		for _, e := range resolution.edges {
+			inWorkList := false
+			for _, w := range workList {
+				if w.pc0 == e.pc0 && w.pc1 == e.pc1 {
+					inWorkList = true
+					break
+				}
+			}
+			if !inWorkList {
+				workList = append([]edge{e}, workList...)
+			}
+		}
+		if prevEdgeMap[e.pc1] == nil {
+			prevEdgeMap[e.pc1] = make(map[int]bool)
+		}
+		prevEdgeMap[e.pc1][e.pc0] = true
+	}
+
+	var finalEdges []edge
+	result.MinStackHeight = -1
+	for pc := 0; pc < len(code); pc++ {
+		resolution := resolve(program, pc, D[pc])
+		if !resolution.resolved {
+			badJumps[resolution.badJump.pc] = true
+		}
+		finalEdges = append(finalEdges, resolution.edges...)
+
+		if len(D[pc].stackset) == 0 {
+			continue // not reachable
+		}
+		stmt := program.stmts[pc]
+		if !stmt.inferredAsData && (stmt.opcode == JUMP || stmt.opcode == JUMPI) {
+			result.Jumps++
+		}
+		for _, stack := range D[pc].stackset {
+			height := stack.height()
+			if height == absStackLen {
+				result.StackUnbounded = true
+			}
+			if result.MinStackHeight < 0 || height < result.MinStackHeight {
+				result.MinStackHeight = height
+			}
+			if height > result.MaxStackHeight {
+				result.MaxStackHeight = height
+			}
+		}
+	}
+	if result.MinStackHeight < 0 {
+		result.MinStackHeight = 0
+	}
+	result.Edges = len(getEntryReachableEdges(0, finalEdges))
+
+	for pc := range badJumps {
+		result.UnresolvedJumps = append(result.UnresolvedJumps, pc)
+	}
+	sort.Ints(result.UnresolvedJumps)
+	result.ResolvedJumps = result.Jumps - len(result.UnresolvedJumps)
+	return result
+}
+
+// height returns the number of values pushed on the stack, or absStackLen if it is not known because the stack
+// grew beyond the modelled depth
+func (s *astack) height() int {
+	for i, value := range s.values {
+		if value.kind == BotValue {
+			return i
+		}
+	}
+	return absStackLen
+}
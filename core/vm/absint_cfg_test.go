@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAbsIntCfg(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       []byte
+		jumps      int
+		unresolved []int
+		maxStack   int
+		failed     bool
+	}{
+		{
+			name:     "straight",
+			code:     []byte{byte(PUSH1), 0x1, byte(PUSH1), 0x1, byte(ADD), byte(STOP)},
+			maxStack: 2,
+		},
+		{
+			name:     "static jump",
+			code:     []byte{byte(PUSH1), 0x3, byte(JUMP), byte(JUMPDEST), byte(STOP)},
+			jumps:    1,
+			maxStack: 1,
+		},
+		{
+			name:     "conditional jump",
+			code:     []byte{byte(PUSH1), 0x0, byte(CALLDATALOAD), byte(PUSH1), 0x8, byte(JUMPI), byte(STOP), byte(STOP), byte(JUMPDEST), byte(STOP)},
+			jumps:    1,
+			maxStack: 2,
+		},
+		{
+			name:       "jump into push data",
+			code:       []byte{byte(PUSH1), 0x1, byte(JUMP), byte(STOP)},
+			jumps:      1,
+			unresolved: []int{2},
+			maxStack:   1,
+		},
+		{
+			name:       "jump beyond code",
+			code:       []byte{byte(PUSH1), 0xff, byte(JUMP)},
+			jumps:      1,
+			unresolved: []int{2},
+			maxStack:   1,
+		},
+		{
+			name:       "dynamic jump",
+			code:       []byte{byte(PUSH1), 0x0, byte(CALLDATALOAD), byte(JUMP), byte(JUMPDEST), byte(STOP)},
+			jumps:      1,
+			unresolved: []int{3},
+			maxStack:   1,
+		},
+		{
+			name:   "deep stack",
+			code:   append(bytes.Repeat([]byte{byte(POP)}, absStackLen+1), byte(STOP)),
+			failed: true,
+		},
+	}
+	for _, test := range tests {
+		a := AbsIntCfg(test.code, 1000)
+		if test.failed {
+			if a.Error == "" {
+				t.Errorf("%s: expected the analysis to fail", test.name)
+			}
+			continue
+		}
+		if a.Error != "" {
+			t.Errorf("%s: unexpected error %s", test.name, a.Error)
+			continue
+		}
+		if a.Jumps != test.jumps {
+			t.Errorf("%s: jumps %d, expected %d", test.name, a.Jumps, test.jumps)
+		}
+		unresolved := test.unresolved
+		if unresolved == nil {
+			unresolved = []int{}
+		}
+		if !reflect.DeepEqual(a.UnresolvedJumps, unresolved) {
+			t.Errorf("%s: unresolved jumps %v, expected %v", test.name, a.UnresolvedJumps, unresolved)
+		}
+		if a.ResolvedJumps != test.jumps-len(unresolved) {
+			t.Errorf("%s: resolved jumps %d, expected %d", test.name, a.ResolvedJumps, test.jumps-len(unresolved))
+		}
+		if a.MaxStackHeight != test.maxStack {
+			t.Errorf("%s: max stack height %d, expected %d", test.name, a.MaxStackHeight, test.maxStack)
+		}
+		if !strings.HasPrefix(a.Dot(), "digraph") {
+			t.Errorf("%s: unexpected dot output %q", test.name, a.Dot())
+		}
+	}
+}
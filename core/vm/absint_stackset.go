@@ -426,10 +426,10 @@ func resolve(program *program, pc0 int, st0 *astate) ResolveResult {
 			if jumpDest.kind == TopValue {
 				isBadJump = true
 			} else if jumpDest.kind == ConcreteValue {
-				if jumpDest.value.IsUint64() {
+				if jumpDest.value.IsUint64() && jumpDest.value.Uint64() < uint64(len(program.stmts)) {
 					pc1 := int(jumpDest.value.Uint64())
 
-					if program.stmts[pc1].opcode != JUMPDEST {
+					if program.stmts[pc1].opcode != JUMPDEST || program.stmts[pc1].inferredAsData {
 						isBadJump = true
 					} else {
 						edges = append(edges, edge{pc0, stmt, pc1, true})
@@ -589,10 +589,35 @@ func printAnlyState(program *program, prevEdgeMap map[int]map[int]bool, D map[in
 		fmt.Println(badJump)
 	}
 
+	g := cfgGraph(program, prevEdgeMap, badJumps)
+
+	path := "cfg.dot"
+	_ = os.Remove(path)
+
+	f, errcr := os.Create(path)
+	if errcr != nil {
+		panic(errcr)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	_, errwr := w.WriteString(g.String())
+	if errwr != nil {
+		panic(errwr)
+	}
+	_ = w.Flush()
+}
+
+// cfgGraph builds the graph of the basic blocks connected by the edges discovered so far, the blocks ending with
+// unresolved jumps are highlighted
+func cfgGraph(program *program, prevEdgeMap map[int]map[int]bool, badJumps map[int]bool) *dot.Graph {
 	g := dot.NewGraph(dot.Directed)
 	block2node := make(map[*block]*dot.Node)
 	for _, block := range program.blocks {
 		n := g.Node(fmt.Sprintf("%v\n%v", block.entrypc, block.exitpc)).Box()
+		if badJumps[block.exitpc] {
+			n.Attr("color", "red")
+		}
 		block2node[block] = &n
 	}
 
@@ -614,22 +639,7 @@ func printAnlyState(program *program, prevEdgeMap map[int]map[int]bool, D map[in
 			g.Edge(*n0, *n1)
 		}
 	}
-
-	path := "cfg.dot"
-	_ = os.Remove(path)
-
-	f, errcr := os.Create(path)
-	if errcr != nil {
-		panic(errcr)
-	}
-	defer f.Close()
-
-	w := bufio.NewWriter(f)
-	_, errwr := w.WriteString(g.String())
-	if errwr != nil {
-		panic(errwr)
-	}
-	_ = w.Flush()
+	return g
 }
 
 func check(program *program, prevEdgeMap map[int]map[int]bool) {
@@ -0,0 +1,78 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// registeredPrecompiles contains the implementations of the precompiled contracts which are not part
+// of the Ethereum protocol, the chain configs enable them by name (see params.PrecompileConfig)
+var (
+	registeredPrecompiles     = make(map[string]PrecompiledContract)
+	registeredPrecompilesLock sync.RWMutex
+)
+
+// RegisterPrecompile makes a precompiled contract implementation available to the chain configs under
+// the given name. The gas it consumes is reported by its RequiredGas method. It is meant to be called
+// before the node starts, e.g. from main or an init function, and panics if the name is taken.
+func RegisterPrecompile(name string, p PrecompiledContract) {
+	registeredPrecompilesLock.Lock()
+	defer registeredPrecompilesLock.Unlock()
+	if _, ok := registeredPrecompiles[name]; ok {
+		panic(fmt.Sprintf("precompile %q is already registered", name))
+	}
+	registeredPrecompiles[name] = p
+}
+
+func registeredPrecompile(name string) (PrecompiledContract, bool) {
+	registeredPrecompilesLock.RLock()
+	defer registeredPrecompilesLock.RUnlock()
+	p, ok := registeredPrecompiles[name]
+	return p, ok
+}
+
+// CheckPrecompiles verifies that the precompiles enabled by the chain config are registered, and that
+// their addresses are neither used by other precompiles of the config nor by the standard ones.
+func CheckPrecompiles(config *params.ChainConfig) error {
+	seen := make(map[common.Address]string)
+	for _, p := range config.Precompiles {
+		if _, ok := registeredPrecompile(p.Name); !ok {
+			return fmt.Errorf("precompile %q at %x is not registered", p.Name, p.Address)
+		}
+		if _, ok := PrecompiledContractsYoloV1[p.Address]; ok {
+			return fmt.Errorf("precompile %q at %x clashes with a standard precompile", p.Name, p.Address)
+		}
+		if name, ok := seen[p.Address]; ok {
+			return fmt.Errorf("precompiles %q and %q have the same address %x", name, p.Name, p.Address)
+		}
+		seen[p.Address] = p.Name
+	}
+	return nil
+}
+
+// precompiledContracts returns the standard precompiles of the rules, extended with the precompiles of
+// the chain config which are active at the given block.
+func precompiledContracts(config *params.ChainConfig, rules params.Rules, num *big.Int) map[common.Address]PrecompiledContract {
+	standard := activePrecompiledContracts(rules)
+	custom := config.CustomPrecompiles(num)
+	if len(custom) == 0 {
+		return standard
+	}
+	precompiles := make(map[common.Address]PrecompiledContract, len(standard)+len(custom))
+	for addr, p := range standard {
+		precompiles[addr] = p
+	}
+	for _, c := range custom {
+		p, ok := registeredPrecompile(c.Name)
+		if !ok {
+			// Executing the block without it would silently produce a different state
+			panic(fmt.Sprintf("precompile %q at %x is not registered", c.Name, c.Address))
+		}
+		precompiles[c.Address] = p
+	}
+	return precompiles
+}
@@ -0,0 +1,80 @@
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// reverse is a test precompile returning its input reversed, for 10 gas per byte
+type reverse struct{}
+
+func (reverse) RequiredGas(input []byte) uint64 { return 10 * uint64(len(input)) }
+
+func (reverse) Run(input []byte) ([]byte, error) {
+	out := make([]byte, len(input))
+	for i, b := range input {
+		out[len(input)-1-i] = b
+	}
+	return out, nil
+}
+
+func init() {
+	RegisterPrecompile("test.reverse", reverse{})
+}
+
+func TestCustomPrecompiles(t *testing.T) {
+	addr := common.HexToAddress("0x0100")
+	config := *params.TestChainConfig
+	config.Precompiles = []params.PrecompileConfig{{Name: "test.reverse", Address: addr, Block: big.NewInt(5)}}
+	if err := CheckPrecompiles(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	before := NewEVM(Context{BlockNumber: big.NewInt(4)}, nil, &config, Config{})
+	if _, ok := before.precompile(addr); ok {
+		t.Errorf("precompile active before its activation block")
+	}
+	if len(before.ActivePrecompiles()) != len(PrecompiledContractsIstanbul) {
+		t.Errorf("active precompiles %d, expected %d", len(before.ActivePrecompiles()), len(PrecompiledContractsIstanbul))
+	}
+
+	after := NewEVM(Context{BlockNumber: big.NewInt(5)}, nil, &config, Config{})
+	p, ok := after.precompile(addr)
+	if !ok {
+		t.Fatalf("precompile not active at its activation block")
+	}
+	if _, ok := after.precompile(common.BytesToAddress([]byte{1})); !ok {
+		t.Errorf("standard precompiles are not active")
+	}
+	if len(after.ActivePrecompiles()) != len(PrecompiledContractsIstanbul)+1 {
+		t.Errorf("active precompiles %d, expected %d", len(after.ActivePrecompiles()), len(PrecompiledContractsIstanbul)+1)
+	}
+	ret, gas, err := RunPrecompiledContract(p, []byte{1, 2, 3}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ret, []byte{3, 2, 1}) || gas != 70 {
+		t.Errorf("unexpected result %x, remaining gas %d", ret, gas)
+	}
+}
+
+func TestCheckPrecompiles(t *testing.T) {
+	for _, precompiles := range [][]params.PrecompileConfig{
+		{{Name: "test.unknown", Address: common.HexToAddress("0x0100"), Block: big.NewInt(0)}},
+		{{Name: "test.reverse", Address: common.BytesToAddress([]byte{1}), Block: big.NewInt(0)}},
+		{
+			{Name: "test.reverse", Address: common.HexToAddress("0x0100"), Block: big.NewInt(0)},
+			{Name: "test.reverse", Address: common.HexToAddress("0x0100"), Block: big.NewInt(10)},
+		},
+	} {
+		config := *params.TestChainConfig
+		config.Precompiles = precompiles
+		if err := CheckPrecompiles(&config); err == nil {
+			t.Errorf("expected an error for %v", precompiles)
+		}
+	}
+}
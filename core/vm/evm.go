@@ -46,21 +46,20 @@ type (
 )
 
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
-	var precompiles map[common.Address]PrecompiledContract
-	switch {
-	case evm.chainRules.IsYoloV1:
-		precompiles = PrecompiledContractsYoloV1
-	case evm.chainRules.IsIstanbul:
-		precompiles = PrecompiledContractsIstanbul
-	case evm.chainRules.IsByzantium:
-		precompiles = PrecompiledContractsByzantium
-	default:
-		precompiles = PrecompiledContractsHomestead
-	}
-	p, ok := precompiles[addr]
+	p, ok := evm.precompiles[addr]
 	return p, ok
 }
 
+// ActivePrecompiles returns the addresses of the precompiles enabled in the current block, including
+// the ones enabled by the chain config. They are added to the access list of every transaction (EIP-2929).
+func (evm *EVM) ActivePrecompiles() []common.Address {
+	addresses := make([]common.Address, 0, len(evm.precompiles))
+	for addr := range evm.precompiles {
+		addresses = append(addresses, addr)
+	}
+	return addresses
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	for _, interpreter := range evm.interpreters {
@@ -124,6 +123,8 @@ type EVM struct {
 	chainConfig *params.ChainConfig
 	// chain rules contains the chain rules for the current epoch
 	chainRules params.Rules
+	// precompiles contains the precompiled contracts active in the current block
+	precompiles map[common.Address]PrecompiledContract
 	// virtual machine configuration options used to initialise the
 	// evm.
 	vmConfig Config
@@ -151,6 +152,7 @@ func NewEVM(ctx Context, state IntraBlockState, chainConfig *params.ChainConfig,
 		chainRules:      chainConfig.Rules(ctx.BlockNumber),
 		interpreters:    make([]Interpreter, 0, 1),
 	}
+	evm.precompiles = precompiledContracts(chainConfig, evm.chainRules, ctx.BlockNumber)
 
 	if chainConfig.IsEWASM(ctx.BlockNumber) {
 		// to be implemented by EVM-C and Wagon PRs.
@@ -169,8 +171,7 @@ func NewEVM(ctx Context, state IntraBlockState, chainConfig *params.ChainConfig,
 	}
 
 	if vmConfig.EVMInterpreter != "" {
-		InitEVMCEVM(vmConfig.EVMInterpreter)
-		evm.interpreters = append(evm.interpreters, &EVMC{evmModule, evm, evmc.CapabilityEVM1, false})
+		evm.interpreters = append(evm.interpreters, &EVMC{InitEVMCEVM(vmConfig.EVMInterpreter), evm, evmc.CapabilityEVM1, false})
 	} else {
 		evm.interpreters = append(evm.interpreters, NewEVMInterpreter(evm, vmConfig))
 	}
@@ -432,6 +433,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	}
 	nonce := evm.IntraBlockState.GetNonce(caller.Address())
 	evm.IntraBlockState.SetNonce(caller.Address(), nonce+1)
+	// We add this to the access list _before_ taking a snapshot. Even if the creation fails,
+	// the access-list change should not be rolled back
+	if evm.chainRules.IsBerlin {
+		evm.IntraBlockState.AddAddressToAccessList(address)
+	}
 
 	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.IntraBlockState.GetCodeHash(address)
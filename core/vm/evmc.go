@@ -45,20 +45,24 @@ type EVMC struct {
 }
 
 var (
-	evmModule   *evmc.VM
+	evmModules  = make(map[string]*evmc.VM)
 	ewasmModule *evmc.VM
 	evmcMux     sync.Mutex
 )
 
-func InitEVMCEVM(config string) {
+// InitEVMCEVM returns the EVMC module of the given config, loading it on the first use. The modules are
+// kept per config, so that different ones (e.g. --vm.evm and --vm.evm.diff) can be used side by side
+func InitEVMCEVM(config string) *evmc.VM {
 	evmcMux.Lock()
 	defer evmcMux.Unlock()
-	if evmModule != nil {
-		return
+	if module, ok := evmModules[config]; ok {
+		return module
 	}
 
-	evmModule = initEVMC(evmc.CapabilityEVM1, config)
+	module := initEVMC(evmc.CapabilityEVM1, config)
+	evmModules[config] = module
 	log.Info("initialized EVMC interpreter", "path", config)
+	return module
 }
 
 func InitEVMCEwasm(config string) {
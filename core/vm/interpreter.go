@@ -38,6 +38,11 @@ type Config struct {
 	EWASMInterpreter string // External EWASM interpreter options
 	EVMInterpreter   string // External EVM interpreter options
 
+	DiffEVMInterpreter string // External EVM interpreter options to re-execute the transactions with and compare against the built-in interpreter
+	DiffStop           bool   // Fail on the first divergence of the DiffEVMInterpreter instead of logging it
+
+	ExecutionWorkers int // Goroutines executing the transactions of a block speculatively, 0 or 1 to execute them in order
+
 	ExtraEips []int // Additional EIPS that are to be enabled
 }
 
@@ -100,6 +105,8 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	switch {
 	case evm.chainRules.IsYoloV1:
 		jt = &yoloV1InstructionSet
+	case evm.chainRules.IsBerlin:
+		jt = &berlinInstructionSet
 	case evm.chainRules.IsIstanbul:
 		jt = &istanbulInstructionSet
 	case evm.chainRules.IsConstantinople:
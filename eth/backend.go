@@ -18,6 +18,7 @@
 package eth
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -47,7 +48,9 @@ import (
 	"github.com/ledgerwatch/turbo-geth/eth/downloader"
 	"github.com/ledgerwatch/turbo-geth/eth/filters"
 	"github.com/ledgerwatch/turbo-geth/eth/gasprice"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/ethdb/remote/remotedbserver"
 	"github.com/ledgerwatch/turbo-geth/event"
 	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
@@ -61,6 +64,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/statediff"
 )
 
 // Ethereum implements the Ethereum full node service.
@@ -87,9 +91,10 @@ type Ethereum struct {
 
 	APIBackend *EthAPIBackend
 
-	miner     *miner.Miner
-	gasPrice  *big.Int
-	etherbase common.Address
+	miner      *miner.Miner
+	gasPrice   *big.Int
+	etherbase  common.Address
+	quitMining chan struct{} // Closed to stop the staged sync miner, nil when it is not running
 
 	networkID     uint64
 	netRPCService *ethapi.PublicNetAPI
@@ -97,6 +102,13 @@ type Ethereum struct {
 	p2pServer     *p2p.Server
 	txPoolStarted bool
 
+	stateDiffs   *statediff.Publisher // nil if the state diffs of the Execution stage aren't published
+	stateDiffLog *statediff.FileLog
+
+	sentryControl  *ControlServerImpl // nil if the node connects to the peers itself
+	stopSentrySync context.CancelFunc
+	sentryWg       sync.WaitGroup
+
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
 
@@ -229,7 +241,35 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, chainDb, txCacher)
 
+	var stateDiffServer *remotedbserver.StateDiffServer
+	if config.StateDiffFile != "" || config.StateDiffStream {
+		var stateDiffLog statediff.Log
+		if config.StateDiffFile != "" {
+			if eth.stateDiffLog, err = statediff.OpenFileLog(stack.ResolvePath(config.StateDiffFile)); err != nil {
+				return nil, err
+			}
+			stateDiffLog = eth.stateDiffLog
+		}
+		var sinks []statediff.Sink
+		if config.StateDiffStream {
+			if stack.Config().PrivateApiAddr == "" {
+				log.Warn("State diffs are not streamed without the private API address")
+			}
+			stateDiffServer = remotedbserver.NewStateDiffServer(stateDiffLog)
+			sinks = append(sinks, stateDiffServer)
+		}
+		if eth.stateDiffs, err = statediff.NewPublisher(stateDiffLog, sinks...); err != nil {
+			return nil, err
+		}
+	}
+
 	if stack.Config().PrivateApiAddr != "" {
+		var acl *remotedbserver.ACL
+		if stack.Config().PrivateApiACL != "" {
+			if acl, err = remotedbserver.LoadACL(stack.Config().PrivateApiACL); err != nil {
+				return nil, err
+			}
+		}
 		if stack.Config().TLSConnection {
 			// load peer cert/key, ca cert
 			var creds credentials.TransportCredentials
@@ -261,9 +301,9 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			if err != nil {
 				return nil, err
 			}
-			remotedbserver.StartGrpc(chainDb.KV(), eth, stack.Config().PrivateApiAddr, &creds)
+			remotedbserver.StartGrpc(chainDb.KV(), eth, stateDiffServer, stack.Config().PrivateApiAddr, &creds, acl)
 		} else {
-			remotedbserver.StartGrpc(chainDb.KV(), eth, stack.Config().PrivateApiAddr, nil)
+			remotedbserver.StartGrpc(chainDb.KV(), eth, stateDiffServer, stack.Config().PrivateApiAddr, nil, acl)
 		}
 	}
 
@@ -274,6 +314,22 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	if eth.protocolManager, err = NewProtocolManager(chainConfig, checkpoint, config.SyncMode, config.NetworkID, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb, config.Whitelist, config.StagedSync); err != nil {
 		return nil, err
 	}
+	eth.protocolManager.stagedSync.StateDiffs = eth.stateDiffs
+	if config.ExecutionCache > 0 {
+		eth.protocolManager.stagedSync.StateCache = stagedsync.NewStateCache(config.ExecutionCache)
+	}
+	if len(config.SentryAddrs) > 0 {
+		sentries := make([]remote.SENTRYClient, len(config.SentryAddrs))
+		for i, addr := range config.SentryAddrs {
+			if sentries[i], err = GrpcSentryClient(context.Background(), addr); err != nil {
+				return nil, err
+			}
+		}
+		status := SentryStatus(chainDb, config.NetworkID, chainConfig, genesisHash, rawdb.ReadTd(chainDb, genesisHash, 0))
+		eth.sentryControl = NewControlServer(stack.ResolvePath("headers"), sentries, status, chainConfig, eth.engine, chainDb)
+		eth.protocolManager.stagedSync.Sentry = eth.sentryControl.Download()
+	}
+	stack.RegisterHandler("Sync status", "/sync", node.NewHTTPHandlerStack(&syncStatusHandler{eth}, stack.Config().HTTPCors, stack.Config().HTTPVirtualHosts))
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.protocolManager.SetDataDir(stack.Config().DataDir)
 	eth.protocolManager.SetHdd(config.Hdd)
@@ -330,6 +386,9 @@ func BlockchainRuntimeConfig(config *Config) (vm.Config, *core.CacheConfig) {
 			EnablePreimageRecording: config.EnablePreimageRecording,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
+			DiffEVMInterpreter:      config.DiffEVMInterpreter,
+			DiffStop:                config.DiffStop,
+			ExecutionWorkers:        config.ExecutionWorkers,
 		}
 		cacheConfig = &core.CacheConfig{
 			Pruning:             config.Pruning,
@@ -594,6 +653,9 @@ func (s *Ethereum) StartMining(threads int) error {
 		// introduced to speed sync times.
 		atomic.StoreUint32(&s.protocolManager.acceptTxs, 1)
 
+		if s.config.SyncMode == downloader.StagedSync {
+			return s.startStagedMining(eb)
+		}
 		go s.miner.Start(eb)
 	}
 	return nil
@@ -611,9 +673,18 @@ func (s *Ethereum) StopMining() {
 	}
 	// Stop the block creating itself
 	s.miner.Stop()
+	s.stopStagedMining()
+}
+
+func (s *Ethereum) IsMining() bool {
+	if s.config.SyncMode == downloader.StagedSync {
+		s.lock.RLock()
+		defer s.lock.RUnlock()
+		return s.quitMining != nil
+	}
+	return s.miner.Mining()
 }
 
-func (s *Ethereum) IsMining() bool      { return s.miner.Mining() }
 func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 
 func (s *Ethereum) AccountManager() *accounts.Manager  { return s.accountManager }
@@ -633,10 +704,17 @@ func (s *Ethereum) SyncProgress() ethereum.SyncProgress {
 func (s *Ethereum) Synced() bool                     { return atomic.LoadUint32(&s.protocolManager.acceptTxs) == 1 }
 func (s *Ethereum) ArchiveMode() bool                { return !s.config.Pruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer { return s.bloomIndexer }
+func (s *Ethereum) SyncStatus() (*ethdb.SyncStatus, error) {
+	return s.protocolManager.stagedSync.Status.Report(), nil
+}
 
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
+	if s.sentryControl != nil {
+		// the eth protocol runs in the sentries
+		return nil
+	}
 	protos := make([]p2p.Protocol, len(ProtocolVersions))
 	for i, vsn := range ProtocolVersions {
 		protos[i] = s.protocolManager.makeProtocol(vsn)
@@ -662,6 +740,10 @@ func (s *Ethereum) Start() error {
 		s.startBloomHandlers(params.BloomBitsBlocks)
 	}
 
+	if s.sentryControl != nil {
+		s.startSentrySync()
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	withTxPool := s.config.SyncMode != downloader.StagedSync
@@ -669,6 +751,39 @@ func (s *Ethereum) Start() error {
 	return s.protocolManager.Start(maxPeers, withTxPool)
 }
 
+// startSentrySync runs the sync cycles on the headers and the bodies downloaded through the sentries, instead of
+// the sync with the peers of the node by eth/downloader
+func (s *Ethereum) startSentrySync() {
+	var ctx context.Context
+	ctx, s.stopSentrySync = context.WithCancel(context.Background())
+	s.sentryControl.Start(ctx)
+	s.sentryWg.Add(1)
+	go func() {
+		defer s.sentryWg.Done()
+		pm := s.protocolManager
+		s.sentryControl.StagesLoop(ctx, s.chainDb, func() (*stagedsync.State, error) {
+			return pm.stagedSync.Prepare(
+				pm.downloader,
+				s.blockchain.Config(),
+				s.blockchain,
+				s.blockchain.GetVMConfig(),
+				s.chainDb,
+				s.chainDb,
+				"",
+				s.config.StorageMode,
+				pm.datadir,
+				s.config.Hdd,
+				ctx.Done(),
+				nil,
+				s.txPool,
+				pm.StartTxPool,
+				nil,
+				nil,
+			)
+		})
+	}()
+}
+
 func (s *Ethereum) StartTxPool() error {
 	if s.txPoolStarted {
 		return errors.New("transaction pool is already started")
@@ -703,6 +818,10 @@ func (s *Ethereum) StopTxPool() error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	// Stop all the peer-related stuff first.
+	if s.stopSentrySync != nil {
+		s.stopSentrySync()
+		s.sentryWg.Wait()
+	}
 	s.protocolManager.Stop()
 
 	// Then stop everything else.
@@ -712,12 +831,18 @@ func (s *Ethereum) Stop() error {
 		log.Warn("error while stopping transaction pool", "err", err)
 	}
 	s.miner.Stop()
+	s.stopStagedMining()
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.eventMux.Stop()
 	if s.txPool != nil {
 		s.txPool.Stop()
 	}
+	if s.stateDiffLog != nil {
+		if err := s.stateDiffLog.Close(); err != nil {
+			log.Warn("error while closing state diff log", "err", err)
+		}
+	}
 	//s.chainDb.Close()
 	return nil
 }
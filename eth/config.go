@@ -66,6 +66,7 @@ var DefaultConfig = Config{
 	TrieCleanCacheRejournal: 60 * time.Minute,
 	TrieDirtyCache:          256,
 	TrieTimeout:             60 * time.Minute,
+	ExecutionCache:          256,
 	StorageMode:             ethdb.DefaultStorageMode,
 	Miner: miner.Config{
 		GasFloor: 8000000,
@@ -170,6 +171,21 @@ type Config struct {
 	// Transaction pool options
 	TxPool core.TxPoolConfig
 
+	// File to append the state diffs of the Execution stage to ("" for none)
+	StateDiffFile string
+
+	// Stream the state diffs of the Execution stage over the private API
+	StateDiffStream bool
+
+	// Megabytes of memory for the state cache of the Execution stage (0 to disable)
+	ExecutionCache int
+
+	// Goroutines executing the transactions of a block speculatively (0 or 1 to execute them in order)
+	ExecutionWorkers int
+
+	// Addresses of the sentries to download the headers and the bodies through, instead of running p2p in the node
+	SentryAddrs []string `toml:",omitempty"`
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
@@ -188,6 +204,13 @@ type Config struct {
 	// Type of the EVM interpreter ("" for default)
 	EVMInterpreter string
 
+	// Type of the EVM interpreter to re-execute the transactions with during the
+	// Execution stage, comparing the results with the built-in interpreter ("" for none)
+	DiffEVMInterpreter string
+
+	// Stop the Execution stage on the first divergence of DiffEVMInterpreter
+	DiffStop bool
+
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap uint64 `toml:",omitempty"`
 
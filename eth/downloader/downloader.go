@@ -572,6 +572,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, blockNumb
 
 	// Turbo-Geth's staged sync goes here
 	if mode == StagedSync {
+		d.stagedSync.Status.SetHighestBlock(height)
 		hashStateStageProgress, _, err := stages.GetStageProgress(d.stateDB, stages.HashState) // because later stages can be disabled
 		if err != nil {
 			return err
@@ -609,6 +610,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, blockNumb
 			txPool,
 			poolStart,
 			nil,
+			nil,
 		)
 		if err != nil {
 			return err
@@ -1663,6 +1665,11 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, blockNumber uin
 						var reorg bool
 						var forkBlockNumber uint64
 						reorg, forkBlockNumber, err = stagedsync.InsertHeaderChain(d.stateDB, chunk, d.chainConfig, d.blockchain.Engine(), frequency)
+						if err != nil {
+							// The headers of a chunk are written only if they all pass the checks of the engine
+							log.Debug("Invalid header encountered", "from", chunk[0].Number, "to", chunk[len(chunk)-1].Number, "err", err)
+							return fmt.Errorf("%w: %v", errInvalidChain, err)
+						}
 						if reorg && d.headersUnwinder != nil {
 							// Need to unwind further stages
 							if err1 := d.headersUnwinder.UnwindTo(forkBlockNumber, d.stateDB); err1 != nil {
@@ -7,8 +7,10 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 )
@@ -193,3 +195,110 @@ func (d *Downloader) SpawnHeaderDownloadStage(
 	defer d.Cancel() // No matter what, we can't leave the cancel channel open
 	return d.spawnSync(fetchers)
 }
+
+// SpawnReceiptDownloadStage downloads the receipts for the blocks which bodies are already in the database,
+// instead of producing them by executing the blocks. The receipts are verified against the ReceiptHash of
+// the headers when delivered (see queue.DeliverReceipts)
+func (d *Downloader) SpawnReceiptDownloadStage(
+	id string,
+	s *stagedsync.StageState,
+	u stagedsync.Unwinder,
+) (bool, error) {
+	bodyProgress, _, err := stages.GetStageProgress(d.stateDB, stages.Bodies)
+	if err != nil {
+		return false, err
+	}
+	origin := s.BlockNumber
+	if origin >= bodyProgress {
+		// No more receipts to download
+		return false, nil
+	}
+	// Create cancel channel for aborting mid-flight and mark the master peer
+	d.cancelLock.Lock()
+	d.cancelCh = make(chan struct{})
+	d.cancelPeer = id
+	d.cancelLock.Unlock()
+
+	defer d.Cancel() // No matter what, we can't leave the cancel channel open
+	const N = 65536
+	from := origin + 1
+	to := bodyProgress
+	if to-origin > N {
+		to = origin + N
+	}
+	hashes := make([]common.Hash, 0, to-origin)
+	headers := make(map[common.Hash]*types.Header, to-origin)
+	for number := from; number <= to; number++ {
+		if err = common.Stopped(d.quitCh); err != nil {
+			return false, err
+		}
+		hash := rawdb.ReadCanonicalHash(d.stateDB, number)
+		header := rawdb.ReadHeader(d.stateDB, hash, number)
+		if header == nil {
+			if err1 := u.UnwindTo(number-1, d.stateDB); err1 != nil {
+				return false, fmt.Errorf("resetting SyncStage Receipts to missing header: %w", err1)
+			}
+			// This will cause the sync return to the header stage
+			return false, nil
+		}
+		hashes = append(hashes, hash)
+		headers[hash] = header
+	}
+
+	log.Info("Downloading receipts", "from", from, "to", to)
+	d.queue.Reset(blockCacheMaxItems, blockCacheInitialItems)
+	d.queue.Prepare(from, FastSync) // FastSync mode makes results wait for the receipts
+	d.queue.ScheduleReceipts(from, hashes, headers)
+
+	select {
+	case d.receiptWakeCh <- true:
+	case <-d.cancelCh:
+	case <-d.quitCh:
+		return false, errCanceled
+	}
+
+	fetchers := []func() error{
+		func() error { return d.fetchReceipts(from) },
+		func() error { return d.processReceiptsStage(s, to) },
+	}
+
+	if err := d.spawnSync(fetchers); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// processReceiptsStage takes fetch results from the queue and writes the receipts into the database
+func (d *Downloader) processReceiptsStage(s *stagedsync.StageState, to uint64) error {
+	for {
+		if err := common.Stopped(d.quitCh); err != nil {
+			return err
+		}
+
+		results := d.queue.Results(true)
+		if len(results) == 0 {
+			return nil
+		}
+		batch := d.stateDB.NewBatch()
+		for _, result := range results {
+			rawdb.WriteReceipts(batch, result.Header.Hash(), result.Header.Number.Uint64(), result.Receipts)
+		}
+		lastNumber := results[len(results)-1].Header.Number.Uint64()
+		if err := s.Update(batch, lastNumber); err != nil {
+			batch.Rollback()
+			return fmt.Errorf("saving SyncStage Receipts progress: %w", err)
+		}
+		if _, err := batch.Commit(); err != nil {
+			return fmt.Errorf("committing receipts: %w", err)
+		}
+		if lastNumber == to {
+			select {
+			case d.receiptWakeCh <- false:
+			case <-d.quitCh:
+			case <-d.cancelCh:
+			}
+			return nil
+		}
+	}
+}
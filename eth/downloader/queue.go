@@ -130,6 +130,7 @@ type queue struct {
 	receiptTaskPool  map[common.Hash]*types.Header // [eth/63] Pending receipt retrieval tasks, mapping hashes to headers
 	receiptTaskQueue *prque.Prque                  // [eth/63] Priority queue of the headers to fetch the receipts for
 	receiptPendPool  map[string]*fetchRequest      // [eth/63] Currently pending receipt retrieval operations
+	receiptsOnly     bool                          // Bodies are already in the database, only receipts are retrieved (staged sync)
 
 	resultCache *resultStore       // Downloaded but not yet delivered fetch results
 	resultSize  common.StorageSize // Approximate size of a block (exponential moving average)
@@ -173,6 +174,7 @@ func (q *queue) Reset(blockCacheLimit int, thresholdInitialSize int) {
 	q.receiptTaskPool = make(map[common.Hash]*types.Header)
 	q.receiptTaskQueue.Reset()
 	q.receiptPendPool = make(map[string]*fetchRequest)
+	q.receiptsOnly = false
 
 	q.resultCache = newResultStore(blockCacheLimit)
 	q.resultCache.SetThrottleThreshold(uint64(thresholdInitialSize))
@@ -311,6 +313,29 @@ func (q *queue) ScheduleBodies(from uint64, hashes []common.Hash, headers map[co
 	}
 }
 
+// ScheduleReceipts adds the headers of the blocks, which bodies are already in the database, for receipt
+// retrieval. The queue needs to be prepared in FastSync mode, so that the results wait for the receipts
+func (q *queue) ScheduleReceipts(from uint64, hashes []common.Hash, headers map[common.Hash]*types.Header) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.receiptsOnly = true
+	for _, hash := range hashes {
+		header := headers[hash]
+		// Make sure no duplicate requests are executed
+		if _, ok := q.receiptTaskPool[hash]; ok {
+			log.Warn("Header already scheduled for receipt fetch", "number", header.Number, "hash", hash)
+			continue
+		}
+		// Queue the header for receipt retrieval, including the blocks with empty receipts,
+		// so that the results are delivered without gaps
+		q.receiptTaskPool[hash] = header
+		q.receiptTaskQueue.Push(header, -int64(from))
+
+		q.headerHead = hash
+		from++
+	}
+}
+
 // Schedule adds a set of headers for the download queue for scheduling, returning
 // the new headers encountered.
 func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
@@ -550,6 +575,10 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 			// There are no resultslots available. Leave it in the task queue
 			break
 		}
+		if q.receiptsOnly {
+			// Bodies are not going to be delivered
+			item.SetBodyDone()
+		}
 		if item.Done(kind) {
 			// If it's a noop, we can skip this task
 			delete(taskPool, header.Hash())
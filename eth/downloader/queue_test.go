@@ -430,3 +430,50 @@ func (n *network) headers(from int) []*types.Header {
 	}
 	return hdrs
 }
+
+func TestScheduleReceipts(t *testing.T) {
+	blocks, receipts := makeChain(16, 0, genesis, false)
+	q := newQueue(100, 100)
+	q.Prepare(1, FastSync)
+	hashes := make([]common.Hash, len(blocks))
+	headers := make(map[common.Hash]*types.Header, len(blocks))
+	for i, b := range blocks {
+		hashes[i] = b.Hash()
+		headers[b.Hash()] = b.Header()
+	}
+	q.ScheduleReceipts(1, hashes, headers)
+	if got, exp := q.PendingBlocks(), 0; got != exp {
+		t.Errorf("wrong pending block count, got %d, exp %d", got, exp)
+	}
+	// All the blocks are scheduled, including the ones with empty receipts
+	if got, exp := q.PendingReceipts(), len(blocks); got != exp {
+		t.Errorf("wrong pending receipt count, got %d, exp %d", got, exp)
+	}
+	peer := dummyPeer("peer-1")
+	fetchReq, _, _ := q.ReserveReceipts(peer, 100)
+	// Blocks with empty receipts are not requested
+	if got, exp := len(fetchReq.Headers), len(blocks)/2; got != exp {
+		t.Fatalf("expected %d requests, got %d", exp, got)
+	}
+	receiptMap := make(map[common.Hash]types.Receipts, len(blocks))
+	for i, b := range blocks {
+		receiptMap[b.Hash()] = receipts[i]
+	}
+	receiptList := make([][]*types.Receipt, len(fetchReq.Headers))
+	for i, header := range fetchReq.Headers {
+		receiptList[i] = receiptMap[header.Hash()]
+	}
+	if _, err := q.DeliverReceipts(peer.id, receiptList); err != nil {
+		t.Fatalf("delivering receipts: %v", err)
+	}
+	// Results are complete without the bodies
+	results := q.Results(false)
+	if got, exp := len(results), len(blocks); got != exp {
+		t.Fatalf("expected %d results, got %d", exp, got)
+	}
+	for i, result := range results {
+		if got, exp := len(result.Receipts), len(receipts[i]); got != exp {
+			t.Errorf("block %d: expected %d receipts, got %d", i+1, exp, got)
+		}
+	}
+}
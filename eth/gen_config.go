@@ -46,11 +46,18 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Miner                   miner.Config
 		Ethash                  ethash.Config
 		TxPool                  core.TxPoolConfig
+		StateDiffFile           string
+		StateDiffStream         bool
+		ExecutionCache          int
+		ExecutionWorkers        int
+		SentryAddrs             []string `toml:",omitempty"`
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
 		EWASMInterpreter        string
 		EVMInterpreter          string
+		DiffEVMInterpreter      string
+		DiffStop                bool
 		RPCGasCap               uint64                         `toml:",omitempty"`
 		RPCTxFeeCap             float64                        `toml:",omitempty"`
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
@@ -84,11 +91,18 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
+	enc.StateDiffFile = c.StateDiffFile
+	enc.StateDiffStream = c.StateDiffStream
+	enc.ExecutionCache = c.ExecutionCache
+	enc.ExecutionWorkers = c.ExecutionWorkers
+	enc.SentryAddrs = c.SentryAddrs
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
 	enc.EVMInterpreter = c.EVMInterpreter
+	enc.DiffEVMInterpreter = c.DiffEVMInterpreter
+	enc.DiffStop = c.DiffStop
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
@@ -127,11 +141,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Miner                   *miner.Config
 		Ethash                  *ethash.Config
 		TxPool                  *core.TxPoolConfig
+		StateDiffFile           *string
+		StateDiffStream         *bool
+		ExecutionCache          *int
+		ExecutionWorkers        *int
+		SentryAddrs             []string `toml:",omitempty"`
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
 		EWASMInterpreter        *string
 		EVMInterpreter          *string
+		DiffEVMInterpreter      *string
+		DiffStop                *bool
 		RPCGasCap               *uint64                        `toml:",omitempty"`
 		RPCTxFeeCap             *float64                       `toml:",omitempty"`
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
@@ -226,6 +247,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
+	if dec.StateDiffFile != nil {
+		c.StateDiffFile = *dec.StateDiffFile
+	}
+	if dec.StateDiffStream != nil {
+		c.StateDiffStream = *dec.StateDiffStream
+	}
+	if dec.ExecutionCache != nil {
+		c.ExecutionCache = *dec.ExecutionCache
+	}
+	if dec.ExecutionWorkers != nil {
+		c.ExecutionWorkers = *dec.ExecutionWorkers
+	}
+	if dec.SentryAddrs != nil {
+		c.SentryAddrs = dec.SentryAddrs
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -241,6 +277,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EVMInterpreter != nil {
 		c.EVMInterpreter = *dec.EVMInterpreter
 	}
+	if dec.DiffEVMInterpreter != nil {
+		c.DiffEVMInterpreter = *dec.DiffEVMInterpreter
+	}
+	if dec.DiffStop != nil {
+		c.DiffStop = *dec.DiffStop
+	}
 	if dec.RPCGasCap != nil {
 		c.RPCGasCap = *dec.RPCGasCap
 	}
@@ -0,0 +1,445 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/forkid"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/bodydownload"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
+)
+
+// ControlServerImpl is the core side of the sentry protocol. It consumes inbound messages coming from all
+// the connected sentries, feeds them into the header and body download algorithms, and sends out requests
+// and penalties through the sentries
+type ControlServerImpl struct {
+	engine        consensus.Engine
+	hd            *headerdownload.HeaderDownload
+	bd            *bodydownload.BodyDownload
+	sentries      []remote.SENTRYClient
+	requestWakeUp chan struct{}
+	bodyWakeUp    chan struct{}
+	statusLock    sync.RWMutex
+	statusData    *remote.StatusData
+}
+
+// NewControlServer - the engine verifies the downloaded headers against the chain in chainDb, like the clique
+// signers of the snapshot at the parent
+func NewControlServer(filesDir string, sentries []remote.SENTRYClient, statusData *remote.StatusData, chainConfig *params.ChainConfig, engine consensus.Engine, chainDb ethdb.Database) *ControlServerImpl {
+	cr := stagedsync.NewChainReader(chainConfig, chainDb)
+	calcDiffFunc := func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int {
+		return engine.CalcDifficulty(cr, childTimestamp, parentTime, parentDifficulty, parentNumber, parentHash, parentUncleHash)
+	}
+	verifySealFunc := func(header *types.Header) error {
+		return engine.VerifySeal(cr, header)
+	}
+	hd := headerdownload.NewHeaderDownload(
+		filesDir,
+		16*1024, /* tipLimit */
+		1024,    /* initPowDepth */
+		calcDiffFunc,
+		verifySealFunc,
+		3600, /* newAnchor future limit */
+		3600, /* newAnchor past limit */
+	)
+	// Insert hard-coded headers if present
+	if _, err := os.Stat("hard-coded-headers.dat"); err == nil {
+		if f, err1 := os.Open("hard-coded-headers.dat"); err1 == nil {
+			defer f.Close()
+			var hBuffer [headerdownload.HeaderSerLength]byte
+			var dBuffer [32]byte
+			i := 0
+			for {
+				var h types.Header
+				var d uint256.Int
+				if _, err2 := io.ReadFull(f, hBuffer[:]); err2 == nil {
+					headerdownload.DeserialiseHeader(&h, hBuffer[:])
+				} else if errors.Is(err2, io.EOF) {
+					break
+				} else {
+					log.Error("Failed to read hard coded header", "i", i, "error", err2)
+					break
+				}
+				if _, err2 := io.ReadFull(f, dBuffer[:]); err2 == nil {
+					d.SetBytes(dBuffer[:])
+				} else {
+					log.Error("Failed to read hard coded difficulty", "i", i, "error", err2)
+					break
+				}
+				if err2 := hd.HardCodedHeader(&h, d, uint64(time.Now().Unix())); err2 != nil {
+					log.Error("Failed to insert hard coded header", "i", i, "block", h.Number.Uint64(), "error", err2)
+				}
+				i++
+			}
+		}
+	}
+	// Restore the working trees persisted before the restart
+	if err := hd.RecoverFromFiles(uint64(time.Now().Unix())); err != nil {
+		log.Error("Recovery from files failed", "error", err)
+	}
+	return &ControlServerImpl{
+		engine:        engine,
+		hd:            hd,
+		bd:            bodydownload.NewBodyDownload(1024 /* outstandingLimit */),
+		sentries:      sentries,
+		requestWakeUp: make(chan struct{}, 1),
+		bodyWakeUp:    make(chan struct{}, 1),
+		statusData:    statusData,
+	}
+}
+
+// Download returns the header and body download of Headers and Bodies stages, see stagedsync.StagedSync.Sentry
+func (cs *ControlServerImpl) Download() *stagedsync.SentryDownload {
+	return &stagedsync.SentryDownload{
+		Headers:        cs.hd,
+		RequestHeaders: cs.sendHeaderRequest,
+		HeadersWakeUp:  cs.requestWakeUp,
+		Bodies:         cs.bd,
+		RequestBodies:  cs.sendBodyRequest,
+		BodiesWakeUp:   cs.bodyWakeUp,
+		Engine:         cs.engine,
+	}
+}
+
+// Start consumes the inbound messages of all the sentries until the context is cancelled
+func (cs *ControlServerImpl) Start(ctx context.Context) {
+	for _, sentry := range cs.sentries {
+		go cs.sentryLoop(ctx, sentry)
+	}
+}
+
+func wakeUp(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (cs *ControlServerImpl) newBlockHashes(ctx context.Context, inreq *remote.InboundMessage, _ remote.SENTRYClient) error {
+	var request NewBlockHashesData
+	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
+		return fmt.Errorf("decode NewBlockHashes: %v", err)
+	}
+	for _, announce := range request {
+		if !cs.hd.HasTip(announce.Hash) {
+			log.Info(fmt.Sprintf("Sending header request {hash: %x, height: %d, length: %d}", announce.Hash, announce.Number, 1))
+			cs.sendHeaderRequest(ctx, &headerdownload.HeaderRequest{
+				Hash:   announce.Hash,
+				Number: announce.Number,
+				Length: 1,
+			})
+		}
+	}
+	return nil
+}
+
+func (cs *ControlServerImpl) newBlock(ctx context.Context, inreq *remote.InboundMessage, sentry remote.SENTRYClient) error {
+	var request NewBlockData
+	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
+		return fmt.Errorf("decode NewBlockMsg: %v", err)
+	}
+	segments, penalty, err := cs.hd.HandleNewBlockMsg(request.Block.Header())
+	if err == nil && penalty == headerdownload.NoPenalty {
+		cs.hd.ProcessSegment(segments[0], uint64(time.Now().Unix())) // There is only one segment in this case
+	}
+	if err != nil {
+		return fmt.Errorf("HandleNewBlockMsg: %v", err)
+	}
+	if penalty != headerdownload.NoPenalty {
+		// Send penalty back to the sentry
+		penalize(ctx, sentry, inreq.PeerId, penalty)
+		return nil
+	}
+	log.Info(fmt.Sprintf("NewBlockMsg{blockNumber: %d}", request.Block.NumberU64()))
+	wakeUp(cs.requestWakeUp)
+	return nil
+}
+
+func (cs *ControlServerImpl) blockHeaders(ctx context.Context, inreq *remote.InboundMessage, sentry remote.SENTRYClient) error {
+	var headers []*types.Header
+	if err := rlp.DecodeBytes(inreq.Data, &headers); err != nil {
+		return fmt.Errorf("decode BlockHeadersMsg: %v", err)
+	}
+	segments, penalty, err := cs.hd.HandleHeadersMsg(headers)
+	if err == nil && penalty == headerdownload.NoPenalty {
+		currentTime := uint64(time.Now().Unix())
+		for _, segment := range segments {
+			cs.hd.ProcessSegment(segment, currentTime)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("HandleHeadersMsg: %v", err)
+	}
+	if penalty != headerdownload.NoPenalty {
+		penalize(ctx, sentry, inreq.PeerId, penalty)
+		return nil
+	}
+	log.Debug("HeadersMsg processed")
+	wakeUp(cs.requestWakeUp)
+	return nil
+}
+
+func (cs *ControlServerImpl) blockBodies(inreq *remote.InboundMessage) error {
+	var request []*types.Body
+	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
+		return fmt.Errorf("decode BlockBodiesMsg: %v", err)
+	}
+	var delivered int
+	for _, body := range request {
+		if cs.bd.DeliverBody(body) {
+			delivered++
+		}
+	}
+	log.Debug(fmt.Sprintf("[%s] BlockBodiesMsg", inreq.PeerId), "bodies", len(request), "delivered", delivered)
+	if delivered > 0 {
+		wakeUp(cs.bodyWakeUp)
+	}
+	return nil
+}
+
+func (cs *ControlServerImpl) handleInboundMessage(ctx context.Context, inreq *remote.InboundMessage, sentry remote.SENTRYClient) error {
+	switch inreq.Id {
+	case remote.MessageId_NewBlockHashes:
+		return cs.newBlockHashes(ctx, inreq, sentry)
+	case remote.MessageId_NewBlock:
+		return cs.newBlock(ctx, inreq, sentry)
+	case remote.MessageId_BlockHeaders:
+		return cs.blockHeaders(ctx, inreq, sentry)
+	case remote.MessageId_BlockBodies:
+		return cs.blockBodies(inreq)
+	default:
+		return fmt.Errorf("not implemented for message Id: %s", inreq.Id)
+	}
+}
+
+func penalize(ctx context.Context, sentry remote.SENTRYClient, peerID string, penalty headerdownload.Penalty) {
+	log.Warn(fmt.Sprintf("Penalizing peer %s: %s", peerID, penalty))
+	if _, err := sentry.PenalizePeer(ctx, &remote.PenalizePeerRequest{PeerId: peerID, Penalty: remote.PenaltyKind_Kick}); err != nil {
+		log.Error("Could not send penalty", "peer", peerID, "err", err)
+	}
+}
+
+// sendByMinBlock tries the sentries one by one, until one of them finds a suitable peer to send the message to
+func (cs *ControlServerImpl) sendByMinBlock(ctx context.Context, minBlock uint64, outreq *remote.OutboundMessageData) bool {
+	for _, sentry := range cs.sentries {
+		sentPeers, err := sentry.SendMessageByMinBlock(ctx, &remote.SendMessageByMinBlockRequest{Data: outreq, MinBlock: minBlock})
+		if err != nil {
+			log.Debug("Could not send message via sentry", "id", outreq.Id, "err", err)
+			continue
+		}
+		if len(sentPeers.Peers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *ControlServerImpl) sendHeaderRequest(ctx context.Context, req *headerdownload.HeaderRequest) bool {
+	bytes, err := rlp.EncodeToBytes(&GetBlockHeadersData{
+		Amount:  uint64(req.Length),
+		Reverse: true,
+		Skip:    0,
+		Origin:  HashOrNumber{Hash: req.Hash},
+	})
+	if err != nil {
+		log.Error("Could not encode header request", "err", err)
+		return false
+	}
+	return cs.sendByMinBlock(ctx, req.Number, &remote.OutboundMessageData{Id: remote.MessageId_GetBlockHeaders, Data: bytes})
+}
+
+func (cs *ControlServerImpl) sendBodyRequest(ctx context.Context, req *bodydownload.BodyRequest) bool {
+	bytes, err := rlp.EncodeToBytes(req.Hashes)
+	if err != nil {
+		log.Error("Could not encode block bodies request", "err", err)
+		return false
+	}
+	return cs.sendByMinBlock(ctx, req.BlockNums[len(req.BlockNums)-1], &remote.OutboundMessageData{Id: remote.MessageId_GetBlockBodies, Data: bytes})
+}
+
+// HeaderRequestLoop periodically produces header requests from the request queue of the header download.
+// It is only used when there is no database to insert the headers into, otherwise the requests are
+// produced by the Headers stage
+func (cs *ControlServerImpl) HeaderRequestLoop(ctx context.Context) {
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for {
+		for _, req := range cs.hd.RequestMoreHeaders(uint64(time.Now().Unix()), 5 /*timeout */) {
+			cs.sendHeaderRequest(ctx, req)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-logEvery.C:
+			log.Info(cs.hd.AnchorState())
+		case <-time.After(time.Second):
+		case <-cs.requestWakeUp:
+		}
+	}
+}
+
+// StagesLoop runs the sync cycles prepared by prepare until the context is cancelled. After every cycle, the sentries
+// get the new head of the database for the handshakes with the peers
+func (cs *ControlServerImpl) StagesLoop(ctx context.Context, db ethdb.Database, prepare func() (*stagedsync.State, error)) {
+	for {
+		state, err := prepare()
+		if err != nil {
+			log.Error("Preparing staged sync failed", "err", err)
+			return
+		}
+		if err = state.Run(db, db); err != nil {
+			if errors.Is(err, common.ErrStopped) {
+				return
+			}
+			log.Error("Staged sync failed", "err", err)
+		}
+		cs.updateStatus(ctx, db)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func (cs *ControlServerImpl) getStatus() *remote.StatusData {
+	cs.statusLock.RLock()
+	defer cs.statusLock.RUnlock()
+	return cs.statusData
+}
+
+// updateStatus sets the head of the database as the best block in the status of the core node
+func (cs *ControlServerImpl) updateStatus(ctx context.Context, db ethdb.Database) {
+	cs.statusLock.Lock()
+	old := cs.statusData
+	status := &remote.StatusData{
+		NetworkId:       old.NetworkId,
+		TotalDifficulty: old.TotalDifficulty,
+		BestHash:        old.BestHash,
+		MaxBlock:        old.MaxBlock,
+		ForkData:        old.ForkData,
+	}
+	readHead(db, status)
+	cs.statusData = status
+	cs.statusLock.Unlock()
+	for _, sentry := range cs.sentries {
+		if _, err := sentry.SetStatus(ctx, status); err != nil {
+			log.Debug("Could not set status on sentry", "err", err)
+		}
+	}
+}
+
+// sentryLoop sets the status of the core node on the sentry and consumes its inbound messages. If the sentry
+// is restarted or the connection is lost, the loop reconnects and sets the status again
+func (cs *ControlServerImpl) sentryLoop(ctx context.Context, sentry remote.SENTRYClient) {
+	for {
+		if err := cs.receiveFromSentry(ctx, sentry); err != nil {
+			log.Warn("Receiving messages from sentry failed, reconnecting", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (cs *ControlServerImpl) receiveFromSentry(ctx context.Context, sentry remote.SENTRYClient) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if _, err := sentry.SetStatus(streamCtx, cs.getStatus(), grpc.WaitForReady(true)); err != nil {
+		return fmt.Errorf("setting status: %w", err)
+	}
+	events, err := sentry.ReceivePeerEvents(streamCtx, &remote.ReceivePeerEventsRequest{})
+	if err != nil {
+		return fmt.Errorf("receiving peer events: %w", err)
+	}
+	go func() {
+		for {
+			event, err1 := events.Recv()
+			if err1 != nil {
+				return
+			}
+			log.Debug(fmt.Sprintf("[%s] Peer event %s", event.PeerId, event.EventId), "height", event.BlockHeight)
+		}
+	}()
+	stream, err := sentry.ReceiveMessages(streamCtx, &remote.ReceiveMessagesRequest{})
+	if err != nil {
+		return fmt.Errorf("receiving messages: %w", err)
+	}
+	for {
+		inreq, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || streamCtx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err = cs.handleInboundMessage(ctx, inreq, sentry); err != nil {
+			log.Error("Handling inbound message failed", "id", inreq.Id, "peer", inreq.PeerId, "err", err)
+		}
+	}
+}
+
+// GrpcSentryClient connects to the sentry listening on sentryAddr
+func GrpcSentryClient(ctx context.Context, sentryAddr string) (remote.SENTRYClient, error) {
+	log.Info("Connecting to sentry", "addr", sentryAddr)
+	dialOpts := []grpc.DialOption{
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.DefaultConfig}),
+		grpc.WithInsecure(),
+	}
+	conn, err := grpc.DialContext(ctx, sentryAddr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating client connection to sentry %s: %w", sentryAddr, err)
+	}
+	return remote.NewSENTRYClient(conn), nil
+}
+
+// SentryStatus prepares the status of the core node, which sentries use in eth handshakes with peers. Without
+// the database, the best block is the genesis
+func SentryStatus(db ethdb.Database, networkID uint64, chainConfig *params.ChainConfig, genesisHash common.Hash, genesisDifficulty *big.Int) *remote.StatusData {
+	status := &remote.StatusData{
+		NetworkId:       networkID,
+		TotalDifficulty: genesisDifficulty.Bytes(),
+		BestHash:        genesisHash.Bytes(),
+		ForkData: &remote.Forks{
+			Genesis: genesisHash.Bytes(),
+			Forks:   forkid.GatherForks(chainConfig),
+		},
+	}
+	if db != nil {
+		readHead(db, status)
+	}
+	return status
+}
+
+// readHead sets the head header of the database as the best block of the status
+func readHead(db ethdb.Database, status *remote.StatusData) {
+	if hash := rawdb.ReadHeadHeaderHash(db); hash != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
+			if td := rawdb.ReadTd(db, hash, *number); td != nil {
+				status.TotalDifficulty, status.BestHash, status.MaxBlock = td.Bytes(), hash.Bytes(), *number
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/u256"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// testSentry - the sentry with one peer which has the given blocks. The replies of the peer are delivered to the
+// control server as soon as the requests are sent
+type testSentry struct {
+	remote.SENTRYClient
+	t      *testing.T
+	cs     *ControlServerImpl
+	blocks map[common.Hash]*types.Block
+	status *remote.StatusData
+}
+
+func (s *testSentry) SetStatus(_ context.Context, in *remote.StatusData, _ ...grpc.CallOption) (*remote.SetStatusReply, error) {
+	s.status = in
+	return &remote.SetStatusReply{}, nil
+}
+
+func (s *testSentry) SendMessageByMinBlock(ctx context.Context, in *remote.SendMessageByMinBlockRequest, _ ...grpc.CallOption) (*remote.SentPeers, error) {
+	var reply interface{}
+	var id remote.MessageId
+	switch in.Data.Id {
+	case remote.MessageId_GetBlockHeaders:
+		var req GetBlockHeadersData
+		require.NoError(s.t, rlp.DecodeBytes(in.Data.Data, &req))
+		var headers []*types.Header
+		for hash := req.Origin.Hash; uint64(len(headers)) < req.Amount; {
+			block, ok := s.blocks[hash]
+			if !ok {
+				break
+			}
+			headers = append(headers, block.Header())
+			hash = block.ParentHash()
+		}
+		reply, id = headers, remote.MessageId_BlockHeaders
+	case remote.MessageId_GetBlockBodies:
+		var hashes []common.Hash
+		require.NoError(s.t, rlp.DecodeBytes(in.Data.Data, &hashes))
+		var bodies []*types.Body
+		for _, hash := range hashes {
+			if block, ok := s.blocks[hash]; ok {
+				bodies = append(bodies, block.Body())
+			}
+		}
+		reply, id = bodies, remote.MessageId_BlockBodies
+	default:
+		s.t.Fatalf("unexpected message %s", in.Data.Id)
+	}
+	data, err := rlp.EncodeToBytes(reply)
+	require.NoError(s.t, err)
+	require.NoError(s.t, s.cs.handleInboundMessage(ctx, &remote.InboundMessage{Id: id, Data: data, PeerId: "peer"}, s))
+	return &remote.SentPeers{Peers: []string{"peer"}}, nil
+}
+
+func (s *testSentry) PenalizePeer(_ context.Context, in *remote.PenalizePeerRequest, _ ...grpc.CallOption) (*remote.PenalizePeerReply, error) {
+	s.t.Errorf("peer penalized: %s", in.Penalty)
+	return &remote.PenalizePeerReply{}, nil
+}
+
+func TestSentryDownload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sentry")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	const blocks = 64
+	gspec := &core.Genesis{
+		Config:    params.TestChainConfig,
+		Alloc:     core.GenesisAlloc{testBank: {Balance: big.NewInt(params.Ether)}},
+		Timestamp: uint64(time.Now().Unix()) - 10*blocks - 10, // the headers are announced within the past limit of new anchors
+	}
+	dbGen := ethdb.NewMemDatabase()
+	defer dbGen.Close()
+	genesis := gspec.MustCommit(dbGen)
+	signer := types.MakeSigner(gspec.Config, big.NewInt(1))
+	chain, _, err := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), dbGen, blocks, func(i int, b *core.BlockGen) {
+		if i%3 == 0 {
+			tx, err1 := types.SignTx(types.NewTransaction(b.TxNonce(testBank), common.Address{1}, u256.Num1, params.TxGas, u256.Num1, nil), signer, testBankKey)
+			require.NoError(t, err1)
+			b.AddTx(tx)
+		}
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	gspec.MustCommit(db)
+	cs := NewControlServer(dir, nil, SentryStatus(db, 1, gspec.Config, genesis.Hash(), genesis.Difficulty()), gspec.Config, ethash.NewFaker(), db)
+	sentry := &testSentry{t: t, cs: cs, blocks: map[common.Hash]*types.Block{}}
+	for _, block := range chain {
+		sentry.blocks[block.Hash()] = block
+	}
+	cs.sentries = []remote.SENTRYClient{sentry}
+
+	// the peer announces its head, and the stages download the chain down to the genesis
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	head := chain[len(chain)-1]
+	data, err := rlp.EncodeToBytes(&NewBlockData{Block: head, TD: big.NewInt(0)})
+	require.NoError(t, err)
+	require.NoError(t, cs.handleInboundMessage(ctx, &remote.InboundMessage{Id: remote.MessageId_NewBlock, Data: data, PeerId: "peer"}, sentry))
+	sync := stagedsync.New(stagedsync.DownloadStages(), stagedsync.DownloadUnwindOrder())
+	sync.Sentry = cs.Download()
+	state, err := sync.Prepare(nil, gspec.Config, nil, nil, db, db, "", ethdb.DefaultStorageMode, dir, false, ctx.Done(), nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, state.Run(db, db))
+
+	for _, block := range chain {
+		number := block.NumberU64()
+		require.Equal(t, block.Hash(), rawdb.ReadCanonicalHash(db, number), "block %d", number)
+		body := rawdb.ReadBody(db, block.Hash(), number)
+		require.NotNil(t, body, "block %d", number)
+		require.Equal(t, len(block.Transactions()), len(body.Transactions), "block %d", number)
+	}
+
+	// the sentries get the downloaded head for the handshakes
+	cs.updateStatus(ctx, db)
+	require.Equal(t, head.Hash().Bytes(), sentry.status.BestHash)
+	require.Equal(t, uint64(blocks), sentry.status.MaxBlock)
+}
+
+func TestSentryClique(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sentry")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	signerKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	signerAddr := crypto.PubkeyToAddress(signerKey.PublicKey)
+	gspec := &core.Genesis{
+		Config:    params.AllCliqueProtocolChanges,
+		ExtraData: make([]byte, 32+common.AddressLength+crypto.SignatureLength),
+		Alloc:     core.GenesisAlloc{testBank: {Balance: big.NewInt(params.Ether)}},
+		Timestamp: uint64(time.Now().Unix()) - 60, // the header is announced within the past limit of new anchors
+	}
+	copy(gspec.ExtraData[32:], signerAddr[:])
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	genesis := gspec.MustCommit(db)
+	engine := clique.New(gspec.Config.Clique, db)
+
+	cs := NewControlServer(dir, nil, SentryStatus(db, 1, gspec.Config, genesis.Hash(), genesis.Difficulty()), gspec.Config, engine, db)
+	sentry := &testSentry{t: t, cs: cs, blocks: map[common.Hash]*types.Block{}}
+	cs.sentries = []remote.SENTRYClient{sentry}
+
+	// the seals of the children of the genesis are verified against the signers in the genesis
+	newBlock := func(parent *types.Block, key *ecdsa.PrivateKey) *types.Block {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Root:       genesis.Root(),
+			TxHash:     types.EmptyRootHash,
+			Number:     new(big.Int).Add(parent.Number(), big.NewInt(1)),
+			GasLimit:   genesis.GasLimit(),
+			Time:       parent.Time() + gspec.Config.Clique.Period,
+			Difficulty: big.NewInt(2),
+			Extra:      make([]byte, 32+crypto.SignatureLength),
+		}
+		sig, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+		require.NoError(t, err)
+		copy(header.Extra[32:], sig)
+		return types.NewBlockWithHeader(header)
+	}
+	block1 := newBlock(genesis, signerKey)
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name     string
+		block    *types.Block
+		attached bool
+	}{
+		{"authorized signer", block1, true},
+		{"unauthorized signer", newBlock(genesis, otherKey), false},
+		// the snapshot at block 1 is not in the database yet, the difficulty is not checked and the seal is not verified
+		{"parent not inserted", newBlock(block1, signerKey), false},
+	} {
+		data, err := rlp.EncodeToBytes(&NewBlockData{Block: tt.block, TD: big.NewInt(3)})
+		require.NoError(t, err)
+		require.NoError(t, cs.handleInboundMessage(ctx, &remote.InboundMessage{Id: remote.MessageId_NewBlock, Data: data, PeerId: "peer"}, sentry), tt.name)
+		require.Equal(t, tt.attached, cs.hd.HasTip(tt.block.Hash()), tt.name)
+	}
+}
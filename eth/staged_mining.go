@@ -0,0 +1,173 @@
+package eth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// startStagedMining launches the block production on top of staged sync. In staged sync mode the transaction
+// pool is normally started by the TxPool stage, which never runs on a chain without peers, so it is started here
+// if needed.
+func (s *Ethereum) startStagedMining(etherbase common.Address) error {
+	if !s.txPool.IsStarted() {
+		if err := s.StartTxPool(); err != nil {
+			return err
+		}
+	}
+	quit := make(chan struct{})
+	s.lock.Lock()
+	s.quitMining = quit
+	s.lock.Unlock()
+
+	go s.stagedMiningLoop(etherbase, quit)
+	return nil
+}
+
+// stopStagedMining terminates the staged sync miner, if it is running.
+func (s *Ethereum) stopStagedMining() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.quitMining != nil {
+		close(s.quitMining)
+		s.quitMining = nil
+	}
+}
+
+// stagedMiningLoop builds a new block whenever new transactions arrive and, unless the chain is a clique one
+// which only produces blocks with transactions, once per recommit interval. Sealing of the previous block is
+// aborted every time a new one is built, and the sealed blocks are inserted into the chain.
+func (s *Ethereum) stagedMiningLoop(etherbase common.Address, quit <-chan struct{}) {
+	chainConfig := s.blockchain.Config()
+	mining := stagedsync.New(stagedsync.MiningStages(), stagedsync.MiningUnwindOrder())
+
+	newTxsCh := make(chan core.NewTxsEvent, txChanSize)
+	newTxsSub := s.txPool.SubscribeNewTxsEvent(newTxsCh)
+	defer newTxsSub.Unsubscribe()
+
+	var recommit <-chan time.Time
+	if chainConfig.Clique == nil || chainConfig.Clique.Period > 0 {
+		ticker := time.NewTicker(s.config.Miner.Recommit)
+		defer ticker.Stop()
+		recommit = ticker.C
+	}
+
+	results := make(chan consensus.ResultWithContext, 1)
+	var sealCancel chan struct{}
+	defer func() {
+		if sealCancel != nil {
+			close(sealCancel)
+		}
+	}()
+	commit := func() {
+		if sealCancel != nil {
+			close(sealCancel)
+		}
+		sealCancel = make(chan struct{})
+		if err := s.mineBlock(mining, etherbase, results, sealCancel, quit); err != nil {
+			log.Warn("Failed to mine block", "err", err)
+		}
+	}
+
+	if recommit != nil {
+		commit()
+	}
+	for {
+		select {
+		case <-newTxsCh:
+			commit()
+		case <-recommit:
+			commit()
+		case result := <-results:
+			if err := s.insertMinedBlock(result.Block); err != nil {
+				log.Error("Failed to insert mined block", "number", result.Block.NumberU64(), "hash", result.Block.Hash(), "err", err)
+				continue
+			}
+			// Transactions which did not fit into the block do not produce new events, pick them up right away
+			if pending, _ := s.txPool.Stats(); pending > 0 {
+				commit()
+			}
+		case <-newTxsSub.Err():
+			return
+		case <-quit:
+			return
+		}
+	}
+}
+
+// mineBlock runs the mining stages in a transaction which is rolled back afterwards. If the consensus engine
+// decides to seal the block, it is delivered to results.
+func (s *Ethereum) mineBlock(mining *stagedsync.StagedSync, etherbase common.Address, results chan<- consensus.ResultWithContext, sealCancel <-chan struct{}, quit <-chan struct{}) error {
+	if s.protocolManager.downloader.Synchronising() {
+		log.Debug("Skipping mining while synchronising")
+		return nil
+	}
+	tx, err := s.chainDb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	miningState, err := mining.Prepare(
+		nil,
+		s.blockchain.Config(),
+		s.blockchain,
+		s.blockchain.GetVMConfig(),
+		s.chainDb,
+		tx,
+		"",
+		s.config.StorageMode,
+		s.protocolManager.datadir,
+		false,
+		quit,
+		nil,
+		s.txPool,
+		nil,
+		nil,
+		&stagedsync.MiningState{
+			Etherbase:  etherbase,
+			Extra:      makeExtraData(s.config.Miner.ExtraData),
+			GasFloor:   s.config.Miner.GasFloor,
+			GasCeil:    s.config.Miner.GasCeil,
+			ResultCh:   results,
+			SealCancel: sealCancel,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return miningState.Run(s.chainDb, tx)
+}
+
+// insertMinedBlock inserts a sealed block through the sync stages, then updates the transaction pool and the
+// progress of the stages which are not run by stagedsync.InsertBlockInStages.
+func (s *Ethereum) insertMinedBlock(block *types.Block) error {
+	executionAt, _, err := stages.GetStageProgress(s.chainDb, stages.Execution)
+	if err != nil {
+		return err
+	}
+	if block.ParentHash() != rawdb.ReadCanonicalHash(s.chainDb, executionAt) {
+		return errors.New("parent of the mined block is not the head of the chain anymore")
+	}
+	if err = stagedsync.InsertBlockInStages(s.chainDb, s.blockchain.Config(), s.engine, block, s.blockchain); err != nil {
+		return err
+	}
+	number := block.NumberU64()
+	for _, stage := range []stages.SyncStage{stages.TxPool, stages.Finish} {
+		if err = stages.SaveStageProgress(s.chainDb, stage, number, nil); err != nil {
+			return err
+		}
+	}
+	s.txPool.ResetHead(block.GasLimit(), number)
+
+	log.Info("Successfully sealed new block", "number", number, "hash", block.Hash(), "txs", len(block.Transactions()))
+	return s.eventMux.Post(core.NewMinedBlockEvent{Block: block})
+}
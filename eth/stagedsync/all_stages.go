@@ -2,6 +2,7 @@ package stagedsync
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"time"
 
@@ -73,7 +74,7 @@ func InsertBlockInStages(db ethdb.Database, config *params.ChainConfig, engine c
 	if err := SpawnExecuteBlocksStage(&StageState{
 		Stage:       stages.Execution,
 		BlockNumber: num - 1,
-	}, db, config, bc, bc.GetVMConfig(), 0, nil, true, false, nil); err != nil {
+	}, db, config, bc, bc.GetVMConfig(), 0, nil, true, false, nil, nil, nil); err != nil {
 		return err
 	}
 
@@ -118,3 +119,49 @@ func InsertBlockInStages(db ethdb.Database, config *params.ChainConfig, engine c
 
 	return nil
 }
+
+// UnwindBlocksInStages unwinds the stages run by InsertBlockInStages to the given block, in the order in which
+// the unwinds of DefaultUnwindOrder are popped from the unwind stack. The canonical headers after the block are deleted, so that the blocks of a side chain
+// can be inserted on top of it.
+func UnwindBlocksInStages(db ethdb.Database, engine consensus.Engine, unwindPoint uint64) error {
+	unwind := func(stage stages.SyncStage, unwindFunc func(u *UnwindState, s *StageState) error) error {
+		progress, _, err := stages.GetStageProgress(db, stage)
+		if err != nil {
+			return err
+		}
+		if progress <= unwindPoint {
+			return nil
+		}
+		if err = unwindFunc(&UnwindState{Stage: stage, UnwindPoint: unwindPoint}, &StageState{Stage: stage, BlockNumber: progress}); err != nil {
+			return fmt.Errorf("unwinding %s: %w", stage, err)
+		}
+		return nil
+	}
+	done := func(u *UnwindState, s *StageState) error {
+		return u.Done(db)
+	}
+	for _, stage := range []struct {
+		id         stages.SyncStage
+		unwindFunc func(u *UnwindState, s *StageState) error
+	}{
+		{stages.TxLookup, func(u *UnwindState, s *StageState) error { return UnwindTxLookup(u, s, db, "", nil) }},
+		{stages.StorageHistoryIndex, func(u *UnwindState, s *StageState) error { return UnwindStorageHistoryIndex(u, db, nil) }},
+		{stages.AccountHistoryIndex, func(u *UnwindState, s *StageState) error { return UnwindAccountHistoryIndex(u, db, nil) }},
+		{stages.HashState, func(u *UnwindState, s *StageState) error { return UnwindHashStateStage(u, s, db, "", nil) }},
+		{stages.IntermediateHashes, func(u *UnwindState, s *StageState) error {
+			return UnwindIntermediateHashesStage(u, s, db, "", nil)
+		}},
+		{stages.Execution, func(u *UnwindState, s *StageState) error {
+			return UnwindExecutionStage(u, s, db, true /* writeReceipts */, nil, nil)
+		}},
+		{stages.Senders, func(u *UnwindState, s *StageState) error { return UnwindSendersStage(u, db) }},
+		{stages.Bodies, done},
+		{stages.BlockHashes, done},
+		{stages.Headers, func(u *UnwindState, s *StageState) error { return HeadersUnwind(u, s, db, engine) }},
+	} {
+		if err := unwind(stage.id, stage.unwindFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
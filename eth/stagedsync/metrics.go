@@ -0,0 +1,43 @@
+package stagedsync
+
+import (
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+)
+
+// stageMetrics are registered per stage: stages/<stage>/duration, stages/<stage>/blocks, stages/<stage>/progress,
+// stages/<stage>/unwind and stages/<stage>/unwinds
+type stageMetrics struct {
+	duration metrics.Timer   // of the stage runs
+	blocks   metrics.Meter   // blocks processed by the stage
+	progress metrics.Gauge   // the block the stage is at
+	unwind   metrics.Timer   // of the unwinds of the stage
+	unwinds  metrics.Counter // number of the unwinds of the stage
+}
+
+func metricsOf(id stages.SyncStage) *stageMetrics {
+	prefix := "stages/" + string(id) + "/"
+	return &stageMetrics{
+		duration: metrics.GetOrRegisterTimer(prefix+"duration", nil),
+		blocks:   metrics.GetOrRegisterMeter(prefix+"blocks", nil),
+		progress: metrics.GetOrRegisterGauge(prefix+"progress", nil),
+		unwind:   metrics.GetOrRegisterTimer(prefix+"unwind", nil),
+		unwinds:  metrics.GetOrRegisterCounter(prefix+"unwinds", nil),
+	}
+}
+
+func (m *stageMetrics) ran(start time.Time, from, to uint64) {
+	m.duration.UpdateSince(start)
+	if to > from {
+		m.blocks.Mark(int64(to - from))
+	}
+	m.progress.Update(int64(to))
+}
+
+func (m *stageMetrics) unwound(start time.Time, to uint64) {
+	m.unwind.UpdateSince(start)
+	m.unwinds.Inc(1)
+	m.progress.Update(int64(to))
+}
@@ -44,12 +44,27 @@ type StageState struct {
 
 // Update updates the stage state (current block number) in the database. Can be called multiple times during stage execution.
 func (s *StageState) Update(db ethdb.Putter, newBlockNum uint64) error {
-	return stages.SaveStageProgress(db, s.Stage, newBlockNum, nil)
+	if err := stages.SaveStageProgress(db, s.Stage, newBlockNum, nil); err != nil {
+		return err
+	}
+	s.progress(newBlockNum)
+	return nil
 }
 
 // UpdateWithStageData updates both the current block number for that stage, as well as some additional information as array of bytes: stageData.
 func (s *StageState) UpdateWithStageData(db ethdb.Putter, newBlockNum uint64, stageData []byte) error {
-	return stages.SaveStageProgress(db, s.Stage, newBlockNum, stageData)
+	if err := stages.SaveStageProgress(db, s.Stage, newBlockNum, stageData); err != nil {
+		return err
+	}
+	s.progress(newBlockNum)
+	return nil
+}
+
+// progress passes the saved progress of the stage to the sync status
+func (s *StageState) progress(blockNum uint64) {
+	if s.state != nil {
+		s.state.status.progress(s.Stage, blockNum)
+	}
 }
 
 // Done makes sure that the stage execution is complete and proceeds to the next state.
@@ -68,6 +83,9 @@ func (s *StageState) ExecutionAt(db ethdb.Getter) (uint64, error) {
 // DoneAndUpdate a convenience method combining both `Done()` and `Update()` calls together.
 func (s *StageState) DoneAndUpdate(db ethdb.Putter, newBlockNum uint64) error {
 	err := stages.SaveStageProgress(db, s.Stage, newBlockNum, nil)
+	if err == nil {
+		s.progress(newBlockNum)
+	}
 	s.state.NextStage()
 	return err
 }
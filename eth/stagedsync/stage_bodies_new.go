@@ -0,0 +1,102 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/bodydownload"
+)
+
+// BodiesForward progresses Bodies stage in the forward direction. Unlike spawnBodyDownloadStage, it does not
+// depend on eth/downloader: requests produced by bodydownload.BodyDownload are sent out via requestBodies
+// (for example, to one of the sentries), and the delivered bodies are fed into the same BodyDownload object
+// by the caller, which then signals the wakeUpChan
+func BodiesForward(
+	s *StageState,
+	ctx context.Context,
+	db ethdb.Database,
+	bd *bodydownload.BodyDownload,
+	requestBodies func(context.Context, *bodydownload.BodyRequest) bool,
+	wakeUpChan chan struct{},
+	timeout int,
+) error {
+	headerProgress, _, err := stages.GetStageProgress(db, stages.Headers)
+	if err != nil {
+		return err
+	}
+	bodyProgress := s.BlockNumber
+	if bodyProgress >= headerProgress {
+		s.Done()
+		return nil
+	}
+	log.Info("[Bodies] Processing bodies...", "from", bodyProgress, "to", headerProgress)
+	bd.Reset(bodyProgress)
+
+	batch := db.NewBatch()
+	defer batch.Rollback()
+	timer := time.NewTimer(time.Second)
+	defer timer.Stop()
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for bodyProgress < headerProgress {
+		currentTime := uint64(time.Now().Unix())
+		for {
+			req, err := bd.RequestMoreBodies(db, headerProgress, currentTime, uint64(timeout))
+			if err != nil {
+				return err
+			}
+			if req == nil {
+				break
+			}
+			// Requests which could not be sent are retried after the timeout
+			if !requestBodies(ctx, req) {
+				break
+			}
+		}
+		for _, block := range bd.GetDeliveries() {
+			rawdb.WriteBody(ctx, batch, block.Hash(), block.NumberU64(), block.Body())
+			bodyProgress = block.NumberU64()
+		}
+		if batch.BatchSize() >= batch.IdealBatchSize() {
+			if err = s.Update(batch, bodyProgress); err != nil {
+				return err
+			}
+			if err = batch.CommitAndBegin(); err != nil {
+				return err
+			}
+		}
+		if bodyProgress >= headerProgress {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return common.ErrStopped
+		case <-logEvery.C:
+			log.Info("[Bodies] Downloading bodies", "progress", bodyProgress, "target", headerProgress, "batch", common.StorageSize(batch.BatchSize()))
+		case <-timer.C:
+		case <-wakeUpChan:
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Second)
+	}
+	if err = s.Update(batch, bodyProgress); err != nil {
+		return err
+	}
+	if _, err = batch.Commit(); err != nil {
+		return fmt.Errorf("[Bodies] committing batch: %w", err)
+	}
+	log.Info("[Bodies] Processed", "highest", bodyProgress)
+	s.Done()
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/statediff"
 )
 
 const (
@@ -33,7 +34,7 @@ type HasChangeSetWriter interface {
 
 type ChangeSetHook func(blockNum uint64, wr *state.ChangeSetWriter)
 
-func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig *params.ChainConfig, chainContext core.ChainContext, vmConfig *vm.Config, toBlock uint64, quit <-chan struct{}, writeReceipts bool, hdd bool, changeSetHook ChangeSetHook) error {
+func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig *params.ChainConfig, chainContext core.ChainContext, vmConfig *vm.Config, toBlock uint64, quit <-chan struct{}, writeReceipts bool, hdd bool, changeSetHook ChangeSetHook, stateDiffs *statediff.Publisher, stateCache *StateCache) error {
 	prevStageProgress, _, errStart := stages.GetStageProgress(stateDB, stages.Senders)
 	if errStart != nil {
 		return errStart
@@ -83,6 +84,7 @@ func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 	logBlock := stageProgress
+	stateCache.begin(stageProgress)
 	// Warmup only works for HDD sync, and for long ranges
 	var warmup = hdd && (to-s.BlockNumber) > 30000
 
@@ -118,15 +120,31 @@ func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig
 		var stateReader state.StateReader
 		var stateWriter state.WriterWithChangeSets
 
-		stateReader = state.NewPlainStateReader(batch)
-		stateWriter = state.NewPlainStateWriter(batch, tx, blockNum)
+		plainReader := state.NewPlainStateReader(batch)
+		plainWriter := state.NewPlainStateWriter(batch, tx, blockNum)
+		stateCache.attach(plainReader, plainWriter)
+		stateReader = plainReader
+		stateWriter = plainWriter
+
+		var blockWriter state.WriterWithChangeSets = stateWriter
+		var diffWriter *statediff.Writer
+		if stateDiffs != nil {
+			diffWriter = statediff.NewWriter(stateWriter)
+			blockWriter = diffWriter
+		}
 
 		// where the magic happens
-		receipts, err := core.ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter)
+		receipts, err := core.ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, blockWriter)
 		if err != nil {
 			return err
 		}
 
+		if diffWriter != nil {
+			if err = stateDiffs.Apply(block, diffWriter); err != nil {
+				return err
+			}
+		}
+
 		if writeReceipts {
 			// Convert the receipts into their storage form and serialize them
 			storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
@@ -175,6 +193,9 @@ func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig
 		default:
 		case <-logEvery.C:
 			logBlock = logProgress(logBlock, blockNum, batch)
+			if stateCache != nil {
+				log.Info("State cache hit rates", stateCache.hitRates()...)
+			}
 		}
 	}
 
@@ -189,7 +210,11 @@ func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig
 			return err
 		}
 	}
+	stateCache.done(stageProgress)
 	log.Info("Completed on", "block", stageProgress)
+	if stateCache != nil {
+		log.Info("State cache hit rates", stateCache.hitRates()...)
+	}
 	s.Done()
 	return nil
 }
@@ -209,11 +234,13 @@ func logProgress(prev, now uint64, batch ethdb.DbWithPendingMutations) uint64 {
 	return now
 }
 
-func UnwindExecutionStage(u *UnwindState, s *StageState, stateDB ethdb.Database, writeReceipts bool) error {
+func UnwindExecutionStage(u *UnwindState, s *StageState, stateDB ethdb.Database, writeReceipts bool, stateDiffs *statediff.Publisher, stateCache *StateCache) error {
 	if u.UnwindPoint >= s.BlockNumber {
 		s.Done()
 		return nil
 	}
+	// the cached state is of the blocks being unwound
+	stateCache.reset()
 
 	log.Info("Unwind Execution stage", "from", s.BlockNumber, "to", u.UnwindPoint)
 	batch := stateDB.NewBatch()
@@ -296,6 +323,11 @@ func UnwindExecutionStage(u *UnwindState, s *StageState, stateDB ethdb.Database,
 	if err != nil {
 		return fmt.Errorf("unwind Execute: failed to write db commit: %v", err)
 	}
+	if stateDiffs != nil {
+		if err = stateDiffs.Unwind(u.UnwindPoint, rawdb.ReadCanonicalHash(stateDB, u.UnwindPoint)); err != nil {
+			return fmt.Errorf("unwind Execution: %w", err)
+		}
+	}
 	return nil
 }
 
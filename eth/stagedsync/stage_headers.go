@@ -43,12 +43,28 @@ func SpawnHeaderDownloadStage(s *StageState, u Unwinder, d DownloaderGlue, heade
 	return err
 }
 
+// UnwindHeadersStage removes the data derived from the headers after the unwind point by the consensus
+// engine, like the clique voting snapshots. The headers themselves stay in the database.
+func UnwindHeadersStage(u *UnwindState, db ethdb.Database, engine consensus.Engine) error {
+	if unwinder, ok := engine.(consensus.Unwinder); ok {
+		if err := unwinder.UnwindTo(u.UnwindPoint); err != nil {
+			return fmt.Errorf("unwinding the consensus engine to %d: %w", u.UnwindPoint, err)
+		}
+	}
+	return u.Done(db)
+}
+
 // Implements consensus.ChainReader
 type ChainReader struct {
 	config *params.ChainConfig
 	db     ethdb.Database
 }
 
+// NewChainReader - the headers and blocks of the chain in the database
+func NewChainReader(config *params.ChainConfig, db ethdb.Database) ChainReader {
+	return ChainReader{config: config, db: db}
+}
+
 // Config retrieves the blockchain's chain configuration.
 func (cr ChainReader) Config() *params.ChainConfig {
 	return cr.config
@@ -58,6 +74,9 @@ func (cr ChainReader) Config() *params.ChainConfig {
 func (cr ChainReader) CurrentHeader() *types.Header {
 	hash := rawdb.ReadHeadHeaderHash(cr.db)
 	number := rawdb.ReadHeaderNumber(cr.db, hash)
+	if number == nil {
+		return nil
+	}
 	return rawdb.ReadHeader(cr.db, hash, *number)
 }
 
@@ -75,6 +94,9 @@ func (cr ChainReader) GetHeaderByNumber(number uint64) *types.Header {
 // GetHeaderByHash retrieves a block header from the database by its hash.
 func (cr ChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
 	number := rawdb.ReadHeaderNumber(cr.db, hash)
+	if number == nil {
+		return nil
+	}
 	return rawdb.ReadHeader(cr.db, hash, *number)
 }
 
@@ -174,6 +196,7 @@ Error: %v
 		deepFork = true
 	}
 	var forkBlockNumber uint64
+	var forkFound bool // forkBlockNumber is 0 as well when the chains diverge right after the genesis
 	ignored := 0
 	batch := db.NewBatch()
 	// Do a full insert if pre-checks passed
@@ -189,8 +212,9 @@ Error: %v
 		}
 		number := header.Number.Uint64()
 		hashesMatch := header.Hash() == rawdb.ReadCanonicalHash(batch, number)
-		if newCanonical && !deepFork && forkBlockNumber == 0 && !hashesMatch {
+		if newCanonical && !deepFork && !forkFound && !hashesMatch {
 			forkBlockNumber = number - 1
+			forkFound = true
 		} else if newCanonical && hashesMatch {
 			forkBlockNumber = number
 		}
@@ -0,0 +1,175 @@
+package stagedsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
+)
+
+// insertHeadersChunk is the maximum number of headers passed to InsertHeaderChain at once
+const insertHeadersChunk = 1024
+
+// HeadersForward progresses Headers stage in the forward direction. Unlike SpawnHeaderDownloadStage, it does not
+// depend on eth/downloader: requests produced by headerdownload.HeaderDownload are sent out via requestHeaders
+// (for example, to one of the sentries), and the delivered headers are fed into the same HeaderDownload object
+// by the caller, which then signals the wakeUpChan. Working trees which get connected to the headers in the database
+// are inserted using InsertHeaderChain. If the insertion causes a reorg, the unwind is scheduled via u
+func HeadersForward(
+	s *StageState,
+	u Unwinder,
+	ctx context.Context,
+	db ethdb.Database,
+	hd *headerdownload.HeaderDownload,
+	chainConfig *params.ChainConfig,
+	engine consensus.Engine,
+	requestHeaders func(context.Context, *headerdownload.HeaderRequest) bool,
+	wakeUpChan chan struct{},
+) error {
+	headerProgress := s.BlockNumber
+	if headHash := rawdb.ReadHeadHeaderHash(db); headHash != (common.Hash{}) {
+		if headNumber := rawdb.ReadHeaderNumber(db, headHash); headNumber != nil && *headNumber > headerProgress {
+			headerProgress = *headNumber
+		}
+	}
+	log.Info("[Headers] Processing headers...", "from", headerProgress)
+
+	hasHeader := func(hash common.Hash, number uint64) bool {
+		return rawdb.HasHeader(db, hash, number)
+	}
+	var reorg bool
+	var forkBlockNumber uint64
+	insertHeaders := func(headers []*types.Header) error {
+		for len(headers) > 0 {
+			chunk := headers
+			if len(chunk) > insertHeadersChunk {
+				chunk = chunk[:insertHeadersChunk]
+			}
+			headers = headers[len(chunk):]
+			reorgChunk, forkBlockNumberChunk, err := InsertHeaderChain(db, chunk, chainConfig, engine, 0 /* checkFreq */)
+			if err != nil {
+				return err
+			}
+			if reorgChunk && (!reorg || forkBlockNumberChunk < forkBlockNumber) {
+				reorg = true
+				forkBlockNumber = forkBlockNumberChunk
+			}
+			if lastNumber := chunk[len(chunk)-1].Number.Uint64(); lastNumber > headerProgress {
+				headerProgress = lastNumber
+			}
+		}
+		return nil
+	}
+
+	timer := time.NewTimer(time.Second)
+	defer timer.Stop()
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	defer func() {
+		if err := hd.FlushBuffer(); err != nil {
+			log.Error("[Headers] Could not flush header buffer", "error", err)
+		}
+	}()
+	for {
+		for _, req := range hd.RequestMoreHeaders(uint64(time.Now().Unix()), 5 /* timeout */) {
+			requestHeaders(ctx, req)
+		}
+		inserted, err := hd.InsertHeaders(hasHeader, insertHeaders)
+		if err != nil {
+			return fmt.Errorf("[Headers] inserting headers: %w", err)
+		}
+		if reorg {
+			// The canonical chain above the fork block is already replaced by the inserted headers. The new head is
+			// saved as the stage data, so that the unwind of this stage keeps them, and only removes what was derived
+			// from the abandoned fork
+			log.Info("[Headers] Reorg detected, unwinding", "fork block", forkBlockNumber, "highest", headerProgress)
+			if err = s.UpdateWithStageData(db, headerProgress, rawdb.ReadHeadHeaderHash(db).Bytes()); err != nil {
+				return err
+			}
+			if err = u.UnwindTo(forkBlockNumber, db); err != nil {
+				return err
+			}
+			s.Done()
+			return nil
+		}
+		if inserted > 0 {
+			if err = s.Update(db, headerProgress); err != nil {
+				return err
+			}
+		}
+		if hd.AnchorCount() == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return common.ErrStopped
+		case <-logEvery.C:
+			log.Info("[Headers] Downloading headers", "progress", headerProgress, "working trees", hd.AnchorCount())
+		case <-timer.C:
+		case <-wakeUpChan:
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Second)
+	}
+	if err := s.Update(db, headerProgress); err != nil {
+		return err
+	}
+	log.Info("[Headers] Processed", "highest", headerProgress)
+	s.Done()
+	return nil
+}
+
+// quitContext - the context of HeadersForward and BodiesForward, which is cancelled when the sync is stopped
+func quitContext(quitCh <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-quitCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// HeadersUnwind unwinds Headers stage: the canonical headers above the unwind point are deleted, and the head
+// header is moved back to the unwind point. After a reorg by HeadersForward, the stage data holds the new head,
+// and the canonical headers, which are those of the new chain, are kept
+func HeadersUnwind(u *UnwindState, s *StageState, db ethdb.Database, engine consensus.Engine) error {
+	batch := db.NewBatch()
+	defer batch.Rollback()
+	if reorgHead := rawdb.ReadHeadHeaderHash(batch); !bytes.Equal(s.StageData, reorgHead.Bytes()) {
+		for number := u.UnwindPoint + 1; ; number++ {
+			hash := rawdb.ReadCanonicalHash(batch, number)
+			if hash == (common.Hash{}) {
+				break
+			}
+			rawdb.DeleteTd(batch, hash, number)
+			rawdb.DeleteHeader(batch, hash, number)
+			rawdb.DeleteHeaderNumber(batch, hash)
+			rawdb.DeleteCanonicalHash(batch, number)
+		}
+		rawdb.WriteHeadHeaderHash(batch, rawdb.ReadCanonicalHash(batch, u.UnwindPoint))
+	}
+	if err := UnwindHeadersStage(u, batch, engine); err != nil {
+		return err
+	}
+	if _, err := batch.Commit(); err != nil {
+		return fmt.Errorf("[Headers] committing unwind: %w", err)
+	}
+	return nil
+}
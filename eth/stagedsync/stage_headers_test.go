@@ -5,9 +5,12 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
 	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/stretchr/testify/assert"
@@ -107,3 +110,113 @@ func TestInsertHeaderChainTotalDifficulty(t *testing.T) {
 	td = rawdb.ReadTd(db, lastHeader2.Hash(), lastHeader2.Number.Uint64())
 	assert.Equal(t, expectedTdBlock4, td)
 }
+
+func TestHeadersUnwind(t *testing.T) {
+	origin, headers := generateFakeBlocks(1, 4)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	rawdb.WriteHeaderNumber(db, origin.Hash(), 0)
+	rawdb.WriteTd(db, origin.Hash(), 0, origin.Difficulty)
+	rawdb.WriteHeader(context.TODO(), db, origin)
+	rawdb.WriteHeadHeaderHash(db, origin.Hash())
+	rawdb.WriteCanonicalHash(db, origin.Hash(), 0)
+
+	_, _, err := InsertHeaderChain(db, headers, params.AllEthashProtocolChanges, ethash.NewFaker(), 0)
+	assert.NoError(t, err)
+	assert.NoError(t, stages.SaveStageProgress(db, stages.Headers, 4, nil))
+
+	u := &UnwindState{Stage: stages.Headers, UnwindPoint: 2}
+	assert.NoError(t, HeadersUnwind(u, &StageState{Stage: stages.Headers, BlockNumber: 4}, db, ethash.NewFaker()))
+
+	// the headers above the unwind point are gone, together with their canonical hashes
+	for _, h := range headers {
+		number := h.Number.Uint64()
+		if number <= 2 {
+			assert.Equal(t, h.Hash(), rawdb.ReadCanonicalHash(db, number))
+			assert.NotNil(t, rawdb.ReadHeader(db, h.Hash(), number))
+			continue
+		}
+		assert.Equal(t, common.Hash{}, rawdb.ReadCanonicalHash(db, number))
+		assert.Nil(t, rawdb.ReadHeader(db, h.Hash(), number))
+		assert.Nil(t, rawdb.ReadTd(db, h.Hash(), number))
+		assert.Nil(t, rawdb.ReadHeaderNumber(db, h.Hash()))
+	}
+	assert.Equal(t, headers[1].Hash(), rawdb.ReadHeadHeaderHash(db))
+
+	progress, _, err := stages.GetStageProgress(db, stages.Headers)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), progress)
+}
+
+// unwindRecorder - the engine which records the unwind point of its data
+type unwindRecorder struct {
+	consensus.Engine
+	unwindPoint *uint64
+}
+
+func (e unwindRecorder) UnwindTo(unwindPoint uint64) error {
+	*e.unwindPoint = unwindPoint
+	return nil
+}
+
+func TestHeadersUnwindReorg(t *testing.T) {
+	origin, headers := generateFakeBlocks(1, 4)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	rawdb.WriteHeaderNumber(db, origin.Hash(), 0)
+	rawdb.WriteTd(db, origin.Hash(), 0, origin.Difficulty)
+	rawdb.WriteHeader(context.TODO(), db, origin)
+	rawdb.WriteHeadHeaderHash(db, origin.Hash())
+	rawdb.WriteCanonicalHash(db, origin.Hash(), 0)
+
+	_, _, err := InsertHeaderChain(db, headers, params.AllEthashProtocolChanges, ethash.NewFaker(), 0)
+	assert.NoError(t, err)
+
+	// the fork of block 2 with the higher total difficulty replaces blocks 3 and 4
+	fork := make([]*types.Header, 3)
+	parent := headers[1]
+	for i := range fork {
+		fork[i] = &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Root:       types.EmptyRootHash,
+			Difficulty: ethash.CalcDifficulty(params.AllEthashProtocolChanges, parent.Time+2, parent.Time, parent.Difficulty, parent.Number, parent.UncleHash),
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			GasLimit:   6000,
+			Time:       parent.Time + 2,
+		}
+		parent = fork[i]
+	}
+	reorg, forkBlockNumber, err := InsertHeaderChain(db, fork, params.AllEthashProtocolChanges, ethash.NewFaker(), 0)
+	assert.NoError(t, err)
+	assert.True(t, reorg)
+	assert.Equal(t, uint64(2), forkBlockNumber)
+
+	// as saved by HeadersForward before it schedules the unwind
+	head := rawdb.ReadHeadHeaderHash(db)
+	assert.NoError(t, stages.SaveStageProgress(db, stages.Headers, 5, head.Bytes()))
+	progress, stageData, err := stages.GetStageProgress(db, stages.Headers)
+	assert.NoError(t, err)
+
+	var unwindPoint uint64
+	u := &UnwindState{Stage: stages.Headers, UnwindPoint: forkBlockNumber}
+	s := &StageState{Stage: stages.Headers, BlockNumber: progress, StageData: stageData}
+	assert.NoError(t, HeadersUnwind(u, s, db, unwindRecorder{ethash.NewFaker(), &unwindPoint}))
+
+	// the engine drops what it derived from the abandoned fork, and the headers of the new chain stay canonical
+	assert.Equal(t, forkBlockNumber, unwindPoint)
+	for _, h := range fork {
+		number := h.Number.Uint64()
+		assert.Equal(t, h.Hash(), rawdb.ReadCanonicalHash(db, number))
+		assert.NotNil(t, rawdb.ReadHeader(db, h.Hash(), number))
+	}
+	assert.Equal(t, head, rawdb.ReadHeadHeaderHash(db))
+
+	progress, _, err = stages.GetStageProgress(db, stages.Headers)
+	assert.NoError(t, err)
+	assert.Equal(t, forkBlockNumber, progress)
+}
@@ -218,7 +218,9 @@ func (p *HashPromoter) Unwind(s *StageState, u *UnwindState, storage bool, load
 	return nil
 }
 
-func incrementIntermediateHashes(s *StageState, db ethdb.Database, to uint64, datadir string, expectedRootHash common.Hash, quit <-chan struct{}) error {
+// changedKeysRetainList returns the retain list containing the hashed keys of the accounts and storage items
+// changed after the given block, so the intermediate hashes covering them are not used for the root calculation
+func changedKeysRetainList(s *StageState, db ethdb.Database, from, to uint64, datadir string, quit <-chan struct{}) (*trie.RetainList, error) {
 	p := NewHashPromoter(db, quit)
 	p.TempDir = datadir
 	var exclude [][]byte
@@ -229,17 +231,25 @@ func incrementIntermediateHashes(s *StageState, db ethdb.Database, to uint64, da
 		return nil
 	}
 
-	if err := p.Promote(s, s.BlockNumber, to, false /* storage */, collect); err != nil {
-		return err
+	if err := p.Promote(s, from, to, false /* storage */, collect); err != nil {
+		return nil, err
 	}
-	if err := p.Promote(s, s.BlockNumber, to, true /* storage */, collect); err != nil {
-		return err
+	if err := p.Promote(s, from, to, true /* storage */, collect); err != nil {
+		return nil, err
 	}
 	sort.Slice(exclude, func(i, j int) bool { return bytes.Compare(exclude[i], exclude[j]) < 0 })
 	unfurl := trie.NewRetainList(0)
 	for i := range exclude {
 		unfurl.AddKey(exclude[i])
 	}
+	return unfurl, nil
+}
+
+func incrementIntermediateHashes(s *StageState, db ethdb.Database, to uint64, datadir string, expectedRootHash common.Hash, quit <-chan struct{}) error {
+	unfurl, err := changedKeysRetainList(s, db, s.BlockNumber, to, datadir, quit)
+	if err != nil {
+		return err
+	}
 
 	buf := etl.NewSortableBuffer(etl.BufferOptimalSize)
 	comparator := db.(ethdb.HasTx).Tx().Comparator(dbutils.IntermediateTrieHashBucket)
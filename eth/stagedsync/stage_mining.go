@@ -0,0 +1,299 @@
+package stagedsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/misc"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+// MiningState contains the configuration of the mining stages and the block they are building.
+// The mining stages run in a transaction which is rolled back once the block has been passed to
+// the consensus engine, so the state of the block being mined is never written to the database.
+type MiningState struct {
+	Etherbase common.Address // Beneficiary of the mined blocks
+	Extra     []byte         // Extra data of the mined blocks
+	GasFloor  uint64         // Target gas floor for the mined blocks
+	GasCeil   uint64         // Target gas ceiling for the mined blocks
+
+	// ResultCh receives the blocks sealed by the consensus engine
+	ResultCh chan<- consensus.ResultWithContext
+	// SealCancel aborts the sealing of the block, once closed
+	SealCancel <-chan struct{}
+
+	Block MiningBlock
+}
+
+// MiningBlock is the block being built by the mining stages.
+type MiningBlock struct {
+	Header   *types.Header
+	Uncles   []*types.Header
+	Txs      []*types.Transaction
+	Receipts types.Receipts
+
+	LocalTxs  *types.TransactionsByPriceAndNonce
+	RemoteTxs *types.TransactionsByPriceAndNonce
+}
+
+// SpawnMiningCreateBlockStage creates the header of the block to mine on top of the last executed block and
+// collects the pending transactions from the transaction pool, local ones (to be included first) separately
+func SpawnMiningCreateBlockStage(s *StageState, db ethdb.Database, chainConfig *params.ChainConfig, engine consensus.Engine, txPool *core.TxPool, mining *MiningState, quit <-chan struct{}) error {
+	if mining.Etherbase == (common.Address{}) {
+		return errors.New("refusing to mine without etherbase")
+	}
+	if txPool == nil || !txPool.IsStarted() {
+		return errors.New("transaction pool is not started")
+	}
+	executionAt, err := s.ExecutionAt(db)
+	if err != nil {
+		return err
+	}
+	parent := rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, executionAt), executionAt)
+	if parent == nil {
+		return fmt.Errorf("parent block %d not found", executionAt)
+	}
+
+	timestamp := uint64(time.Now().Unix())
+	if parent.Time() >= timestamp {
+		timestamp = parent.Time() + 1
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent, mining.GasFloor, mining.GasCeil),
+		Extra:      common.CopyBytes(mining.Extra),
+		Time:       timestamp,
+		Coinbase:   mining.Etherbase,
+	}
+	if err = engine.Prepare(ChainReader{config: chainConfig, db: db}, header); err != nil {
+		return fmt.Errorf("failed to prepare header for mining: %w", err)
+	}
+	// If we are care about TheDAO hard-fork check whether to override the extra-data or not
+	if daoBlock := chainConfig.DAOForkBlock; daoBlock != nil {
+		// Check whether the block is among the fork extra-override range
+		limit := new(big.Int).Add(daoBlock, params.DAOForkExtraRange)
+		if header.Number.Cmp(daoBlock) >= 0 && header.Number.Cmp(limit) < 0 {
+			// Depending whether we support or oppose the fork, override differently
+			if chainConfig.DAOForkSupport {
+				header.Extra = common.CopyBytes(params.DAOForkBlockExtra)
+			} else if bytes.Equal(header.Extra, params.DAOForkBlockExtra) {
+				header.Extra = []byte{} // If miner opposes, don't let it use the reserved extra-data
+			}
+		}
+	}
+
+	pending, err := txPool.Pending()
+	if err != nil {
+		return fmt.Errorf("failed to fetch pending transactions: %w", err)
+	}
+	// Split the pending transactions into locals and remotes
+	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
+	for _, account := range txPool.Locals() {
+		if txs := remoteTxs[account]; len(txs) > 0 {
+			delete(remoteTxs, account)
+			localTxs[account] = txs
+		}
+	}
+	signer := types.MakeSigner(chainConfig, header.Number)
+
+	mining.Block = MiningBlock{
+		Header:    header,
+		LocalTxs:  types.NewTransactionsByPriceAndNonce(signer, localTxs),
+		RemoteTxs: types.NewTransactionsByPriceAndNonce(signer, remoteTxs),
+	}
+	s.Done()
+	return nil
+}
+
+// SpawnMiningExecStage fills the block being mined with the pending transactions which fit into it, keeping
+// their receipts and state changes, then finalizes the block and writes the plain state and the change sets of
+// the block. The progress of the Execution stage is moved to the mined block, so the following stages treat it
+// as the next block of the chain
+func SpawnMiningExecStage(s *StageState, db ethdb.Database, chainConfig *params.ChainConfig, vmConfig *vm.Config, chainContext core.ChainContext, mining *MiningState, quit <-chan struct{}) error {
+	current := &mining.Block
+	header := current.Header
+	chainContext = miningChainContext{ChainContext: chainContext, author: mining.Etherbase}
+	ibs := state.New(state.NewPlainStateReader(db))
+	if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
+		misc.ApplyDAOHardFork(ibs)
+	}
+
+	gasPool := new(core.GasPool).AddGas(header.GasLimit)
+	noop := state.NewNoopWriter()
+	header.GasUsed = 0
+	for _, txs := range []*types.TransactionsByPriceAndNonce{current.LocalTxs, current.RemoteTxs} {
+		if err := addTransactionsToMiningBlock(current, chainConfig, vmConfig, chainContext, ibs, noop, gasPool, txs, quit); err != nil {
+			return err
+		}
+	}
+
+	// The state of the transactions applied above is committed together with the block rewards, so the block
+	// doesn't need to be executed once more
+	block, err := chainContext.Engine().FinalizeAndAssemble(chainConfig, header, ibs, current.Txs, current.Uncles, current.Receipts)
+	if err != nil {
+		return fmt.Errorf("finalize of block %d failed: %w", header.Number.Uint64(), err)
+	}
+	blockNum := block.NumberU64()
+	stateWriter := state.NewPlainStateWriter(db, db, blockNum)
+	if err = ibs.CommitBlock(chainConfig.WithEIPsFlags(context.Background(), header.Number), stateWriter); err != nil {
+		return fmt.Errorf("committing block %d failed: %w", blockNum, err)
+	}
+	if err = stateWriter.WriteChangeSets(); err != nil {
+		return fmt.Errorf("writing changesets for block %d failed: %w", blockNum, err)
+	}
+	current.Header = block.Header()
+
+	if err = stages.SaveStageProgress(db, stages.Execution, blockNum, nil); err != nil {
+		return err
+	}
+	s.Done()
+	return nil
+}
+
+// miningChainContext reports the miner as the author of the block being mined, since the author of a block
+// can not always be derived from its header before it is sealed (for example, clique recovers it from the seal)
+type miningChainContext struct {
+	core.ChainContext
+	author common.Address
+}
+
+func (c miningChainContext) Engine() consensus.Engine {
+	return miningEngine{Engine: c.ChainContext.Engine(), author: c.author}
+}
+
+type miningEngine struct {
+	consensus.Engine
+	author common.Address
+}
+
+func (e miningEngine) Author(header *types.Header) (common.Address, error) {
+	return e.author, nil
+}
+
+// addTransactionsToMiningBlock applies the transactions to the given state one by one, adding the successful
+// ones to the block. It stops when the block runs out of gas or there are no transactions left
+func addTransactionsToMiningBlock(current *MiningBlock, chainConfig *params.ChainConfig, vmConfig *vm.Config, chainContext core.ChainContext, ibs *state.IntraBlockState, stateWriter state.StateWriter, gasPool *core.GasPool, txs *types.TransactionsByPriceAndNonce, quit <-chan struct{}) error {
+	header := current.Header
+	signer := types.MakeSigner(chainConfig, header.Number)
+	for {
+		if err := common.Stopped(quit); err != nil {
+			return err
+		}
+		// If we don't have enough gas for any further transactions then we're done
+		if gasPool.Gas() < params.TxGas {
+			log.Trace("Not enough gas for further transactions", "have", gasPool, "want", params.TxGas)
+			return nil
+		}
+		// Retrieve the next transaction and abort if all done
+		tx := txs.Peek()
+		if tx == nil {
+			return nil
+		}
+		// Error may be ignored here. The error has already been checked
+		// during transaction acceptance is the transaction pool.
+		from, _ := types.Sender(signer, tx)
+		// Check whether the tx is replay protected. If we're not in the EIP155 hf
+		// phase, start ignoring the sender until we do.
+		if tx.Protected() && !chainConfig.IsEIP155(header.Number) {
+			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", chainConfig.EIP155Block)
+			txs.Pop()
+			continue
+		}
+		// Start executing the transaction
+		ibs.Prepare(tx.Hash(), common.Hash{}, len(current.Txs))
+		snap := ibs.Snapshot()
+		receipt, err := core.ApplyTransaction(chainConfig, chainContext, nil /* author */, gasPool, ibs, stateWriter, header, tx, &header.GasUsed, *vmConfig)
+		if err != nil {
+			ibs.RevertToSnapshot(snap)
+		}
+		switch {
+		case errors.Is(err, core.ErrGasLimitReached):
+			// Pop the current out-of-gas transaction without shifting in the next from the account
+			log.Trace("Gas limit exceeded for current block", "sender", from)
+			txs.Pop()
+
+		case errors.Is(err, core.ErrNonceTooLow):
+			// New head notification data race between the transaction pool and miner, shift
+			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
+			txs.Shift()
+
+		case errors.Is(err, core.ErrNonceTooHigh):
+			// Reorg notification data race between the transaction pool and miner, skip account =
+			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
+			txs.Pop()
+
+		case err == nil:
+			// Everything ok, shift in the next transaction from the same account
+			current.Txs = append(current.Txs, tx)
+			current.Receipts = append(current.Receipts, receipt)
+			txs.Shift()
+
+		default:
+			// Strange error, discard the transaction and get the next in line (note, the
+			// nonce-too-high clause will prevent us from executing in vain).
+			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
+			txs.Shift()
+		}
+	}
+}
+
+// SpawnMiningStateRootStage calculates the state root of the block being mined. It expects the hashed state
+// to be promoted up to the mined block already, and reuses the intermediate hashes of the parent for the parts
+// of the trie which are not touched by the block
+func SpawnMiningStateRootStage(s *StageState, db ethdb.Database, mining *MiningState, datadir string, quit <-chan struct{}) error {
+	header := mining.Block.Header
+	ihProgress, _, err := stages.GetStageProgress(db, stages.IntermediateHashes)
+	if err != nil {
+		return err
+	}
+	unfurl := trie.NewRetainList(0)
+	if ihProgress > 0 {
+		if unfurl, err = changedKeysRetainList(s, db, ihProgress, header.Number.Uint64(), datadir, quit); err != nil {
+			return err
+		}
+	}
+	loader := trie.NewFlatDBTrieLoader(dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
+	if err = loader.Reset(unfurl, nil /* HashCollector */, false); err != nil {
+		return err
+	}
+	root, err := loader.CalcTrieRoot(db, quit)
+	if err != nil {
+		return err
+	}
+	header.Root = root
+	s.Done()
+	return nil
+}
+
+// SpawnMiningFinishStage assembles the block being mined and passes it to the consensus engine for sealing.
+// The sealed block is delivered to mining.ResultCh asynchronously, and only if the engine decides to seal it
+func SpawnMiningFinishStage(s *StageState, db ethdb.Database, chainConfig *params.ChainConfig, engine consensus.Engine, mining *MiningState, quit <-chan struct{}) error {
+	current := &mining.Block
+	block := types.NewBlockWithHeader(current.Header).WithBody(current.Txs, current.Uncles)
+
+	log.Info("Commit new mining work", "number", block.Number(), "sealhash", engine.SealHash(block.Header()),
+		"uncles", len(current.Uncles), "txs", len(current.Txs), "gas", block.GasUsed(), "root", block.Root())
+
+	if err := engine.Seal(consensus.NewCancel(), ChainReader{config: chainConfig, db: db}, block, mining.ResultCh, mining.SealCancel); err != nil {
+		return fmt.Errorf("block sealing failed: %w", err)
+	}
+	s.Done()
+	return nil
+}
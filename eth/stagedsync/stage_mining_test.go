@@ -0,0 +1,85 @@
+package stagedsync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiningStages(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	etherbase := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	config := params.TestChainConfig
+	genesis := (&core.Genesis{
+		Config: config,
+		Alloc:  core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+	}).MustCommit(db)
+	engine := ethash.NewFaker()
+	blockchain, err := core.NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	txCacher := core.NewTxSenderCacher(1)
+	defer txCacher.Close()
+	txPoolConfig := core.DefaultTxPoolConfig
+	txPoolConfig.Journal = ""
+	txPool := core.NewTxPool(txPoolConfig, config, db, txCacher)
+	require.NoError(t, txPool.Start(genesis.GasLimit(), 0))
+	defer txPool.Stop()
+	signer := types.MakeSigner(config, big.NewInt(1))
+	txn, err := types.SignTx(types.NewTransaction(0, recipient, uint256.NewInt().SetUint64(1000), params.TxGas, uint256.NewInt().SetUint64(1), nil), signer, key)
+	require.NoError(t, err)
+	require.NoError(t, txPool.AddLocal(txn))
+
+	results := make(chan consensus.ResultWithContext, 1)
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	mining := &MiningState{
+		Etherbase:  etherbase,
+		GasFloor:   genesis.GasLimit(),
+		GasCeil:    genesis.GasLimit(),
+		ResultCh:   results,
+		SealCancel: make(chan struct{}),
+	}
+	st, err := New(MiningStages(), MiningUnwindOrder()).Prepare(nil, config, blockchain, &vm.Config{}, db, tx, "", ethdb.DefaultStorageMode, "", false, nil, nil, txPool, nil, nil, mining)
+	require.NoError(t, err)
+	require.NoError(t, st.Run(db, tx))
+	tx.Rollback()
+
+	result := <-results
+	block := result.Block
+	assert.Equal(t, uint64(1), block.NumberU64())
+	assert.Equal(t, genesis.Hash(), block.ParentHash())
+	assert.Equal(t, etherbase, block.Coinbase())
+	require.Equal(t, 1, len(block.Transactions()))
+	assert.Equal(t, txn.Hash(), block.Transactions()[0].Hash())
+
+	// The state root and the receipts of the mined block are verified on insertion
+	_, err = InsertBlocksInStages(db, config, engine, []*types.Block{block}, blockchain)
+	require.NoError(t, err)
+	reader := state.NewPlainStateReader(db)
+	acc, err := reader.ReadAccountData(recipient)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), acc.Balance.Uint64())
+	acc, err = reader.ReadAccountData(etherbase)
+	require.NoError(t, err)
+	assert.NotNil(t, acc)
+}
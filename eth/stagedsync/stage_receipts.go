@@ -0,0 +1,45 @@
+package stagedsync
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+func spawnReceiptDownloadStage(s *StageState, u Unwinder, d DownloaderGlue, pid string) error {
+	cont, err := d.SpawnReceiptDownloadStage(pid, s, u)
+	if err != nil {
+		return err
+	}
+	if !cont {
+		s.Done()
+	}
+	return nil
+}
+
+func unwindReceiptDownloadStage(u *UnwindState, s *StageState, db ethdb.Database) error {
+	if u.UnwindPoint >= s.BlockNumber {
+		return u.Done(db)
+	}
+	log.Info("Unwind Receipts stage", "from", s.BlockNumber, "to", u.UnwindPoint)
+	batch := db.NewBatch()
+	defer batch.Rollback()
+	if err := db.Walk(dbutils.BlockReceiptsPrefix, dbutils.EncodeBlockNumber(u.UnwindPoint+1), 0, func(k, _ []byte) (bool, error) {
+		if err1 := batch.Delete(dbutils.BlockReceiptsPrefix, common.CopyBytes(k)); err1 != nil {
+			return false, fmt.Errorf("unwind Receipts: delete receipts: %v", err1)
+		}
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("unwind Receipts: walking receipts: %v", err)
+	}
+	if err := u.Done(batch); err != nil {
+		return fmt.Errorf("unwind Receipts: reset: %v", err)
+	}
+	if _, err := batch.Commit(); err != nil {
+		return fmt.Errorf("unwind Receipts: failed to write db commit: %v", err)
+	}
+	return nil
+}
@@ -1,14 +1,18 @@
 package stagedsync
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 
+	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/vm"
 	"github.com/ledgerwatch/turbo-geth/crypto/secp256k1"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/statediff"
 )
 
 // StageParameters contains the stage that stages receives at runtime when initializes.
@@ -34,6 +38,11 @@ type StageParameters struct {
 	poolStart        func() error
 	changeSetHook    ChangeSetHook
 	prefetchedBlocks *PrefetchedBlocks
+	stateDiffs       *statediff.Publisher
+	stateCache       *StateCache
+	sentry           *SentryDownload
+	// mining is the configuration and the block being built by the mining stages, nil for the sync stages
+	mining *MiningState
 }
 
 // StageBuilder represent an object to create a single stage for staged sync
@@ -56,6 +65,16 @@ func (bb StageBuilders) Build(world StageParameters) []*Stage {
 	return stages
 }
 
+// byID returns the builder of the stage with the given ID, it panics if there is no such stage in the list
+func (bb StageBuilders) byID(id stages.SyncStage) StageBuilder {
+	for _, builder := range bb {
+		if bytes.Equal(builder.ID, id) {
+			return builder
+		}
+	}
+	panic(fmt.Sprintf("stage builder not found: %s", id))
+}
+
 // DefaultStages contains the list of default stage builders that are used by turbo-geth.
 func DefaultStages() StageBuilders {
 	return []StageBuilder{
@@ -66,10 +85,22 @@ func DefaultStages() StageBuilders {
 					ID:          stages.Headers,
 					Description: "Download headers",
 					ExecFunc: func(s *StageState, u Unwinder) error {
+						if world.sentry != nil {
+							ctx, cancel := quitContext(world.QuitCh)
+							defer cancel()
+							return HeadersForward(s, u, ctx, world.db, world.sentry.Headers, world.chainConfig, world.sentry.Engine, world.sentry.RequestHeaders, world.sentry.HeadersWakeUp)
+						}
 						return SpawnHeaderDownloadStage(s, u, world.d, world.headersFetchers)
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
-						return u.Done(world.db)
+						var engine consensus.Engine
+						if world.chainContext != nil {
+							engine = world.chainContext.Engine()
+						}
+						if world.sentry != nil {
+							return HeadersUnwind(u, s, world.db, engine)
+						}
+						return UnwindHeadersStage(u, world.db, engine)
 					},
 				}
 			},
@@ -96,6 +127,11 @@ func DefaultStages() StageBuilders {
 					ID:          stages.Bodies,
 					Description: "Download block bodies",
 					ExecFunc: func(s *StageState, u Unwinder) error {
+						if world.sentry != nil {
+							ctx, cancel := quitContext(world.QuitCh)
+							defer cancel()
+							return BodiesForward(s, ctx, world.db, world.sentry.Bodies, world.sentry.RequestBodies, world.sentry.BodiesWakeUp, 5 /* timeout */)
+						}
 						return spawnBodyDownloadStage(s, u, world.d, world.pid, world.prefetchedBlocks)
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
@@ -140,10 +176,10 @@ func DefaultStages() StageBuilders {
 					ID:          stages.Execution,
 					Description: "Execute blocks w/o hash checks",
 					ExecFunc: func(s *StageState, u Unwinder) error {
-						return SpawnExecuteBlocksStage(s, world.TX, world.chainConfig, world.chainContext, world.vmConfig, 0 /* limit (meaning no limit) */, world.QuitCh, world.storageMode.Receipts, world.hdd, world.changeSetHook)
+						return SpawnExecuteBlocksStage(s, world.TX, world.chainConfig, world.chainContext, world.vmConfig, 0 /* limit (meaning no limit) */, world.QuitCh, world.storageMode.Receipts, world.hdd, world.changeSetHook, world.stateDiffs, world.stateCache)
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
-						return UnwindExecutionStage(u, s, world.TX, world.storageMode.Receipts)
+						return UnwindExecutionStage(u, s, world.TX, world.storageMode.Receipts, world.stateDiffs, world.stateCache)
 					},
 				}
 			},
@@ -293,3 +329,147 @@ func DefaultUnwindOrder() UnwindOrder {
 		7, 8, 9,
 	}
 }
+
+// ReceiptsStages contains the list of stage builders for a node which downloads receipts from the peers instead of
+// executing the blocks. Such a node has no state, but it can serve blocks, transactions and receipts.
+func ReceiptsStages() StageBuilders {
+	defaultStages := DefaultStages()
+	return []StageBuilder{
+		defaultStages.byID(stages.Headers),
+		defaultStages.byID(stages.BlockHashes),
+		defaultStages.byID(stages.Bodies),
+		defaultStages.byID(stages.Senders),
+		{
+			ID: stages.Receipts,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.Receipts,
+					Description: "Download receipts",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return spawnReceiptDownloadStage(s, u, world.d, world.pid)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return unwindReceiptDownloadStage(u, s, world.db)
+					},
+				}
+			},
+		},
+		defaultStages.byID(stages.TxLookup),
+		{
+			ID: stages.Finish,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.Finish,
+					Description: "Final: update current block for the RPC API",
+					ExecFunc: func(s *StageState, _ Unwinder) error {
+						receiptsAt, _, err := stages.GetStageProgress(world.TX, stages.Receipts)
+						if err != nil {
+							return err
+						}
+						return s.DoneAndUpdate(world.TX, receiptsAt)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						receiptsAt, _, err := stages.GetStageProgress(world.TX, stages.Receipts)
+						if err != nil {
+							return err
+						}
+						return s.DoneAndUpdate(world.TX, receiptsAt)
+					},
+				}
+			},
+		},
+	}
+}
+
+// ReceiptsUnwindOrder contains the unwind order for `ReceiptsStages()`.
+func ReceiptsUnwindOrder() UnwindOrder {
+	return []int{
+		0, 1, 2, 3, 4, 5,
+	}
+}
+
+// DownloadStages contains the list of stage builders which only download the headers and the bodies through the
+// sentries, without executing the blocks (see `headers download`)
+func DownloadStages() StageBuilders {
+	defaultStages := DefaultStages()
+	return []StageBuilder{
+		defaultStages.byID(stages.Headers),
+		defaultStages.byID(stages.BlockHashes),
+		defaultStages.byID(stages.Bodies),
+	}
+}
+
+// DownloadUnwindOrder contains the unwind order for `DownloadStages()`.
+func DownloadUnwindOrder() UnwindOrder {
+	return []int{
+		0, 1, 2,
+	}
+}
+
+// MiningStages contains the list of stage builders for producing a block on top of the last executed one. They are
+// supposed to run in a transaction which is rolled back afterwards: the block is sealed asynchronously by the
+// consensus engine and gets into the chain only if it is inserted like any other block.
+func MiningStages() StageBuilders {
+	defaultStages := DefaultStages()
+	return []StageBuilder{
+		{
+			ID: stages.MiningCreateBlock,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningCreateBlock,
+					Description: "Mining: construct new block from tx pool",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningCreateBlockStage(s, world.TX, world.chainConfig, world.chainContext.Engine(), world.txPool, world.mining, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error { return nil },
+				}
+			},
+		},
+		{
+			ID: stages.MiningExecution,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningExecution,
+					Description: "Mining: execute new block from tx pool",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningExecStage(s, world.TX, world.chainConfig, world.vmConfig, world.chainContext, world.mining, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error { return nil },
+				}
+			},
+		},
+		defaultStages.byID(stages.HashState),
+		{
+			ID: stages.MiningStateRoot,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningStateRoot,
+					Description: "Mining: calculate state root",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningStateRootStage(s, world.TX, world.mining, world.datadir, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error { return nil },
+				}
+			},
+		},
+		{
+			ID: stages.MiningFinish,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningFinish,
+					Description: "Mining: create and propagate valid block",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningFinishStage(s, world.TX, world.chainConfig, world.chainContext.Engine(), world.mining, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error { return nil },
+				}
+			},
+		},
+	}
+}
+
+// MiningUnwindOrder contains the unwind order for `MiningStages()`. The mining stages are never unwound, the
+// transaction they run in is rolled back instead.
+func MiningUnwindOrder() UnwindOrder {
+	return []int{}
+}
@@ -1,23 +1,53 @@
 package stagedsync
 
 import (
+	"context"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/vm"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/bodydownload"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/turbo-geth/turbo/statediff"
 )
 
 const prof = false // whether to profile
 
 type StagedSync struct {
 	PrefetchedBlocks *PrefetchedBlocks
-	stageBuilders    StageBuilders
-	unwindOrder      UnwindOrder
+	// StateDiffs publishes the state diffs of the Execution stage, nil if they aren't published
+	StateDiffs *statediff.Publisher
+	// StateCache keeps the state read and written by the Execution stage across the blocks, nil if it isn't cached
+	StateCache *StateCache
+	// Status reports the progress of the stages
+	Status *Status
+	// Sentry downloads the headers and the bodies through the sentries instead of eth/downloader, nil if the p2p
+	// layer runs in the node
+	Sentry        *SentryDownload
+	stageBuilders StageBuilders
+	unwindOrder   UnwindOrder
+}
+
+// SentryDownload - the header and body download through the sentries (see eth.ControlServerImpl). The requests of
+// Headers and Bodies stages are sent out with RequestHeaders and RequestBodies, and the headers and the bodies
+// delivered by the peers are fed into Headers and Bodies, which then signal the wake up channels
+type SentryDownload struct {
+	Headers        *headerdownload.HeaderDownload
+	RequestHeaders func(context.Context, *headerdownload.HeaderRequest) bool
+	HeadersWakeUp  chan struct{}
+	Bodies         *bodydownload.BodyDownload
+	RequestBodies  func(context.Context, *bodydownload.BodyRequest) bool
+	BodiesWakeUp   chan struct{}
+	// Engine verifies the headers inserted into the database
+	Engine consensus.Engine
 }
 
 func New(stages StageBuilders, unwindOrder UnwindOrder) *StagedSync {
 	return &StagedSync{
 		PrefetchedBlocks: NewPrefetchedBlocks(),
+		Status:           NewStatus(),
 		stageBuilders:    stages,
 		unwindOrder:      unwindOrder,
 	}
@@ -39,6 +69,7 @@ func (stagedSync *StagedSync) Prepare(
 	txPool *core.TxPool,
 	poolStart func() error,
 	changeSetHook ChangeSetHook,
+	mining *MiningState,
 ) (*State, error) {
 	stages := stagedSync.stageBuilders.Build(
 		StageParameters{
@@ -58,6 +89,10 @@ func (stagedSync *StagedSync) Prepare(
 			changeSetHook:    changeSetHook,
 			hdd:              hdd,
 			prefetchedBlocks: stagedSync.PrefetchedBlocks,
+			stateDiffs:       stagedSync.StateDiffs,
+			stateCache:       stagedSync.StateCache,
+			sentry:           stagedSync.Sentry,
+			mining:           mining,
 		},
 	)
 	state := NewState(stages)
@@ -71,5 +106,9 @@ func (stagedSync *StagedSync) Prepare(
 	if err := state.LoadUnwindInfo(db); err != nil {
 		return nil, err
 	}
+	if err := stagedSync.Status.reset(stages, db); err != nil {
+		return nil, err
+	}
+	state.status = stagedSync.Status
 	return state, nil
 }
@@ -38,6 +38,7 @@ var (
 	Bodies              SyncStage = []byte("Bodies")              // Block bodies are downloaded, TxHash and UncleHash are getting verified
 	Senders             SyncStage = []byte("Senders")             // "From" recovered from signatures, bodies re-written
 	Execution           SyncStage = []byte("Execution")           // Executing each block w/o buildinf a trie
+	Receipts            SyncStage = []byte("Receipts")            // Receipts are downloaded instead of executing blocks, verified against ReceiptHash
 	IntermediateHashes  SyncStage = []byte("IntermediateHashes")  // Generate intermediate hashes, calculate the state root hash
 	HashState           SyncStage = []byte("HashState")           // Apply Keccak256 to all the keys in the state
 	AccountHistoryIndex SyncStage = []byte("AccountHistoryIndex") // Generating history index for accounts
@@ -45,6 +46,13 @@ var (
 	TxLookup            SyncStage = []byte("TxLookup")            // Generating transactions lookup index
 	TxPool              SyncStage = []byte("TxPool")              // Starts Backend
 	Finish              SyncStage = []byte("Finish")              // Nominal stage after all other stages
+
+	// Mining stages run in a transaction which is rolled back afterwards, so their progress
+	// is never persisted and they are not listed in AllStages
+	MiningCreateBlock SyncStage = []byte("MiningCreateBlock") // Create the header of the block to mine and collect the pending transactions
+	MiningExecution   SyncStage = []byte("MiningExecution")   // Execute the transactions of the block to mine
+	MiningStateRoot   SyncStage = []byte("MiningStateRoot")   // Calculate the state root of the block to mine
+	MiningFinish      SyncStage = []byte("MiningFinish")      // Assemble the block to mine and pass it to the consensus engine for sealing
 )
 
 var AllStages = []SyncStage{
@@ -53,6 +61,7 @@ var AllStages = []SyncStage{
 	Bodies,
 	Senders,
 	Execution,
+	Receipts,
 	IntermediateHashes,
 	HashState,
 	AccountHistoryIndex,
@@ -17,6 +17,7 @@ type State struct {
 	stages       []*Stage
 	unwindOrder  []*Stage
 	currentStage uint
+	status       *Status
 
 	beforeStageRun    map[string]func() error
 	onBeforeUnwind    func(stages.SyncStage) error
@@ -220,11 +221,21 @@ func (s *State) runStage(stage *Stage, db ethdb.Getter, tx ethdb.Getter) error {
 	message := fmt.Sprintf("Sync stage %d/%d. %v...", index+1, s.Len(), stage.Description)
 	log.Info(message)
 
+	s.status.stageStarted(stage.ID, stageState.BlockNumber)
+	defer s.status.stageFinished()
+
 	err = stage.ExecFunc(stageState, s)
 	if err != nil {
 		return err
 	}
 
+	progress, _, err := stages.GetStageProgress(db, stage.ID)
+	if err != nil {
+		return err
+	}
+	metricsOf(stage.ID).ran(start, stageState.BlockNumber, progress)
+	s.status.progress(stage.ID, progress)
+
 	if time.Since(start) > 30*time.Second {
 		log.Info(fmt.Sprintf("%s DONE!", message))
 	}
@@ -261,6 +272,8 @@ func (s *State) UnwindStage(unwind *UnwindState, db ethdb.GetterPutter, tx ethdb
 	if err != nil {
 		return err
 	}
+	metricsOf(stage.ID).unwound(start, unwind.UnwindPoint)
+	s.status.unwound(stage.ID, unwind.UnwindPoint)
 
 	if time.Since(start) > 30*time.Second {
 		log.Info("Unwinding... DONE!")
@@ -0,0 +1,130 @@
+package stagedsync
+
+import (
+	"fmt"
+
+	"github.com/VictoriaMetrics/fastcache"
+
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+)
+
+// StateCache - the accounts, the storage and the code sizes read and written by the Execution stage, kept across
+// the blocks and the runs of the stage. The plain state writer updates the caches along with the database.
+// They are dropped when the plain state changes otherwise: on unwind, and when the Execution stage isn't at
+// the block the caches were left at, because the transaction of the stage was rolled back.
+type StateCache struct {
+	accounts    *cacheWithStats
+	storage     *cacheWithStats
+	codeSizes   *cacheWithStats
+	blockNumber uint64 // the caches hold the plain state after this block
+	valid       bool   // false while the stage runs, until it has committed blockNumber
+}
+
+// NewStateCache - size is in megabytes, a quarter for the accounts and most of the rest for the storage.
+// fastcache allocates at least 32MB for each of the caches.
+func NewStateCache(size int) *StateCache {
+	bytes := size * 1024 * 1024
+	prefix := "stages/" + string(stages.Execution) + "/cache/"
+	return &StateCache{
+		accounts:  newCacheWithStats(prefix+"accounts/", bytes/4),
+		storage:   newCacheWithStats(prefix+"storage/", bytes-bytes/4-bytes/16),
+		codeSizes: newCacheWithStats(prefix+"codesizes/", bytes/16),
+	}
+}
+
+// attach makes the reader and the writer of the block use the caches
+func (c *StateCache) attach(r *state.PlainStateReader, w *state.PlainStateWriter) {
+	if c == nil {
+		return
+	}
+	r.SetAccountCache(c.accounts.cache)
+	r.SetStorageCache(c.storage.cache)
+	r.SetCodeSizeCache(c.codeSizes.cache)
+	w.SetAccountCache(c.accounts.cache)
+	w.SetStorageCache(c.storage.cache)
+	w.SetCodeSizeCache(c.codeSizes.cache)
+}
+
+// begin is called before the stage executes the blocks after blockNumber
+func (c *StateCache) begin(blockNumber uint64) {
+	if c == nil {
+		return
+	}
+	if !c.valid || c.blockNumber != blockNumber {
+		c.reset()
+	}
+	c.valid = false
+}
+
+// done is called when the stage has written the state after blockNumber
+func (c *StateCache) done(blockNumber uint64) {
+	if c == nil {
+		return
+	}
+	c.blockNumber = blockNumber
+	c.valid = true
+}
+
+// reset drops the cached state
+func (c *StateCache) reset() {
+	if c == nil {
+		return
+	}
+	for _, cache := range []*cacheWithStats{c.accounts, c.storage, c.codeSizes} {
+		cache.reset()
+	}
+	c.valid = false
+}
+
+// hitRates reports the hit rates of the caches since the previous call, for the logs, and updates the metrics
+func (c *StateCache) hitRates() []interface{} {
+	if c == nil {
+		return nil
+	}
+	return []interface{}{
+		"accounts", c.accounts.hitRate(),
+		"storage", c.storage.hitRate(),
+		"codeSizes", c.codeSizes.hitRate(),
+	}
+}
+
+// cacheWithStats exports stages/Execution/cache/<cache>/hits, misses and size
+type cacheWithStats struct {
+	cache    *fastcache.Cache
+	hits     metrics.Counter
+	misses   metrics.Counter
+	size     metrics.Gauge
+	reported fastcache.Stats // the counters of the cache at the previous hitRate
+}
+
+func newCacheWithStats(prefix string, size int) *cacheWithStats {
+	return &cacheWithStats{
+		cache:  fastcache.New(size),
+		hits:   metrics.GetOrRegisterCounter(prefix+"hits", nil),
+		misses: metrics.GetOrRegisterCounter(prefix+"misses", nil),
+		size:   metrics.GetOrRegisterGauge(prefix+"size", nil),
+	}
+}
+
+func (c *cacheWithStats) hitRate() string {
+	var s fastcache.Stats
+	c.cache.UpdateStats(&s)
+	calls, misses := s.GetCalls-c.reported.GetCalls, s.Misses-c.reported.Misses
+	c.reported = s
+	c.hits.Inc(int64(calls - misses))
+	c.misses.Inc(int64(misses))
+	c.size.Update(int64(s.BytesSize))
+	if calls == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(calls-misses)/float64(calls))
+}
+
+func (c *cacheWithStats) reset() {
+	c.hitRate() // the counters of fastcache are reset along with the entries
+	c.cache.Reset()
+	c.reported = fastcache.Stats{}
+	c.size.Update(0)
+}
@@ -0,0 +1,85 @@
+package stagedsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestStateCache(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	address := common.HexToAddress("0x1234")
+	key := common.HexToHash("0x01")
+	account := accounts.NewAccount()
+	account.Incarnation = 1
+	account.Nonce = 5
+
+	cache := NewStateCache(1)
+	block := func(blockNumber uint64) (*state.PlainStateReader, *state.PlainStateWriter) {
+		r, w := state.NewPlainStateReader(db), state.NewPlainStateWriter(db, db, blockNumber)
+		cache.attach(r, w)
+		return r, w
+	}
+
+	// the writes of a block are read by the next ones from the cache
+	cache.begin(0)
+	_, w := block(1)
+	require.NoError(w.UpdateAccountData(ctx, address, &accounts.Account{}, &account))
+	require.NoError(w.WriteAccountStorage(ctx, address, 1, &key, uint256.NewInt(), uint256.NewInt().SetUint64(7)))
+	cache.done(1)
+	cache.hitRates()
+
+	cache.begin(1)
+	r, _ := block(2)
+	acc, err := r.ReadAccountData(address)
+	require.NoError(err)
+	require.Equal(uint64(5), acc.Nonce)
+	v, err := r.ReadAccountStorage(address, 1, &key)
+	require.NoError(err)
+	require.Equal([]byte{7}, v)
+	cache.done(2)
+	require.Equal([]interface{}{"accounts", "100.0%", "storage", "100.0%", "codeSizes", "-"}, cache.hitRates())
+
+	// the state was changed without the cache, the transaction of the run was rolled back for example
+	account.Nonce = 6
+	require.NoError(state.NewPlainStateWriter(db, db, 1).UpdateAccountData(ctx, address, &accounts.Account{}, &account))
+	cache.begin(1)
+	r, _ = block(2)
+	acc, err = r.ReadAccountData(address)
+	require.NoError(err)
+	require.Equal(uint64(6), acc.Nonce)
+	require.Equal([]interface{}{"accounts", "0.0%", "storage", "-", "codeSizes", "-"}, cache.hitRates())
+	cache.done(2)
+
+	// the unwind drops the cached state
+	require.NoError(stages.SaveStageProgress(db, stages.Execution, 2, nil))
+	u := &UnwindState{Stage: stages.Execution, UnwindPoint: 1}
+	s := &StageState{Stage: stages.Execution, BlockNumber: 2}
+	require.NoError(UnwindExecutionStage(u, s, db, false, nil, cache))
+	cache.begin(1)
+	r, _ = block(2)
+	_, err = r.ReadAccountStorage(address, 1, &key)
+	require.NoError(err)
+	require.Equal([]interface{}{"accounts", "-", "storage", "0.0%", "codeSizes", "-"}, cache.hitRates())
+}
+
+func TestStateCacheNil(t *testing.T) {
+	var cache *StateCache
+	cache.begin(1)
+	cache.attach(state.NewPlainStateReader(nil), state.NewPlainStateWriter(nil, nil, 1))
+	cache.done(2)
+	cache.reset()
+	require.Nil(t, cache.hitRates())
+}
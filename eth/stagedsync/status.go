@@ -0,0 +1,194 @@
+package stagedsync
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+// Status keeps the progress and the throughput of the stages for the sync status reports.
+// The methods can be called on a nil Status, they do nothing then.
+type Status struct {
+	lock         sync.Mutex
+	stages       []*stageStatus
+	current      *stageStatus // nil between the stage runs
+	highestBlock uint64
+	now          func() time.Time
+}
+
+type stageStatus struct {
+	id              stages.SyncStage
+	description     string
+	disabled        bool
+	blockNumber     uint64
+	startBlock      uint64 // at the start of the current or the last run
+	blocksPerSecond float64
+	unwinds         uint64
+	startedAt       time.Time
+	updatedAt       time.Time
+}
+
+func NewStatus() *Status {
+	return &Status{now: time.Now}
+}
+
+// SetHighestBlock sets the highest block known from the peers, the stages are expected to reach it
+func (st *Status) SetHighestBlock(blockNum uint64) {
+	if st == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	st.highestBlock = blockNum
+}
+
+// reset takes the stages of a new sync cycle and their progress. The throughput and the unwinds
+// of the previous cycles are kept.
+func (st *Status) reset(list []*Stage, db ethdb.Getter) error {
+	if st == nil {
+		return nil
+	}
+	statuses := make([]*stageStatus, len(list))
+	for i, stage := range list {
+		blockNum, _, err := stages.GetStageProgress(db, stage.ID)
+		if err != nil {
+			return err
+		}
+		statuses[i] = &stageStatus{id: stage.ID, description: stage.Description, disabled: stage.Disabled, blockNumber: blockNum}
+	}
+
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	for _, s := range statuses {
+		if prev := st.find(s.id); prev != nil {
+			s.startBlock, s.blocksPerSecond, s.unwinds = prev.startBlock, prev.blocksPerSecond, prev.unwinds
+			s.startedAt, s.updatedAt = prev.startedAt, prev.updatedAt
+		}
+	}
+	st.stages, st.current = statuses, nil
+	return nil
+}
+
+func (st *Status) stageStarted(id stages.SyncStage, blockNum uint64) {
+	if st == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	s := st.find(id)
+	if s == nil {
+		return
+	}
+	s.blockNumber, s.startBlock, s.startedAt = blockNum, blockNum, st.now()
+	st.current = s
+}
+
+// progress records the progress of the running stage. The throughput is only updated when the stage moves
+// forward, so that the runs at the head of the chain with nothing to do keep the last known one.
+func (st *Status) progress(id stages.SyncStage, blockNum uint64) {
+	if st == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	s := st.find(id)
+	if s == nil || s.blockNumber == blockNum {
+		return
+	}
+	now := st.now()
+	s.blockNumber, s.updatedAt = blockNum, now
+	if elapsed := now.Sub(s.startedAt).Seconds(); blockNum > s.startBlock && elapsed > 0 {
+		s.blocksPerSecond = float64(blockNum-s.startBlock) / elapsed
+	}
+}
+
+func (st *Status) stageFinished() {
+	if st == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	st.current = nil
+}
+
+func (st *Status) unwound(id stages.SyncStage, unwindPoint uint64) {
+	if st == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	s := st.find(id)
+	if s == nil {
+		return
+	}
+	s.blockNumber, s.updatedAt = unwindPoint, st.now()
+	s.unwinds++
+}
+
+func (st *Status) find(id stages.SyncStage) *stageStatus {
+	for _, s := range st.stages {
+		if bytes.Equal(s.id, id) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Report returns the progress of the stages. The completion time is estimated by the time each enabled stage
+// needs to reach the highest block known, at its last throughput; the stages run one after another.
+func (st *Status) Report() *ethdb.SyncStatus {
+	if st == nil {
+		return &ethdb.SyncStatus{}
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	target := st.highestBlock
+	for _, s := range st.stages {
+		if s.blockNumber > target {
+			target = s.blockNumber
+		}
+	}
+
+	report := &ethdb.SyncStatus{HighestBlock: target, Stages: make([]ethdb.SyncStageStatus, len(st.stages))}
+	if st.current != nil {
+		report.CurrentStage = string(st.current.id)
+	}
+	var left float64
+	known := true
+	for i, s := range st.stages {
+		report.Stages[i] = ethdb.SyncStageStatus{
+			Stage:           string(s.id),
+			Description:     s.description,
+			Disabled:        s.disabled,
+			BlockNumber:     s.blockNumber,
+			BlocksPerSecond: s.blocksPerSecond,
+			Unwinds:         s.unwinds,
+			StartedAt:       timeOrNil(s.startedAt),
+			UpdatedAt:       timeOrNil(s.updatedAt),
+		}
+		if s.disabled || s.blockNumber >= target {
+			continue
+		}
+		if s.blocksPerSecond == 0 {
+			known = false
+			continue
+		}
+		left += float64(target-s.blockNumber) / s.blocksPerSecond
+	}
+	if known {
+		eta := st.now().Add(time.Duration(left * float64(time.Second)))
+		report.ETA = &eta
+	}
+	return report
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,103 @@
+package stagedsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestStatusReport(t *testing.T) {
+	require := require.New(t)
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	clock := time.Unix(1600000000, 0)
+	status := NewStatus()
+	status.now = func() time.Time { return clock }
+
+	var currentStages []string
+	run := func(s *StageState, took time.Duration) error {
+		currentStages = append(currentStages, status.Report().CurrentStage)
+		clock = clock.Add(took)
+		return s.DoneAndUpdate(db, 100)
+	}
+	unwind := func(u *UnwindState, s *StageState) error {
+		return u.Done(db)
+	}
+	list := []*Stage{
+		{
+			ID:          stages.Headers,
+			Description: "Downloading headers",
+			ExecFunc:    func(s *StageState, _ Unwinder) error { return run(s, 10*time.Second) },
+			UnwindFunc:  unwind,
+		},
+		{
+			ID:          stages.Bodies,
+			Description: "Downloading block bodies",
+			ExecFunc:    func(s *StageState, _ Unwinder) error { return run(s, 20*time.Second) },
+			UnwindFunc:  unwind,
+		},
+		{
+			ID:          stages.Senders,
+			Description: "Recovering senders from tx signatures",
+			Disabled:    true,
+		},
+	}
+
+	// the throughput is not known before the first run
+	state := NewState(list)
+	state.unwindOrder = []*Stage{list[1], list[0]}
+	state.status = status
+	require.NoError(status.reset(list, db))
+	status.SetHighestBlock(200)
+	report := status.Report()
+	require.Len(report.Stages, 3)
+	require.Nil(report.ETA)
+
+	require.NoError(state.Run(db, db))
+	require.Equal([]string{"Headers", "Bodies"}, currentStages)
+	report = status.Report()
+	require.Empty(report.CurrentStage)
+	require.Equal(uint64(200), report.HighestBlock)
+	require.Equal(uint64(100), report.Stages[0].BlockNumber)
+	require.Equal(10.0, report.Stages[0].BlocksPerSecond)
+	require.Equal(5.0, report.Stages[1].BlocksPerSecond)
+	require.True(report.Stages[2].Disabled)
+	require.Equal(clock, *report.Stages[1].UpdatedAt)
+	require.Nil(report.Stages[2].StartedAt)
+	// 100 blocks of each stage: 10 seconds of Headers and 20 seconds of Bodies
+	require.NotNil(report.ETA)
+	require.Equal(clock.Add(30*time.Second), *report.ETA)
+
+	// the next cycle keeps the throughput, the unwinds are counted
+	state = NewState(list)
+	state.unwindOrder = []*Stage{list[1], list[0]}
+	state.status = status
+	require.NoError(status.reset(list, db))
+	require.NoError(state.UnwindTo(50, db))
+	require.NoError(state.Run(db, db))
+	report = status.Report()
+	require.Equal(uint64(1), report.Stages[0].Unwinds)
+	require.Equal(uint64(1), report.Stages[1].Unwinds)
+	require.Equal(uint64(100), report.Stages[1].BlockNumber)
+	require.Equal(5.0, report.Stages[0].BlocksPerSecond)
+	require.Equal(2.5, report.Stages[1].BlocksPerSecond)
+
+	// all the stages are at the highest block
+	status.SetHighestBlock(100)
+	require.Equal(clock, *status.Report().ETA)
+}
+
+func TestStatusNil(t *testing.T) {
+	var status *Status
+	status.SetHighestBlock(1)
+	status.stageStarted(stages.Headers, 0)
+	status.progress(stages.Headers, 1)
+	status.unwound(stages.Headers, 0)
+	status.stageFinished()
+	require.Empty(t, status.Report().Stages)
+}
@@ -3,4 +3,5 @@ package stagedsync
 type DownloaderGlue interface {
 	SpawnHeaderDownloadStage([]func() error, *StageState, Unwinder) error
 	SpawnBodyDownloadStage(string, *StageState, Unwinder, *PrefetchedBlocks) (bool, error)
+	SpawnReceiptDownloadStage(string, *StageState, Unwinder) (bool, error)
 }
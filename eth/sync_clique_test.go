@@ -0,0 +1,333 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/downloader"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/event"
+	"github.com/ledgerwatch/turbo-geth/p2p"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+const (
+	cliqueExtraVanity = 32
+	cliqueExtraSeal   = crypto.SignatureLength
+)
+
+// cliqueTester generates clique chains from the same genesis, signed by the authorized keys
+type cliqueTester struct {
+	config  *params.ChainConfig
+	gspec   *core.Genesis
+	signers []*ecdsa.PrivateKey // initial signers, sorted by address
+	keys    map[common.Address]*ecdsa.PrivateKey
+}
+
+func newCliqueTester(t *testing.T, signers int) *cliqueTester {
+	config := *params.AllCliqueProtocolChanges
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 8}
+	ct := &cliqueTester{
+		config: &config,
+		keys:   make(map[common.Address]*ecdsa.PrivateKey),
+	}
+	for i := 0; i < signers; i++ {
+		ct.signers = append(ct.signers, ct.newKey(t))
+	}
+	ct.sort(ct.signers)
+	extra := make([]byte, cliqueExtraVanity, cliqueExtraVanity+signers*common.AddressLength+cliqueExtraSeal)
+	for _, key := range ct.signers {
+		extra = append(extra, crypto.PubkeyToAddress(key.PublicKey).Bytes()...)
+	}
+	ct.gspec = &core.Genesis{
+		Config:    ct.config,
+		ExtraData: append(extra, make([]byte, cliqueExtraSeal)...),
+		Alloc:     core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}},
+	}
+	return ct
+}
+
+func (ct *cliqueTester) newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct.keys[crypto.PubkeyToAddress(key.PublicKey)] = key
+	return key
+}
+
+func (ct *cliqueTester) sort(keys []*ecdsa.PrivateKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(crypto.PubkeyToAddress(keys[i].PublicKey).Bytes(), crypto.PubkeyToAddress(keys[j].PublicKey).Bytes()) < 0
+	})
+}
+
+// chain generates n blocks sending value to the recipient in every block. The signers cast the votes
+// to authorize the addresses in the votes map by block number, and the block in the impostors map by
+// number is signed by the given key instead of an authorized signer.
+func (ct *cliqueTester) chain(t *testing.T, n int, recipient common.Address, votes map[uint64]common.Address, impostors map[uint64]*ecdsa.PrivateKey) []*types.Block {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	genesis := ct.gspec.MustCommit(db)
+	signer := types.MakeSigner(ct.config, big.NewInt(1))
+	blocks, _, err := core.GenerateChain(ct.config, genesis, clique.New(ct.config.Clique, db), db, n, func(i int, gen *core.BlockGen) {
+		gen.SetDifficulty(big.NewInt(2)) // the difficulty is set again when the block is signed
+		// No fees, the clique signer would earn them instead of the coinbase of the generated blocks
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(testBank), recipient, uint256.NewInt().SetUint64(1000), params.TxGas, uint256.NewInt(), nil), signer, testBankKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen.AddTx(tx)
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := append([]*ecdsa.PrivateKey{}, ct.signers...)
+	recents := make(map[common.Address]uint64)
+	tally := make(map[common.Address]map[common.Address]bool)
+	for i, block := range blocks {
+		number := block.NumberU64()
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		extra := make([]byte, cliqueExtraVanity)
+		if number%ct.config.Clique.Epoch == 0 {
+			for _, key := range signers {
+				extra = append(extra, crypto.PubkeyToAddress(key.PublicKey).Bytes()...)
+			}
+			tally = make(map[common.Address]map[common.Address]bool)
+		} else if candidate, ok := votes[number]; ok {
+			header.Coinbase = candidate
+			header.Nonce = types.EncodeNonce(^uint64(0))
+		}
+		header.Extra = append(extra, make([]byte, cliqueExtraSeal)...)
+
+		// The signer in turn, or the first one which has not signed recently
+		key, inTurn := signers[number%uint64(len(signers))], true
+		for j := 0; recentlySigned(recents, key, number, len(signers)); j++ {
+			key, inTurn = signers[j], false
+		}
+		if impostor, ok := impostors[number]; ok {
+			key = impostor
+		}
+		header.Difficulty = big.NewInt(1)
+		if inTurn {
+			header.Difficulty = big.NewInt(2)
+		}
+		sig, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		copy(header.Extra[len(header.Extra)-cliqueExtraSeal:], sig)
+		blocks[i] = block.WithSeal(header)
+
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		recents[address] = number
+		if candidate, ok := votes[number]; ok && number%ct.config.Clique.Epoch != 0 {
+			if tally[candidate] == nil {
+				tally[candidate] = make(map[common.Address]bool)
+			}
+			tally[candidate][address] = true
+			if len(tally[candidate]) > len(signers)/2 {
+				signers = append(signers, ct.keys[candidate])
+				ct.sort(signers)
+				delete(tally, candidate)
+			}
+		}
+	}
+	return blocks
+}
+
+func recentlySigned(recents map[common.Address]uint64, key *ecdsa.PrivateKey, number uint64, signers int) bool {
+	last, ok := recents[crypto.PubkeyToAddress(key.PublicKey)]
+	limit := uint64(signers/2 + 1)
+	return ok && (number < limit || last > number-limit)
+}
+
+// newProtocolManager creates a protocol manager in staged sync mode serving the blocks, which are written
+// to the database without being verified nor executed
+func (ct *cliqueTester) newProtocolManager(t *testing.T, blocks []*types.Block) (*ProtocolManager, *ethdb.ObjectDatabase, func()) {
+	db := ethdb.NewMemDatabase()
+	genesis := ct.gspec.MustCommit(db)
+	td := new(big.Int).Set(genesis.Difficulty())
+	for _, block := range blocks {
+		td.Add(td, block.Difficulty())
+		rawdb.WriteBlock(context.Background(), db, block)
+		rawdb.WriteTd(db, block.Hash(), block.NumberU64(), td)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	if len(blocks) > 0 {
+		rawdb.WriteHeadHeaderHash(db, blocks[len(blocks)-1].Hash())
+		rawdb.WriteHeadBlockHash(db, blocks[len(blocks)-1].Hash())
+	}
+	engine := clique.New(ct.config.Clique, db)
+	blockchain, err := core.NewBlockChain(db, nil, ct.config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm, err := NewProtocolManager(ct.config, &params.TrustedCheckpoint{}, downloader.StagedSync, DefaultConfig.NetworkID, new(event.TypeMux), &testTxPool{pool: make(map[common.Hash]*types.Transaction)}, engine, blockchain, db, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pm.Start(1000, true); err != nil {
+		t.Fatal(err)
+	}
+	return pm, db, func() {
+		pm.Stop()
+		blockchain.Stop()
+		db.Close()
+	}
+}
+
+// syncFrom connects the two protocol managers and runs a staged sync cycle of pm with the source
+// queuedRW sends the messages from a goroutine, as both peers request the head header of the other one
+// before reading anything, which deadlocks on the unbuffered message pipe
+type queuedRW struct {
+	p2p.MsgReadWriter
+	queue chan p2p.Msg
+}
+
+func newQueuedRW(rw p2p.MsgReadWriter) *queuedRW {
+	q := &queuedRW{MsgReadWriter: rw, queue: make(chan p2p.Msg, 1024)}
+	go func() {
+		for msg := range q.queue {
+			if err := rw.WriteMsg(msg); err != nil {
+				return
+			}
+		}
+	}()
+	return q
+}
+
+func (q *queuedRW) WriteMsg(msg p2p.Msg) error {
+	payload, err := ioutil.ReadAll(msg.Payload)
+	if err != nil {
+		return err
+	}
+	msg.Payload = bytes.NewReader(payload)
+	q.queue <- msg
+	return nil
+}
+
+func syncFrom(t *testing.T, pm, source *ProtocolManager, id enode.ID) error {
+	io1, io2 := p2p.MsgPipe()
+	go source.handle(source.newPeer(65, p2p.NewPeer(enode.ID{}, "syncing", nil), newQueuedRW(io2), source.txpool.Get)) //nolint:errcheck
+	go pm.handle(pm.newPeer(65, p2p.NewPeer(id, "source", nil), newQueuedRW(io1), pm.txpool.Get))                      //nolint:errcheck
+
+	// In staged sync the number of the remote head is only known once its header has arrived
+	peerID := pm.newPeer(65, p2p.NewPeer(id, "source", nil), nil, nil).id
+	sourceHead := source.blockchain.CurrentHeader().Number.Uint64()
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if p := pm.peers.Peer(peerID); p != nil {
+			if _, number := p.Head(); number == sourceHead {
+				break
+			}
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("peers not connected")
+		}
+	}
+	return pm.doSync(peerToSyncOp(downloader.StagedSync, pm.peers.Peer(peerID)))
+}
+
+func checkCliqueHead(t *testing.T, ct *cliqueTester, pm *ProtocolManager, db *ethdb.ObjectDatabase, head *types.Block, recipient common.Address, signers int) {
+	t.Helper()
+	if hash := rawdb.ReadHeadHeaderHash(db); hash != head.Hash() {
+		t.Fatalf("head header %x, expected %x", hash, head.Hash())
+	}
+	for _, stage := range []stages.SyncStage{stages.Bodies, stages.Execution, stages.IntermediateHashes} {
+		if progress, _, err := stages.GetStageProgress(db, stage); err != nil || progress != head.NumberU64() {
+			t.Errorf("stage %s at %d (%v), expected %d", stage, progress, err, head.NumberU64())
+		}
+	}
+	account, err := state.NewPlainStateReader(db).ReadAccountData(recipient)
+	if err != nil || account == nil || account.Balance.Uint64() != 1000*head.NumberU64() {
+		t.Errorf("recipient account %+v (%v), expected a balance of %d", account, err, 1000*head.NumberU64())
+	}
+	api := pm.blockchain.Engine().APIs(pm.blockchain)[0].Service.(*clique.API)
+	if authorized, err := api.GetSignersAtHash(head.Hash()); err != nil || len(authorized) != signers {
+		t.Errorf("signers %x (%v), expected %d", authorized, err, signers)
+	}
+}
+
+func TestCliqueStagedSync(t *testing.T) {
+	ct := newCliqueTester(t, 3)
+	candidate := crypto.PubkeyToAddress(ct.newKey(t).PublicKey)
+	recipient := common.HexToAddress("0xcafe")
+	// The candidate is voted in by two of the three signers, and signs from then on, across two checkpoints
+	blocks := ct.chain(t, 20, recipient, map[uint64]common.Address{1: candidate, 2: candidate}, nil)
+
+	source, _, closeSource := ct.newProtocolManager(t, blocks)
+	defer closeSource()
+	pm, db, closePm := ct.newProtocolManager(t, nil)
+	defer closePm()
+
+	if err := syncFrom(t, pm, source, enode.ID{1}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	checkCliqueHead(t, ct, pm, db, blocks[len(blocks)-1], recipient, 4)
+}
+
+func TestCliqueStagedSyncUnauthorizedSigner(t *testing.T) {
+	ct := newCliqueTester(t, 3)
+	impostor := ct.newKey(t)
+	blocks := ct.chain(t, 10, common.HexToAddress("0xcafe"), nil, map[uint64]*ecdsa.PrivateKey{5: impostor})
+
+	source, _, closeSource := ct.newProtocolManager(t, blocks)
+	defer closeSource()
+	pm, db, closePm := ct.newProtocolManager(t, nil)
+	defer closePm()
+
+	if err := syncFrom(t, pm, source, enode.ID{1}); err == nil {
+		t.Fatal("expected the sync to fail on the block of the unauthorized signer")
+	}
+	if progress, _, _ := stages.GetStageProgress(db, stages.Execution); progress != 0 {
+		t.Errorf("blocks executed up to %d, expected none", progress)
+	}
+}
+
+func TestCliqueStagedSyncReorg(t *testing.T) {
+	ct := newCliqueTester(t, 3)
+	// The chains diverge from the first block, the long one has the higher total difficulty
+	short := ct.chain(t, 10, common.HexToAddress("0xcafe"), nil, nil)
+	long := ct.chain(t, 14, common.HexToAddress("0xbeef"), nil, nil)
+
+	shortSource, _, closeShort := ct.newProtocolManager(t, short)
+	defer closeShort()
+	longSource, _, closeLong := ct.newProtocolManager(t, long)
+	defer closeLong()
+	pm, db, closePm := ct.newProtocolManager(t, nil)
+	defer closePm()
+
+	if err := syncFrom(t, pm, shortSource, enode.ID{1}); err != nil {
+		t.Fatalf("sync with the short chain failed: %v", err)
+	}
+	checkCliqueHead(t, ct, pm, db, short[len(short)-1], common.HexToAddress("0xcafe"), 3)
+	if err := syncFrom(t, pm, longSource, enode.ID{2}); err != nil {
+		t.Fatalf("sync with the long chain failed: %v", err)
+	}
+	checkCliqueHead(t, ct, pm, db, long[len(long)-1], common.HexToAddress("0xbeef"), 3)
+	if account, _ := state.NewPlainStateReader(db).ReadAccountData(common.HexToAddress("0xcafe")); account != nil {
+		t.Errorf("the account of the short chain is left after the reorg: %+v", account)
+	}
+}
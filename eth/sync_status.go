@@ -0,0 +1,29 @@
+package eth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// syncStatusHandler serves the progress of the staged sync as JSON, to tell a slow sync from a stuck one
+type syncStatusHandler struct {
+	eth *Ethereum
+}
+
+func (h *syncStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status, err := h.eth.SyncStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(status); err != nil {
+		log.Warn("Failed to write the sync status", "err", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/stack"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// TxTracer is a vm.Tracer that assembles a JSON result over the course of a
+// transaction execution. Both the JavaScript tracer and the native Go tracers
+// implement it.
+type TxTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON encoded result of the trace, or any error
+	// accumulated during tracing.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// native contains the built in tracers that have a Go implementation, keyed by
+// the name of their JavaScript counterpart.
+var native = map[string]func() TxTracer{
+	"callTracer":     func() TxTracer { return newCallTracer() },
+	"prestateTracer": func() TxTracer { return newPrestateTracer() },
+	"4byteTracer":    func() TxTracer { return newFourByteTracer() },
+}
+
+// NewTracer instantiates a tracer by name or from JavaScript code. Built in
+// tracers with a native Go implementation are preferred over the JavaScript
+// ones, producing the same output at a fraction of the cost.
+func NewTracer(code string) (TxTracer, error) {
+	if ctor, ok := native[code]; ok {
+		return ctor(), nil
+	}
+	tracer, err := New(code)
+	if err != nil {
+		return nil, err
+	}
+	return tracer, nil
+}
+
+// stackPeek returns the nth-from-the-top element of the stack, or zero if the
+// stack is not deep enough, mirroring the JavaScript stack wrapper.
+func stackPeek(st *stack.Stack, n int) *uint256.Int {
+	if st.Len() <= n || n < 0 {
+		log.Warn("Tracer accessed out of bound stack", "size", st.Len(), "index", n)
+		return new(uint256.Int)
+	}
+	return st.Back(n)
+}
+
+// stackUint64 returns the nth-from-the-top element of the stack as a uint64,
+// saturating on overflow.
+func stackUint64(st *stack.Stack, n int) uint64 {
+	v := stackPeek(st, n)
+	if !v.IsUint64() {
+		return math.MaxUint64
+	}
+	return v.Uint64()
+}
+
+// memorySlice returns a copy of the requested memory range, or nil if the range
+// is out of bounds, mirroring the JavaScript memory wrapper.
+func memorySlice(mem *vm.Memory, offset, size uint64) []byte {
+	if size == 0 {
+		return []byte{}
+	}
+	if offset+size < offset || uint64(mem.Len()) < offset+size {
+		log.Warn("Tracer accessed out of bound memory", "available", mem.Len(), "offset", offset, "size", size)
+		return nil
+	}
+	return mem.GetCopy(offset, size)
+}
+
+// isPrecompiled reports whether addr is a precompiled contract, using the same
+// set as the JavaScript tracers.
+func isPrecompiled(addr common.Address) bool {
+	_, ok := vm.PrecompiledContractsIstanbul[addr]
+	return ok
+}
+
+// hexBig formats n the way the JavaScript tracers do with '0x' + n.toString(16).
+func hexBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/stack"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+)
+
+// prestateAccount is the prestate of a single account, as reported by
+// prestate_tracer.js.
+type prestateAccount struct {
+	Balance string            `json:"balance"`
+	Nonce   int64             `json:"nonce"`
+	Code    string            `json:"code"`
+	Storage map[string]string `json:"storage"`
+
+	balance *big.Int
+}
+
+// prestateTracer is a native implementation of prestate_tracer.js, outputting
+// sufficient information to create a local execution of the transaction from
+// a custom assembled genesis block.
+type prestateTracer struct {
+	prestate map[string]*prestateAccount
+	db       vm.IntraBlockState // Last state seen, used to finalize the result
+
+	// Transaction context gathered throughout execution
+	create bool
+	from   common.Address
+	to     common.Address
+	value  *big.Int
+
+	err       error  // Error, if one has occurred
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newPrestateTracer() *prestateTracer {
+	return &prestateTracer{}
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount injects the specified account into the prestate object.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	acc := hexutil.Encode(addr.Bytes())
+	if _, ok := t.prestate[acc]; ok {
+		return
+	}
+	t.prestate[acc] = &prestateAccount{
+		Nonce:   int64(t.db.GetNonce(addr)),
+		Code:    hexutil.Encode(t.db.GetCode(addr)),
+		Storage: make(map[string]string),
+		balance: t.db.GetBalance(addr).ToBig(),
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate object.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+
+	acc := t.prestate[hexutil.Encode(addr.Bytes())]
+	idx := hexutil.Encode(key.Bytes())
+	if _, ok := acc.Storage[idx]; ok {
+		return
+	}
+	var value uint256.Int
+	t.db.GetState(addr, &key, &value)
+	acc.Storage[idx] = hexutil.Encode(value.Bytes())
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(depth int, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	if depth != 0 {
+		return nil
+	}
+	t.create = create
+	t.from, t.to = from, to
+	t.value = new(big.Int).Set(value)
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return nil
+	}
+	t.db = env.IntraBlockState
+
+	// Add the current account if we just started tracing
+	if t.prestate == nil {
+		t.prestate = make(map[string]*prestateAccount)
+		// Balance will potentially be wrong here, since this will include the value
+		// sent along with the message. We fix that in GetResult.
+		t.lookupAccount(contract.Address())
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		t.lookupAccount(common.Address(stackPeek(st, 0).Bytes20()))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+	case vm.CREATE2:
+		// stack: salt, size, offset, endowment
+		from := contract.Address()
+		offset, size := stackUint64(st, 1), stackUint64(st, 2)
+		salt := common.Hash(stackPeek(st, 3).Bytes32())
+		codeHash := crypto.Keccak256(memorySlice(memory, offset, size))
+		t.lookupAccount(crypto.CreateAddress2(from, salt, codeHash))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.Address(stackPeek(st, 1).Bytes20()))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.Hash(stackPeek(st, 0).Bytes32()))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(depth int, output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureCreate(creator, creation common.Address) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the assembled allocations (prestate) of the traced transaction.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.prestate == nil || t.value == nil {
+		return nil, wrapError("result", fmt.Errorf("no state accessed by the transaction"))
+	}
+	// At this point, we need to deduct the 'value' from the
+	// outer transaction, and move it back to the origin
+	t.lookupAccount(t.from)
+
+	fromAcc := t.prestate[hexutil.Encode(t.from.Bytes())]
+	toAcc, ok := t.prestate[hexutil.Encode(t.to.Bytes())]
+	if !ok {
+		return nil, wrapError("result", fmt.Errorf("recipient %x missing from prestate", t.to))
+	}
+	fromBal, toBal := fromAcc.balance, toAcc.balance
+
+	toAcc.balance = new(big.Int).Sub(toBal, t.value)
+	fromAcc.balance = new(big.Int).Add(fromBal, t.value)
+
+	// Decrement the caller's nonce, and remove empty create targets
+	fromAcc.Nonce--
+	if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		delete(t.prestate, hexutil.Encode(t.to.Bytes()))
+	}
+	for _, acc := range t.prestate {
+		acc.Balance = hexBig(acc.balance)
+	}
+	return json.Marshal(t.prestate)
+}
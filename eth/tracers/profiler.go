@@ -0,0 +1,263 @@
+package tracers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/stack"
+)
+
+// Profiler is a vm.Tracer which aggregates statistics about the executed opcodes over any number
+// of transactions, unlike the TxTracer implementations which produce a result per transaction.
+// It counts how many times each opcode was executed, how much gas it consumed and how long it
+// took, how storage slots were accessed and which contracts consumed the most gas.
+//
+// The gas of an opcode is the gas it consumed itself: the gas consumed by the frame called by
+// CALL, CREATE and friends is attributed to the opcodes of that frame. The gas burnt by a failed
+// frame beyond the cost of its last opcode is attributed to the opcode which called it.
+//
+// A storage access is cold when the slot was not accessed before in the same transaction
+// (regardless of the fork, so that the pre-Berlin history can be analysed too), warm otherwise.
+//
+// Profiler is not safe for concurrent use.
+type Profiler struct {
+	txs       uint64
+	ops       [256]opProfile
+	contracts map[common.Address]uint64
+	sload     [2]accessProfile // cold, warm
+	sstore    [2]accessProfile // cold, warm
+
+	frames   []profiledFrame // indexed by the depth
+	accessed map[storageSlot]struct{}
+	lastOp   vm.OpCode
+	lastTime time.Time
+	timing   bool
+}
+
+type opProfile struct {
+	count uint64
+	gas   uint64
+	time  time.Duration
+}
+
+type accessProfile struct {
+	count uint64
+	gas   uint64
+}
+
+type storageSlot struct {
+	address common.Address
+	slot    common.Hash
+}
+
+// profiledFrame holds the last opcode executed in a frame, until the gas it consumed is known
+type profiledFrame struct {
+	pending  bool
+	op       vm.OpCode
+	gas      uint64 // gas available before the opcode
+	cost     uint64
+	childGas uint64 // gas consumed by the frames called by the opcode
+	contract common.Address
+}
+
+// NewProfiler creates an empty profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{
+		contracts: make(map[common.Address]uint64),
+		accessed:  make(map[storageSlot]struct{}),
+	}
+}
+
+// CaptureStart implements the Tracer interface, a new transaction starts at depth 0.
+func (p *Profiler) CaptureStart(depth int, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	if depth == 0 {
+		p.txs++
+		p.frames = p.frames[:0]
+		p.accessed = make(map[storageSlot]struct{})
+	}
+	return nil
+}
+
+// CaptureState implements the Tracer interface.
+func (p *Profiler) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	now := time.Now()
+	p.stopTimer(now)
+
+	p.unwind(depth)
+	for len(p.frames) <= depth {
+		p.frames = append(p.frames, profiledFrame{})
+	}
+	frame := &p.frames[depth]
+	if frame.pending {
+		p.settle(depth, frame.gas-gas)
+	}
+	*frame = profiledFrame{pending: true, op: op, gas: gas, cost: cost, contract: contract.Address()}
+	p.ops[op].count++
+
+	if (op == vm.SLOAD || op == vm.SSTORE) && err == nil && st.Len() > 0 {
+		key := storageSlot{address: contract.Address(), slot: common.Hash(st.Back(0).Bytes32())}
+		access := 1 // warm
+		if _, ok := p.accessed[key]; !ok {
+			p.accessed[key] = struct{}{}
+			access = 0
+		}
+		stats := &p.sload[access]
+		if op == vm.SSTORE {
+			stats = &p.sstore[access]
+		}
+		stats.count++
+		stats.gas += cost
+	}
+
+	p.lastOp, p.lastTime, p.timing = op, now, true
+	return nil
+}
+
+// CaptureFault implements the Tracer interface, the faulting opcode has been captured already.
+func (p *Profiler) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface, the end at depth 0 concludes the transaction.
+func (p *Profiler) CaptureEnd(depth int, output []byte, gasUsed uint64, t time.Duration, err error) error {
+	p.stopTimer(time.Now())
+	if depth == 0 {
+		p.unwind(0)
+	}
+	return nil
+}
+
+func (p *Profiler) CaptureCreate(creator common.Address, creation common.Address) error { return nil }
+func (p *Profiler) CaptureAccountRead(account common.Address) error                     { return nil }
+func (p *Profiler) CaptureAccountWrite(account common.Address) error                    { return nil }
+
+// stopTimer attributes the time elapsed since the last opcode started to that opcode
+func (p *Profiler) stopTimer(now time.Time) {
+	if p.timing {
+		p.ops[p.lastOp].time += now.Sub(p.lastTime)
+		p.timing = false
+	}
+}
+
+// settle attributes the gas consumed by the pending opcode of the frame at the given depth, total
+// includes the gas consumed by the frames it called
+func (p *Profiler) settle(depth int, total uint64) {
+	frame := &p.frames[depth]
+	own := uint64(0)
+	if total > frame.childGas {
+		own = total - frame.childGas
+	}
+	p.ops[frame.op].gas += own
+	p.contracts[frame.contract] += own
+	if depth > 0 {
+		p.frames[depth-1].childGas += total
+	}
+	frame.pending = false
+}
+
+// unwind settles the frames deeper than depth, which have returned, charging their last opcodes
+// with their cost
+func (p *Profiler) unwind(depth int) {
+	for d := len(p.frames) - 1; d > depth; d-- {
+		if frame := &p.frames[d]; frame.pending {
+			p.settle(d, frame.cost+frame.childGas)
+		}
+	}
+	if len(p.frames) > depth+1 {
+		p.frames = p.frames[:depth+1]
+	}
+}
+
+// ProfileResult is the summary of the statistics collected by a Profiler.
+type ProfileResult struct {
+	Blocks    uint64            `json:"blocks"`
+	Txs       uint64            `json:"txs"`
+	Gas       uint64            `json:"gas"`
+	Opcodes   []OpcodeProfile   `json:"opcodes"`
+	Storage   []StorageProfile  `json:"storage"`
+	Contracts []ContractProfile `json:"contracts"`
+}
+
+// OpcodeProfile contains the statistics of one opcode.
+type OpcodeProfile struct {
+	Op     string `json:"op"`
+	Count  uint64 `json:"count"`
+	Gas    uint64 `json:"gas"`
+	TimeNs int64  `json:"timeNs"`
+}
+
+// StorageProfile contains the statistics of the cold or warm accesses by SLOAD or SSTORE.
+type StorageProfile struct {
+	Op     string `json:"op"`
+	Access string `json:"access"`
+	Count  uint64 `json:"count"`
+	Gas    uint64 `json:"gas"`
+}
+
+// ContractProfile contains the gas consumed in the context of a contract.
+type ContractProfile struct {
+	Address common.Address `json:"address"`
+	Gas     uint64         `json:"gas"`
+}
+
+// Result summarises the collected statistics, the executed opcodes and the topContracts contracts
+// (all of them if topContracts is negative) are sorted by the gas consumed.
+func (p *Profiler) Result(topContracts int) *ProfileResult {
+	result := &ProfileResult{Txs: p.txs}
+	for i := range p.ops {
+		op := &p.ops[i]
+		if op.count == 0 {
+			continue
+		}
+		result.Gas += op.gas
+		result.Opcodes = append(result.Opcodes, OpcodeProfile{Op: vm.OpCode(i).String(), Count: op.count, Gas: op.gas, TimeNs: op.time.Nanoseconds()})
+	}
+	sort.SliceStable(result.Opcodes, func(i, j int) bool { return result.Opcodes[i].Gas > result.Opcodes[j].Gas })
+
+	for i, access := range []string{"cold", "warm"} {
+		result.Storage = append(result.Storage,
+			StorageProfile{Op: vm.SLOAD.String(), Access: access, Count: p.sload[i].count, Gas: p.sload[i].gas},
+			StorageProfile{Op: vm.SSTORE.String(), Access: access, Count: p.sstore[i].count, Gas: p.sstore[i].gas},
+		)
+	}
+
+	result.Contracts = make([]ContractProfile, 0, len(p.contracts))
+	for address, gas := range p.contracts {
+		result.Contracts = append(result.Contracts, ContractProfile{Address: address, Gas: gas})
+	}
+	sort.Slice(result.Contracts, func(i, j int) bool {
+		if result.Contracts[i].Gas != result.Contracts[j].Gas {
+			return result.Contracts[i].Gas > result.Contracts[j].Gas
+		}
+		return bytes.Compare(result.Contracts[i].Address[:], result.Contracts[j].Address[:]) < 0
+	})
+	if topContracts >= 0 && len(result.Contracts) > topContracts {
+		result.Contracts = result.Contracts[:topContracts]
+	}
+	return result
+}
+
+// WriteCSV writes the result as a single table with the columns kind, name, count, gas and
+// time_ns, where kind is one of opcode, storage and contract.
+func (r *ProfileResult) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	u := func(n uint64) string { return strconv.FormatUint(n, 10) }
+	rows := [][]string{{"kind", "name", "count", "gas", "time_ns"}}
+	for _, op := range r.Opcodes {
+		rows = append(rows, []string{"opcode", op.Op, u(op.Count), u(op.Gas), strconv.FormatInt(op.TimeNs, 10)})
+	}
+	for _, s := range r.Storage {
+		rows = append(rows, []string{"storage", s.Op + " " + s.Access, u(s.Count), u(s.Gas), ""})
+	}
+	for _, c := range r.Contracts {
+		rows = append(rows, []string{"contract", c.Address.Hex(), "", u(c.Gas), ""})
+	}
+	return out.WriteAll(rows)
+}
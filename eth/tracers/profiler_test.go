@@ -0,0 +1,85 @@
+package tracers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/runtime"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestProfiler(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	statedb := state.New(state.NewTrieDbState(common.Hash{}, db, 0))
+
+	caller := common.HexToAddress("0xaa")
+	callee := common.HexToAddress("0xbb")
+	// SLOAD(0), SLOAD(0), SSTORE(0, 1), CALL(callee), STOP
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH20),
+	}
+	code = append(code, callee.Bytes()...)
+	code = append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.POP), byte(vm.STOP))
+	statedb.SetCode(caller, code)
+	// SLOAD(0), STOP - the slot of another contract is cold
+	statedb.SetCode(callee, []byte{byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), byte(vm.STOP)})
+
+	profiler := NewProfiler()
+	gasLimit := uint64(1000000)
+	_, leftOver, err := runtime.Call(caller, nil, &runtime.Config{
+		State:     statedb,
+		GasLimit:  gasLimit,
+		EVMConfig: vm.Config{Debug: true, Tracer: profiler},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := profiler.Result(10)
+
+	if result.Txs != 1 {
+		t.Errorf("txs %d, expected 1", result.Txs)
+	}
+	if result.Gas != gasLimit-leftOver {
+		t.Errorf("gas %d, expected %d", result.Gas, gasLimit-leftOver)
+	}
+	counts := make(map[string]uint64)
+	for _, op := range result.Opcodes {
+		counts[op.Op] = op.Count
+	}
+	if counts["SLOAD"] != 3 || counts["SSTORE"] != 1 || counts["CALL"] != 1 || counts["STOP"] != 2 {
+		t.Errorf("unexpected opcode counts %v", counts)
+	}
+	accesses := make(map[string]uint64)
+	for _, s := range result.Storage {
+		accesses[s.Op+" "+s.Access] = s.Count
+	}
+	expected := map[string]uint64{"SLOAD cold": 2, "SLOAD warm": 1, "SSTORE cold": 0, "SSTORE warm": 1}
+	for k, v := range expected {
+		if accesses[k] != v {
+			t.Errorf("%s accesses %d, expected %d", k, accesses[k], v)
+		}
+	}
+	if len(result.Contracts) != 2 || result.Contracts[0].Address != caller || result.Contracts[1].Address != callee {
+		t.Errorf("unexpected contracts %v", result.Contracts)
+	}
+	if result.Contracts[0].Gas+result.Contracts[1].Gas != result.Gas {
+		t.Errorf("contracts gas %d + %d, expected %d", result.Contracts[0].Gas, result.Contracts[1].Gas, result.Gas)
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "kind,name,count,gas,time_ns\n") || !strings.Contains(buf.String(), "storage,SLOAD cold,2,") {
+		t.Errorf("unexpected csv\n%s", buf.String())
+	}
+}
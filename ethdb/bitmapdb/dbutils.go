@@ -0,0 +1,81 @@
+package bitmapdb
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// ChunkLimit is the maximal size of a serialized chunk of a history index. A chunk together with
+// its key fits into a single LMDB page, so the index of an often changed key is spread across
+// several chunks instead of being stored in overflow pages and rewritten as a whole.
+const ChunkLimit = 1950
+
+// CutLeft removes the lowest values of bm, as many as fit into sizeLimit bytes once serialized,
+// and returns them. It returns nil if bm is empty.
+func CutLeft(bm *roaring.Bitmap, sizeLimit uint64) *roaring.Bitmap {
+	if bm.IsEmpty() {
+		return nil
+	}
+	if bm.GetSerializedSizeInBytes() <= sizeLimit {
+		lft := bm.Clone()
+		bm.Clear()
+		return lft
+	}
+	from := uint64(bm.Minimum())
+	// The first width giving a chunk larger than the limit
+	width := uint64(sort.Search(int(uint64(bm.Maximum())-from), func(i int) bool {
+		lft := roaring.New()
+		lft.AddRange(from, from+uint64(i)+1)
+		lft.And(bm)
+		return lft.GetSerializedSizeInBytes() > sizeLimit
+	}))
+	if width == 0 { // the limit is too small even for a single value
+		width = 1
+	}
+	lft := roaring.New()
+	lft.AddRange(from, from+width)
+	lft.And(bm)
+	bm.RemoveRange(from, from+width)
+	return lft
+}
+
+// WalkChunkWithKeys splits bm into chunks of at most sizeLimit bytes and calls f for each of them,
+// from the lowest values on, with the key of the chunk in the history index: k followed by the
+// last value of the chunk, or by ^uint64(0) for the last chunk. k must not contain an incarnation.
+// The values are removed from bm.
+func WalkChunkWithKeys(k []byte, bm *roaring.Bitmap, sizeLimit uint64, f func(chunkKey []byte, chunk *roaring.Bitmap) error) error {
+	for chunk := CutLeft(bm, sizeLimit); chunk != nil; chunk = CutLeft(bm, sizeLimit) {
+		chunkKey := make([]byte, len(k)+8)
+		copy(chunkKey, k)
+		if bm.IsEmpty() {
+			binary.BigEndian.PutUint64(chunkKey[len(k):], ^uint64(0))
+		} else {
+			binary.BigEndian.PutUint64(chunkKey[len(k):], uint64(chunk.Maximum()))
+		}
+		if err := f(chunkKey, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeekInBitmap returns the lowest value of bm which is greater than or equal to n.
+func SeekInBitmap(bm *roaring.Bitmap, n uint64) (uint64, bool) {
+	if n > uint64(^uint32(0)) {
+		return 0, false
+	}
+	var lower uint64 // number of the values lower than n
+	if n > 0 {
+		lower = bm.Rank(uint32(n - 1))
+	}
+	if lower >= bm.GetCardinality() {
+		return 0, false
+	}
+	v, err := bm.Select(uint32(lower))
+	if err != nil {
+		return 0, false
+	}
+	return uint64(v), true
+}
@@ -0,0 +1,65 @@
+package bitmapdb
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCutLeft(t *testing.T) {
+	bm := roaring.New()
+	for j := 0; j < 10_000; j += 20 {
+		bm.AddRange(uint64(j), uint64(j+10))
+	}
+	total := bm.GetCardinality()
+	var collected uint64
+	var prevMax uint32
+	for chunk := CutLeft(bm, 1024); chunk != nil; chunk = CutLeft(bm, 1024) {
+		require.LessOrEqual(t, chunk.GetSerializedSizeInBytes(), uint64(1024))
+		if collected > 0 {
+			require.Greater(t, chunk.Minimum(), prevMax)
+		}
+		prevMax = chunk.Maximum()
+		collected += chunk.GetCardinality()
+	}
+	require.Equal(t, total, collected)
+	require.True(t, bm.IsEmpty())
+}
+
+func TestWalkChunkWithKeys(t *testing.T) {
+	k := []byte{0xaa, 0xbb}
+	bm := roaring.New()
+	for j := uint32(0); j < 5_000; j += 3 {
+		bm.Add(j)
+	}
+	var keys [][]byte
+	merged := roaring.New()
+	require.NoError(t, WalkChunkWithKeys(k, bm.Clone(), 512, func(chunkKey []byte, chunk *roaring.Bitmap) error {
+		require.Equal(t, k, chunkKey[:len(k)])
+		keys = append(keys, chunkKey)
+		if len(keys) > 1 {
+			require.Greater(t, uint64(chunk.Minimum()), binary.BigEndian.Uint64(keys[len(keys)-2][len(k):]))
+		}
+		merged.Or(chunk)
+		return nil
+	}))
+	require.Greater(t, len(keys), 1)
+	require.Equal(t, ^uint64(0), binary.BigEndian.Uint64(keys[len(keys)-1][len(k):]))
+	require.True(t, merged.Equals(bm))
+}
+
+func TestSeekInBitmap(t *testing.T) {
+	bm := roaring.BitmapOf(3, 10, 1_000_000)
+	for _, tt := range []struct {
+		n, found uint64
+		ok       bool
+	}{
+		{0, 3, true}, {3, 3, true}, {4, 10, true}, {11, 1_000_000, true}, {1_000_001, 0, false}, {1 << 40, 0, false},
+	} {
+		found, ok := SeekInBitmap(bm, tt.n)
+		require.Equal(t, tt.ok, ok, "seek %d", tt.n)
+		require.Equal(t, tt.found, found, "seek %d", tt.n)
+	}
+}
@@ -3,6 +3,8 @@ package ethdb
 import (
 	"context"
 	"errors"
+	"time"
+
 	"github.com/ledgerwatch/turbo-geth/common"
 
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
@@ -188,6 +190,47 @@ type Backend interface {
 	Etherbase() (common.Address, error)
 	NetVersion() (uint64, error)
 	BloomStatus() (uint64, uint64, common.Hash)
+	// PoolContent - pending and queued transactions of the transaction pool, grouped by sender and sorted by nonce
+	PoolContent() (pending, queued []PoolTransaction, err error)
+	// PoolStatus - amount of pending and queued transactions in the transaction pool
+	PoolStatus() (pending, queued uint64, err error)
+	// PoolInspect - human readable summary of pending and queued transactions of the transaction pool
+	PoolInspect() (pending, queued []PoolInspectItem, err error)
+	// SyncStatus - progress of the stages of the staged sync
+	SyncStatus() (*SyncStatus, error)
+}
+
+// PoolTransaction - RLP encoded transaction of the transaction pool and its sender
+type PoolTransaction struct {
+	Sender common.Address
+	RlpTx  []byte
+}
+
+// PoolInspectItem - summary of one transaction of the transaction pool
+type PoolInspectItem struct {
+	Sender  common.Address
+	Nonce   uint64
+	Summary string
+}
+
+// SyncStatus - progress of the staged sync, and its estimated completion time
+type SyncStatus struct {
+	CurrentStage string            `json:"currentStage,omitempty"` // empty between the sync cycles
+	HighestBlock uint64            `json:"highestBlock"`
+	Stages       []SyncStageStatus `json:"stages"`
+	ETA          *time.Time        `json:"eta,omitempty"` // nil if the throughput of some stage is not known yet
+}
+
+// SyncStageStatus - progress and throughput of one stage
+type SyncStageStatus struct {
+	Stage           string     `json:"stage"`
+	Description     string     `json:"description"`
+	Disabled        bool       `json:"disabled,omitempty"`
+	BlockNumber     uint64     `json:"blockNumber"`
+	BlocksPerSecond float64    `json:"blocksPerSecond"` // of the current or the last run which made progress
+	Unwinds         uint64     `json:"unwinds"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"` // of the current or the last run
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty"` // when the progress of the stage changed the last time
 }
 
 type DbProvider uint8
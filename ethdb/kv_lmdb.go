@@ -15,6 +15,7 @@ import (
 	"github.com/ledgerwatch/lmdb-go/lmdb"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/metrics"
 )
 
 const (
@@ -106,6 +107,7 @@ func (opts lmdbOpts) Open() (KV, error) {
 		log:     logger,
 		wg:      &sync.WaitGroup{},
 		buckets: dbutils.BucketsCfg{},
+		readTxs: map[*lmdbTx]time.Time{},
 	}
 	customBuckets := opts.bucketsCfg(dbutils.BucketsConfigs)
 	for name, cfg := range customBuckets { // copy map to avoid changing global variable
@@ -186,6 +188,12 @@ func (opts lmdbOpts) Open() (KV, error) {
 		} else if staleReaders > 0 {
 			db.log.Debug("cleared reader slots from dead processes", "amount", staleReaders)
 		}
+		if metrics.Enabled {
+			var ctx context.Context
+			ctx, db.stopStaleReadsCheck = context.WithCancel(context.Background())
+			db.wg.Add(1)
+			go db.staleReadsCheck(ctx)
+		}
 	}
 	return db, nil
 }
@@ -205,6 +213,8 @@ type LmdbKV struct {
 	buckets             dbutils.BucketsCfg
 	stopStaleReadsCheck context.CancelFunc
 	wg                  *sync.WaitGroup
+	readTxsLock         sync.Mutex
+	readTxs             map[*lmdbTx]time.Time // read transactions of this process and their start times
 }
 
 func NewLMDB() lmdbOpts {
@@ -214,6 +224,9 @@ func NewLMDB() lmdbOpts {
 // Close closes db
 // All transactions must be closed before closing the database.
 func (db *LmdbKV) Close() {
+	if db.stopStaleReadsCheck != nil {
+		db.stopStaleReadsCheck()
+	}
 	if db.env != nil {
 		db.wg.Wait()
 	}
@@ -270,20 +283,28 @@ func (db *LmdbKV) Begin(ctx context.Context, parent Tx, writable bool) (Tx, erro
 		return nil, err
 	}
 	tx.RawRead = true
-	return &lmdbTx{
-		db:      db,
-		ctx:     ctx,
-		tx:      tx,
-		isSubTx: isSubTx,
-	}, nil
+	t := &lmdbTx{
+		db:       db,
+		ctx:      ctx,
+		tx:       tx,
+		isSubTx:  isSubTx,
+		readOnly: !writable,
+	}
+	if t.readOnly && !isSubTx {
+		db.readTxsLock.Lock()
+		db.readTxs[t] = time.Now()
+		db.readTxsLock.Unlock()
+	}
+	return t, nil
 }
 
 type lmdbTx struct {
-	isSubTx bool
-	tx      *lmdb.Txn
-	ctx     context.Context
-	db      *LmdbKV
-	cursors []*lmdb.Cursor
+	isSubTx  bool
+	readOnly bool
+	tx       *lmdb.Txn
+	ctx      context.Context
+	db       *LmdbKV
+	cursors  []*lmdb.Cursor
 }
 
 type LmdbCursor struct {
@@ -494,7 +515,7 @@ func (tx *lmdbTx) Commit(ctx context.Context) error {
 	defer func() {
 		tx.tx = nil
 		if !tx.isSubTx {
-			tx.db.wg.Done()
+			tx.db.finished(tx)
 			runtime.UnlockOSThread()
 		}
 	}()
@@ -505,6 +526,9 @@ func (tx *lmdbTx) Commit(ctx context.Context) error {
 		return err
 	}
 	commitTook := time.Since(commitTimer)
+	if !tx.readOnly {
+		lmdbCommitTimer.Update(commitTook)
+	}
 	if commitTook > 20*time.Second {
 		log.Info("Batch", "commit", commitTook)
 	}
@@ -515,6 +539,7 @@ func (tx *lmdbTx) Commit(ctx context.Context) error {
 			log.Warn("fsync after commit failed", "err", err)
 		}
 		fsyncTook := time.Since(fsyncTimer)
+		lmdbFsyncTimer.Update(fsyncTook)
 		if fsyncTook > 20*time.Second {
 			log.Info("Batch", "fsync", fsyncTook)
 		}
@@ -522,6 +547,16 @@ func (tx *lmdbTx) Commit(ctx context.Context) error {
 	return nil
 }
 
+// finished releases the top level transaction
+func (db *LmdbKV) finished(tx *lmdbTx) {
+	if tx.readOnly {
+		db.readTxsLock.Lock()
+		delete(db.readTxs, tx)
+		db.readTxsLock.Unlock()
+	}
+	db.wg.Done()
+}
+
 func (tx *lmdbTx) Rollback() {
 	if tx.db.env == nil {
 		return
@@ -532,7 +567,7 @@ func (tx *lmdbTx) Rollback() {
 	defer func() {
 		tx.tx = nil
 		if !tx.isSubTx {
-			tx.db.wg.Done()
+			tx.db.finished(tx)
 			runtime.UnlockOSThread()
 		}
 	}()
@@ -0,0 +1,193 @@
+package ethdb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"sort"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/lmdb-go/lmdb"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// CopyBatchSize - amount of data written by one transaction of CopyTo, the dirty pages of a write transaction are limited
+var CopyBatchSize = 128 * datasize.MB
+
+// BucketChecksum - number of the entries of the bucket and the checksum of them, in the order of the bucket
+type BucketChecksum struct {
+	Bucket   string
+	Entries  uint64
+	Checksum common.Hash
+}
+
+// CopyTo copies the buckets of the database into the empty database to, in the same snapshot. The entries are
+// appended in order, so the pages of the copy are filled up and it has no free pages. The raw entries are copied,
+// with the configs of the buckets of the database to, which must be the same. It returns the checksums of
+// the copied buckets, sorted by name.
+func (db *LmdbKV) CopyTo(ctx context.Context, to *LmdbKV) ([]BucketChecksum, error) {
+	tx, err := db.Begin(ctx, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	buckets, err := tx.(*lmdbTx).ExistingBuckets()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(buckets)
+	for _, name := range buckets {
+		if _, ok := db.buckets[name]; !ok {
+			return nil, fmt.Errorf("bucket %s has no config, add it to dbutils.Buckets", name)
+		}
+		if _, ok := to.buckets[name]; !ok {
+			return nil, fmt.Errorf("bucket %s has no config in the destination", name)
+		}
+		if db.buckets[name].Flags != to.buckets[name].Flags || db.buckets[name].CustomDupComparator != to.buckets[name].CustomDupComparator {
+			return nil, fmt.Errorf("bucket %s has different configs in the source and the destination", name)
+		}
+	}
+
+	checksums := make([]BucketChecksum, len(buckets))
+	for i, name := range buckets {
+		log.Info("Copying bucket", "bucket", name, "progress", fmt.Sprintf("%d/%d", i+1, len(buckets)))
+		if checksums[i], err = db.copyBucket(ctx, tx.(*lmdbTx), to, name); err != nil {
+			return nil, fmt.Errorf("copying bucket %s: %w", name, err)
+		}
+	}
+	return checksums, nil
+}
+
+func (db *LmdbKV) copyBucket(ctx context.Context, tx *lmdbTx, to *LmdbKV, name string) (BucketChecksum, error) {
+	result := BucketChecksum{Bucket: name}
+	dbi := db.buckets[name].DBI
+	stat, err := tx.tx.Stat(dbi)
+	if err != nil {
+		return result, err
+	}
+	c, err := tx.tx.OpenCursor(dbi)
+	if err != nil {
+		return result, err
+	}
+	defer c.Close()
+
+	dupSort := db.buckets[name].Flags&lmdb.DupSort != 0
+	h := newEntriesHasher()
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	var prevK []byte
+	k, v, err := c.Get(nil, nil, lmdb.First)
+	for {
+		batch, err1 := to.Begin(ctx, nil, true)
+		if err1 != nil {
+			return result, err1
+		}
+		if err1 = batch.(BucketMigrator).CreateBucket(name); err1 != nil {
+			batch.Rollback()
+			return result, err1
+		}
+		dst, err1 := batch.(*lmdbTx).tx.OpenCursor(to.buckets[name].DBI)
+		if err1 != nil {
+			batch.Rollback()
+			return result, err1
+		}
+		var written uint64
+		for ; k != nil && written < uint64(CopyBatchSize); k, v, err = c.Get(nil, nil, lmdb.Next) {
+			if err != nil {
+				batch.Rollback()
+				return result, err
+			}
+			flags := uint(lmdb.Append)
+			if dupSort && bytes.Equal(k, prevK) {
+				flags = lmdb.AppendDup
+			}
+			if err = dst.Put(k, v, flags); err != nil {
+				batch.Rollback()
+				return result, err
+			}
+			h.add(k, v)
+			result.Entries++
+			written += uint64(len(k) + len(v))
+			prevK = append(prevK[:0], k...)
+
+			select {
+			case <-logEvery.C:
+				log.Info("Progress", "bucket", name, "entries", fmt.Sprintf("%d/%d", result.Entries, stat.Entries))
+			default:
+			}
+		}
+		if err != nil && !lmdb.IsNotFound(err) {
+			batch.Rollback()
+			return result, err
+		}
+		if err1 = batch.Commit(ctx); err1 != nil {
+			return result, err1
+		}
+		if k == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+	}
+	result.Checksum = h.sum()
+	return result, nil
+}
+
+// Checksum reads the entries of the bucket, to compare them with the entries of the copy of the bucket
+func (db *LmdbKV) Checksum(ctx context.Context, bucket string) (BucketChecksum, error) {
+	result := BucketChecksum{Bucket: bucket}
+	cfg, ok := db.buckets[bucket]
+	if !ok || cfg.DBI == NonExistingDBI {
+		return result, fmt.Errorf("bucket %s not found", bucket)
+	}
+	if err := db.View(ctx, func(tx Tx) error {
+		c, err := tx.(*lmdbTx).tx.OpenCursor(cfg.DBI)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		h := newEntriesHasher()
+		for k, v, err := c.Get(nil, nil, lmdb.First); k != nil; k, v, err = c.Get(nil, nil, lmdb.Next) {
+			if err != nil {
+				return err
+			}
+			h.add(k, v)
+			result.Entries++
+		}
+		result.Checksum = h.sum()
+		return nil
+	}); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// entriesHasher hashes the sequence of the entries, the sizes of the keys and the values are included
+type entriesHasher struct {
+	h    hash.Hash
+	size [binary.MaxVarintLen64]byte
+}
+
+func newEntriesHasher() *entriesHasher {
+	return &entriesHasher{h: sha256.New()}
+}
+
+func (e *entriesHasher) add(k, v []byte) {
+	e.h.Write(e.size[:binary.PutUvarint(e.size[:], uint64(len(k)))])
+	e.h.Write(k)
+	e.h.Write(e.size[:binary.PutUvarint(e.size[:], uint64(len(v)))])
+	e.h.Write(v)
+}
+
+func (e *entriesHasher) sum() common.Hash {
+	return common.BytesToHash(e.h.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package ethdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+)
+
+func TestCopyTo(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	from := NewLMDB().InMem().MustOpen()
+	defer from.Close()
+	to := NewLMDB().InMem().MustOpen()
+	defer to.Close()
+
+	address := func(i int) common.Address { return common.BytesToAddress([]byte(fmt.Sprintf("%020d", i))) }
+	storageKey := func(i, j int) []byte {
+		return dbutils.PlainGenerateCompositeStorageKey(address(i), 1, common.BytesToHash([]byte(fmt.Sprintf("%032d", j))))
+	}
+	require.NoError(from.Update(ctx, func(tx Tx) error {
+		headers := tx.Cursor(dbutils.HeaderPrefix)
+		state := tx.Cursor(dbutils.PlainStateBucket) // DupSort
+		for i := 0; i < 1000; i++ {
+			if err := headers.Put([]byte(fmt.Sprintf("%04d", i)), make([]byte, i)); err != nil {
+				return err
+			}
+			// an account and its storage, the storage keys are the duplicates of the account address
+			if err := state.Put(address(i).Bytes(), []byte{1}); err != nil {
+				return err
+			}
+			for j := 0; j < i%5; j++ {
+				if err := state.Put(storageKey(i, j), []byte{byte(j + 1)}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}))
+
+	defer func(size datasize.ByteSize) { CopyBatchSize = size }(CopyBatchSize)
+	CopyBatchSize = 64 * datasize.KB // many transactions per bucket
+	checksums, err := from.(*LmdbKV).CopyTo(ctx, to.(*LmdbKV))
+	require.NoError(err)
+
+	copied := map[string]BucketChecksum{}
+	for _, c := range checksums {
+		copied[c.Bucket] = c
+		checksum, err := to.(*LmdbKV).Checksum(ctx, c.Bucket)
+		require.NoError(err)
+		require.Equal(c, checksum)
+	}
+	require.Equal(uint64(1000), copied[dbutils.HeaderPrefix].Entries)
+	require.Equal(uint64(1000+1000/5*(0+1+2+3+4)), copied[dbutils.PlainStateBucket].Entries)
+	checksum, err := from.(*LmdbKV).Checksum(ctx, dbutils.PlainStateBucket)
+	require.NoError(err)
+	require.Equal(copied[dbutils.PlainStateBucket], checksum)
+
+	require.NoError(to.View(ctx, func(tx Tx) error {
+		v, err := tx.Get(dbutils.PlainStateBucket, storageKey(999, 3))
+		require.NoError(err)
+		require.Equal([]byte{4}, v)
+		v, err = tx.Get(dbutils.HeaderPrefix, []byte("0999"))
+		require.NoError(err)
+		require.Len(v, 999)
+		return nil
+	}))
+
+	// the copy is checked against the source
+	require.NoError(to.Update(ctx, func(tx Tx) error {
+		return tx.Cursor(dbutils.HeaderPrefix).Put([]byte("0500"), []byte{1})
+	}))
+	checksum, err = to.(*LmdbKV).Checksum(ctx, dbutils.HeaderPrefix)
+	require.NoError(err)
+	require.Equal(copied[dbutils.HeaderPrefix].Entries, checksum.Entries)
+	require.NotEqual(copied[dbutils.HeaderPrefix].Checksum, checksum.Checksum)
+}
@@ -0,0 +1,222 @@
+package ethdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/lmdb-go/lmdb"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+)
+
+// LmdbStatInterval - how often the stale reader slots are cleared, and the LMDB metrics are collected
+var LmdbStatInterval = 10 * time.Second
+
+var (
+	lmdbCommitTimer           = metrics.NewRegisteredTimer("db/lmdb/commit", nil)
+	lmdbFsyncTimer            = metrics.NewRegisteredTimer("db/lmdb/fsync", nil)
+	lmdbMapSizeGauge          = metrics.NewRegisteredGauge("db/lmdb/size/map", nil)
+	lmdbUsedGauge             = metrics.NewRegisteredGauge("db/lmdb/size/used", nil)
+	lmdbFreeGauge             = metrics.NewRegisteredGauge("db/lmdb/size/free", nil)
+	lmdbPinnedGauge           = metrics.NewRegisteredGauge("db/lmdb/size/pinned", nil)
+	lmdbReaderSlotsGauge      = metrics.NewRegisteredGauge("db/lmdb/readers/slots", nil)
+	lmdbActiveReadersGauge    = metrics.NewRegisteredGauge("db/lmdb/readers/active", nil)
+	lmdbStaleReadersCounter   = metrics.NewRegisteredCounter("db/lmdb/readers/stale", nil)
+	lmdbOldestReaderLagGauge  = metrics.NewRegisteredGauge("db/lmdb/readers/lag", nil)
+	lmdbLongestReadTxAgeGauge = metrics.NewRegisteredGauge("db/lmdb/readers/longest", nil) // milliseconds
+)
+
+// LmdbStat - health of the LMDB environment. The pages freed by the write transactions are reused by the later
+// ones, unless a read transaction which began before they were freed is still open: such pages are pinned,
+// and the file grows instead.
+type LmdbStat struct {
+	PageSize    uint64
+	MapSize     uint64 // bytes, the maximal size of the file
+	UsedPages   uint64 // pages of the file, up to the last used one
+	FreePages   uint64 // pages of the freelist
+	PinnedPages uint64 // pages of the freelist the next write can't reuse: freed by the last transaction, or after the oldest reader began
+	LastTxnID   uint64
+	MaxReaders  uint64
+	Readers     []LmdbReader // slots of the reader table, of all the processes using the environment
+	// StaleReaders - slots of the dead processes cleared by the last check
+	StaleReaders uint64
+	// LongestReadTx - age of the oldest read transaction of this process, 0 if there is none.
+	// The age of the transactions of other processes is not known, their lag is in Readers.
+	LongestReadTx time.Duration
+}
+
+// LmdbReader - slot of the reader table
+type LmdbReader struct {
+	Pid    int
+	Thread string
+	TxnID  uint64 // snapshot the read transaction is at, 0 if the slot has no transaction
+}
+
+// ActiveReaders - number of the reader slots having a read transaction
+func (s *LmdbStat) ActiveReaders() int {
+	var active int
+	for _, r := range s.Readers {
+		if r.TxnID != 0 {
+			active++
+		}
+	}
+	return active
+}
+
+// OldestReaderLag - number of the transactions committed since the oldest read transaction began
+func (s *LmdbStat) OldestReaderLag() uint64 {
+	var lag uint64
+	for _, r := range s.Readers {
+		if r.TxnID != 0 && s.LastTxnID-r.TxnID > lag {
+			lag = s.LastTxnID - r.TxnID
+		}
+	}
+	return lag
+}
+
+// Stat collects the statistics of the environment, and clears the reader slots of the dead processes
+func (db *LmdbKV) Stat(ctx context.Context) (*LmdbStat, error) {
+	if db.env == nil {
+		return nil, fmt.Errorf("db closed")
+	}
+	stale, err := db.env.ReaderCheck()
+	if err != nil {
+		return nil, fmt.Errorf("checking the reader slots: %w", err)
+	}
+	envStat, err := db.env.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info, err := db.env.Info()
+	if err != nil {
+		return nil, err
+	}
+	readers, err := db.readers()
+	if err != nil {
+		return nil, err
+	}
+	stat := &LmdbStat{
+		PageSize:      uint64(envStat.PSize),
+		MapSize:       uint64(info.MapSize),
+		UsedPages:     uint64(info.LastPNO) + 1,
+		LastTxnID:     uint64(info.LastTxnID),
+		MaxReaders:    uint64(info.MaxReaders),
+		Readers:       readers,
+		StaleReaders:  uint64(stale),
+		LongestReadTx: db.longestReadTx(),
+	}
+
+	// The pages freed by a transaction can be reused once all the readers are past it, as in mdb_find_oldest
+	oldest := stat.LastTxnID
+	for _, r := range readers {
+		if r.TxnID != 0 && r.TxnID < oldest {
+			oldest = r.TxnID
+		}
+	}
+	if err = db.View(ctx, func(tx Tx) error {
+		c, err := tx.(*lmdbTx).tx.OpenCursor(0) // the freelist
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		for k, v, err := c.Get(nil, nil, lmdb.First); k != nil; k, v, err = c.Get(nil, nil, lmdb.Next) {
+			if err != nil {
+				return err
+			}
+			if len(k) < 8 || len(v) < 8 {
+				return fmt.Errorf("unexpected freelist record %x", k)
+			}
+			// The key is the ID of the transaction which freed the pages, the value is the list of the pages,
+			// prefixed by its length. Both are in the native byte order.
+			pages := binary.LittleEndian.Uint64(v)
+			stat.FreePages += pages
+			if binary.LittleEndian.Uint64(k) >= oldest {
+				stat.PinnedPages += pages
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("reading the freelist: %w", err)
+	}
+	return stat, nil
+}
+
+// readers parses the reader table, as dumped by mdb_reader_list
+func (db *LmdbKV) readers() ([]LmdbReader, error) {
+	var readers []LmdbReader
+	var parseErr error
+	if err := db.env.ReaderList(func(msg string) error {
+		scanner := bufio.NewScanner(strings.NewReader(msg))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 3 || fields[0] == "pid" || strings.HasPrefix(fields[0], "(") {
+				continue // the header, or "(no active readers)"
+			}
+			pid, err := strconv.Atoi(fields[0])
+			if err != nil {
+				parseErr = fmt.Errorf("reader list: %w", err)
+				continue
+			}
+			r := LmdbReader{Pid: pid, Thread: fields[1]}
+			if fields[2] != "-" {
+				if r.TxnID, err = strconv.ParseUint(fields[2], 10, 64); err != nil {
+					parseErr = fmt.Errorf("reader list: %w", err)
+					continue
+				}
+			}
+			readers = append(readers, r)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return readers, parseErr
+}
+
+func (db *LmdbKV) longestReadTx() time.Duration {
+	db.readTxsLock.Lock()
+	defer db.readTxsLock.Unlock()
+	var longest time.Duration
+	for _, started := range db.readTxs {
+		if age := time.Since(started); age > longest {
+			longest = age
+		}
+	}
+	return longest
+}
+
+// staleReadsCheck clears the reader slots of the dead processes, which keep the pages freed after their
+// transactions pinned, and updates the metrics of the environment
+func (db *LmdbKV) staleReadsCheck(ctx context.Context) {
+	defer db.wg.Done()
+	ticker := time.NewTicker(LmdbStatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stat, err := db.Stat(ctx)
+		if err != nil {
+			db.log.Warn("failed to collect LMDB stats", "err", err)
+			continue
+		}
+		if stat.StaleReaders > 0 {
+			db.log.Warn("cleared reader slots from dead processes", "amount", stat.StaleReaders)
+		}
+		lmdbMapSizeGauge.Update(int64(stat.MapSize))
+		lmdbUsedGauge.Update(int64(stat.UsedPages * stat.PageSize))
+		lmdbFreeGauge.Update(int64(stat.FreePages * stat.PageSize))
+		lmdbPinnedGauge.Update(int64(stat.PinnedPages * stat.PageSize))
+		lmdbReaderSlotsGauge.Update(int64(len(stat.Readers)))
+		lmdbActiveReadersGauge.Update(int64(stat.ActiveReaders()))
+		lmdbStaleReadersCounter.Inc(int64(stat.StaleReaders))
+		lmdbOldestReaderLagGauge.Update(int64(stat.OldestReaderLag()))
+		lmdbLongestReadTxAgeGauge.Update(stat.LongestReadTx.Milliseconds())
+	}
+}
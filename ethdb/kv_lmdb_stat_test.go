@@ -0,0 +1,65 @@
+package ethdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+)
+
+func TestLmdbStat(t *testing.T) {
+	require := require.New(t)
+	kv := NewLMDB().InMem().MustOpen()
+	defer kv.Close()
+	db := kv.(*LmdbKV)
+	ctx := context.Background()
+
+	value := make([]byte, 4096)
+	require.NoError(kv.Update(ctx, func(tx Tx) error {
+		c := tx.Cursor(dbutils.HeaderPrefix)
+		for i := 0; i < 100; i++ {
+			if err := c.Put([]byte(fmt.Sprintf("%03d", i)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	// a reader keeps the snapshot with the values, while they are deleted
+	started, stop, stopped := make(chan error), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tx, err := kv.Begin(ctx, nil, false)
+		started <- err
+		if err != nil {
+			return
+		}
+		defer tx.Rollback()
+		<-stop
+	}()
+	require.NoError(<-started)
+	require.NoError(kv.Update(ctx, func(tx Tx) error {
+		return tx.(BucketMigrator).ClearBucket(dbutils.HeaderPrefix)
+	}))
+
+	stat, err := db.Stat(ctx)
+	require.NoError(err)
+	require.Equal(uint64(4096), stat.PageSize)
+	require.True(stat.UsedPages > 100)
+	require.True(stat.FreePages > 100)
+	require.True(stat.PinnedPages > 100)
+	require.Equal(1, stat.ActiveReaders())
+	require.Equal(uint64(1), stat.OldestReaderLag())
+	require.True(stat.LongestReadTx > 0)
+
+	close(stop)
+	<-stopped
+	stat, err = db.Stat(ctx)
+	require.NoError(err)
+	require.Equal(0, stat.ActiveReaders())
+	require.Equal(uint64(0), stat.OldestReaderLag())
+	require.Zero(stat.LongestReadTx)
+}
@@ -24,16 +24,21 @@ import (
 //go:generate protoc --go_out=. "./remote/kv.proto"
 //go:generate protoc --go_out=. "./remote/db.proto"
 //go:generate protoc --go_out=. "./remote/ethbackend.proto"
+//go:generate protoc --go_out=. "./remote/sentry.proto"
+//go:generate protoc --go_out=. "./remote/statediff.proto"
 
 // generate the services
 //go:generate protoc --go-grpc_out=. "./remote/kv.proto"
 //go:generate protoc --go-grpc_out=. "./remote/db.proto"
 //go:generate protoc --go-grpc_out=. "./remote/ethbackend.proto"
+//go:generate protoc --go-grpc_out=. "./remote/sentry.proto"
+//go:generate protoc --go-grpc_out=. "./remote/statediff.proto"
 
 type remoteOpts struct {
 	DialAddress string
 	inMemConn   *bufconn.Listener // for tests
 	bucketsCfg  BucketConfigsFunc
+	token       string // sent to the server with every request, if not empty
 }
 
 type RemoteKV struct {
@@ -89,6 +94,12 @@ func (opts remoteOpts) InMem(listener *bufconn.Listener) remoteOpts {
 	return opts
 }
 
+// WithToken - the bearer token which identifies the client to the server, see remotedbserver.ClientPolicy
+func (opts remoteOpts) WithToken(token string) remoteOpts {
+	opts.token = token
+	return opts
+}
+
 func (opts remoteOpts) Open(certFile, keyFile, caCert string) (KV, Backend, error) {
 	var dialOpts []grpc.DialOption
 	if certFile == "" {
@@ -134,6 +145,13 @@ func (opts remoteOpts) Open(certFile, keyFile, caCert string) (KV, Backend, erro
 		}
 	}
 
+	if opts.token != "" {
+		if certFile == "" {
+			return nil, nil, fmt.Errorf("the token of the private api is only sent over TLS, the certificate is not set")
+		}
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials(opts.token)))
+	}
+
 	if opts.inMemConn != nil {
 		dialOpts = append(dialOpts, grpc.WithContextDialer(func(ctx context.Context, url string) (net.Conn, error) {
 			return opts.inMemConn.Dial()
@@ -171,6 +189,17 @@ func (opts remoteOpts) Open(certFile, keyFile, caCert string) (KV, Backend, erro
 	return db, eth, nil
 }
 
+// tokenCredentials - the bearer token, sent over TLS only: anyone seeing it could use the private api as the client
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
 func (opts remoteOpts) MustOpen() (KV, Backend) {
 	db, txPool, err := opts.Open("", "", "")
 	if err != nil {
@@ -399,3 +428,80 @@ func (back *RemoteBackend) BloomStatus() (uint64, uint64, common.Hash) {
 	res, _ := back.remoteEthBackend.BloomStatus(context.Background(), &remote.BloomStatusRequest{})
 	return res.Size, res.Sections, common.BytesToHash(res.Hash)
 }
+
+func (back *RemoteBackend) PoolContent() ([]PoolTransaction, []PoolTransaction, error) {
+	res, err := back.remoteEthBackend.PoolContent(context.Background(), &remote.PoolContentRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	convert := func(in []*remote.PoolTransaction) []PoolTransaction {
+		out := make([]PoolTransaction, len(in))
+		for i, tx := range in {
+			out[i] = PoolTransaction{Sender: common.BytesToAddress(tx.Sender), RlpTx: tx.Rlptx}
+		}
+		return out
+	}
+	return convert(res.Pending), convert(res.Queued), nil
+}
+
+func (back *RemoteBackend) PoolStatus() (uint64, uint64, error) {
+	res, err := back.remoteEthBackend.PoolStatus(context.Background(), &remote.PoolStatusRequest{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return res.Pending, res.Queued, nil
+}
+
+func (back *RemoteBackend) PoolInspect() ([]PoolInspectItem, []PoolInspectItem, error) {
+	res, err := back.remoteEthBackend.PoolInspect(context.Background(), &remote.PoolInspectRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	convert := func(in []*remote.PoolInspectItem) []PoolInspectItem {
+		out := make([]PoolInspectItem, len(in))
+		for i, item := range in {
+			out[i] = PoolInspectItem{Sender: common.BytesToAddress(item.Sender), Nonce: item.Nonce, Summary: item.Summary}
+		}
+		return out
+	}
+	return convert(res.Pending), convert(res.Queued), nil
+}
+
+func (back *RemoteBackend) SyncStatus() (*SyncStatus, error) {
+	res, err := back.remoteEthBackend.SyncStatus(context.Background(), &remote.SyncStatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SyncStatus{
+		CurrentStage: res.CurrentStage,
+		HighestBlock: res.HighestBlock,
+		Stages:       make([]SyncStageStatus, len(res.Stages)),
+		ETA:          timeFromMillis(res.Eta),
+	}
+	for i, s := range res.Stages {
+		status.Stages[i] = SyncStageStatus{
+			Stage:           s.Stage,
+			Description:     s.Description,
+			Disabled:        s.Disabled,
+			BlockNumber:     s.BlockNumber,
+			BlocksPerSecond: s.BlocksPerSecond,
+			Unwinds:         s.Unwinds,
+			StartedAt:       timeFromMillis(s.StartedAt),
+			UpdatedAt:       timeFromMillis(s.UpdatedAt),
+		}
+	}
+	return status, nil
+}
+
+// timeFromMillis converts unix time in milliseconds, 0 means no time
+func timeFromMillis(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := time.Unix(0, ms*int64(time.Millisecond))
+	return &t
+}
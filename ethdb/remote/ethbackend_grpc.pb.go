@@ -21,6 +21,10 @@ type ETHBACKENDClient interface {
 	Etherbase(ctx context.Context, in *EtherbaseRequest, opts ...grpc.CallOption) (*EtherbaseReply, error)
 	NetVersion(ctx context.Context, in *NetVersionRequest, opts ...grpc.CallOption) (*NetVersionReply, error)
 	BloomStatus(ctx context.Context, in *BloomStatusRequest, opts ...grpc.CallOption) (*BloomStatusReply, error)
+	PoolContent(ctx context.Context, in *PoolContentRequest, opts ...grpc.CallOption) (*PoolContentReply, error)
+	PoolStatus(ctx context.Context, in *PoolStatusRequest, opts ...grpc.CallOption) (*PoolStatusReply, error)
+	PoolInspect(ctx context.Context, in *PoolInspectRequest, opts ...grpc.CallOption) (*PoolInspectReply, error)
+	SyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusReply, error)
 }
 
 type eTHBACKENDClient struct {
@@ -67,6 +71,42 @@ func (c *eTHBACKENDClient) BloomStatus(ctx context.Context, in *BloomStatusReque
 	return out, nil
 }
 
+func (c *eTHBACKENDClient) PoolContent(ctx context.Context, in *PoolContentRequest, opts ...grpc.CallOption) (*PoolContentReply, error) {
+	out := new(PoolContentReply)
+	err := c.cc.Invoke(ctx, "/remote.ETHBACKEND/PoolContent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) PoolStatus(ctx context.Context, in *PoolStatusRequest, opts ...grpc.CallOption) (*PoolStatusReply, error) {
+	out := new(PoolStatusReply)
+	err := c.cc.Invoke(ctx, "/remote.ETHBACKEND/PoolStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) PoolInspect(ctx context.Context, in *PoolInspectRequest, opts ...grpc.CallOption) (*PoolInspectReply, error) {
+	out := new(PoolInspectReply)
+	err := c.cc.Invoke(ctx, "/remote.ETHBACKEND/PoolInspect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTHBACKENDClient) SyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusReply, error) {
+	out := new(SyncStatusReply)
+	err := c.cc.Invoke(ctx, "/remote.ETHBACKEND/SyncStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ETHBACKENDServer is the server API for ETHBACKEND service.
 // All implementations must embed UnimplementedETHBACKENDServer
 // for forward compatibility
@@ -75,6 +115,10 @@ type ETHBACKENDServer interface {
 	Etherbase(context.Context, *EtherbaseRequest) (*EtherbaseReply, error)
 	NetVersion(context.Context, *NetVersionRequest) (*NetVersionReply, error)
 	BloomStatus(context.Context, *BloomStatusRequest) (*BloomStatusReply, error)
+	PoolContent(context.Context, *PoolContentRequest) (*PoolContentReply, error)
+	PoolStatus(context.Context, *PoolStatusRequest) (*PoolStatusReply, error)
+	PoolInspect(context.Context, *PoolInspectRequest) (*PoolInspectReply, error)
+	SyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusReply, error)
 	mustEmbedUnimplementedETHBACKENDServer()
 }
 
@@ -94,6 +138,18 @@ func (*UnimplementedETHBACKENDServer) NetVersion(context.Context, *NetVersionReq
 func (*UnimplementedETHBACKENDServer) BloomStatus(context.Context, *BloomStatusRequest) (*BloomStatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BloomStatus not implemented")
 }
+func (*UnimplementedETHBACKENDServer) PoolContent(context.Context, *PoolContentRequest) (*PoolContentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PoolContent not implemented")
+}
+func (*UnimplementedETHBACKENDServer) PoolStatus(context.Context, *PoolStatusRequest) (*PoolStatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PoolStatus not implemented")
+}
+func (*UnimplementedETHBACKENDServer) PoolInspect(context.Context, *PoolInspectRequest) (*PoolInspectReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PoolInspect not implemented")
+}
+func (*UnimplementedETHBACKENDServer) SyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncStatus not implemented")
+}
 func (*UnimplementedETHBACKENDServer) mustEmbedUnimplementedETHBACKENDServer() {}
 
 func RegisterETHBACKENDServer(s *grpc.Server, srv ETHBACKENDServer) {
@@ -172,6 +228,78 @@ func _ETHBACKEND_BloomStatus_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ETHBACKEND_PoolContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PoolContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).PoolContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.ETHBACKEND/PoolContent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).PoolContent(ctx, req.(*PoolContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_PoolStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PoolStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).PoolStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.ETHBACKEND/PoolStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).PoolStatus(ctx, req.(*PoolStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_PoolInspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PoolInspectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).PoolInspect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.ETHBACKEND/PoolInspect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).PoolInspect(ctx, req.(*PoolInspectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETHBACKEND_SyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETHBACKENDServer).SyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.ETHBACKEND/SyncStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETHBACKENDServer).SyncStatus(ctx, req.(*SyncStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ETHBACKEND_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "remote.ETHBACKEND",
 	HandlerType: (*ETHBACKENDServer)(nil),
@@ -192,6 +320,22 @@ var _ETHBACKEND_serviceDesc = grpc.ServiceDesc{
 			MethodName: "BloomStatus",
 			Handler:    _ETHBACKEND_BloomStatus_Handler,
 		},
+		{
+			MethodName: "PoolContent",
+			Handler:    _ETHBACKEND_PoolContent_Handler,
+		},
+		{
+			MethodName: "PoolStatus",
+			Handler:    _ETHBACKEND_PoolStatus_Handler,
+		},
+		{
+			MethodName: "PoolInspect",
+			Handler:    _ETHBACKEND_PoolInspect_Handler,
+		},
+		{
+			MethodName: "SyncStatus",
+			Handler:    _ETHBACKEND_SyncStatus_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "remote/ethbackend.proto",
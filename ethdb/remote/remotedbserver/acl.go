@@ -0,0 +1,314 @@
+package remotedbserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// ClientPolicy - what a client of the private API is allowed to do. An example of the policy file:
+//
+//	{"Clients": [
+//		{"Name": "rpcdaemon", "Subjects": ["CN=rpcdaemon,O=Example"], "Services": ["KV", "DB", "ETHBACKEND"]},
+//		{"Name": "partner", "Tokens": ["secret"], "Buckets": ["CST2", "b", "r"],
+//		 "MaxStreams": 4, "MaxTxTime": "5s", "Rate": 100, "Burst": 200}
+//	]}
+type ClientPolicy struct {
+	Name       string   // to tell the clients apart in the logs, and to keep the count of the streams on reload
+	Subjects   []string // subjects of the client certificate, like "CN=rpcdaemon,O=Example", or common names
+	Tokens     []string // sent by the client as "authorization: Bearer <token>" metadata
+	Services   []string // services, like "STATEDIFF", or methods, like "ETHBACKEND/Etherbase", the client can call; DefaultServices if empty
+	Buckets    []string // buckets the client can read, all the buckets if empty
+	MaxStreams int      // concurrent streams of the client, unlimited if 0
+	MaxTxTime  string   // how long a read transaction of the client can stay open, MaxTxTTL if empty
+	Rate       float64  // requests per second: calls, and the messages of the streams; unlimited if 0
+	Burst      int      // requests above the rate, Rate rounded up if 0
+}
+
+type Policy struct {
+	Clients []*ClientPolicy
+}
+
+// DefaultServices - the services of the clients which have no Services in the policy: reading the database.
+// The state diffs and the node backend (e.g. sending transactions) have to be granted.
+var DefaultServices = []string{"KV", "DB"}
+
+// aclClient - the client policy in the form checked on every request
+type aclClient struct {
+	name       string
+	services   map[string]struct{} // services and methods, without the "/remote." prefix of the full methods
+	buckets    map[string]struct{} // nil - all the buckets
+	maxStreams int32
+	maxTxTime  time.Duration
+	limiter    *rate.Limiter // nil - unlimited
+	streams    *int32        // open streams, shared by the versions of the policy of the client
+}
+
+// canCall checks the full method, like "/remote.KV/Seek", against the services and the methods of the client
+func (c *aclClient) canCall(fullMethod string) bool {
+	method := strings.TrimPrefix(fullMethod, "/remote.")
+	if _, ok := c.services[method]; ok {
+		return true
+	}
+	service := method
+	if i := strings.IndexByte(method, '/'); i >= 0 {
+		service = method[:i]
+	}
+	_, ok := c.services[service]
+	return ok
+}
+
+func (c *aclClient) canRead(bucket string) bool {
+	if c.buckets == nil {
+		return true
+	}
+	_, ok := c.buckets[bucket]
+	return ok
+}
+
+func (c *aclClient) allow() error {
+	if c.limiter != nil && !c.limiter.Allow() {
+		return status.Errorf(codes.ResourceExhausted, "client %s exceeded the request rate", c.name)
+	}
+	return nil
+}
+
+// ACL checks the requests to the private API against the policy loaded from a file, see ClientPolicy
+type ACL struct {
+	path string
+
+	lock      sync.RWMutex
+	bySubject map[string]*aclClient
+	byToken   map[string]*aclClient
+	streams   map[string]*int32 // by client name
+}
+
+// LoadACL reads the policy from the file, the policy is read again from it by Reload
+func LoadACL(path string) (*ACL, error) {
+	acl := &ACL{path: path, streams: map[string]*int32{}}
+	if err := acl.Reload(); err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+// Reload reads the policy file again, the requests are checked against the old policy if it fails
+func (a *ACL) Reload() error {
+	data, err := ioutil.ReadFile(a.path)
+	if err != nil {
+		return err
+	}
+	var policy Policy
+	if err = json.Unmarshal(data, &policy); err != nil {
+		return fmt.Errorf("parsing %s: %w", a.path, err)
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.apply(&policy)
+}
+
+func (a *ACL) apply(policy *Policy) error {
+	bySubject := map[string]*aclClient{}
+	byToken := map[string]*aclClient{}
+	for i, p := range policy.Clients {
+		if p.Name == "" {
+			return fmt.Errorf("client %d has no name", i)
+		}
+		c := &aclClient{name: p.Name, maxStreams: int32(p.MaxStreams)}
+		services := p.Services
+		if len(services) == 0 {
+			services = DefaultServices
+		}
+		c.services = make(map[string]struct{}, len(services))
+		for _, service := range services {
+			c.services[service] = struct{}{}
+		}
+		if len(p.Buckets) > 0 {
+			c.buckets = make(map[string]struct{}, len(p.Buckets))
+			for _, bucket := range p.Buckets {
+				c.buckets[bucket] = struct{}{}
+			}
+		}
+		if p.MaxTxTime != "" {
+			var err error
+			if c.maxTxTime, err = time.ParseDuration(p.MaxTxTime); err != nil {
+				return fmt.Errorf("MaxTxTime of client %s: %w", p.Name, err)
+			}
+		}
+		if p.Rate > 0 {
+			burst := p.Burst
+			if burst == 0 {
+				burst = int(math.Ceil(p.Rate))
+			}
+			c.limiter = rate.NewLimiter(rate.Limit(p.Rate), burst)
+		}
+		if c.streams = a.streams[p.Name]; c.streams == nil {
+			c.streams = new(int32)
+			a.streams[p.Name] = c.streams
+		}
+		for _, subject := range p.Subjects {
+			if _, ok := bySubject[subject]; ok {
+				return fmt.Errorf("subject %s is assigned to more than one client", subject)
+			}
+			bySubject[subject] = c
+		}
+		for _, token := range p.Tokens {
+			if _, ok := byToken[token]; ok {
+				return fmt.Errorf("a token of client %s is assigned to more than one client", p.Name)
+			}
+			byToken[token] = c
+		}
+	}
+	a.bySubject, a.byToken = bySubject, byToken
+	return nil
+}
+
+// ReloadOnSIGHUP reloads the policy when the process receives SIGHUP
+func (a *ACL) ReloadOnSIGHUP() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if err := a.Reload(); err != nil {
+				log.Error("Could not reload the private API policy, keeping the old one", "file", a.path, "err", err)
+				continue
+			}
+			log.Info("Reloaded the private API policy", "file", a.path)
+		}
+	}()
+}
+
+// authenticate finds the client by the bearer token, if it is sent, or by the verified client certificate
+func (a *ACL) authenticate(ctx context.Context) (*aclClient, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token := strings.TrimPrefix(values[0], "Bearer ")
+			if c, ok := a.byToken[token]; ok {
+				return c, nil
+			}
+			return nil, status.Error(codes.Unauthenticated, "unknown token")
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+			cert := tlsInfo.State.VerifiedChains[0][0]
+			if c, ok := a.bySubject[cert.Subject.String()]; ok {
+				return c, nil
+			}
+			if c, ok := a.bySubject[cert.Subject.CommonName]; ok {
+				return c, nil
+			}
+			return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to use the private API", cert.Subject)
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "no client certificate or token")
+}
+
+type aclClientKey struct{}
+
+// clientFromContext - the client of the request, nil if the requests are not checked
+func clientFromContext(ctx context.Context) *aclClient {
+	c, _ := ctx.Value(aclClientKey{}).(*aclClient)
+	return c
+}
+
+// bucketRequest - the requests which read a bucket
+type bucketRequest interface {
+	GetBucketName() string
+}
+
+func checkMethod(c *aclClient, fullMethod string) error {
+	if !c.canCall(fullMethod) {
+		return status.Errorf(codes.PermissionDenied, "client %s can't call %s", c.name, fullMethod)
+	}
+	return nil
+}
+
+func checkBucket(c *aclClient, req interface{}) error {
+	if r, ok := req.(bucketRequest); ok && !c.canRead(r.GetBucketName()) {
+		return status.Errorf(codes.PermissionDenied, "client %s can't read bucket %s", c.name, r.GetBucketName())
+	}
+	return nil
+}
+
+func (a *ACL) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		c, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkMethod(c, info.FullMethod); err != nil {
+			return nil, err
+		}
+		if err = c.allow(); err != nil {
+			return nil, err
+		}
+		if err = checkBucket(c, req); err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, aclClientKey{}, c), req)
+	}
+}
+
+func (a *ACL) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c, err := a.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		if err = checkMethod(c, info.FullMethod); err != nil {
+			return err
+		}
+		if err = c.allow(); err != nil {
+			return err
+		}
+		if open := atomic.AddInt32(c.streams, 1); c.maxStreams > 0 && open > c.maxStreams {
+			atomic.AddInt32(c.streams, -1)
+			return status.Errorf(codes.ResourceExhausted, "client %s has %d streams open already", c.name, c.maxStreams)
+		}
+		defer atomic.AddInt32(c.streams, -1)
+		return handler(srv, &aclServerStream{ServerStream: stream, ctx: context.WithValue(stream.Context(), aclClientKey{}, c), client: c})
+	}
+}
+
+// aclServerStream checks the messages received from the client
+type aclServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	client *aclClient
+}
+
+func (s *aclServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *aclServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := s.client.allow(); err != nil {
+		return err
+	}
+	return checkBucket(s.client, m)
+}
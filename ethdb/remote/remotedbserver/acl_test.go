@@ -0,0 +1,267 @@
+package remotedbserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+)
+
+// testBackend - the node backend of the ETHBACKEND service, only NetVersion is called
+type testBackend struct {
+	core.Backend
+}
+
+func (testBackend) NetVersion() (uint64, error) {
+	return 1, nil
+}
+
+func writePolicy(t *testing.T, path string, policy string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(policy), 0600))
+}
+
+func startACLServer(t *testing.T, acl *ACL) (*grpc.ClientConn, func()) {
+	kv := ethdb.NewLMDB().InMem().MustOpen()
+	require.NoError(t, kv.Update(context.Background(), func(tx ethdb.Tx) error {
+		for _, bucket := range []string{dbutils.HeaderPrefix, dbutils.PlainStateBucket} {
+			c := tx.Cursor(bucket)
+			for _, k := range []string{"a", "b", "c"} {
+				if err := c.Put([]byte(k), []byte(k)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}))
+
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(
+		grpc.StreamInterceptor(acl.StreamServerInterceptor()),
+		grpc.UnaryInterceptor(acl.UnaryServerInterceptor()),
+	)
+	remote.RegisterKVServer(grpcServer, NewKvServer(kv))
+	remote.RegisterDBServer(grpcServer, NewDBServer(kv))
+	remote.RegisterETHBACKENDServer(grpcServer, NewEthBackendServer(testBackend{}))
+	remote.RegisterSTATEDIFFServer(grpcServer, NewStateDiffServer(nil))
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	return conn, func() {
+		conn.Close()
+		grpcServer.Stop()
+		kv.Close()
+	}
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// seek reads the first pair of the bucket, the stream is closed on cancel
+func seek(ctx context.Context, client remote.KVClient, bucket string, streaming bool) (remote.KV_SeekClient, error) {
+	stream, err := client.Seek(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.Send(&remote.SeekRequest{BucketName: bucket, StartSreaming: streaming}); err != nil {
+		return nil, err
+	}
+	if _, err = stream.Recv(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func requireCode(t *testing.T, code codes.Code, err error) {
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestACLBuckets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+	writePolicy(t, path, `{"Clients": [{"Name": "partner", "Tokens": ["partner-token"], "Buckets": ["h"]}]}`)
+	acl, err := LoadACL(path)
+	require.NoError(t, err)
+	conn, stop := startACLServer(t, acl)
+	defer stop()
+	kvClient, dbClient := remote.NewKVClient(conn), remote.NewDBClient(conn)
+
+	ctx, cancel := context.WithCancel(withToken("partner-token"))
+	defer cancel()
+	_, err = seek(ctx, kvClient, dbutils.HeaderPrefix, true)
+	require.NoError(t, err)
+	_, err = seek(ctx, kvClient, dbutils.PlainStateBucket, true)
+	requireCode(t, codes.PermissionDenied, err)
+	_, err = dbClient.BucketSize(ctx, &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+	require.NoError(t, err)
+	_, err = dbClient.BucketSize(ctx, &remote.BucketSizeRequest{BucketName: dbutils.PlainStateBucket})
+	requireCode(t, codes.PermissionDenied, err)
+
+	_, err = seek(withToken("unknown"), kvClient, dbutils.HeaderPrefix, true)
+	requireCode(t, codes.Unauthenticated, err)
+	_, err = dbClient.BucketSize(context.Background(), &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+	requireCode(t, codes.Unauthenticated, err)
+
+	// the new policy applies to the next requests, a broken one is not loaded
+	writePolicy(t, path, `{"Clients": [{"Name": "partner", "Tokens": ["partner-token"]}]}`)
+	require.NoError(t, acl.Reload())
+	_, err = seek(ctx, kvClient, dbutils.PlainStateBucket, true)
+	require.NoError(t, err)
+	writePolicy(t, path, `{"Clients": [{"Name": "partner", "Tokens": ["partner-token"], "MaxTxTime": "soon"}]}`)
+	require.Error(t, acl.Reload())
+	_, err = seek(ctx, kvClient, dbutils.PlainStateBucket, true)
+	require.NoError(t, err)
+}
+
+func TestACLServices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+	writePolicy(t, path, `{"Clients": [
+		{"Name": "reader", "Tokens": ["reader-token"]},
+		{"Name": "rpcdaemon", "Tokens": ["rpcdaemon-token"], "Services": ["KV", "ETHBACKEND/NetVersion", "STATEDIFF"]}
+	]}`)
+	acl, err := LoadACL(path)
+	require.NoError(t, err)
+	conn, stop := startACLServer(t, acl)
+	defer stop()
+	kvClient, dbClient := remote.NewKVClient(conn), remote.NewDBClient(conn)
+	ethClient, stateDiffClient := remote.NewETHBACKENDClient(conn), remote.NewSTATEDIFFClient(conn)
+	// the server has no state diff log, so the granted subscription from a cursor fails after the checks
+	subscribe := func(ctx context.Context) error {
+		stream, err := stateDiffClient.Subscribe(ctx, &remote.StateDiffCursor{BlockNumber: 1})
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	// the database only by default
+	ctx, cancel := context.WithCancel(withToken("reader-token"))
+	defer cancel()
+	_, err = seek(ctx, kvClient, dbutils.HeaderPrefix, true)
+	require.NoError(t, err)
+	_, err = dbClient.BucketSize(ctx, &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+	require.NoError(t, err)
+	_, err = ethClient.NetVersion(ctx, &remote.NetVersionRequest{})
+	requireCode(t, codes.PermissionDenied, err)
+	requireCode(t, codes.PermissionDenied, subscribe(ctx))
+
+	// the granted services and methods only
+	ctx, cancel = context.WithCancel(withToken("rpcdaemon-token"))
+	defer cancel()
+	_, err = seek(ctx, kvClient, dbutils.HeaderPrefix, true)
+	require.NoError(t, err)
+	_, err = dbClient.BucketSize(ctx, &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+	requireCode(t, codes.PermissionDenied, err)
+	reply, err := ethClient.NetVersion(ctx, &remote.NetVersionRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), reply.Id)
+	_, err = ethClient.Etherbase(ctx, &remote.EtherbaseRequest{})
+	requireCode(t, codes.PermissionDenied, err)
+	requireCode(t, codes.FailedPrecondition, subscribe(ctx))
+}
+
+func TestACLQuotas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.json")
+	writePolicy(t, path, `{"Clients": [
+		{"Name": "streams", "Tokens": ["streams-token"], "MaxStreams": 1},
+		{"Name": "rate", "Tokens": ["rate-token"], "Rate": 0.001, "Burst": 2},
+		{"Name": "tx", "Tokens": ["tx-token"], "MaxTxTime": "100ms"}
+	]}`)
+	acl, err := LoadACL(path)
+	require.NoError(t, err)
+	conn, stop := startACLServer(t, acl)
+	defer stop()
+	kvClient, dbClient := remote.NewKVClient(conn), remote.NewDBClient(conn)
+
+	ctx, cancel := context.WithCancel(withToken("streams-token"))
+	_, err = seek(ctx, kvClient, dbutils.HeaderPrefix, false)
+	require.NoError(t, err)
+	_, err = seek(withToken("streams-token"), kvClient, dbutils.HeaderPrefix, false)
+	requireCode(t, codes.ResourceExhausted, err)
+	cancel()
+	require.Eventually(t, func() bool {
+		stream, err := seek(withToken("streams-token"), kvClient, dbutils.HeaderPrefix, true)
+		if err == nil {
+			for _, err = stream.Recv(); err == nil; _, err = stream.Recv() {
+			}
+			return true
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		_, err = dbClient.BucketSize(withToken("rate-token"), &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+		require.NoError(t, err)
+	}
+	_, err = dbClient.BucketSize(withToken("rate-token"), &remote.BucketSizeRequest{BucketName: dbutils.HeaderPrefix})
+	requireCode(t, codes.ResourceExhausted, err)
+
+	// the client which doesn't ask for the next pair can't hold the transaction open
+	stream, err := seek(withToken("tx-token"), kvClient, dbutils.HeaderPrefix, false)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	_, err = stream.Recv()
+	requireCode(t, codes.DeadlineExceeded, err)
+}
+
+func TestACLCertificateSubjects(t *testing.T) {
+	acl := &ACL{streams: map[string]*int32{}}
+	require.NoError(t, acl.apply(&Policy{Clients: []*ClientPolicy{
+		{Name: "rpcdaemon", Subjects: []string{"CN=rpcdaemon,O=Example"}},
+		{Name: "partner", Subjects: []string{"partner"}},
+	}}))
+	withCert := func(subject pkix.Name) context.Context {
+		state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}}}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	c, err := acl.authenticate(withCert(pkix.Name{CommonName: "rpcdaemon", Organization: []string{"Example"}}))
+	require.NoError(t, err)
+	require.Equal(t, "rpcdaemon", c.name)
+	c, err = acl.authenticate(withCert(pkix.Name{CommonName: "partner", Organization: []string{"Partner"}}))
+	require.NoError(t, err)
+	require.Equal(t, "partner", c.name)
+	_, err = acl.authenticate(withCert(pkix.Name{CommonName: "rpcdaemon", Organization: []string{"Partner"}}))
+	requireCode(t, codes.PermissionDenied, err)
+	_, err = acl.authenticate(context.Background())
+	requireCode(t, codes.Unauthenticated, err)
+
+	require.Error(t, acl.apply(&Policy{Clients: []*ClientPolicy{
+		{Name: "rpcdaemon", Subjects: []string{"rpcdaemon"}},
+		{Name: "partner", Subjects: []string{"rpcdaemon"}},
+	}}))
+}
@@ -2,38 +2,35 @@ package remotedbserver
 
 import (
 	"context"
+	"time"
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/core"
-	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/params"
-	"github.com/ledgerwatch/turbo-geth/rlp"
 )
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
 
-	eth core.Backend
+	eth  core.Backend
+	pool *core.EthBackend
 }
 
 func NewEthBackendServer(eth core.Backend) *EthBackendServer {
-	return &EthBackendServer{eth: eth}
+	return &EthBackendServer{eth: eth, pool: core.NewEthBackend(eth)}
 }
 
 func (s *EthBackendServer) Add(_ context.Context, in *remote.TxRequest) (*remote.AddReply, error) {
-	signedTx := new(types.Transaction)
 	out := &remote.AddReply{Hash: common.Hash{}.Bytes()}
 
-	if err := rlp.DecodeBytes(in.Signedtx, signedTx); err != nil {
-		return out, err
-	}
-
-	if err := s.eth.TxPool().AddLocal(signedTx); err != nil {
+	hash, err := s.pool.AddLocal(in.Signedtx)
+	if err != nil {
 		return out, err
 	}
 
-	out.Hash = signedTx.Hash().Bytes()
+	out.Hash = hash
 	return out, nil
 }
 
@@ -62,3 +59,78 @@ func (s *EthBackendServer) BloomStatus(_ context.Context, _ *remote.BloomStatusR
 
 	return &remote.BloomStatusReply{Size: params.BloomBitsBlocks, Sections: sections}, nil
 }
+
+func (s *EthBackendServer) PoolContent(_ context.Context, _ *remote.PoolContentRequest) (*remote.PoolContentReply, error) {
+	pending, queued, err := s.pool.PoolContent()
+	if err != nil {
+		return &remote.PoolContentReply{}, err
+	}
+
+	convert := func(in []ethdb.PoolTransaction) []*remote.PoolTransaction {
+		out := make([]*remote.PoolTransaction, len(in))
+		for i := range in {
+			out[i] = &remote.PoolTransaction{Sender: in[i].Sender.Bytes(), Rlptx: in[i].RlpTx}
+		}
+		return out
+	}
+	return &remote.PoolContentReply{Pending: convert(pending), Queued: convert(queued)}, nil
+}
+
+func (s *EthBackendServer) PoolStatus(_ context.Context, _ *remote.PoolStatusRequest) (*remote.PoolStatusReply, error) {
+	pending, queued, err := s.pool.PoolStatus()
+	if err != nil {
+		return &remote.PoolStatusReply{}, err
+	}
+	return &remote.PoolStatusReply{Pending: pending, Queued: queued}, nil
+}
+
+func (s *EthBackendServer) PoolInspect(_ context.Context, _ *remote.PoolInspectRequest) (*remote.PoolInspectReply, error) {
+	pending, queued, err := s.pool.PoolInspect()
+	if err != nil {
+		return &remote.PoolInspectReply{}, err
+	}
+
+	convert := func(in []ethdb.PoolInspectItem) []*remote.PoolInspectItem {
+		out := make([]*remote.PoolInspectItem, len(in))
+		for i := range in {
+			out[i] = &remote.PoolInspectItem{Sender: in[i].Sender.Bytes(), Nonce: in[i].Nonce, Summary: in[i].Summary}
+		}
+		return out
+	}
+	return &remote.PoolInspectReply{Pending: convert(pending), Queued: convert(queued)}, nil
+}
+
+func (s *EthBackendServer) SyncStatus(_ context.Context, _ *remote.SyncStatusRequest) (*remote.SyncStatusReply, error) {
+	status, err := s.eth.SyncStatus()
+	if err != nil {
+		return &remote.SyncStatusReply{}, err
+	}
+
+	out := &remote.SyncStatusReply{
+		CurrentStage: status.CurrentStage,
+		HighestBlock: status.HighestBlock,
+		Stages:       make([]*remote.SyncStageStatus, len(status.Stages)),
+		Eta:          millis(status.ETA),
+	}
+	for i, stage := range status.Stages {
+		out.Stages[i] = &remote.SyncStageStatus{
+			Stage:           stage.Stage,
+			Description:     stage.Description,
+			Disabled:        stage.Disabled,
+			BlockNumber:     stage.BlockNumber,
+			BlocksPerSecond: stage.BlocksPerSecond,
+			Unwinds:         stage.Unwinds,
+			StartedAt:       millis(stage.StartedAt),
+			UpdatedAt:       millis(stage.UpdatedAt),
+		}
+	}
+	return out, nil
+}
+
+// millis converts the time to unix time in milliseconds, 0 if there is no time
+func millis(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -10,7 +10,9 @@ import (
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/core"
@@ -28,7 +30,9 @@ type KvServer struct {
 	kv ethdb.KV
 }
 
-func StartGrpc(kv ethdb.KV, eth core.Backend, addr string, creds *credentials.TransportCredentials) {
+// StartGrpc starts the private API server, stateDiffs is nil if the state diffs aren't streamed,
+// acl is nil if any client can read all the buckets
+func StartGrpc(kv ethdb.KV, eth core.Backend, stateDiffs *StateDiffServer, addr string, creds *credentials.TransportCredentials, acl *ACL) {
 	log.Info("Starting private RPC server", "on", addr)
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -49,6 +53,11 @@ func StartGrpc(kv ethdb.KV, eth core.Backend, addr string, creds *credentials.Tr
 	}
 	streamInterceptors = append(streamInterceptors, grpc_recovery.StreamServerInterceptor())
 	unaryInterceptors = append(unaryInterceptors, grpc_recovery.UnaryServerInterceptor())
+	if acl != nil {
+		streamInterceptors = append(streamInterceptors, acl.StreamServerInterceptor())
+		unaryInterceptors = append(unaryInterceptors, acl.UnaryServerInterceptor())
+		acl.ReloadOnSIGHUP()
+	}
 	var grpcServer *grpc.Server
 	if creds == nil {
 		grpcServer = grpc.NewServer(
@@ -73,6 +82,9 @@ func StartGrpc(kv ethdb.KV, eth core.Backend, addr string, creds *credentials.Tr
 	remote.RegisterKVServer(grpcServer, kvSrv)
 	remote.RegisterDBServer(grpcServer, dbSrv)
 	remote.RegisterETHBACKENDServer(grpcServer, ethBackendSrv)
+	if stateDiffs != nil {
+		remote.RegisterSTATEDIFFServer(grpcServer, stateDiffs)
+	}
 
 	if metrics.Enabled {
 		grpc_prometheus.Register(grpcServer)
@@ -108,7 +120,12 @@ func (s *KvServer) Seek(stream remote.KV_SeekServer) error {
 
 	c := tx.Cursor(bucketName).Prefix(prefix)
 
-	txTicker := time.NewTicker(MaxTxTTL)
+	txTTL := MaxTxTTL
+	client := clientFromContext(stream.Context())
+	if client != nil && client.maxTxTime > 0 {
+		txTTL = client.maxTxTime
+	}
+	txTicker := time.NewTicker(txTTL)
 	defer txTicker.Stop()
 
 	// send all items to client, if k==nil - stil send it to client and break loop
@@ -127,7 +144,11 @@ func (s *KvServer) Seek(stream remote.KV_SeekServer) error {
 
 		// if client not requested stream then wait signal from him before send any item
 		if !in.StartSreaming {
-			in, err = stream.Recv()
+			if client != nil && client.maxTxTime > 0 {
+				in, err = recvWithin(stream, txTTL)
+			} else {
+				in, err = stream.Recv()
+			}
 			if err != nil {
 				if err == io.EOF {
 					return nil
@@ -136,7 +157,6 @@ func (s *KvServer) Seek(stream remote.KV_SeekServer) error {
 			}
 		}
 
-		//TODO: protect against client - which doesn't send any requests
 		select {
 		default:
 		case <-txTicker.C:
@@ -150,3 +170,25 @@ func (s *KvServer) Seek(stream remote.KV_SeekServer) error {
 		}
 	}
 }
+
+// recvWithin - the next request of the client, which doesn't keep the transaction open waiting longer than ttl
+func recvWithin(stream remote.KV_SeekServer, ttl time.Duration) (*remote.SeekRequest, error) {
+	type received struct {
+		in  *remote.SeekRequest
+		err error
+	}
+	ch := make(chan received, 1)
+	go func() {
+		in, err := stream.Recv()
+		ch <- received{in, err}
+	}()
+	timer := time.NewTimer(ttl)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.in, r.err
+	case <-timer.C:
+		// Recv returns when the stream is closed on return from the handler
+		return nil, status.Errorf(codes.DeadlineExceeded, "no request for %s, the transaction is closed", ttl)
+	}
+}
@@ -0,0 +1,91 @@
+package remotedbserver
+
+import (
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/turbo/statediff"
+)
+
+// StateDiffSubscriberBuffer is the number of events a subscriber can lag behind the stream before it gets disconnected
+const StateDiffSubscriberBuffer = 1024
+
+var errSlowSubscriber = errors.New("state diff subscriber is too slow, resubscribe from the cursor")
+
+// StateDiffServer streams the state diffs published by the Execution stage. The subscribers get the events
+// of the log following their cursors first, if there is a log. Without a log, the subscribers can only get
+// the new events, with an empty cursor.
+type StateDiffServer struct {
+	remote.UnimplementedSTATEDIFFServer // must be embedded to have forward compatible implementations.
+
+	log         statediff.Log
+	lock        sync.Mutex
+	subscribers map[chan *remote.StateDiffEvent]struct{}
+}
+
+func NewStateDiffServer(log statediff.Log) *StateDiffServer {
+	return &StateDiffServer{log: log, subscribers: make(map[chan *remote.StateDiffEvent]struct{})}
+}
+
+// Publish passes the event to the subscribers, without waiting for them: the subscribers which are
+// StateDiffSubscriberBuffer events behind are disconnected
+func (s *StateDiffServer) Publish(event *remote.StateDiffEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (s *StateDiffServer) Subscribe(cursor *remote.StateDiffCursor, server remote.STATEDIFF_SubscribeServer) error {
+	// Subscribe before the replay, so that no events are missed in between
+	ch := make(chan *remote.StateDiffEvent, StateDiffSubscriberBuffer)
+	s.lock.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		delete(s.subscribers, ch)
+	}()
+
+	var replayed bool
+	var lastSeq uint64
+	if s.log == nil && (cursor.BlockNumber != 0 || len(cursor.BlockHash) != 0) {
+		return status.Error(codes.FailedPrecondition, "the node keeps no state diff log to resume the stream from the cursor")
+	}
+	if s.log != nil {
+		if err := s.log.Replay(cursor, func(event *remote.StateDiffEvent) error {
+			replayed, lastSeq = true, event.Seq
+			return server.Send(event)
+		}); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case event, ok := <-ch:
+			if !ok {
+				return errSlowSubscriber
+			}
+			if replayed && event.Seq <= lastSeq {
+				continue
+			}
+			if err := server.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package remotedbserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+)
+
+type testSubscribeServer struct {
+	remote.STATEDIFF_SubscribeServer
+	ctx context.Context
+}
+
+func (s *testSubscribeServer) Context() context.Context {
+	return s.ctx
+}
+
+func TestStateDiffSubscribeWithoutLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := NewStateDiffServer(nil)
+
+	// there is no log to resume the stream from
+	err := s.Subscribe(&remote.StateDiffCursor{BlockNumber: 5}, &testSubscribeServer{ctx: ctx})
+	requireCode(t, codes.FailedPrecondition, err)
+	err = s.Subscribe(&remote.StateDiffCursor{BlockHash: []byte{1}}, &testSubscribeServer{ctx: ctx})
+	requireCode(t, codes.FailedPrecondition, err)
+
+	// with an empty cursor the new events are streamed until the subscriber leaves
+	require.NoError(t, s.Subscribe(&remote.StateDiffCursor{}, &testSubscribeServer{ctx: ctx}))
+}
@@ -0,0 +1,1210 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.13.0
+// source: remote/sentry.proto
+
+package remote
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type MessageId int32
+
+const (
+	MessageId_NewBlockHashes  MessageId = 0
+	MessageId_NewBlock        MessageId = 1
+	MessageId_BlockHeaders    MessageId = 2
+	MessageId_BlockBodies     MessageId = 3
+	MessageId_GetBlockHeaders MessageId = 4
+	MessageId_GetBlockBodies  MessageId = 5
+)
+
+// Enum value maps for MessageId.
+var (
+	MessageId_name = map[int32]string{
+		0: "NewBlockHashes",
+		1: "NewBlock",
+		2: "BlockHeaders",
+		3: "BlockBodies",
+		4: "GetBlockHeaders",
+		5: "GetBlockBodies",
+	}
+	MessageId_value = map[string]int32{
+		"NewBlockHashes":  0,
+		"NewBlock":        1,
+		"BlockHeaders":    2,
+		"BlockBodies":     3,
+		"GetBlockHeaders": 4,
+		"GetBlockBodies":  5,
+	}
+)
+
+func (x MessageId) Enum() *MessageId {
+	p := new(MessageId)
+	*p = x
+	return p
+}
+
+func (x MessageId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MessageId) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_sentry_proto_enumTypes[0].Descriptor()
+}
+
+func (MessageId) Type() protoreflect.EnumType {
+	return &file_remote_sentry_proto_enumTypes[0]
+}
+
+func (x MessageId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MessageId.Descriptor instead.
+func (MessageId) EnumDescriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{0}
+}
+
+type PenaltyKind int32
+
+const (
+	PenaltyKind_Kick PenaltyKind = 0
+)
+
+// Enum value maps for PenaltyKind.
+var (
+	PenaltyKind_name = map[int32]string{
+		0: "Kick",
+	}
+	PenaltyKind_value = map[string]int32{
+		"Kick": 0,
+	}
+)
+
+func (x PenaltyKind) Enum() *PenaltyKind {
+	p := new(PenaltyKind)
+	*p = x
+	return p
+}
+
+func (x PenaltyKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PenaltyKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_sentry_proto_enumTypes[1].Descriptor()
+}
+
+func (PenaltyKind) Type() protoreflect.EnumType {
+	return &file_remote_sentry_proto_enumTypes[1]
+}
+
+func (x PenaltyKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PenaltyKind.Descriptor instead.
+func (PenaltyKind) EnumDescriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{1}
+}
+
+type PeerEventId int32
+
+const (
+	PeerEventId_Connect    PeerEventId = 0
+	PeerEventId_Disconnect PeerEventId = 1
+	PeerEventId_MinBlock   PeerEventId = 2 // peer announced new highest block
+)
+
+// Enum value maps for PeerEventId.
+var (
+	PeerEventId_name = map[int32]string{
+		0: "Connect",
+		1: "Disconnect",
+		2: "MinBlock",
+	}
+	PeerEventId_value = map[string]int32{
+		"Connect":    0,
+		"Disconnect": 1,
+		"MinBlock":   2,
+	}
+)
+
+func (x PeerEventId) Enum() *PeerEventId {
+	p := new(PeerEventId)
+	*p = x
+	return p
+}
+
+func (x PeerEventId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PeerEventId) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_sentry_proto_enumTypes[2].Descriptor()
+}
+
+func (PeerEventId) Type() protoreflect.EnumType {
+	return &file_remote_sentry_proto_enumTypes[2]
+}
+
+func (x PeerEventId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PeerEventId.Descriptor instead.
+func (PeerEventId) EnumDescriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{2}
+}
+
+type OutboundMessageData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   MessageId `protobuf:"varint,1,opt,name=id,proto3,enum=remote.MessageId" json:"id,omitempty"`
+	Data []byte    `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"` // RLP encoded message payload
+}
+
+func (x *OutboundMessageData) Reset() {
+	*x = OutboundMessageData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutboundMessageData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundMessageData) ProtoMessage() {}
+
+func (x *OutboundMessageData) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundMessageData.ProtoReflect.Descriptor instead.
+func (*OutboundMessageData) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OutboundMessageData) GetId() MessageId {
+	if x != nil {
+		return x.Id
+	}
+	return MessageId_NewBlockHashes
+}
+
+func (x *OutboundMessageData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SendMessageByMinBlockRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data     *OutboundMessageData `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	MinBlock uint64               `protobuf:"varint,2,opt,name=minBlock,proto3" json:"minBlock,omitempty"`
+}
+
+func (x *SendMessageByMinBlockRequest) Reset() {
+	*x = SendMessageByMinBlockRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SendMessageByMinBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageByMinBlockRequest) ProtoMessage() {}
+
+func (x *SendMessageByMinBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageByMinBlockRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageByMinBlockRequest) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendMessageByMinBlockRequest) GetData() *OutboundMessageData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SendMessageByMinBlockRequest) GetMinBlock() uint64 {
+	if x != nil {
+		return x.MinBlock
+	}
+	return 0
+}
+
+type SendMessageByIdRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data   *OutboundMessageData `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	PeerId string               `protobuf:"bytes,2,opt,name=peerId,proto3" json:"peerId,omitempty"`
+}
+
+func (x *SendMessageByIdRequest) Reset() {
+	*x = SendMessageByIdRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SendMessageByIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageByIdRequest) ProtoMessage() {}
+
+func (x *SendMessageByIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageByIdRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageByIdRequest) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendMessageByIdRequest) GetData() *OutboundMessageData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SendMessageByIdRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type SentPeers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Peers []string `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+}
+
+func (x *SentPeers) Reset() {
+	*x = SentPeers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SentPeers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SentPeers) ProtoMessage() {}
+
+func (x *SentPeers) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SentPeers.ProtoReflect.Descriptor instead.
+func (*SentPeers) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SentPeers) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type PenalizePeerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PeerId  string      `protobuf:"bytes,1,opt,name=peerId,proto3" json:"peerId,omitempty"`
+	Penalty PenaltyKind `protobuf:"varint,2,opt,name=penalty,proto3,enum=remote.PenaltyKind" json:"penalty,omitempty"`
+}
+
+func (x *PenalizePeerRequest) Reset() {
+	*x = PenalizePeerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PenalizePeerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PenalizePeerRequest) ProtoMessage() {}
+
+func (x *PenalizePeerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PenalizePeerRequest.ProtoReflect.Descriptor instead.
+func (*PenalizePeerRequest) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PenalizePeerRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PenalizePeerRequest) GetPenalty() PenaltyKind {
+	if x != nil {
+		return x.Penalty
+	}
+	return PenaltyKind_Kick
+}
+
+type PenalizePeerReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PenalizePeerReply) Reset() {
+	*x = PenalizePeerReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PenalizePeerReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PenalizePeerReply) ProtoMessage() {}
+
+func (x *PenalizePeerReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PenalizePeerReply.ProtoReflect.Descriptor instead.
+func (*PenalizePeerReply) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{5}
+}
+
+type InboundMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     MessageId `protobuf:"varint,1,opt,name=id,proto3,enum=remote.MessageId" json:"id,omitempty"`
+	Data   []byte    `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"` // RLP encoded message payload
+	PeerId string    `protobuf:"bytes,3,opt,name=peerId,proto3" json:"peerId,omitempty"`
+}
+
+func (x *InboundMessage) Reset() {
+	*x = InboundMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InboundMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundMessage) ProtoMessage() {}
+
+func (x *InboundMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundMessage.ProtoReflect.Descriptor instead.
+func (*InboundMessage) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InboundMessage) GetId() MessageId {
+	if x != nil {
+		return x.Id
+	}
+	return MessageId_NewBlockHashes
+}
+
+func (x *InboundMessage) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *InboundMessage) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type Forks struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Genesis []byte   `protobuf:"bytes,1,opt,name=genesis,proto3" json:"genesis,omitempty"`
+	Forks   []uint64 `protobuf:"varint,2,rep,packed,name=forks,proto3" json:"forks,omitempty"`
+}
+
+func (x *Forks) Reset() {
+	*x = Forks{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Forks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Forks) ProtoMessage() {}
+
+func (x *Forks) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Forks.ProtoReflect.Descriptor instead.
+func (*Forks) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Forks) GetGenesis() []byte {
+	if x != nil {
+		return x.Genesis
+	}
+	return nil
+}
+
+func (x *Forks) GetForks() []uint64 {
+	if x != nil {
+		return x.Forks
+	}
+	return nil
+}
+
+type StatusData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NetworkId       uint64 `protobuf:"varint,1,opt,name=networkId,proto3" json:"networkId,omitempty"`
+	TotalDifficulty []byte `protobuf:"bytes,2,opt,name=totalDifficulty,proto3" json:"totalDifficulty,omitempty"`
+	BestHash        []byte `protobuf:"bytes,3,opt,name=bestHash,proto3" json:"bestHash,omitempty"`
+	ForkData        *Forks `protobuf:"bytes,4,opt,name=forkData,proto3" json:"forkData,omitempty"`
+	MaxBlock        uint64 `protobuf:"varint,5,opt,name=maxBlock,proto3" json:"maxBlock,omitempty"`
+}
+
+func (x *StatusData) Reset() {
+	*x = StatusData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusData) ProtoMessage() {}
+
+func (x *StatusData) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusData.ProtoReflect.Descriptor instead.
+func (*StatusData) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StatusData) GetNetworkId() uint64 {
+	if x != nil {
+		return x.NetworkId
+	}
+	return 0
+}
+
+func (x *StatusData) GetTotalDifficulty() []byte {
+	if x != nil {
+		return x.TotalDifficulty
+	}
+	return nil
+}
+
+func (x *StatusData) GetBestHash() []byte {
+	if x != nil {
+		return x.BestHash
+	}
+	return nil
+}
+
+func (x *StatusData) GetForkData() *Forks {
+	if x != nil {
+		return x.ForkData
+	}
+	return nil
+}
+
+func (x *StatusData) GetMaxBlock() uint64 {
+	if x != nil {
+		return x.MaxBlock
+	}
+	return 0
+}
+
+type SetStatusReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetStatusReply) Reset() {
+	*x = SetStatusReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetStatusReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStatusReply) ProtoMessage() {}
+
+func (x *SetStatusReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStatusReply.ProtoReflect.Descriptor instead.
+func (*SetStatusReply) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{9}
+}
+
+type ReceiveMessagesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReceiveMessagesRequest) Reset() {
+	*x = ReceiveMessagesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReceiveMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiveMessagesRequest) ProtoMessage() {}
+
+func (x *ReceiveMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiveMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ReceiveMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{10}
+}
+
+type ReceivePeerEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReceivePeerEventsRequest) Reset() {
+	*x = ReceivePeerEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReceivePeerEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceivePeerEventsRequest) ProtoMessage() {}
+
+func (x *ReceivePeerEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceivePeerEventsRequest.ProtoReflect.Descriptor instead.
+func (*ReceivePeerEventsRequest) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{11}
+}
+
+type PeerEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventId     PeerEventId `protobuf:"varint,1,opt,name=eventId,proto3,enum=remote.PeerEventId" json:"eventId,omitempty"`
+	PeerId      string      `protobuf:"bytes,2,opt,name=peerId,proto3" json:"peerId,omitempty"`
+	BlockHeight uint64      `protobuf:"varint,3,opt,name=blockHeight,proto3" json:"blockHeight,omitempty"`
+}
+
+func (x *PeerEvent) Reset() {
+	*x = PeerEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_sentry_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerEvent) ProtoMessage() {}
+
+func (x *PeerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_sentry_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerEvent.ProtoReflect.Descriptor instead.
+func (*PeerEvent) Descriptor() ([]byte, []int) {
+	return file_remote_sentry_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PeerEvent) GetEventId() PeerEventId {
+	if x != nil {
+		return x.EventId
+	}
+	return PeerEventId_Connect
+}
+
+func (x *PeerEvent) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PeerEvent) GetBlockHeight() uint64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+var File_remote_sentry_proto protoreflect.FileDescriptor
+
+var file_remote_sentry_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x73, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x22, 0x4c, 0x0a,
+	0x13, 0x4f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x44, 0x61, 0x74, 0x61, 0x12, 0x21, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x11, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x49, 0x64, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x6b, 0x0a, 0x1c, 0x53,
+	0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x4d, 0x69, 0x6e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x2e, 0x4f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08,
+	0x6d, 0x69, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
+	0x6d, 0x69, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x61, 0x0a, 0x16, 0x53, 0x65, 0x6e, 0x64,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2f, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x4f, 0x75, 0x74, 0x62, 0x6f, 0x75,
+	0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x22, 0x21, 0x0a, 0x09, 0x53,
+	0x65, 0x6e, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x22, 0x5c,
+	0x0a, 0x13, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2d, 0x0a,
+	0x07, 0x70, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13,
+	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x4b,
+	0x69, 0x6e, 0x64, 0x52, 0x07, 0x70, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x22, 0x13, 0x0a, 0x11,
+	0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x5f, 0x0a, 0x0e, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x49, 0x64, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65,
+	0x65, 0x72, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72,
+	0x49, 0x64, 0x22, 0x37, 0x0a, 0x05, 0x46, 0x6f, 0x72, 0x6b, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x67,
+	0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x67, 0x65,
+	0x6e, 0x65, 0x73, 0x69, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x04, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x6b, 0x73, 0x22, 0xb7, 0x01, 0x0a, 0x0a,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x0f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x44, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x44, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c,
+	0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x65, 0x73, 0x74, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x62, 0x65, 0x73, 0x74, 0x48, 0x61, 0x73, 0x68, 0x12, 0x29,
+	0x0a, 0x08, 0x66, 0x6f, 0x72, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6b, 0x73, 0x52,
+	0x08, 0x66, 0x6f, 0x72, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6d, 0x61, 0x78,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x10, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x18, 0x0a, 0x16, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x1a, 0x0a, 0x18, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x74, 0x0a,
+	0x09, 0x50, 0x65, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x07, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64,
+	0x52, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x65,
+	0x72, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x2a, 0x79, 0x0a, 0x09, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64,
+	0x12, 0x12, 0x0a, 0x0e, 0x4e, 0x65, 0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x4e, 0x65, 0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x64,
+	0x69, 0x65, 0x73, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x64, 0x69, 0x65, 0x73, 0x10, 0x05, 0x2a, 0x17,
+	0x0a, 0x0b, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x08, 0x0a,
+	0x04, 0x4b, 0x69, 0x63, 0x6b, 0x10, 0x00, 0x2a, 0x38, 0x0a, 0x0b, 0x50, 0x65, 0x65, 0x72, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x4d, 0x69, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x10,
+	0x02, 0x32, 0xba, 0x03, 0x0a, 0x06, 0x53, 0x45, 0x4e, 0x54, 0x52, 0x59, 0x12, 0x37, 0x0a, 0x09,
+	0x53, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x16, 0x2e,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x46, 0x0a, 0x0c, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a,
+	0x65, 0x50, 0x65, 0x65, 0x72, 0x12, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x50,
+	0x65, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x50, 0x65, 0x6e, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x50, 0x0a,
+	0x15, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x4d, 0x69,
+	0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x24, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e,
+	0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x4d, 0x69, 0x6e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x74, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12,
+	0x44, 0x0a, 0x0f, 0x53, 0x65, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79,
+	0x49, 0x64, 0x12, 0x1e, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x64,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x74,
+	0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x4b, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x30, 0x01, 0x12, 0x4a, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x50, 0x65, 0x65,
+	0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x2e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x50, 0x65, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x2d,
+	0x0a, 0x10, 0x69, 0x6f, 0x2e, 0x74, 0x75, 0x72, 0x62, 0x6f, 0x2d, 0x67, 0x65, 0x74, 0x68, 0x2e,
+	0x64, 0x62, 0x42, 0x06, 0x53, 0x45, 0x4e, 0x54, 0x52, 0x59, 0x50, 0x01, 0x5a, 0x0f, 0x2e, 0x2f,
+	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x3b, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_remote_sentry_proto_rawDescOnce sync.Once
+	file_remote_sentry_proto_rawDescData = file_remote_sentry_proto_rawDesc
+)
+
+func file_remote_sentry_proto_rawDescGZIP() []byte {
+	file_remote_sentry_proto_rawDescOnce.Do(func() {
+		file_remote_sentry_proto_rawDescData = protoimpl.X.CompressGZIP(file_remote_sentry_proto_rawDescData)
+	})
+	return file_remote_sentry_proto_rawDescData
+}
+
+var file_remote_sentry_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_remote_sentry_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_remote_sentry_proto_goTypes = []interface{}{
+	(MessageId)(0),                       // 0: remote.MessageId
+	(PenaltyKind)(0),                     // 1: remote.PenaltyKind
+	(PeerEventId)(0),                     // 2: remote.PeerEventId
+	(*OutboundMessageData)(nil),          // 3: remote.OutboundMessageData
+	(*SendMessageByMinBlockRequest)(nil), // 4: remote.SendMessageByMinBlockRequest
+	(*SendMessageByIdRequest)(nil),       // 5: remote.SendMessageByIdRequest
+	(*SentPeers)(nil),                    // 6: remote.SentPeers
+	(*PenalizePeerRequest)(nil),          // 7: remote.PenalizePeerRequest
+	(*PenalizePeerReply)(nil),            // 8: remote.PenalizePeerReply
+	(*InboundMessage)(nil),               // 9: remote.InboundMessage
+	(*Forks)(nil),                        // 10: remote.Forks
+	(*StatusData)(nil),                   // 11: remote.StatusData
+	(*SetStatusReply)(nil),               // 12: remote.SetStatusReply
+	(*ReceiveMessagesRequest)(nil),       // 13: remote.ReceiveMessagesRequest
+	(*ReceivePeerEventsRequest)(nil),     // 14: remote.ReceivePeerEventsRequest
+	(*PeerEvent)(nil),                    // 15: remote.PeerEvent
+}
+var file_remote_sentry_proto_depIdxs = []int32{
+	0,  // 0: remote.OutboundMessageData.id:type_name -> remote.MessageId
+	3,  // 1: remote.SendMessageByMinBlockRequest.data:type_name -> remote.OutboundMessageData
+	3,  // 2: remote.SendMessageByIdRequest.data:type_name -> remote.OutboundMessageData
+	1,  // 3: remote.PenalizePeerRequest.penalty:type_name -> remote.PenaltyKind
+	0,  // 4: remote.InboundMessage.id:type_name -> remote.MessageId
+	10, // 5: remote.StatusData.forkData:type_name -> remote.Forks
+	2,  // 6: remote.PeerEvent.eventId:type_name -> remote.PeerEventId
+	11, // 7: remote.SENTRY.SetStatus:input_type -> remote.StatusData
+	7,  // 8: remote.SENTRY.PenalizePeer:input_type -> remote.PenalizePeerRequest
+	4,  // 9: remote.SENTRY.SendMessageByMinBlock:input_type -> remote.SendMessageByMinBlockRequest
+	5,  // 10: remote.SENTRY.SendMessageById:input_type -> remote.SendMessageByIdRequest
+	13, // 11: remote.SENTRY.ReceiveMessages:input_type -> remote.ReceiveMessagesRequest
+	14, // 12: remote.SENTRY.ReceivePeerEvents:input_type -> remote.ReceivePeerEventsRequest
+	12, // 13: remote.SENTRY.SetStatus:output_type -> remote.SetStatusReply
+	8,  // 14: remote.SENTRY.PenalizePeer:output_type -> remote.PenalizePeerReply
+	6,  // 15: remote.SENTRY.SendMessageByMinBlock:output_type -> remote.SentPeers
+	6,  // 16: remote.SENTRY.SendMessageById:output_type -> remote.SentPeers
+	9,  // 17: remote.SENTRY.ReceiveMessages:output_type -> remote.InboundMessage
+	15, // 18: remote.SENTRY.ReceivePeerEvents:output_type -> remote.PeerEvent
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_remote_sentry_proto_init() }
+func file_remote_sentry_proto_init() {
+	if File_remote_sentry_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_remote_sentry_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutboundMessageData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SendMessageByMinBlockRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SendMessageByIdRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SentPeers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PenalizePeerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PenalizePeerReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InboundMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Forks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetStatusReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReceiveMessagesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReceivePeerEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_sentry_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_remote_sentry_proto_rawDesc,
+			NumEnums:      3,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remote_sentry_proto_goTypes,
+		DependencyIndexes: file_remote_sentry_proto_depIdxs,
+		EnumInfos:         file_remote_sentry_proto_enumTypes,
+		MessageInfos:      file_remote_sentry_proto_msgTypes,
+	}.Build()
+	File_remote_sentry_proto = out.File
+	file_remote_sentry_proto_rawDesc = nil
+	file_remote_sentry_proto_goTypes = nil
+	file_remote_sentry_proto_depIdxs = nil
+}
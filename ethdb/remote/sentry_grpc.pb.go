@@ -0,0 +1,339 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package remote
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// SENTRYClient is the client API for SENTRY service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SENTRYClient interface {
+	// SetStatus - provides sentry with the status of the core node, used in eth handshake with peers.
+	// Sentry does not accept peers until the status is set
+	SetStatus(ctx context.Context, in *StatusData, opts ...grpc.CallOption) (*SetStatusReply, error)
+	// PenalizePeer - applies penalty to the peer, for example, for sending invalid headers
+	PenalizePeer(ctx context.Context, in *PenalizePeerRequest, opts ...grpc.CallOption) (*PenalizePeerReply, error)
+	// SendMessageByMinBlock - sends the message to one of the peers which announced given block or higher
+	SendMessageByMinBlock(ctx context.Context, in *SendMessageByMinBlockRequest, opts ...grpc.CallOption) (*SentPeers, error)
+	// SendMessageById - sends the message to the given peer
+	SendMessageById(ctx context.Context, in *SendMessageByIdRequest, opts ...grpc.CallOption) (*SentPeers, error)
+	// ReceiveMessages - streams inbound eth messages from all peers of the sentry
+	ReceiveMessages(ctx context.Context, in *ReceiveMessagesRequest, opts ...grpc.CallOption) (SENTRY_ReceiveMessagesClient, error)
+	// ReceivePeerEvents - streams events about peers connecting, disconnecting and announcing new blocks
+	ReceivePeerEvents(ctx context.Context, in *ReceivePeerEventsRequest, opts ...grpc.CallOption) (SENTRY_ReceivePeerEventsClient, error)
+}
+
+type sENTRYClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSENTRYClient(cc grpc.ClientConnInterface) SENTRYClient {
+	return &sENTRYClient{cc}
+}
+
+func (c *sENTRYClient) SetStatus(ctx context.Context, in *StatusData, opts ...grpc.CallOption) (*SetStatusReply, error) {
+	out := new(SetStatusReply)
+	err := c.cc.Invoke(ctx, "/remote.SENTRY/SetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sENTRYClient) PenalizePeer(ctx context.Context, in *PenalizePeerRequest, opts ...grpc.CallOption) (*PenalizePeerReply, error) {
+	out := new(PenalizePeerReply)
+	err := c.cc.Invoke(ctx, "/remote.SENTRY/PenalizePeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sENTRYClient) SendMessageByMinBlock(ctx context.Context, in *SendMessageByMinBlockRequest, opts ...grpc.CallOption) (*SentPeers, error) {
+	out := new(SentPeers)
+	err := c.cc.Invoke(ctx, "/remote.SENTRY/SendMessageByMinBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sENTRYClient) SendMessageById(ctx context.Context, in *SendMessageByIdRequest, opts ...grpc.CallOption) (*SentPeers, error) {
+	out := new(SentPeers)
+	err := c.cc.Invoke(ctx, "/remote.SENTRY/SendMessageById", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sENTRYClient) ReceiveMessages(ctx context.Context, in *ReceiveMessagesRequest, opts ...grpc.CallOption) (SENTRY_ReceiveMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SENTRY_serviceDesc.Streams[0], "/remote.SENTRY/ReceiveMessages", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sENTRYReceiveMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SENTRY_ReceiveMessagesClient interface {
+	Recv() (*InboundMessage, error)
+	grpc.ClientStream
+}
+
+type sENTRYReceiveMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *sENTRYReceiveMessagesClient) Recv() (*InboundMessage, error) {
+	m := new(InboundMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sENTRYClient) ReceivePeerEvents(ctx context.Context, in *ReceivePeerEventsRequest, opts ...grpc.CallOption) (SENTRY_ReceivePeerEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SENTRY_serviceDesc.Streams[1], "/remote.SENTRY/ReceivePeerEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sENTRYReceivePeerEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SENTRY_ReceivePeerEventsClient interface {
+	Recv() (*PeerEvent, error)
+	grpc.ClientStream
+}
+
+type sENTRYReceivePeerEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sENTRYReceivePeerEventsClient) Recv() (*PeerEvent, error) {
+	m := new(PeerEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SENTRYServer is the server API for SENTRY service.
+// All implementations must embed UnimplementedSENTRYServer
+// for forward compatibility
+type SENTRYServer interface {
+	// SetStatus - provides sentry with the status of the core node, used in eth handshake with peers.
+	// Sentry does not accept peers until the status is set
+	SetStatus(context.Context, *StatusData) (*SetStatusReply, error)
+	// PenalizePeer - applies penalty to the peer, for example, for sending invalid headers
+	PenalizePeer(context.Context, *PenalizePeerRequest) (*PenalizePeerReply, error)
+	// SendMessageByMinBlock - sends the message to one of the peers which announced given block or higher
+	SendMessageByMinBlock(context.Context, *SendMessageByMinBlockRequest) (*SentPeers, error)
+	// SendMessageById - sends the message to the given peer
+	SendMessageById(context.Context, *SendMessageByIdRequest) (*SentPeers, error)
+	// ReceiveMessages - streams inbound eth messages from all peers of the sentry
+	ReceiveMessages(*ReceiveMessagesRequest, SENTRY_ReceiveMessagesServer) error
+	// ReceivePeerEvents - streams events about peers connecting, disconnecting and announcing new blocks
+	ReceivePeerEvents(*ReceivePeerEventsRequest, SENTRY_ReceivePeerEventsServer) error
+	mustEmbedUnimplementedSENTRYServer()
+}
+
+// UnimplementedSENTRYServer must be embedded to have forward compatible implementations.
+type UnimplementedSENTRYServer struct {
+}
+
+func (*UnimplementedSENTRYServer) SetStatus(context.Context, *StatusData) (*SetStatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetStatus not implemented")
+}
+func (*UnimplementedSENTRYServer) PenalizePeer(context.Context, *PenalizePeerRequest) (*PenalizePeerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PenalizePeer not implemented")
+}
+func (*UnimplementedSENTRYServer) SendMessageByMinBlock(context.Context, *SendMessageByMinBlockRequest) (*SentPeers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessageByMinBlock not implemented")
+}
+func (*UnimplementedSENTRYServer) SendMessageById(context.Context, *SendMessageByIdRequest) (*SentPeers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessageById not implemented")
+}
+func (*UnimplementedSENTRYServer) ReceiveMessages(*ReceiveMessagesRequest, SENTRY_ReceiveMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReceiveMessages not implemented")
+}
+func (*UnimplementedSENTRYServer) ReceivePeerEvents(*ReceivePeerEventsRequest, SENTRY_ReceivePeerEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReceivePeerEvents not implemented")
+}
+func (*UnimplementedSENTRYServer) mustEmbedUnimplementedSENTRYServer() {}
+
+func RegisterSENTRYServer(s *grpc.Server, srv SENTRYServer) {
+	s.RegisterService(&_SENTRY_serviceDesc, srv)
+}
+
+func _SENTRY_SetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusData)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SENTRYServer).SetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.SENTRY/SetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SENTRYServer).SetStatus(ctx, req.(*StatusData))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SENTRY_PenalizePeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PenalizePeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SENTRYServer).PenalizePeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.SENTRY/PenalizePeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SENTRYServer).PenalizePeer(ctx, req.(*PenalizePeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SENTRY_SendMessageByMinBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageByMinBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SENTRYServer).SendMessageByMinBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.SENTRY/SendMessageByMinBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SENTRYServer).SendMessageByMinBlock(ctx, req.(*SendMessageByMinBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SENTRY_SendMessageById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SENTRYServer).SendMessageById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.SENTRY/SendMessageById",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SENTRYServer).SendMessageById(ctx, req.(*SendMessageByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SENTRY_ReceiveMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReceiveMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SENTRYServer).ReceiveMessages(m, &sENTRYReceiveMessagesServer{stream})
+}
+
+type SENTRY_ReceiveMessagesServer interface {
+	Send(*InboundMessage) error
+	grpc.ServerStream
+}
+
+type sENTRYReceiveMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *sENTRYReceiveMessagesServer) Send(m *InboundMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SENTRY_ReceivePeerEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReceivePeerEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SENTRYServer).ReceivePeerEvents(m, &sENTRYReceivePeerEventsServer{stream})
+}
+
+type SENTRY_ReceivePeerEventsServer interface {
+	Send(*PeerEvent) error
+	grpc.ServerStream
+}
+
+type sENTRYReceivePeerEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sENTRYReceivePeerEventsServer) Send(m *PeerEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _SENTRY_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.SENTRY",
+	HandlerType: (*SENTRYServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetStatus",
+			Handler:    _SENTRY_SetStatus_Handler,
+		},
+		{
+			MethodName: "PenalizePeer",
+			Handler:    _SENTRY_PenalizePeer_Handler,
+		},
+		{
+			MethodName: "SendMessageByMinBlock",
+			Handler:    _SENTRY_SendMessageByMinBlock_Handler,
+		},
+		{
+			MethodName: "SendMessageById",
+			Handler:    _SENTRY_SendMessageById_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReceiveMessages",
+			Handler:       _SENTRY_ReceiveMessages_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReceivePeerEvents",
+			Handler:       _SENTRY_ReceivePeerEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "remote/sentry.proto",
+}
@@ -0,0 +1,123 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package remote
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// STATEDIFFClient is the client API for STATEDIFF service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type STATEDIFFClient interface {
+	// Subscribe - streams the state diffs of the executed blocks, and the unwinds of the state on reorgs.
+	// If the node keeps a log of the state diffs, the stream starts right after the position of the cursor in the log,
+	// otherwise only the new events are streamed, and a non-empty cursor fails with FAILED_PRECONDITION
+	Subscribe(ctx context.Context, in *StateDiffCursor, opts ...grpc.CallOption) (STATEDIFF_SubscribeClient, error)
+}
+
+type sTATEDIFFClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSTATEDIFFClient(cc grpc.ClientConnInterface) STATEDIFFClient {
+	return &sTATEDIFFClient{cc}
+}
+
+func (c *sTATEDIFFClient) Subscribe(ctx context.Context, in *StateDiffCursor, opts ...grpc.CallOption) (STATEDIFF_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_STATEDIFF_serviceDesc.Streams[0], "/remote.STATEDIFF/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sTATEDIFFSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type STATEDIFF_SubscribeClient interface {
+	Recv() (*StateDiffEvent, error)
+	grpc.ClientStream
+}
+
+type sTATEDIFFSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *sTATEDIFFSubscribeClient) Recv() (*StateDiffEvent, error) {
+	m := new(StateDiffEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// STATEDIFFServer is the server API for STATEDIFF service.
+// All implementations must embed UnimplementedSTATEDIFFServer
+// for forward compatibility
+type STATEDIFFServer interface {
+	// Subscribe - streams the state diffs of the executed blocks, and the unwinds of the state on reorgs.
+	// If the node keeps a log of the state diffs, the stream starts right after the position of the cursor in the log,
+	// otherwise only the new events are streamed, and a non-empty cursor fails with FAILED_PRECONDITION
+	Subscribe(*StateDiffCursor, STATEDIFF_SubscribeServer) error
+	mustEmbedUnimplementedSTATEDIFFServer()
+}
+
+// UnimplementedSTATEDIFFServer must be embedded to have forward compatible implementations.
+type UnimplementedSTATEDIFFServer struct {
+}
+
+func (*UnimplementedSTATEDIFFServer) Subscribe(*StateDiffCursor, STATEDIFF_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (*UnimplementedSTATEDIFFServer) mustEmbedUnimplementedSTATEDIFFServer() {}
+
+func RegisterSTATEDIFFServer(s *grpc.Server, srv STATEDIFFServer) {
+	s.RegisterService(&_STATEDIFF_serviceDesc, srv)
+}
+
+func _STATEDIFF_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StateDiffCursor)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(STATEDIFFServer).Subscribe(m, &sTATEDIFFSubscribeServer{stream})
+}
+
+type STATEDIFF_SubscribeServer interface {
+	Send(*StateDiffEvent) error
+	grpc.ServerStream
+}
+
+type sTATEDIFFSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *sTATEDIFFSubscribeServer) Send(m *StateDiffEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _STATEDIFF_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.STATEDIFF",
+	HandlerType: (*STATEDIFFServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _STATEDIFF_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "remote/statediff.proto",
+}
@@ -122,11 +122,16 @@ func (m *TxDb) Get(bucket string, key []byte) ([]byte, error) {
 	return v, nil
 }
 
+// GetIndexChunk reads the chunk in the transaction, which may have written the index
 func (m *TxDb) GetIndexChunk(bucket string, key []byte, timestamp uint64) ([]byte, error) {
-	if m.db != nil {
-		return m.db.GetIndexChunk(bucket, key, timestamp)
+	k, v, err := m.cursors[bucket].Seek(dbutils.IndexChunkKey(key, timestamp))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(k, dbutils.CompositeKeyWithoutIncarnation(key)) {
+		return nil, ErrKeyNotFound
 	}
-	return nil, ErrKeyNotFound
+	return common.CopyBytes(v), nil
 }
 
 func (m *TxDb) Has(bucket string, key []byte) (bool, error) {
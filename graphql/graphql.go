@@ -219,7 +219,7 @@ func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account,
 	}
 	var signer types.Signer = types.HomesteadSigner{}
 	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainID().ToBig())
+		signer = types.NewEIP2930Signer(tx.ChainID().ToBig())
 	}
 	from, _ := types.Sender(signer, tx)
 
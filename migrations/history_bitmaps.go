@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/common/etl"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+// The history indices are rebuilt as roaring bitmaps from the changesets, up to the progress of
+// their stages, instead of being converted chunk by chunk.
+var accountsHistoryBitmaps = Migration{
+	Name: "accounts_history_bitmaps",
+	Up: func(db ethdb.Database, datadir string, OnLoadCommit etl.LoadCommitHandler) error {
+		return historyToBitmaps(db, datadir, OnLoadCommit, dbutils.AccountsHistoryBucketOld1, dbutils.AccountsHistoryBucket,
+			dbutils.PlainAccountChangeSetBucket, stages.AccountHistoryIndex)
+	},
+}
+
+var storageHistoryBitmaps = Migration{
+	Name: "storage_history_bitmaps",
+	Up: func(db ethdb.Database, datadir string, OnLoadCommit etl.LoadCommitHandler) error {
+		return historyToBitmaps(db, datadir, OnLoadCommit, dbutils.StorageHistoryBucketOld1, dbutils.StorageHistoryBucket,
+			dbutils.PlainStorageChangeSetBucket, stages.StorageHistoryIndex)
+	},
+}
+
+func historyToBitmaps(db ethdb.Database, datadir string, OnLoadCommit etl.LoadCommitHandler, oldBucket, bucket, changeSetBucket string, stage stages.SyncStage) error {
+	if exists, err := db.(ethdb.BucketsMigrator).BucketExists(oldBucket); err != nil {
+		return err
+	} else if !exists {
+		return OnLoadCommit(db, nil, true)
+	}
+
+	if err := db.(ethdb.BucketsMigrator).ClearBuckets(bucket); err != nil {
+		return err
+	}
+	progress, _, err := stages.GetStageProgress(db, stage)
+	if err != nil {
+		return err
+	}
+	if progress > 0 {
+		ig := core.NewIndexGenerator(db, nil)
+		ig.TempDir = datadir
+		if err = ig.GenerateIndex(0, progress, changeSetBucket, datadir); err != nil {
+			return err
+		}
+	}
+
+	if err = db.(ethdb.BucketsMigrator).DropBuckets(oldBucket); err != nil {
+		return err
+	}
+	return OnLoadCommit(db, nil, true)
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestAccountsHistoryBitmaps(t *testing.T) {
+	require, db := require.New(t), ethdb.NewMemDatabase()
+
+	err := db.KV().Update(context.Background(), func(tx ethdb.Tx) error {
+		return tx.(ethdb.BucketMigrator).CreateBucket(dbutils.AccountsHistoryBucketOld1)
+	})
+	require.NoError(err)
+
+	addr := common.HexToAddress("0x1").Bytes()
+	for _, blockNum := range []uint64{1, 3, 4} {
+		cs := changeset.NewAccountChangeSetPlain()
+		require.NoError(cs.Add(addr, []byte{byte(blockNum)}))
+		v, err1 := changeset.EncodeAccountsPlain(cs)
+		require.NoError(err1)
+		require.NoError(db.Put(dbutils.PlainAccountChangeSetBucket, dbutils.EncodeTimestamp(blockNum), v))
+	}
+	// The changeset of the block 4 isn't indexed yet
+	require.NoError(stages.SaveStageProgress(db, stages.AccountHistoryIndex, 3, nil))
+	require.NoError(db.Put(dbutils.AccountsHistoryBucketOld1, append(common.CopyBytes(addr), 0xff), []byte{1}))
+
+	migrator := NewMigrator()
+	migrator.Migrations = []Migration{accountsHistoryBitmaps}
+	require.NoError(migrator.Apply(db, ""))
+
+	v, err := db.Get(dbutils.AccountsHistoryBucket, dbutils.CurrentChunkKey(addr))
+	require.NoError(err)
+	index := roaring.New()
+	require.NoError(index.UnmarshalBinary(v))
+	require.Equal([]uint32{1, 3}, index.ToArray())
+
+	exists, err := db.BucketExists(dbutils.AccountsHistoryBucketOld1)
+	require.NoError(err)
+	require.False(exists)
+
+	// applying the migration again is a no-op
+	require.NoError(migrator.Apply(db, ""))
+}
@@ -60,6 +60,8 @@ var migrations = []Migration{
 	dupSortHashState,
 	dupSortPlainState,
 	dupSortIH,
+	accountsHistoryBitmaps,
+	storageHistoryBitmaps,
 }
 
 type Migration struct {
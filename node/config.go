@@ -186,6 +186,10 @@ type Config struct {
 	// empty string means not to start the listener
 	PrivateApiAddr string
 
+	// Policy file of the clients of the remote database access, see remotedbserver.ClientPolicy
+	// empty string means that any client can read all the buckets
+	PrivateApiACL string
+
 	staticNodesWarning     bool
 	trustedNodesWarning    bool
 	oldGethResourceWarning bool
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,34 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Precompiles: []PrecompileConfig{{Name: "a", Address: common.HexToAddress("0x100"), Block: big.NewInt(10)}}},
+			new:     &ChainConfig{Precompiles: []PrecompileConfig{{Name: "a", Address: common.HexToAddress("0x100"), Block: big.NewInt(20)}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Precompiles: []PrecompileConfig{{Name: "a", Address: common.HexToAddress("0x100"), Block: big.NewInt(10)}}},
+			new:    &ChainConfig{},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0000000000000000000000000000000000000100 activation block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Precompiles: []PrecompileConfig{{Name: "a", Address: common.HexToAddress("0x100"), Block: big.NewInt(10)}}},
+			new:    &ChainConfig{Precompiles: []PrecompileConfig{{Name: "b", Address: common.HexToAddress("0x100"), Block: big.NewInt(10)}}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "precompile 0000000000000000000000000000000000000100 implementation",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
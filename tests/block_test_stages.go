@@ -0,0 +1,280 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// stagedChain is the chain built by the staged sync on top of the genesis of a block test, in an
+// in-memory database
+type stagedChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	db      *ethdb.ObjectDatabase
+	chain   *core.BlockChain
+	genesis common.Hash
+	blocks  []*types.Block // after the genesis
+	td      *big.Int
+}
+
+// RunInStages runs the test like Run, but the blocks are inserted by the stages of the staged sync
+// (Headers, Bodies, Senders, Execution, HashState, IntermediateHashes...) instead of BlockChain.
+// A block of a side chain with a higher total difficulty makes the stages unwind to the fork point,
+// and insert the blocks of the side chain instead. When the final state does not match the post state
+// of the test, the returned diff has a line per mismatching field.
+func (t *BlockTest) RunInStages(vmConfig vm.Config) (postStateDiff []string, err error) {
+	config, ok := Forks[t.json.Network]
+	if !ok {
+		return nil, UnsupportedForkError{t.json.Network}
+	}
+	var engine consensus.Engine
+	if t.json.SealEngine == "NoProof" {
+		engine = ethash.NewFaker()
+	} else {
+		engine = ethash.NewShared()
+	}
+
+	c, err := t.newStagedChain(config, engine, vmConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	// blocks accepted on any chain, the side chains are built from them
+	valid := make(map[common.Hash]*types.Block)
+	for _, b := range t.json.Blocks {
+		cb, err := b.decode()
+		if err != nil {
+			if b.BlockHeader == nil {
+				continue // OK - block is supposed to be invalid, continue with next block
+			}
+			return nil, fmt.Errorf("block RLP decoding failed when expected to succeed: %v", err)
+		}
+		if err = c.insertInStages(valid, cb); err != nil {
+			if b.BlockHeader == nil {
+				continue // OK - block is supposed to be invalid, continue with next block
+			}
+			return nil, fmt.Errorf("block #%v insertion into chain failed: %v", cb.Number(), err)
+		}
+		if b.BlockHeader == nil {
+			return nil, fmt.Errorf("block #%v insertion should have failed", cb.Number())
+		}
+		// validate RLP decoding by checking all values against test file JSON
+		if err = validateHeader(b.BlockHeader, cb.Header()); err != nil {
+			return nil, fmt.Errorf("deserialised block header validation failed: %v", err)
+		}
+		valid[cb.Hash()] = cb
+	}
+
+	postStateDiff = t.postStateDiff(state.New(state.NewPlainStateReader(c.db)))
+	if head := c.head(); common.Hash(t.json.BestBlock) != head {
+		return postStateDiff, fmt.Errorf("last block hash validation mismatch: want: %x, have: %x", t.json.BestBlock, head)
+	}
+	if len(postStateDiff) > 0 {
+		return postStateDiff, fmt.Errorf("post state validation failed: %d mismatches", len(postStateDiff))
+	}
+	return nil, nil
+}
+
+// insertInStages inserts the block into the chain if it is a child of the head. Otherwise the side chain
+// of the block is built from the valid blocks, and if it has a higher total difficulty, the stages are
+// unwound to the fork point and the blocks of the side chain are inserted instead.
+func (c *stagedChain) insertInStages(valid map[common.Hash]*types.Block, block *types.Block) error {
+	if block.ParentHash() == c.head() {
+		err := c.insert(block)
+		if err == nil {
+			return nil
+		}
+		// The stages which succeeded have already written the block
+		if unwindErr := c.unwind(uint64(len(c.blocks))); unwindErr != nil {
+			return fmt.Errorf("%v, then unwinding failed: %v", err, unwindErr)
+		}
+		return err
+	}
+
+	sideBlocks := []*types.Block{block}
+	sideTd := new(big.Int).Set(block.Difficulty())
+	parentHash := block.ParentHash()
+	for !c.isCanonical(parentHash) {
+		parent, ok := valid[parentHash]
+		if !ok {
+			return consensus.ErrUnknownAncestor
+		}
+		sideBlocks = append(sideBlocks, parent)
+		sideTd.Add(sideTd, parent.Difficulty())
+		parentHash = parent.ParentHash()
+	}
+	for i, j := 0, len(sideBlocks)-1; i < j; i, j = i+1, j-1 {
+		sideBlocks[i], sideBlocks[j] = sideBlocks[j], sideBlocks[i]
+	}
+	forkPoint := sideBlocks[0].NumberU64() - 1
+	sideTd.Add(sideTd, rawdb.ReadTd(c.db, parentHash, forkPoint))
+	// On a tie, the shorter chain wins like in the Headers stage, and of the same length the canonical one stays
+	sideLength := forkPoint + uint64(len(sideBlocks))
+	if cmp := sideTd.Cmp(c.td); cmp < 0 || (cmp == 0 && sideLength >= uint64(len(c.blocks))) {
+		return nil
+	}
+
+	canonicalBlocks := append([]*types.Block{}, c.blocks[forkPoint:]...)
+	if err := c.unwind(forkPoint); err != nil {
+		return err
+	}
+	for _, sideBlock := range sideBlocks {
+		err := c.insert(sideBlock)
+		if err == nil {
+			continue
+		}
+		// The block is invalid, switch back to the canonical blocks
+		if unwindErr := c.unwind(forkPoint); unwindErr != nil {
+			return fmt.Errorf("%v, then unwinding failed: %v", err, unwindErr)
+		}
+		for _, canonicalBlock := range canonicalBlocks {
+			if insertErr := c.insert(canonicalBlock); insertErr != nil {
+				return fmt.Errorf("%v, then reinserting the canonical blocks failed: %v", err, insertErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// newStagedChain commits the genesis of the test to a new in-memory database
+func (t *BlockTest) newStagedChain(config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*stagedChain, error) {
+	db := ethdb.NewMemDatabase()
+	gblock, _, err := t.genesis(config).Commit(db, false /* history */)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if gblock.Hash() != t.json.Genesis.Hash {
+		db.Close()
+		return nil, fmt.Errorf("genesis block hash doesn't match test: computed=%x, test=%x", gblock.Hash().Bytes()[:6], t.json.Genesis.Hash[:6])
+	}
+	if gblock.Root() != t.json.Genesis.StateRoot {
+		db.Close()
+		return nil, fmt.Errorf("genesis block state root does not match test: computed=%x, test=%x", gblock.Root().Bytes()[:6], t.json.Genesis.StateRoot[:6])
+	}
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanLimit: 0, Pruning: false}, config, engine, vmConfig, nil, nil)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &stagedChain{
+		config:  config,
+		engine:  engine,
+		db:      db,
+		chain:   chain,
+		genesis: gblock.Hash(),
+		td:      new(big.Int).Set(gblock.Difficulty()),
+	}, nil
+}
+
+func (c *stagedChain) head() common.Hash {
+	if len(c.blocks) == 0 {
+		return c.genesis
+	}
+	return c.blocks[len(c.blocks)-1].Hash()
+}
+
+// insert runs the stages for the block, which must be a child of the head. The checks done by the
+// block fetcher before the Bodies stage, and by BlockChain after the execution, are done here too.
+func (c *stagedChain) insert(block *types.Block) error {
+	header := block.Header()
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, header.UncleHash)
+	}
+	if hash := types.DeriveSha(block.Transactions()); hash != header.TxHash {
+		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
+	}
+	if err := c.engine.VerifyUncles(c.chain, block); err != nil {
+		return err
+	}
+	if err := stagedsync.InsertBlockInStages(c.db, c.config, c.engine, block, c.chain); err != nil {
+		return err
+	}
+	receipts := rawdb.ReadRawReceipts(c.db, block.Hash(), block.NumberU64())
+	var usedGas uint64
+	if len(receipts) > 0 {
+		usedGas = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+	if usedGas != header.GasUsed {
+		return fmt.Errorf("invalid gas used (remote: %d local: %d)", header.GasUsed, usedGas)
+	}
+	if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+		return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, bloom)
+	}
+	c.blocks = append(c.blocks, block)
+	c.td.Add(c.td, header.Difficulty)
+	return nil
+}
+
+// isCanonical returns whether the block is the genesis or one of the inserted blocks
+func (c *stagedChain) isCanonical(hash common.Hash) bool {
+	if hash == c.genesis {
+		return true
+	}
+	for _, block := range c.blocks {
+		if block.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// unwind unwinds the stages to the given block, removing the blocks after it from the chain
+func (c *stagedChain) unwind(to uint64) error {
+	if err := stagedsync.UnwindBlocksInStages(c.db, c.engine, to); err != nil {
+		return err
+	}
+	c.blocks = c.blocks[:to]
+	c.td = rawdb.ReadTd(c.db, c.head(), to)
+	return nil
+}
+
+func (c *stagedChain) close() {
+	c.chain.Stop()
+	c.db.Close()
+}
+
+// postStateDiff compares the accounts of the post state of the test with the state, it returns a
+// line per mismatching code, balance, nonce and storage item
+func (t *BlockTest) postStateDiff(statedb *state.IntraBlockState) []string {
+	var diff []string
+	for addr, acct := range t.json.Post {
+		if code := statedb.GetCode(addr); !bytes.Equal(code, acct.Code) {
+			diff = append(diff, fmt.Sprintf("%x code: want %x, have %x", addr, acct.Code, code))
+		}
+		if balance := statedb.GetBalance(addr); acct.Balance == nil || balance.ToBig().Cmp(acct.Balance) != 0 {
+			diff = append(diff, fmt.Sprintf("%x balance: want %d, have %d", addr, acct.Balance, balance.ToBig()))
+		}
+		if nonce := statedb.GetNonce(addr); nonce != acct.Nonce {
+			diff = append(diff, fmt.Sprintf("%x nonce: want %d, have %d", addr, acct.Nonce, nonce))
+		}
+		for key, value := range acct.Storage {
+			key := key
+			var have uint256.Int
+			statedb.GetState(addr, &key, &have)
+			if common.Hash(have.Bytes32()) != value {
+				diff = append(diff, fmt.Sprintf("%x storage %x: want %x, have %x", addr, key, value, have.Bytes32()))
+			}
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+func TestBlockTestInStages(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xcc")
+		config   = Forks["Istanbul"]
+		signer   = types.MakeSigner(config, big.NewInt(1))
+		gspec    = &core.Genesis{
+			Config:     config,
+			GasLimit:   10000000,
+			Difficulty: big.NewInt(131072),
+			Alloc: core.GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				// SSTORE(NUMBER, CALLVALUE)
+				contract: {Balance: new(big.Int), Code: []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE)}},
+			},
+		}
+	)
+	// generate builds a chain calling the contract with the given value in every block
+	generate := func(n int, value uint64) []*types.Block {
+		db := ethdb.NewMemDatabase()
+		defer db.Close()
+		blocks, _, err := core.GenerateChain(config, gspec.MustCommit(db), ethash.NewFaker(), db, n, func(i int, gen *core.BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), contract, uint256.NewInt().SetUint64(value), 100000, uint256.NewInt().SetUint64(1), nil), signer, key)
+			gen.AddTx(tx)
+		}, false /* intermediateHashes */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blocks
+	}
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	genesis := gspec.MustCommit(db)
+
+	toBtHeader := func(h *types.Header) *btHeader {
+		return &btHeader{
+			Bloom: h.Bloom, Coinbase: h.Coinbase, MixHash: h.MixDigest, Nonce: h.Nonce, Number: h.Number,
+			Hash: h.Hash(), ParentHash: h.ParentHash, ReceiptTrie: h.ReceiptHash, StateRoot: h.Root,
+			TransactionsTrie: h.TxHash, UncleHash: h.UncleHash, ExtraData: h.Extra, Difficulty: h.Difficulty,
+			GasLimit: h.GasLimit, GasUsed: h.GasUsed, Timestamp: h.Time,
+		}
+	}
+	newTest := func(blocks []*types.Block, invalid map[int]bool, best common.Hash, balance int64, storage map[common.Hash]common.Hash) *BlockTest {
+		test := &BlockTest{json: btJSON{
+			Genesis:    *toBtHeader(genesis.Header()),
+			Pre:        gspec.Alloc,
+			Post:       core.GenesisAlloc{contract: {Balance: big.NewInt(balance), Code: gspec.Alloc[contract].Code, Storage: storage}},
+			BestBlock:  common.UnprefixedHash(best),
+			Network:    "Istanbul",
+			SealEngine: "NoProof",
+		}}
+		for i, block := range blocks {
+			enc, err := rlp.EncodeToBytes(block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := btBlock{Rlp: hexutil.Encode(enc)}
+			if !invalid[i] {
+				b.BlockHeader = toBtHeader(block.Header())
+			}
+			test.json.Blocks = append(test.json.Blocks, b)
+		}
+		return test
+	}
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+
+	short, long := generate(2, 1), generate(3, 2)
+	badHeader := short[1].Header()
+	badHeader.Root = common.Hash{1}
+	bad := types.NewBlockWithHeader(badHeader).WithBody(short[1].Transactions(), short[1].Uncles())
+	badLongHeader := long[2].Header()
+	badLongHeader.Root = common.Hash{1}
+	badLong := types.NewBlockWithHeader(badLongHeader).WithBody(long[2].Transactions(), long[2].Uncles())
+
+	for _, tt := range []struct {
+		name     string
+		test     *BlockTest
+		err      string
+		mismatch int
+	}{
+		{
+			name: "canonical",
+			test: newTest(short, nil, short[1].Hash(), 2, map[common.Hash]common.Hash{slot(1): slot(1), slot(2): slot(1)}),
+		},
+		{
+			name: "reorg to the longer side chain",
+			test: newTest(append(append([]*types.Block{}, short...), long...), nil, long[2].Hash(), 6,
+				map[common.Hash]common.Hash{slot(1): slot(2), slot(2): slot(2), slot(3): slot(2)}),
+		},
+		{
+			name: "invalid block of the side chain rejected",
+			test: newTest([]*types.Block{short[0], short[1], long[0], long[1], badLong}, map[int]bool{4: true}, short[1].Hash(), 2,
+				map[common.Hash]common.Hash{slot(1): slot(1), slot(2): slot(1)}),
+		},
+		{
+			name: "invalid block rejected",
+			test: newTest([]*types.Block{short[0], bad, short[1]}, map[int]bool{1: true}, short[1].Hash(), 2,
+				map[common.Hash]common.Hash{slot(1): slot(1), slot(2): slot(1)}),
+		},
+		{
+			name: "invalid block accepted",
+			test: newTest([]*types.Block{short[0], short[1]}, map[int]bool{1: true}, short[1].Hash(), 2, nil),
+			err:  "insertion should have failed",
+		},
+		{
+			name:     "post state mismatch",
+			test:     newTest(short, nil, short[1].Hash(), 2, map[common.Hash]common.Hash{slot(1): slot(1), slot(2): slot(2)}),
+			err:      "post state validation failed",
+			mismatch: 1,
+		},
+	} {
+		diff, err := tt.test.RunInStages(vm.Config{})
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %v, diff %v", tt.name, err, diff)
+		} else if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+		}
+		if len(diff) != tt.mismatch {
+			t.Errorf("%s: expected %d post state mismatches, got %v", tt.name, tt.mismatch, diff)
+		}
+	}
+}
@@ -15,6 +15,7 @@ var DefaultFlags = []cli.Flag{
 	utils.TxPoolNoLocalsFlag,
 	utils.TxPoolJournalFlag,
 	utils.TxPoolRejournalFlag,
+	utils.TxPoolNoPersistFlag,
 	utils.TxPoolPriceLimitFlag,
 	utils.TxPoolPriceBumpFlag,
 	utils.TxPoolAccountSlotsFlag,
@@ -25,6 +26,7 @@ var DefaultFlags = []cli.Flag{
 	utils.TxLookupLimitFlag,
 	utils.StorageModeFlag,
 	utils.HddFlag,
+	utils.ReceiptsSyncFlag,
 	utils.DatabaseFlag,
 	utils.LMDBMapSizeFlag,
 	utils.TLSFlag,
@@ -32,6 +34,7 @@ var DefaultFlags = []cli.Flag{
 	utils.TLSKeyFlag,
 	utils.TLSCACertFlag,
 	utils.PrivateApiAddr,
+	utils.PrivateApiACL,
 	utils.ListenPortFlag,
 	utils.NATFlag,
 	utils.NoDiscoverFlag,
@@ -51,10 +54,28 @@ var DefaultFlags = []cli.Flag{
 	utils.GpoPercentileFlag,
 	utils.EWASMInterpreterFlag,
 	utils.EVMInterpreterFlag,
+	utils.DiffEVMInterpreterFlag,
+	utils.DiffStopFlag,
+	utils.StateDiffFileFlag,
+	utils.StateDiffStreamFlag,
+	utils.ExecutionCacheFlag,
+	utils.ExecutionWorkersFlag,
+	utils.SentryAddrFlag,
 	utils.InsecureUnlockAllowedFlag,
 	utils.MetricsEnabledFlag,
 	utils.MetricsEnabledExpensiveFlag,
 	utils.MetricsHTTPFlag,
 	utils.MetricsPortFlag,
 	utils.IdentityFlag,
+	utils.DeveloperFlag,
+	utils.DeveloperPeriodFlag,
+	utils.PasswordFileFlag,
+	utils.MiningEnabledFlag,
+	utils.MinerThreadsFlag,
+	utils.MinerEtherbaseFlag,
+	utils.MinerGasTargetFlag,
+	utils.MinerGasLimitFlag,
+	utils.MinerGasPriceFlag,
+	utils.MinerExtraDataFlag,
+	utils.MinerRecommitIntervalFlag,
 }
@@ -26,6 +26,9 @@ import (
 type TurboGethNode struct {
 	stack   *node.Node
 	backend *eth.Ethereum
+	// mining is set when the node was started with --mine or --dev, threads is the value of --miner.threads
+	mining  bool
+	threads int
 }
 
 // Serve runs the node and blocks the execution. It returns when the node is existed.
@@ -41,9 +44,13 @@ func (tg *TurboGethNode) Serve() error {
 
 func (tg *TurboGethNode) run() {
 	utils.StartNode(tg.stack)
-	// we don't have accounts locally and we don't do mining
-	// so these parts are ignored
+	// we don't have accounts locally, so unlocking them is ignored
 	// see cmd/geth/main.go#startNode for full implementation
+	if tg.mining {
+		if err := tg.backend.StartMining(tg.threads); err != nil {
+			utils.Fatalf("Failed to start mining: %v", err)
+		}
+	}
 }
 
 // Params contains optional parameters for creating a node.
@@ -75,7 +82,12 @@ func New(
 
 	ethereum := utils.RegisterEthService(node, ethConfig)
 
-	return &TurboGethNode{stack: node, backend: ethereum}
+	return &TurboGethNode{
+		stack:   node,
+		backend: ethereum,
+		mining:  ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name),
+		threads: ctx.GlobalInt(utils.MinerThreadsFlag.Name),
+	}
 }
 
 func makeEthConfig(ctx *cli.Context, node *node.Node) *eth.Config {
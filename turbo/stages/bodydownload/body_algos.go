@@ -0,0 +1,131 @@
+package bodydownload
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// Reset prepares the body download for the new round starting from the block following bodyProgress
+func (bd *BodyDownload) Reset(bodyProgress uint64) {
+	bd.lock.Lock()
+	defer bd.lock.Unlock()
+	bd.requestedLow = bodyProgress + 1
+	bd.requestedMap = make(map[DoubleHash]uint64)
+	for i := range bd.deliveries {
+		bd.deliveries[i] = nil
+		bd.requests[i] = nil
+		bd.delivered[i] = false
+	}
+}
+
+// RequestMoreBodies produces the next batch of body requests for the canonical headers in the
+// range from the lowest not yet delivered block up to headerProgress. Blocks whose previous requests
+// have not timed out yet, as well as already delivered blocks, are skipped
+func (bd *BodyDownload) RequestMoreBodies(db rawdb.DatabaseReader, headerProgress uint64, currentTime, timeout uint64) (*BodyRequest, error) {
+	bd.lock.Lock()
+	defer bd.lock.Unlock()
+	var blockNums []uint64
+	var hashes []common.Hash
+	for blockNum := bd.requestedLow; len(hashes) < MaxBodiesInRequest && blockNum <= headerProgress && blockNum < bd.requestedLow+bd.outstandingLimit; blockNum++ {
+		index := blockNum - bd.requestedLow
+		if bd.delivered[index] {
+			continue
+		}
+		if req := bd.requests[index]; req != nil && currentTime < req.waitUntil {
+			continue
+		}
+		header := bd.deliveries[index]
+		if header == nil {
+			hash := rawdb.ReadCanonicalHash(db, blockNum)
+			if hash == (common.Hash{}) {
+				return nil, fmt.Errorf("canonical hash for block %d not found", blockNum)
+			}
+			h := rawdb.ReadHeader(db, hash, blockNum)
+			if h == nil {
+				return nil, fmt.Errorf("header %d(%x) not found", blockNum, hash)
+			}
+			if h.TxHash == types.EmptyRootHash && h.UncleHash == types.EmptyUncleHash {
+				// Empty body, no need to request it
+				bd.deliveries[index] = types.NewBlockWithHeader(h)
+				bd.delivered[index] = true
+				continue
+			}
+			header = types.NewBlockWithHeader(h)
+			bd.deliveries[index] = header
+			var doubleHash DoubleHash
+			copy(doubleHash[:], h.TxHash[:])
+			copy(doubleHash[common.HashLength:], h.UncleHash[:])
+			bd.requestedMap[doubleHash] = blockNum
+		}
+		blockNums = append(blockNums, blockNum)
+		hashes = append(hashes, header.Hash())
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	req := &BodyRequest{BlockNums: blockNums, Hashes: hashes, waitUntil: currentTime + timeout}
+	for _, blockNum := range blockNums {
+		bd.requests[blockNum-bd.requestedLow] = req
+	}
+	return req, nil
+}
+
+// DeliverBody matches the delivered body with one of the requested headers by comparing
+// the transaction root and the uncle hash. Bodies which do not match any outstanding request are ignored
+func (bd *BodyDownload) DeliverBody(body *types.Body) bool {
+	bd.lock.Lock()
+	defer bd.lock.Unlock()
+	var doubleHash DoubleHash
+	txHash := types.DeriveSha(types.Transactions(body.Transactions))
+	uncleHash := types.CalcUncleHash(body.Uncles)
+	copy(doubleHash[:], txHash[:])
+	copy(doubleHash[common.HashLength:], uncleHash[:])
+	blockNum, ok := bd.requestedMap[doubleHash]
+	if !ok {
+		return false
+	}
+	delete(bd.requestedMap, doubleHash)
+	if blockNum < bd.requestedLow || blockNum >= bd.requestedLow+uint64(len(bd.deliveries)) {
+		return false
+	}
+	index := blockNum - bd.requestedLow
+	block := bd.deliveries[index]
+	if block == nil {
+		return false
+	}
+	bd.deliveries[index] = block.WithBody(body.Transactions, body.Uncles)
+	bd.delivered[index] = true
+	bd.requests[index] = nil
+	return true
+}
+
+// GetDeliveries returns the contiguous sequence of blocks (starting from the lowest requested block)
+// whose bodies have been delivered, and moves the lower bound of requests past them
+func (bd *BodyDownload) GetDeliveries() []*types.Block {
+	bd.lock.Lock()
+	defer bd.lock.Unlock()
+	var i uint64
+	for i = 0; i < uint64(len(bd.delivered)) && bd.delivered[i]; i++ {
+	}
+	if i == 0 {
+		return nil
+	}
+	blocks := make([]*types.Block, i)
+	copy(blocks, bd.deliveries[:i])
+	// Shift the windows
+	copy(bd.deliveries, bd.deliveries[i:])
+	copy(bd.requests, bd.requests[i:])
+	copy(bd.delivered, bd.delivered[i:])
+	for j := uint64(len(bd.delivered)) - i; j < uint64(len(bd.delivered)); j++ {
+		bd.deliveries[j] = nil
+		bd.requests[j] = nil
+		bd.delivered[j] = false
+	}
+	bd.requestedLow += i
+	log.Debug("Delivered bodies", "from", blocks[0].NumberU64(), "to", blocks[len(blocks)-1].NumberU64())
+	return blocks
+}
@@ -0,0 +1,44 @@
+package bodydownload
+
+import (
+	"sync"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+)
+
+// DoubleHash is type to be used for the mapping between TxHash and UncleHash to the block header
+type DoubleHash [2 * common.HashLength]byte
+
+const MaxBodiesInRequest = 128
+
+// BodyDownload represents the state of body downloading process
+// It is safe for concurrent use: requests are produced by the stage, while deliveries come from the network
+type BodyDownload struct {
+	lock             sync.Mutex
+	requestedMap     map[DoubleHash]uint64 // Mapping from (TxHash, UncleHash) to the block number the body was requested for
+	requestedLow     uint64                // Lower bound of block number for outstanding requests
+	outstandingLimit uint64                // Limit of number of outstanding blocks for body requests
+	deliveries       []*types.Block        // Headers of requested blocks, replaced by full blocks when bodies are delivered
+	requests         []*BodyRequest        // Outstanding requests, indexed the same way as deliveries
+	delivered        []bool                // Whether the body for the block has been delivered
+}
+
+// BodyRequest is a batch of block hashes whose bodies are requested from one peer
+type BodyRequest struct {
+	BlockNums []uint64
+	Hashes    []common.Hash
+	waitUntil uint64
+}
+
+// NewBodyDownload create a new body download state object
+func NewBodyDownload(outstandingLimit int) *BodyDownload {
+	bd := &BodyDownload{
+		requestedMap:     make(map[DoubleHash]uint64),
+		outstandingLimit: uint64(outstandingLimit),
+		deliveries:       make([]*types.Block, outstandingLimit+MaxBodiesInRequest),
+		requests:         make([]*BodyRequest, outstandingLimit+MaxBodiesInRequest),
+		delivered:        make([]bool, outstandingLimit+MaxBodiesInRequest),
+	}
+	return bd
+}
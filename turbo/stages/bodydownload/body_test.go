@@ -0,0 +1,77 @@
+package bodydownload
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestRequestAndDeliverBodies(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	// Block 1 has an empty body, blocks 2 and 3 contain one transaction each
+	var bodies []*types.Body
+	parent := common.Hash{}
+	for i := uint64(1); i <= 3; i++ {
+		body := &types.Body{}
+		if i > 1 {
+			body.Transactions = []*types.Transaction{types.NewTransaction(i, common.Address{1}, uint256.NewInt(), 21000, uint256.NewInt(), nil)}
+		}
+		bodies = append(bodies, body)
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(i),
+			TxHash:     types.DeriveSha(types.Transactions(body.Transactions)),
+			UncleHash:  types.CalcUncleHash(body.Uncles),
+		}
+		rawdb.WriteHeader(context.Background(), db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), i)
+		parent = header.Hash()
+	}
+
+	bd := NewBodyDownload(16)
+	bd.Reset(0)
+	req, err := bd.RequestMoreBodies(db, 3, 0, 10)
+	if err != nil {
+		t.Fatalf("request more bodies: %v", err)
+	}
+	if req == nil || len(req.BlockNums) != 2 || req.BlockNums[0] != 2 || req.BlockNums[1] != 3 {
+		t.Fatalf("expected request for blocks 2 and 3, got %+v", req)
+	}
+	// Outstanding requests are not repeated before the timeout
+	if req, err = bd.RequestMoreBodies(db, 3, 5, 10); err != nil || req != nil {
+		t.Fatalf("expected no request before timeout, got %+v, %v", req, err)
+	}
+
+	// Body of block 1 does not need to be requested
+	if blocks := bd.GetDeliveries(); len(blocks) != 1 || blocks[0].NumberU64() != 1 {
+		t.Fatalf("expected delivery of block 1, got %d blocks", len(blocks))
+	}
+	// Delivery out of order is held until the gap is filled
+	if !bd.DeliverBody(bodies[2]) {
+		t.Fatalf("body of block 3 not matched")
+	}
+	if blocks := bd.GetDeliveries(); len(blocks) != 0 {
+		t.Fatalf("expected no deliveries, got %d blocks", len(blocks))
+	}
+	if bd.DeliverBody(&types.Body{Uncles: []*types.Header{{Number: big.NewInt(1)}}}) {
+		t.Fatalf("unexpected body matched")
+	}
+	if !bd.DeliverBody(bodies[1]) {
+		t.Fatalf("body of block 2 not matched")
+	}
+	blocks := bd.GetDeliveries()
+	if len(blocks) != 2 || blocks[0].NumberU64() != 2 || blocks[1].NumberU64() != 3 {
+		t.Fatalf("expected deliveries of blocks 2 and 3, got %d blocks", len(blocks))
+	}
+	if len(blocks[1].Transactions()) != 1 {
+		t.Errorf("expected 1 transaction in block 3, got %d", len(blocks[1].Transactions()))
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/log"
 )
 
 // Implements sort.Interface so we can sort the incoming header in the message by block height
@@ -36,6 +38,8 @@ func (h HeadersByBlockHeight) Swap(i, j int) {
 
 // HandleHeadersMsg converts message containing headers into a collection of chain segments
 func (hd *HeaderDownload) HandleHeadersMsg(msg []*types.Header) ([]*ChainSegment, Penalty, error) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
 	sort.Sort(HeadersByBlockHeight(msg))
 	// Now all headers are order from the highest block height to the lowest
 	var segments []*ChainSegment                         // Segments being built
@@ -82,7 +86,7 @@ func (hd *HeaderDownload) childParentValid(child, parent *types.Header) (bool, P
 		return false, WrongChildBlockHeightPenalty
 	}
 	childDifficulty := hd.calcDifficultyFunc(child.Time, parent.Time, parent.Difficulty, parent.Number, parent.Hash(), parent.UncleHash)
-	if child.Difficulty.Cmp(childDifficulty) != 0 {
+	if !difficultyValid(child.Difficulty, childDifficulty) {
 		return false, WrongChildDifficultyPenalty
 	}
 	return true, NoPenalty
@@ -90,6 +94,8 @@ func (hd *HeaderDownload) childParentValid(child, parent *types.Header) (bool, P
 
 // HandleNewBlockMsg converts message containing 1 header into one singleton chain segment
 func (hd *HeaderDownload) HandleNewBlockMsg(header *types.Header) ([]*ChainSegment, Penalty, error) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
 	headerHash := header.Hash()
 	if _, bad := hd.badHeaders[headerHash]; bad {
 		return nil, BadBlockPenalty, nil
@@ -364,6 +370,7 @@ func (hd *HeaderDownload) HardCodedHeader(header *types.Header, totalDifficulty
 			uncleHash:            header.UncleHash,
 			difficulty:           *diff,
 			noPrepend:            true,
+			header:               header,
 		}
 		tipHash := header.Hash()
 		hd.hardTips[tipHash] = tip
@@ -408,8 +415,8 @@ func (hd *HeaderDownload) AnchorState() string {
 			sb.WriteString(fmt.Sprintf("%d (%d) tips=%d}", end, end-anchor.blockHeight, count))
 		}
 		sb.WriteString(fmt.Sprintf(" => %x", anchorParent))
-		j++
 		ss[j] = sb.String()
+		j++
 	}
 	sort.Strings(ss)
 	return strings.Join(ss, "\n")
@@ -451,7 +458,14 @@ func (h *Heap) Pop() interface{} {
 	return x
 }
 
+// RecoverFromFiles restores the working trees (anchors and tips) from the headers previously written
+// into the files by FlushBuffer
 func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	if hd.filesDir == "" {
+		return nil
+	}
 	fileInfos, err := ioutil.ReadDir(hd.filesDir)
 	if err != nil {
 		return err
@@ -460,7 +474,10 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 	heap.Init(h)
 	var buffer [HeaderSerLength]byte
 	for _, fileInfo := range fileInfos {
-		f, err1 := os.Open(fileInfo.Name())
+		if fileInfo.IsDir() || !strings.HasPrefix(fileInfo.Name(), bufferFilePrefix) {
+			continue
+		}
+		f, err1 := os.Open(filepath.Join(hd.filesDir, fileInfo.Name()))
 		if err1 != nil {
 			return fmt.Errorf("open file %s: %v", fileInfo.Name(), err1)
 		}
@@ -468,8 +485,9 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 		var header types.Header
 		if _, err = io.ReadFull(r, buffer[:]); err != nil {
 			if !errors.Is(err, io.EOF) {
-				fmt.Printf("reading header from file: %v\n", err)
+				log.Warn("Reading header from file", "file", fileInfo.Name(), "error", err)
 			}
+			f.Close()
 			continue
 		}
 		DeserialiseHeader(&header, buffer[:])
@@ -485,8 +503,6 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 		he := (heap.Pop(h)).(HeapElem)
 		if he.blockHeight > prevHeight {
 			// Clear out parent map and move childMap to its place
-			childAnchors = make(map[common.Hash]*Anchor)
-			childDiffs = make(map[common.Hash]*uint256.Int)
 			if he.blockHeight == prevHeight+1 {
 				parentAnchors = childAnchors
 				parentDiffs = childDiffs
@@ -495,27 +511,46 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 				parentAnchors = make(map[common.Hash]*Anchor)
 				parentDiffs = make(map[common.Hash]*uint256.Int)
 			}
+			childAnchors = make(map[common.Hash]*Anchor)
+			childDiffs = make(map[common.Hash]*uint256.Int)
 			prevHeight = he.blockHeight
 		}
-		// Since this header has already been processed, we do not expect overflow
-		headerDiff, overflow := uint256.FromBig(he.header.Difficulty)
-		if overflow {
-			return fmt.Errorf("overflow when converting header.Difficulty to uint256: %s", he.header.Difficulty)
-		}
-		if parentAnchor, found := parentAnchors[he.header.ParentHash]; found {
-			parentDiff := parentDiffs[he.header.ParentHash]
-			cumulativeDiff := headerDiff.Add(headerDiff, parentDiff)
+		headerHash := he.header.Hash()
+		if tip, duplicate := hd.getTip(headerHash, false); duplicate {
+			// The same header may have been flushed more than once
+			childAnchors[headerHash] = tip.anchor
+			childDiffs[headerHash] = new(uint256.Int).Set(&tip.cumulativeDifficulty)
+		} else if parentAnchor, found := parentAnchors[he.header.ParentHash]; found {
+			// Since this header has already been processed, we do not expect overflow
+			headerDiff, overflow := uint256.FromBig(he.header.Difficulty)
+			if overflow {
+				return fmt.Errorf("overflow when converting header.Difficulty to uint256: %s", he.header.Difficulty)
+			}
+			cumulativeDiff := headerDiff.Add(headerDiff, parentDiffs[he.header.ParentHash])
 			if err = hd.addHeaderAsTip(he.header, parentAnchor, *cumulativeDiff, currentTime); err != nil {
 				return fmt.Errorf("add header as tip: %v", err)
 			}
+			childAnchors[headerHash] = parentAnchor
+			childDiffs[headerHash] = cumulativeDiff
 		} else {
+			headerDiff, overflow := uint256.FromBig(he.header.Difficulty)
+			if overflow {
+				return fmt.Errorf("overflow when converting header.Difficulty to uint256: %s", he.header.Difficulty)
+			}
 			// Add header as anchor
 			//TODO - persist powDepth and totalDifficulty
-			if parentAnchor, err = hd.addHeaderAsAnchor(he.header, hd.initPowDepth, uint256.Int{}); err != nil {
-				return fmt.Errorf("add header as anchor: %v", err)
+			anchor, err1 := hd.addHeaderAsAnchor(he.header, hd.initPowDepth, uint256.Int{})
+			if err1 != nil {
+				return fmt.Errorf("add header as anchor: %v", err1)
+			}
+			if err = hd.addHeaderAsTip(he.header, anchor, *headerDiff, currentTime); err != nil {
+				return fmt.Errorf("add anchor header as tip: %v", err)
+			}
+			if he.header.ParentHash != (common.Hash{}) {
+				heap.Push(hd.requestQueue, RequestQueueItem{anchorParent: he.header.ParentHash, waitUntil: currentTime})
 			}
-			childAnchors[he.header.Hash()] = parentAnchor
-			childDiffs[he.header.Hash()] = new(uint256.Int)
+			childAnchors[headerHash] = anchor
+			childDiffs[headerHash] = headerDiff
 		}
 		var header types.Header
 		if _, err = io.ReadFull(he.reader, buffer[:]); err == nil {
@@ -525,10 +560,10 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 			heap.Push(h, he)
 		} else {
 			if !errors.Is(err, io.EOF) {
-				fmt.Printf("reading header from file: %v\n", err)
+				log.Warn("Reading header from file", "file", he.file.Name(), "error", err)
 			}
 			if err = he.file.Close(); err != nil {
-				fmt.Printf("closing file: %v\n", err)
+				log.Warn("Closing file", "file", he.file.Name(), "error", err)
 			}
 		}
 	}
@@ -536,6 +571,8 @@ func (hd *HeaderDownload) RecoverFromFiles(currentTime uint64) error {
 }
 
 func (hd *HeaderDownload) RequestMoreHeaders(currentTime, timeout uint64) []*HeaderRequest {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
 	if hd.requestQueue.Len() == 0 {
 		return nil
 	}
@@ -578,10 +615,21 @@ func (hd *HeaderDownload) resetRequestQueueTimer(prevTopTime, currentTime uint64
 	hd.RequestQueueTimer = time.NewTimer(time.Duration(nextTopTime-currentTime) * time.Second)
 }
 
+// FlushBuffer writes the headers accumulated by AddToBuffer into a new file, so that the working trees can be
+// restored by RecoverFromFiles after restart
 func (hd *HeaderDownload) FlushBuffer() error {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	return hd.flushBuffer()
+}
+
+func (hd *HeaderDownload) flushBuffer() error {
+	if hd.filesDir == "" || len(hd.buffer) == 0 {
+		return nil
+	}
 	// Sort the buffer first
 	sort.Sort(BufferSorter(hd.buffer))
-	if bufferFile, err := ioutil.TempFile(hd.filesDir, "headers-buf"); err == nil {
+	if bufferFile, err := ioutil.TempFile(hd.filesDir, bufferFilePrefix); err == nil {
 		if _, err = bufferFile.Write(hd.buffer); err != nil {
 			bufferFile.Close()
 			return err
@@ -622,13 +670,15 @@ func (hd *HeaderDownload) childTipValid(child *types.Header, tipHash common.Hash
 		return false, WrongChildBlockHeightPenalty
 	}
 	childDifficulty := hd.calcDifficultyFunc(child.Time, tip.timestamp, tip.difficulty.ToBig(), big.NewInt(int64(tip.blockHeight)), tipHash, tip.uncleHash)
-	if child.Difficulty.Cmp(childDifficulty) != 0 {
+	if !difficultyValid(child.Difficulty, childDifficulty) {
 		return false, WrongChildDifficultyPenalty
 	}
 	return true, NoPenalty
 }
 
 func (hd *HeaderDownload) HasTip(tipHash common.Hash) bool {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
 	if _, ok := hd.getTip(tipHash, false); ok {
 		return true
 	}
@@ -682,11 +732,16 @@ func (hd *HeaderDownload) addHeaderAsTip(header *types.Header, anchor *Anchor, c
 		blockHeight:          header.Number.Uint64(),
 		uncleHash:            header.UncleHash,
 		noPrepend:            false,
+		header:               header,
 	}
 	// Move expired items from protected map to the LRU cache
 	for hd.tipQueue.Len() > 0 {
 		if peek := (*hd.tipQueue)[0]; peek.tip.timestamp+hd.newAnchorPastLimit < currentTime {
 			p := heap.Pop(hd.tipQueue).(TipQueueItem)
+			if _, ok := hd.hardTips[p.tipHash]; !ok {
+				// Tip has been removed together with its tree (for example, inserted into the database)
+				continue
+			}
 			delete(hd.hardTips, p.tipHash)
 			hd.tips.Add(p.tipHash, p.tip)
 			//fmt.Printf("Moved tip %d [%x] from hard to soft %d+%d < %d\n", p.tip.blockHeight, tipHash, p.tip.timestamp, hd.newAnchorPastLimit, currentTime)
@@ -743,8 +798,202 @@ func (hd *HeaderDownload) anchorParentValid(anchor *Anchor, parent *types.Header
 		return false
 	}
 	childDifficulty := hd.calcDifficultyFunc(anchor.timestamp, parent.Time, parent.Difficulty, parent.Number, parent.Hash(), parent.UncleHash)
-	if anchor.difficulty.ToBig().Cmp(childDifficulty) != 0 {
+	if !difficultyValid(anchor.difficulty.ToBig(), childDifficulty) {
 		fmt.Printf("anchor.difficulty (%s) != childDifficulty (%s)\n", anchor.difficulty.ToBig(), childDifficulty)
+		return false
+	}
+	return true
+}
+
+// difficultyValid checks the difficulty of the child against the one calculated by the engine. The engine returns
+// nil if it can't tell it from the database, like clique for the parents not inserted yet, then only the seal is verified
+func difficultyValid(difficulty, calculated *big.Int) bool {
+	return calculated == nil || difficulty.Cmp(calculated) == 0
+}
+
+// ProcessSegment attaches the chain segment to the working trees: it either extends an existing tree up or down,
+// connects two trees, or creates a new anchor. Headers which were attached are also added to the buffer for
+// persistence. Returns true if the segment has been attached
+func (hd *HeaderDownload) ProcessSegment(segment *ChainSegment, currentTime uint64) bool {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	log.Debug("processSegment", "from", segment.Headers[0].Number.Uint64(), "to", segment.Headers[len(segment.Headers)-1].Number.Uint64())
+	foundAnchor, start, anchorParent, invalidAnchors := hd.FindAnchors(segment)
+	if len(invalidAnchors) > 0 {
+		if _, err1 := hd.InvalidateAnchors(anchorParent, invalidAnchors); err1 != nil {
+			log.Error("Invalidation of anchor failed", "error", err1)
+		}
+		log.Warn(fmt.Sprintf("Invalidated anchors %v for %x", invalidAnchors, anchorParent))
+	}
+	foundTip, end, penalty := hd.FindTip(segment, start) // We ignore penalty because we will check it as part of PoW check
+	if penalty != NoPenalty {
+		log.Error(fmt.Sprintf("FindTip penalty %d", penalty))
+		return false
+	}
+	var powDepth int
+	if powDepth1, err1 := hd.VerifySeals(segment, foundAnchor, start, end); err1 == nil {
+		powDepth = powDepth1
+	} else {
+		log.Error("VerifySeals", "error", err1)
+		return false
+	}
+	// There are 4 cases
+	if foundAnchor {
+		if foundTip {
+			// Connect
+			if err1 := hd.Connect(segment, start, end, currentTime); err1 != nil {
+				log.Error("Connect failed", "error", err1)
+				return false
+			}
+			log.Debug("Connected", "start", start, "end", end)
+		} else {
+			// ExtendDown
+			if err1 := hd.ExtendDown(segment, start, end, powDepth, currentTime); err1 != nil {
+				log.Error("ExtendDown failed", "error", err1)
+				return false
+			}
+			log.Debug("Extended Down", "start", start, "end", end)
+		}
+	} else if foundTip {
+		if end == 0 {
+			log.Debug("No action needed, tip already exists")
+			return false
+		}
+		// ExtendUp
+		if err1 := hd.ExtendUp(segment, start, end, currentTime); err1 != nil {
+			log.Error("ExtendUp failed", "error", err1)
+			return false
+		}
+		log.Debug("Extended Up", "start", start, "end", end)
+	} else {
+		// NewAnchor
+		if penalty, err1 := hd.NewAnchor(segment, start, end, currentTime); err1 != nil {
+			log.Error("NewAnchor failed", "error", err1)
+			return false
+		} else if penalty != NoPenalty {
+			log.Debug("NewAnchor rejected", "penalty", penalty)
+			return false
+		}
+		log.Debug("NewAnchor", "start", start, "end", end)
+	}
+	hd.AddToBuffer(segment, start, end)
+	if len(hd.buffer) >= bufferLimit {
+		if err := hd.flushBuffer(); err != nil {
+			log.Error("Could not flush header buffer", "error", err)
+		}
+	}
+	return true
+}
+
+// InsertHeaders finds the working trees whose anchors are attached to the headers already present in the
+// database (hasHeader is used to check that), and passes the heaviest chain of every such tree to insertHeaders,
+// ordered from parents to children. The trees are removed from the downloader afterwards, even if insertion failed,
+// because they will be downloaded again if needed. Returns the number of inserted headers
+func (hd *HeaderDownload) InsertHeaders(hasHeader func(hash common.Hash, number uint64) bool, insertHeaders func([]*types.Header) error) (int, error) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	var inserted int
+	for anchorParent, anchors := range hd.anchors {
+		var remaining []*Anchor
+		for _, anchor := range anchors {
+			if anchor.blockHeight == 0 || !hasHeader(anchorParent, anchor.blockHeight-1) {
+				remaining = append(remaining, anchor)
+				continue
+			}
+			chain := hd.heaviestChain(anchor)
+			hd.removeTree(anchor)
+			if len(chain) == 0 {
+				log.Warn("Working tree is broken, dropping it", "anchor", anchor.hash, "height", anchor.blockHeight)
+				continue
+			}
+			if err := insertHeaders(chain); err != nil {
+				return inserted, err
+			}
+			inserted += len(chain)
+		}
+		if len(remaining) > 0 {
+			hd.anchors[anchorParent] = remaining
+		} else {
+			delete(hd.anchors, anchorParent)
+		}
+	}
+	if inserted > 0 && len(hd.anchors) == 0 {
+		// Nothing is left in the working trees, so the persisted headers are no longer needed
+		if err := hd.removeFiles(); err != nil {
+			log.Warn("Could not remove header files", "error", err)
+		}
+	}
+	return inserted, nil
+}
+
+// heaviestChain returns the headers from the anchor to the tip with the highest cumulative difficulty, or nil
+// if some of the headers in between have been evicted
+func (hd *HeaderDownload) heaviestChain(anchor *Anchor) []*types.Header {
+	var best *Tip
+	for _, tipHash := range anchor.tips {
+		if tip, ok := hd.getTip(tipHash, false); ok {
+			if best == nil || tip.cumulativeDifficulty.Gt(&best.cumulativeDifficulty) ||
+				(tip.cumulativeDifficulty.Eq(&best.cumulativeDifficulty) && tip.blockHeight > best.blockHeight) {
+				best = tip
+			}
+		}
+	}
+	if best == nil || best.header == nil {
+		return nil
+	}
+	chain := make([]*types.Header, best.blockHeight-anchor.blockHeight+1)
+	tip := best
+	for i := len(chain) - 1; i >= 0; i-- {
+		if tip == nil || tip.header == nil {
+			return nil
+		}
+		chain[i] = tip.header
+		if i > 0 {
+			tip, _ = hd.getTip(tip.header.ParentHash, false)
+		}
+	}
+	if chain[0].Hash() != anchor.hash {
+		return nil
+	}
+	return chain
+}
+
+// removeTree removes all tips of the tree rooted at the given anchor
+func (hd *HeaderDownload) removeTree(anchor *Anchor) {
+	tips := anchor.tips
+	anchor.tips = nil
+	for _, tipHash := range tips {
+		delete(hd.hardTips, tipHash)
+		hd.tips.Remove(tipHash)
+	}
+}
+
+func (hd *HeaderDownload) removeFiles() error {
+	hd.buffer = hd.buffer[:0]
+	if hd.filesDir == "" {
+		return nil
+	}
+	fileInfos, err := ioutil.ReadDir(hd.filesDir)
+	if err != nil {
+		return err
+	}
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() && strings.HasPrefix(fileInfo.Name(), bufferFilePrefix) {
+			if err = os.Remove(filepath.Join(hd.filesDir, fileInfo.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AnchorCount returns the number of working trees which are not yet connected to the database
+func (hd *HeaderDownload) AnchorCount() int {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	var count int
+	for _, anchors := range hd.anchors {
+		count += len(anchors)
 	}
-	return anchor.difficulty.ToBig().Cmp(childDifficulty) == 0
+	return count
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -31,6 +32,7 @@ type Tip struct {
 	blockHeight          uint64
 	uncleHash            common.Hash
 	noPrepend            bool
+	header               *types.Header // Full header, kept to be inserted into the database when the tree gets connected to it
 }
 
 // First item in ChainSegment is the anchor
@@ -68,10 +70,18 @@ type HeaderRequest struct {
 	Length int
 }
 
+const (
+	// bufferFilePrefix is the name prefix of the files where the headers of the working trees are persisted
+	bufferFilePrefix = "headers-buf"
+	// bufferLimit is the size of the header buffer (in bytes) after which it gets flushed into a file
+	bufferLimit = 16 * 1024 * 1024
+)
+
 type VerifySealFunc func(header *types.Header) error
 type CalcDifficultyFunc func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int
 
 type HeaderDownload struct {
+	lock     sync.Mutex // Guards the high level methods (ProcessSegment, InsertHeaders, RequestMoreHeaders, etc.) against concurrent use
 	buffer   []byte
 	filesDir string
 	//currentFile            *os.File
@@ -2,7 +2,9 @@ package headerdownload
 
 import (
 	"bytes"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 
@@ -473,3 +475,121 @@ func TestHeaderSerialisation(t *testing.T) {
 		t.Errorf("header serialistion must be the same")
 	}
 }
+
+// testChain creates a chain of headers with the given length, attached to the given parent hash at height 0
+func testChain(parentHash common.Hash, length int, currentTime uint64) []*types.Header {
+	headers := make([]*types.Header, length)
+	for i := range headers {
+		h := &types.Header{
+			Number:     big.NewInt(int64(i + 1)),
+			Difficulty: big.NewInt(int64(1000 * (i + 1))),
+			ParentHash: parentHash,
+			Time:       currentTime,
+		}
+		headers[i] = h
+		parentHash = h.Hash()
+	}
+	return headers
+}
+
+func newTestHeaderDownload(filesDir string) *HeaderDownload {
+	return NewHeaderDownload(filesDir, 10, 16, func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int {
+		// To get child difficulty, we just add 1000 to the parent difficulty
+		return big.NewInt(0).Add(parentDifficulty, big.NewInt(1000))
+	}, func(header *types.Header) error {
+		return nil
+	}, 60, 60,
+	)
+}
+
+func TestInsertHeaders(t *testing.T) {
+	hd := newTestHeaderDownload("")
+	currentTime := uint64(time.Now().Unix())
+	genesisHash := common.HexToHash("0x1234")
+	chain := testChain(genesisHash, 5, currentTime)
+	segments, penalty, err := hd.HandleHeadersMsg(append([]*types.Header{}, chain...)) // HandleHeadersMsg sorts the headers in place
+	if err != nil || penalty != NoPenalty || len(segments) != 1 {
+		t.Fatalf("handle headers msg: segments %d, penalty %s, err %v", len(segments), penalty, err)
+	}
+	if !hd.ProcessSegment(segments[0], currentTime) {
+		t.Fatalf("segment expected to be attached")
+	}
+	if hd.AnchorCount() != 1 {
+		t.Fatalf("expected 1 anchor, got %d", hd.AnchorCount())
+	}
+
+	var inserted []*types.Header
+	insert := func(headers []*types.Header) error {
+		inserted = append(inserted, headers...)
+		return nil
+	}
+	// Parent of the anchor is not in the database yet
+	if n, err := hd.InsertHeaders(func(common.Hash, uint64) bool { return false }, insert); err != nil || n != 0 {
+		t.Fatalf("expected no headers inserted, got %d, err %v", n, err)
+	}
+	// Parent of the anchor is in the database
+	hasHeader := func(hash common.Hash, number uint64) bool {
+		return hash == genesisHash && number == 0
+	}
+	if n, err := hd.InsertHeaders(hasHeader, insert); err != nil || n != len(chain) {
+		t.Fatalf("expected %d headers inserted, got %d, err %v", len(chain), n, err)
+	}
+	for i, h := range inserted {
+		if h.Hash() != chain[i].Hash() {
+			t.Errorf("inserted header %d: expected %x, got %x", i, chain[i].Hash(), h.Hash())
+		}
+	}
+	if hd.AnchorCount() != 0 {
+		t.Errorf("expected no anchors after insertion, got %d", hd.AnchorCount())
+	}
+	if hd.HasTip(chain[len(chain)-1].Hash()) {
+		t.Errorf("expected tips to be removed after insertion")
+	}
+}
+
+func TestRecoverFromFiles(t *testing.T) {
+	filesDir, err := ioutil.TempDir("", "headers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filesDir)
+	hd := newTestHeaderDownload(filesDir)
+	currentTime := uint64(time.Now().Unix())
+	genesisHash := common.HexToHash("0x1234")
+	chain := testChain(genesisHash, 5, currentTime)
+	segments, _, err := hd.HandleHeadersMsg(append([]*types.Header{}, chain...)) // HandleHeadersMsg sorts the headers in place
+	if err != nil {
+		t.Fatal(err)
+	}
+	hd.ProcessSegment(segments[0], currentTime)
+	if err = hd.FlushBuffer(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start again with the same directory
+	hd = newTestHeaderDownload(filesDir)
+	if err = hd.RecoverFromFiles(currentTime); err != nil {
+		t.Fatal(err)
+	}
+	if hd.AnchorCount() != 1 {
+		t.Fatalf("expected 1 anchor after recovery, got %d", hd.AnchorCount())
+	}
+	if !hd.HasTip(chain[len(chain)-1].Hash()) {
+		t.Errorf("expected the highest header to be recovered as a tip")
+	}
+	hasHeader := func(hash common.Hash, number uint64) bool {
+		return hash == genesisHash && number == 0
+	}
+	n, err := hd.InsertHeaders(hasHeader, func([]*types.Header) error { return nil })
+	if err != nil || n != len(chain) {
+		t.Fatalf("expected %d headers inserted, got %d, err %v", len(chain), n, err)
+	}
+	// Files are removed once all the working trees are inserted
+	fileInfos, err := ioutil.ReadDir(filesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fileInfos) != 0 {
+		t.Errorf("expected no files left, got %d", len(fileInfos))
+	}
+}
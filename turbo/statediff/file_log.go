@@ -0,0 +1,202 @@
+package statediff
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// FileLog is an append-only file of the state diff events. Every event is a StateDiffEvent protobuf message
+// prefixed by its size as a varint, so the file can be read with the usual delimited protobuf readers.
+// The offsets of the positions of the blocks in the file are kept in memory, so that the replays seek to
+// the cursor instead of reading the file from the start.
+type FileLog struct {
+	lock      sync.Mutex
+	path      string
+	f         *os.File
+	w         *bufio.Writer
+	last      *remote.StateDiffEvent
+	end       int64
+	positions map[uint64][]logPosition // by block number, in the order of the file
+	origin    *remote.StateDiffCursor  // the state at the start of the file, at the parent of the first block
+}
+
+// logPosition is the offset in the file after the event which brings the state to the block
+type logPosition struct {
+	hash   []byte
+	offset int64
+}
+
+// OpenFileLog opens the log, creating the file if it doesn't exist. An event written partially, when the node
+// stops in the middle of writing it, is removed from the end of the file.
+func OpenFileLog(path string) (*FileLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &FileLog{path: path, f: f, positions: make(map[uint64][]logPosition)}
+	end, err := readEvents(f, func(event *remote.StateDiffEvent, end int64) error {
+		l.index(event, end)
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading state diff log %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() > end {
+		log.Warn("Removing partially written event from the state diff log", "file", path, "bytes", info.Size()-end)
+		if err = f.Truncate(end); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if _, err = f.Seek(end, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.end = end
+	l.w = bufio.NewWriter(f)
+	return l, nil
+}
+
+// index records the position after the event, which ends at the given offset
+func (l *FileLog) index(event *remote.StateDiffEvent, end int64) {
+	// The state before the first event is at the parent of its block
+	if l.last == nil && event.Kind == remote.StateDiffKind_Apply && event.BlockNumber > 0 {
+		l.origin = &remote.StateDiffCursor{BlockNumber: event.BlockNumber - 1, BlockHash: event.ParentHash}
+	}
+	l.positions[event.BlockNumber] = append(l.positions[event.BlockNumber], logPosition{hash: event.BlockHash, offset: end})
+	l.last = event
+}
+
+func (l *FileLog) Publish(event *remote.StateDiffEvent) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var size [binary.MaxVarintLen64]byte
+	sizeLen := binary.PutUvarint(size[:], uint64(len(data)))
+	if _, err = l.w.Write(size[:sizeLen]); err != nil {
+		return err
+	}
+	if _, err = l.w.Write(data); err != nil {
+		return err
+	}
+	// The readers of the file see the complete events only
+	if err = l.w.Flush(); err != nil {
+		return err
+	}
+	l.end += int64(sizeLen + len(data))
+	l.index(event, l.end)
+	return nil
+}
+
+func (l *FileLog) Last() (*remote.StateDiffEvent, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.last, nil
+}
+
+// Replay seeks to the last position of the cursor in the file, and reads the events following it up to the end
+// of the file at the time it is reached
+func (l *FileLog) Replay(cursor *remote.StateDiffCursor, f func(event *remote.StateDiffEvent) error) error {
+	from, ok := l.cursorOffset(cursor)
+	if !ok {
+		return ErrCursorNotFound
+	}
+	r, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if _, err = r.Seek(from, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = readEvents(r, func(event *remote.StateDiffEvent, _ int64) error {
+		return f(event)
+	})
+	return err
+}
+
+// cursorOffset returns the offset of the last position of the cursor in the file
+func (l *FileLog) cursorOffset(cursor *remote.StateDiffCursor) (int64, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	positions := l.positions[cursor.BlockNumber]
+	for i := len(positions) - 1; i >= 0; i-- {
+		if atCursor(cursor, cursor.BlockNumber, positions[i].hash) {
+			return positions[i].offset, true
+		}
+	}
+	if l.origin != nil && atCursor(cursor, l.origin.BlockNumber, l.origin.BlockHash) {
+		return 0, true
+	}
+	return 0, false
+}
+
+func (l *FileLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	if err := l.f.Sync(); err != nil {
+		return err
+	}
+	return l.f.Close()
+}
+
+// readEvents calls f for the complete events from the current position of the reader, with the offset of the end
+// of the event. It returns the offset of the end of the last complete event.
+func readEvents(r io.ReadSeeker, f func(event *remote.StateDiffEvent, end int64) error) (int64, error) {
+	offset, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	br := bufio.NewReader(r)
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, nil
+			}
+			return offset, err
+		}
+		data := make([]byte, size)
+		if _, err = io.ReadFull(br, data); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, nil
+			}
+			return offset, err
+		}
+		event := &remote.StateDiffEvent{}
+		if err = proto.Unmarshal(data, event); err != nil {
+			return offset, fmt.Errorf("decoding the event at %d: %w", offset, err)
+		}
+		offset += int64(varintSize(size)) + int64(size)
+		if err = f(event, offset); err != nil {
+			return offset, err
+		}
+	}
+}
+
+func varintSize(x uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], x)
+}
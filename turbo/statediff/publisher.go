@@ -0,0 +1,117 @@
+package statediff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+)
+
+// ErrCursorNotFound is returned when the state never was at the block of the cursor in the log
+var ErrCursorNotFound = errors.New("cursor not found in the state diff log")
+
+// Sink receives the events of the state diff stream, in order
+type Sink interface {
+	Publish(event *remote.StateDiffEvent) error
+}
+
+// Log is a Sink keeping the events, so that the stream can be resumed from a cursor
+type Log interface {
+	Sink
+	// Last returns the last event of the log, nil if the log is empty
+	Last() (*remote.StateDiffEvent, error)
+	// Replay calls f for the events following the last position of the log where the state is at the cursor,
+	// it returns ErrCursorNotFound if there is no such position
+	Replay(cursor *remote.StateDiffCursor, f func(event *remote.StateDiffEvent) error) error
+}
+
+// Publisher numbers the state diffs of the blocks executed by the Execution stage, and the unwinds of the stage,
+// and passes them to the sinks
+type Publisher struct {
+	lock    sync.Mutex
+	sinks   []Sink
+	seq     uint64 // of the next event
+	head    uint64 // the block the state is at after the last event
+	hasHead bool
+}
+
+// NewPublisher creates a publisher continuing the stream of the log, the log receives the events before
+// the other sinks. The log can be nil.
+func NewPublisher(log Log, sinks ...Sink) (*Publisher, error) {
+	p := &Publisher{}
+	if log != nil {
+		last, err := log.Last()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			p.seq, p.head, p.hasHead = last.Seq+1, last.BlockNumber, true
+		}
+		p.sinks = append(p.sinks, log)
+	}
+	p.sinks = append(p.sinks, sinks...)
+	return p, nil
+}
+
+// Apply publishes the state diff of the executed block. The diffs are published before the changes of the state
+// are committed, so the blocks executed again after a restart may already be in the stream: an unwind to the
+// parent of the block is published first in this case.
+func (p *Publisher) Apply(block *types.Block, w *Writer) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	blockNum := block.NumberU64()
+	if p.hasHead && blockNum <= p.head {
+		if err := p.publish(&remote.StateDiffEvent{
+			Kind:        remote.StateDiffKind_Unwind,
+			BlockNumber: blockNum - 1,
+			BlockHash:   block.ParentHash().Bytes(),
+		}); err != nil {
+			return err
+		}
+	}
+	accountDiffs, storageDiffs, codes := w.Diff()
+	return p.publish(&remote.StateDiffEvent{
+		Kind:        remote.StateDiffKind_Apply,
+		BlockNumber: blockNum,
+		BlockHash:   block.Hash().Bytes(),
+		ParentHash:  block.ParentHash().Bytes(),
+		Accounts:    accountDiffs,
+		Storage:     storageDiffs,
+		Codes:       codes,
+	})
+}
+
+// Unwind publishes the unwind of the state to the given block, if the stream is past it
+func (p *Publisher) Unwind(blockNum uint64, blockHash common.Hash) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.hasHead || blockNum >= p.head {
+		return nil
+	}
+	return p.publish(&remote.StateDiffEvent{
+		Kind:        remote.StateDiffKind_Unwind,
+		BlockNumber: blockNum,
+		BlockHash:   blockHash.Bytes(),
+	})
+}
+
+func (p *Publisher) publish(event *remote.StateDiffEvent) error {
+	event.Seq = p.seq
+	for _, sink := range p.sinks {
+		if err := sink.Publish(event); err != nil {
+			return fmt.Errorf("publishing state diff %d of block %d: %w", event.Seq, event.BlockNumber, err)
+		}
+	}
+	p.seq++
+	p.head, p.hasHead = event.BlockNumber, true
+	return nil
+}
+
+// atCursor tells whether the state after an event with the given block is at the cursor
+func atCursor(cursor *remote.StateDiffCursor, blockNum uint64, blockHash []byte) bool {
+	return blockNum == cursor.BlockNumber && (len(cursor.BlockHash) == 0 || bytes.Equal(blockHash, cursor.BlockHash))
+}
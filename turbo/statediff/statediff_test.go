@@ -0,0 +1,196 @@
+package statediff
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+)
+
+func TestWriterDiff(t *testing.T) {
+	require := require.New(t)
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	ctx := context.Background()
+	w := NewWriter(state.NewPlainStateWriter(db, db, 1))
+	addr1, addr2, addr3 := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")
+
+	created := accounts.NewAccount()
+	created.Nonce = 1
+	created.Incarnation = 1
+	require.NoError(w.UpdateAccountData(ctx, addr2, &accounts.Account{}, &created))
+	code := []byte{0x60, 0x00}
+	require.NoError(w.UpdateAccountCode(addr2, 1, common.HexToHash("0xc0de"), code))
+	location := common.HexToHash("0x5")
+	require.NoError(w.WriteAccountStorage(ctx, addr2, 1, &location, uint256.NewInt(), uint256.NewInt().SetUint64(7)))
+
+	existing := accounts.NewAccount()
+	existing.Initialised = true
+	existing.Balance.SetUint64(100)
+	require.NoError(w.DeleteAccount(ctx, addr1, &existing))
+
+	// written, but not changed
+	require.NoError(w.UpdateAccountData(ctx, addr3, &existing, &existing))
+	require.NoError(w.WriteAccountStorage(ctx, addr3, 1, &location, uint256.NewInt().SetUint64(1), uint256.NewInt().SetUint64(1)))
+
+	accountDiffs, storageDiffs, codes := w.Diff()
+	require.Len(accountDiffs, 2)
+	require.Equal(addr1.Bytes(), accountDiffs[0].Address)
+	require.Equal([]byte{100}, accountDiffs[0].Before.Balance)
+	require.Nil(accountDiffs[0].After)
+	require.Equal(addr2.Bytes(), accountDiffs[1].Address)
+	require.Nil(accountDiffs[1].Before)
+	require.Equal(uint64(1), accountDiffs[1].After.Nonce)
+
+	require.Len(storageDiffs, 1)
+	require.Equal(location.Bytes(), storageDiffs[0].Location)
+	require.Empty(storageDiffs[0].Before)
+	require.Equal([]byte{7}, storageDiffs[0].After)
+
+	require.Len(codes, 1)
+	require.Equal(code, codes[0].Code)
+
+	// the writes are passed to the wrapped writer
+	acc, err := state.NewPlainStateReader(db).ReadAccountData(addr2)
+	require.NoError(err)
+	require.NotNil(acc)
+	require.Equal(uint64(1), acc.Nonce)
+}
+
+type testChain struct {
+	blocks map[uint64]*types.Block
+}
+
+// block returns the block with the given number, on the fork if fork > 0
+func (c *testChain) block(n uint64, fork int64) *types.Block {
+	var parentHash common.Hash
+	if n > 0 {
+		parentHash = c.blocks[n-1].Hash()
+	}
+	b := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parentHash, Extra: big.NewInt(fork).Bytes()})
+	c.blocks[n] = b
+	return b
+}
+
+func publishBlocks(t *testing.T, p *Publisher, chain *testChain, from, to uint64, fork int64) {
+	for n := from; n <= to; n++ {
+		if err := p.Apply(chain.block(n, fork), NewWriter(nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func replay(log Log, cursor *remote.StateDiffCursor) ([]*remote.StateDiffEvent, error) {
+	var events []*remote.StateDiffEvent
+	err := log.Replay(cursor, func(event *remote.StateDiffEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+func TestFileLog(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "statediff")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "statediff")
+
+	l, err := OpenFileLog(file)
+	require.NoError(err)
+	p, err := NewPublisher(l)
+	require.NoError(err)
+	chain := &testChain{blocks: make(map[uint64]*types.Block)}
+	chain.block(0, 0)
+	publishBlocks(t, p, chain, 1, 5, 0)
+	hash4 := chain.blocks[4].Hash()
+
+	// reorg: unwind to 3, and the blocks 4..6 of the fork
+	require.NoError(p.Unwind(3, chain.blocks[3].Hash()))
+	publishBlocks(t, p, chain, 4, 6, 1)
+	require.NoError(l.Close())
+
+	// the stream continues after reopening, the blocks executed again come after an unwind
+	l, err = OpenFileLog(file)
+	require.NoError(err)
+	defer l.Close()
+	p, err = NewPublisher(l)
+	require.NoError(err)
+	publishBlocks(t, p, chain, 6, 6, 1)
+
+	events, err := replay(l, &remote.StateDiffCursor{})
+	require.NoError(err)
+	require.Len(events, 11)
+	for i, event := range events {
+		require.Equal(uint64(i), event.Seq)
+	}
+	require.Equal(remote.StateDiffKind_Unwind, events[5].Kind)
+	require.Equal(remote.StateDiffKind_Unwind, events[9].Kind)
+	require.Equal(uint64(5), events[9].BlockNumber)
+
+	// the last position of the block 4 is on the fork
+	events, err = replay(l, &remote.StateDiffCursor{BlockNumber: 4})
+	require.NoError(err)
+	require.Len(events, 4)
+	require.Equal(uint64(5), events[0].BlockNumber)
+
+	// a subscriber which has processed the block 4 before the reorg gets the unwind
+	events, err = replay(l, &remote.StateDiffCursor{BlockNumber: 4, BlockHash: hash4.Bytes()})
+	require.NoError(err)
+	require.Len(events, 7)
+	require.Equal(remote.StateDiffKind_Apply, events[0].Kind)
+	require.Equal(uint64(5), events[0].BlockNumber)
+	require.Equal(remote.StateDiffKind_Unwind, events[1].Kind)
+
+	_, err = replay(l, &remote.StateDiffCursor{BlockNumber: 7})
+	require.Equal(ErrCursorNotFound, err)
+}
+
+func TestFileLogPartialEvent(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "statediff")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "statediff")
+
+	l, err := OpenFileLog(file)
+	require.NoError(err)
+	p, err := NewPublisher(l)
+	require.NoError(err)
+	chain := &testChain{blocks: make(map[uint64]*types.Block)}
+	chain.block(0, 0)
+	publishBlocks(t, p, chain, 1, 2, 0)
+	require.NoError(l.Close())
+
+	info, err := os.Stat(file)
+	require.NoError(err)
+	require.NoError(os.Truncate(file, info.Size()-1))
+
+	l, err = OpenFileLog(file)
+	require.NoError(err)
+	defer l.Close()
+	last, err := l.Last()
+	require.NoError(err)
+	require.Equal(uint64(1), last.BlockNumber)
+	p, err = NewPublisher(l)
+	require.NoError(err)
+	publishBlocks(t, p, chain, 2, 2, 0)
+
+	events, err := replay(l, &remote.StateDiffCursor{})
+	require.NoError(err)
+	require.Len(events, 2)
+	require.Equal(uint64(1), events[1].Seq)
+}
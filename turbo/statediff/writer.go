@@ -0,0 +1,164 @@
+package statediff
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+)
+
+type accountDiff struct {
+	before, after *accounts.Account // nil if the account doesn't exist
+}
+
+type storageKey struct {
+	address     common.Address
+	incarnation uint64
+	location    common.Hash
+}
+
+type storageDiff struct {
+	before, after uint256.Int
+}
+
+// Writer passes the writes of a block to the wrapped writer, and keeps the changes of the state they make,
+// from the state before the block to the state after it, to be published as the state diff of the block
+type Writer struct {
+	state.WriterWithChangeSets
+	accounts map[common.Address]*accountDiff
+	storage  map[storageKey]*storageDiff
+	codes    []*remote.CodeDeployment
+}
+
+func NewWriter(w state.WriterWithChangeSets) *Writer {
+	return &Writer{
+		WriterWithChangeSets: w,
+		accounts:             make(map[common.Address]*accountDiff),
+		storage:              make(map[storageKey]*storageDiff),
+	}
+}
+
+func (w *Writer) UpdateAccountData(ctx context.Context, address common.Address, original, account *accounts.Account) error {
+	if err := w.WriterWithChangeSets.UpdateAccountData(ctx, address, original, account); err != nil {
+		return err
+	}
+	w.account(address, original).after = account.SelfCopy()
+	return nil
+}
+
+func (w *Writer) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	if err := w.WriterWithChangeSets.UpdateAccountCode(address, incarnation, codeHash, code); err != nil {
+		return err
+	}
+	w.codes = append(w.codes, &remote.CodeDeployment{
+		Address:     address.Bytes(),
+		Incarnation: incarnation,
+		CodeHash:    codeHash.Bytes(),
+		Code:        common.CopyBytes(code),
+	})
+	return nil
+}
+
+func (w *Writer) DeleteAccount(ctx context.Context, address common.Address, original *accounts.Account) error {
+	if err := w.WriterWithChangeSets.DeleteAccount(ctx, address, original); err != nil {
+		return err
+	}
+	w.account(address, original).after = nil
+	return nil
+}
+
+func (w *Writer) WriteAccountStorage(ctx context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	if err := w.WriterWithChangeSets.WriteAccountStorage(ctx, address, incarnation, key, original, value); err != nil {
+		return err
+	}
+	k := storageKey{address: address, incarnation: incarnation, location: *key}
+	d, ok := w.storage[k]
+	if !ok {
+		d = &storageDiff{before: *original}
+		w.storage[k] = d
+	}
+	d.after = *value
+	return nil
+}
+
+// account returns the diff of the account, the state before the block is taken from the first write of it
+func (w *Writer) account(address common.Address, original *accounts.Account) *accountDiff {
+	d, ok := w.accounts[address]
+	if !ok {
+		d = &accountDiff{}
+		if original != nil && original.Initialised {
+			d.before = original.SelfCopy()
+		}
+		w.accounts[address] = d
+	}
+	return d
+}
+
+// Diff returns the changes of the accounts and of the storage, skipping the items which are written but
+// not changed, and the deployed code, sorted by address
+func (w *Writer) Diff() ([]*remote.AccountDiff, []*remote.StorageDiff, []*remote.CodeDeployment) {
+	accountDiffs := make([]*remote.AccountDiff, 0, len(w.accounts))
+	for address, d := range w.accounts {
+		if d.before == nil && d.after == nil || d.before != nil && d.after != nil && d.before.Equals(d.after) {
+			continue
+		}
+		accountDiffs = append(accountDiffs, &remote.AccountDiff{
+			Address: common.CopyBytes(address[:]),
+			Before:  accountMessage(d.before),
+			After:   accountMessage(d.after),
+		})
+	}
+	sort.Slice(accountDiffs, func(i, j int) bool {
+		return bytes.Compare(accountDiffs[i].Address, accountDiffs[j].Address) < 0
+	})
+
+	keys := make([]storageKey, 0, len(w.storage))
+	for k, d := range w.storage {
+		if !d.before.Eq(&d.after) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c := bytes.Compare(keys[i].address[:], keys[j].address[:]); c != 0 {
+			return c < 0
+		}
+		if keys[i].incarnation != keys[j].incarnation {
+			return keys[i].incarnation < keys[j].incarnation
+		}
+		return bytes.Compare(keys[i].location[:], keys[j].location[:]) < 0
+	})
+	storageDiffs := make([]*remote.StorageDiff, len(keys))
+	for i, k := range keys {
+		d := w.storage[k]
+		storageDiffs[i] = &remote.StorageDiff{
+			Address:     common.CopyBytes(k.address[:]),
+			Incarnation: k.incarnation,
+			Location:    common.CopyBytes(k.location[:]),
+			Before:      d.before.Bytes(),
+			After:       d.after.Bytes(),
+		}
+	}
+
+	sort.SliceStable(w.codes, func(i, j int) bool {
+		return bytes.Compare(w.codes[i].Address, w.codes[j].Address) < 0
+	})
+	return accountDiffs, storageDiffs, w.codes
+}
+
+func accountMessage(a *accounts.Account) *remote.Account {
+	if a == nil {
+		return nil
+	}
+	return &remote.Account{
+		Nonce:       a.Nonce,
+		Balance:     a.Balance.Bytes(),
+		Incarnation: a.Incarnation,
+		CodeHash:    a.CodeHash.Bytes(),
+	}
+}
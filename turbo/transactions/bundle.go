@@ -0,0 +1,246 @@
+package transactions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/turbo-geth/accounts/abi"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/rpchelper"
+)
+
+// BundleTransaction is a single transaction of a bundle. It is either a signed
+// transaction, or the arguments of an unsigned call executed from the given sender.
+type BundleTransaction struct {
+	Tx   *types.Transaction
+	Call *ethapi.CallArgs
+}
+
+// UnmarshalJSON decodes a hex string as a raw signed transaction, and an object
+// as the arguments of an unsigned call.
+func (btx *BundleTransaction) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		var raw hexutil.Bytes
+		if err := json.Unmarshal(input, &raw); err != nil {
+			return err
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return err
+		}
+		btx.Tx = tx
+		return nil
+	}
+	btx.Call = new(ethapi.CallArgs)
+	return json.Unmarshal(input, btx.Call)
+}
+
+// BlockOverrides replaces fields of the block context a bundle is simulated in.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number"`
+	Time     *hexutil.Uint64 `json:"timestamp"`
+	Coinbase *common.Address `json:"coinbase"`
+	GasLimit *hexutil.Uint64 `json:"gasLimit"`
+}
+
+func (o *BlockOverrides) apply(header *types.Header) {
+	if o == nil {
+		return
+	}
+	if o.Number != nil {
+		header.Number = new(big.Int).Set(o.Number.ToInt())
+	}
+	if o.Time != nil {
+		header.Time = uint64(*o.Time)
+	}
+	if o.Coinbase != nil {
+		header.Coinbase = *o.Coinbase
+	}
+	if o.GasLimit != nil {
+		header.GasLimit = uint64(*o.GasLimit)
+	}
+}
+
+// BundleTxResult is the outcome of a single transaction of a simulated bundle.
+type BundleTxResult struct {
+	TxHash       *common.Hash    `json:"txHash,omitempty"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	ReturnData   hexutil.Bytes   `json:"returnData"`
+	Logs         []*types.Log    `json:"logs"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	CoinbaseDiff *hexutil.Big    `json:"coinbaseDiff"`
+}
+
+// BundleResult is the outcome of a simulated bundle.
+type BundleResult struct {
+	StateBlockNumber hexutil.Uint64   `json:"stateBlockNumber"`
+	BlockNumber      *hexutil.Big     `json:"blockNumber"`
+	Coinbase         common.Address   `json:"coinbase"`
+	TotalGasUsed     hexutil.Uint64   `json:"totalGasUsed"`
+	CoinbaseDiff     *hexutil.Big     `json:"coinbaseDiff"`
+	Results          []BundleTxResult `json:"results"`
+}
+
+// DoCallBundle executes the given transactions in order, carrying the state
+// changes of each over to the next one. By default the bundle is applied on top
+// of the state of the given block, in the context of a block following it. With
+// onParent set, it is applied on top of the state of the parent instead, in the
+// context of the given block itself. The block context can be further adjusted
+// with the overrides.
+//
+// Transactions that fail consensus checks (nonce, balance, block gas limit) fail
+// the whole bundle, whereas reverted or otherwise failed executions are reported
+// in the result of the transaction. Unsigned calls skip the nonce check, like
+// eth_call, but still advance the nonce of their sender.
+func DoCallBundle(ctx context.Context, txs []BundleTransaction, kv ethdb.KV, dbReader rawdb.DatabaseReader, blockNrOrHash rpc.BlockNumberOrHash, onParent bool, overrides *BlockOverrides, GasCap uint64) (*BundleResult, error) {
+	if len(txs) == 0 {
+		return nil, errors.New("bundle is empty")
+	}
+	blockNumber, hash, err := rpchelper.GetBlockNumber(blockNrOrHash, dbReader)
+	if err != nil {
+		return nil, err
+	}
+	block := rawdb.ReadHeader(dbReader, hash, blockNumber)
+	if block == nil {
+		return nil, fmt.Errorf("block %d(%x) not found", blockNumber, hash)
+	}
+
+	var (
+		stateBlockNumber uint64
+		header           *types.Header
+	)
+	if onParent {
+		if blockNumber == 0 {
+			return nil, errors.New("genesis block has no parent state")
+		}
+		stateBlockNumber = blockNumber - 1
+		header = types.CopyHeader(block)
+	} else {
+		stateBlockNumber = blockNumber
+		header = &types.Header{
+			ParentHash: block.Hash(),
+			Coinbase:   block.Coinbase,
+			Difficulty: new(big.Int).Set(block.Difficulty),
+			Number:     new(big.Int).SetUint64(blockNumber + 1),
+			GasLimit:   block.GasLimit,
+			Time:       block.Time + 1,
+		}
+	}
+	overrides.apply(header)
+
+	chainConfig, err := readChainConfig(dbReader)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := state.NewPlainDBState(kv, stateBlockNumber)
+	ibs := state.New(ds)
+	if ibs == nil {
+		return nil, fmt.Errorf("can't get the state for %d", stateBlockNumber)
+	}
+
+	// Setup context so it may be cancelled once the bundle has completed
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var (
+		signer         = types.MakeSigner(chainConfig, header.Number)
+		rules          = chainConfig.WithEIPsFlags(ctx, header.Number)
+		gp             = new(core.GasPool).AddGas(header.GasLimit)
+		coinbaseBefore = ibs.GetBalance(header.Coinbase).ToBig()
+	)
+	res := &BundleResult{
+		StateBlockNumber: hexutil.Uint64(stateBlockNumber),
+		BlockNumber:      (*hexutil.Big)(new(big.Int).Set(header.Number)),
+		Coinbase:         header.Coinbase,
+		Results:          make([]BundleTxResult, 0, len(txs)),
+	}
+	for i, btx := range txs {
+		if err := common.Stopped(ctx.Done()); err != nil {
+			return nil, err
+		}
+		var (
+			msg    types.Message
+			txHash common.Hash
+			txRes  BundleTxResult
+		)
+		switch {
+		case btx.Tx != nil:
+			if msg, err = btx.Tx.AsMessage(signer); err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", i, err)
+			}
+			txHash = btx.Tx.Hash()
+			txRes.TxHash = &txHash
+		case btx.Call != nil:
+			args := *btx.Call
+			// Unless limited by the caller, let the call use all the gas left in the block
+			if args.Gas == nil {
+				gas := hexutil.Uint64(gp.Gas())
+				args.Gas = &gas
+			}
+			msg = args.ToMessage(GasCap)
+		default:
+			return nil, fmt.Errorf("transaction %d: neither signed transaction nor call arguments given", i)
+		}
+		txRes.From, txRes.To = msg.From(), msg.To()
+
+		ibs.Prepare(txHash, common.Hash{}, i)
+		balanceBefore := ibs.GetBalance(header.Coinbase).ToBig()
+
+		evm := vm.NewEVM(GetEvmContext(msg, header, blockNrOrHash.RequireCanonical, dbReader), ibs, chainConfig, vm.Config{})
+		// Wait for the context to be done and cancel the evm. Even if the
+		// EVM has finished, cancelling may be done (repeatedly)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+
+		result, err := core.ApplyMessage(evm, msg, gp)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		// If the timer caused an abort, return an appropriate error message
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", callTimeout)
+		}
+		if err = ibs.FinalizeTx(rules, ds); err != nil {
+			return nil, err
+		}
+
+		txRes.GasUsed = hexutil.Uint64(result.UsedGas)
+		txRes.ReturnData = common.CopyBytes(result.ReturnData)
+		if result.Err != nil {
+			txRes.Error = result.Err.Error()
+			if reason, errUnpack := abi.UnpackRevert(result.Revert()); errUnpack == nil {
+				txRes.RevertReason = reason
+			}
+		}
+		txRes.Logs = []*types.Log{}
+		for _, l := range ibs.GetLogs(txHash) {
+			if l.TxIndex == uint(i) {
+				txRes.Logs = append(txRes.Logs, l)
+			}
+		}
+		txRes.CoinbaseDiff = (*hexutil.Big)(new(big.Int).Sub(ibs.GetBalance(header.Coinbase).ToBig(), balanceBefore))
+
+		res.TotalGasUsed += txRes.GasUsed
+		res.Results = append(res.Results, txRes)
+	}
+	res.CoinbaseDiff = (*hexutil.Big)(new(big.Int).Sub(ibs.GetBalance(header.Coinbase).ToBig(), coinbaseBefore))
+	return res, nil
+}
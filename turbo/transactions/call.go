@@ -24,14 +24,6 @@ import (
 const callTimeout = 5 * time.Minute
 
 func DoCall(ctx context.Context, args ethapi.CallArgs, kv ethdb.KV, dbReader rawdb.DatabaseReader, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]ethapi.Account, GasCap uint64) (*core.ExecutionResult, error) {
-	// todo: Pending state is only known by the miner
-	/*
-		if blockNrOrHash.BlockNumber != nil && *blockNrOrHash.BlockNumber == rpc.PendingBlockNumber {
-			block, state, _ := b.eth.miner.Pending()
-			return state, block.Header(), nil
-		}
-	*/
-
 	blockNumber, hash, err := rpchelper.GetBlockNumber(blockNrOrHash, dbReader)
 	if err != nil {
 		return nil, err
@@ -48,6 +40,87 @@ func DoCall(ctx context.Context, args ethapi.CallArgs, kv ethdb.KV, dbReader raw
 		return nil, fmt.Errorf("block %d(%x) not found", blockNumber, hash)
 	}
 
+	chainConfig, err := readChainConfig(dbReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return doCall(ctx, args, state, header, blockNrOrHash.RequireCanonical, dbReader, chainConfig, overrides, GasCap)
+}
+
+// DoCallPending executes the call on top of the pending state: the latest executed
+// block with the given pending transactions (grouped by sender and sorted by nonce)
+// applied in price and nonce order. Transactions which fail to apply are skipped
+// together with the rest of transactions of the same sender.
+func DoCallPending(ctx context.Context, args ethapi.CallArgs, kv ethdb.KV, dbReader rawdb.DatabaseReader, pending map[common.Address]types.Transactions, overrides *map[common.Address]ethapi.Account, GasCap uint64) (*core.ExecutionResult, error) {
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	blockNumber, hash, err := rpchelper.GetBlockNumber(latest, dbReader)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := rawdb.ReadHeader(dbReader, hash, blockNumber)
+	if parent == nil {
+		return nil, fmt.Errorf("block %d(%x) not found", blockNumber, hash)
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).SetUint64(blockNumber + 1),
+		GasLimit:   parent.GasLimit,
+		Time:       uint64(time.Now().Unix()),
+	}
+	if header.Time <= parent.Time {
+		header.Time = parent.Time + 1
+	}
+
+	chainConfig, err := readChainConfig(dbReader)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := state.NewPlainDBState(kv, blockNumber)
+	state := state.New(ds)
+	if state == nil {
+		return nil, fmt.Errorf("can't get the state for %d", blockNumber)
+	}
+
+	signer := types.MakeSigner(chainConfig, header.Number)
+	txs := types.NewTransactionsByPriceAndNonce(signer, pending)
+	gp := new(core.GasPool).AddGas(header.GasLimit)
+	txIndex := 0
+	for tx := txs.Peek(); tx != nil; tx = txs.Peek() {
+		if err := common.Stopped(ctx.Done()); err != nil {
+			return nil, err
+		}
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			txs.Pop()
+			continue
+		}
+		// resets the access list of EIP-2929, so the transactions don't warm up the accounts for each other
+		state.Prepare(tx.Hash(), common.Hash{}, txIndex)
+		snapshot := state.Snapshot()
+		evm := vm.NewEVM(GetEvmContext(msg, header, true, dbReader), state, chainConfig, vm.Config{})
+		if _, err := core.ApplyMessage(evm, msg, gp); err != nil {
+			log.Debug("Skipping pending transaction", "hash", tx.Hash(), "err", err)
+			state.RevertToSnapshot(snapshot)
+			txs.Pop()
+			continue
+		}
+		if err := state.FinalizeTx(chainConfig.WithEIPsFlags(ctx, header.Number), ds); err != nil {
+			return nil, err
+		}
+		txs.Shift()
+		txIndex++
+	}
+	state.Prepare(common.Hash{}, common.Hash{}, txIndex)
+
+	return doCall(ctx, args, state, header, true, dbReader, chainConfig, overrides, GasCap)
+}
+
+func doCall(ctx context.Context, args ethapi.CallArgs, state *state.IntraBlockState, header *types.Header, requireCanonical bool, dbReader rawdb.DatabaseReader, chainConfig *params.ChainConfig, overrides *map[common.Address]ethapi.Account, GasCap uint64) (*core.ExecutionResult, error) {
 	// Override the fields of specified contracts before execution.
 	if overrides != nil {
 		for addr, account := range *overrides {
@@ -97,9 +170,9 @@ func DoCall(ctx context.Context, args ethapi.CallArgs, kv ethdb.KV, dbReader raw
 	// Get a new instance of the EVM.
 	msg := args.ToMessage(GasCap)
 
-	evmCtx := GetEvmContext(msg, header, blockNrOrHash.RequireCanonical, dbReader)
+	evmCtx := GetEvmContext(msg, header, requireCanonical, dbReader)
 
-	evm := vm.NewEVM(evmCtx, state, params.MainnetChainConfig, vm.Config{})
+	evm := vm.NewEVM(evmCtx, state, chainConfig, vm.Config{})
 
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
@@ -121,6 +194,16 @@ func DoCall(ctx context.Context, args ethapi.CallArgs, kv ethdb.KV, dbReader raw
 	return result, nil
 }
 
+// readChainConfig returns the chain config stored for the canonical genesis block.
+func readChainConfig(dbReader rawdb.DatabaseReader) (*params.ChainConfig, error) {
+	genesisHash := rawdb.ReadCanonicalHash(dbReader, 0)
+	chainConfig := rawdb.ReadChainConfig(dbReader, genesisHash)
+	if chainConfig == nil {
+		return nil, fmt.Errorf("chain config not found for genesis %x", genesisHash)
+	}
+	return chainConfig, nil
+}
+
 func GetEvmContext(msg core.Message, header *types.Header, requireCanonical bool, dbReader rawdb.DatabaseReader) vm.Context {
 	return vm.Context{
 		CanTransfer: core.CanTransfer,
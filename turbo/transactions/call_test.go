@@ -0,0 +1,87 @@
+package transactions
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+func TestDoCallPending(t *testing.T) {
+	var (
+		key1, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _  = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		address1 = crypto.PubkeyToAddress(key1.PublicKey)
+		address2 = crypto.PubkeyToAddress(key2.PublicKey)
+		// returns the gas spent on SLOAD(0), which is cold the first time in a transaction (EIP-2929)
+		sloadGas = common.HexToAddress("0xc1")
+		// returns BALANCE(address1), BALANCE(address2)
+		balances = common.HexToAddress("0xc2")
+		config   = *params.AllEthashProtocolChanges
+	)
+	config.BerlinBlock = big.NewInt(0)
+	balancesCode := append([]byte{byte(vm.PUSH20)}, address1.Bytes()...)
+	balancesCode = append(balancesCode, byte(vm.BALANCE), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH20))
+	balancesCode = append(balancesCode, address2.Bytes()...)
+	balancesCode = append(balancesCode, byte(vm.BALANCE), byte(vm.PUSH1), 32, byte(vm.MSTORE), byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.RETURN))
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			address1: {Balance: big.NewInt(params.Ether)},
+			address2: {Balance: big.NewInt(params.Ether)},
+			sloadGas: {Balance: new(big.Int), Code: []byte{
+				byte(vm.GAS), byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), byte(vm.GAS), byte(vm.SWAP1), byte(vm.SUB),
+				byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+			}},
+			balances: {Balance: new(big.Int), Code: balancesCode},
+		},
+	}
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	if _, _, err := gspec.Commit(db, true /* history */); err != nil {
+		t.Fatal(err)
+	}
+
+	// both pending transactions read the same slot, the first one pays twice as much per gas
+	signer := types.MakeSigner(&config, big.NewInt(1))
+	tx1, _ := types.SignTx(types.NewTransaction(0, sloadGas, uint256.NewInt(), 100000, uint256.NewInt().SetUint64(2), nil), signer, key1)
+	tx2, _ := types.SignTx(types.NewTransaction(0, sloadGas, uint256.NewInt(), 100000, uint256.NewInt().SetUint64(1), nil), signer, key2)
+
+	call := func(to common.Address) []byte {
+		pending := map[common.Address]types.Transactions{address1: {tx1}, address2: {tx2}}
+		result, err := DoCallPending(context.Background(), ethapi.CallArgs{To: &to}, db.KV(), db, pending, nil, 1000000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Err != nil {
+			t.Fatalf("call to %x failed: %v", to, result.Err)
+		}
+		return result.ReturnData
+	}
+
+	// the pending transactions are applied, and the slot is cold in both of them
+	ret := call(balances)
+	spent1 := new(big.Int).Sub(big.NewInt(params.Ether), new(big.Int).SetBytes(ret[:32]))
+	spent2 := new(big.Int).Sub(big.NewInt(params.Ether), new(big.Int).SetBytes(ret[32:]))
+	if spent2.Sign() == 0 {
+		t.Fatalf("pending transaction of %x is not applied", address2)
+	}
+	if spent1.Cmp(new(big.Int).Mul(spent2, big.NewInt(2))) != 0 {
+		t.Errorf("pending transactions used different gas: %d at price 2, %d at price 1", spent1, spent2)
+	}
+
+	// the slot read by the pending transactions is cold in the call
+	if gas := new(big.Int).SetBytes(call(sloadGas)); gas.Uint64() != params.ColdSloadCostEIP2929+7 {
+		t.Errorf("SLOAD used %d gas, expected the cold access %d", gas, params.ColdSloadCostEIP2929+7)
+	}
+}
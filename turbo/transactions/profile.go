@@ -0,0 +1,62 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/consensus/misc"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/eth/tracers"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+)
+
+// ProfileBlocks re-executes the canonical blocks from..to (inclusive) on top of the historical state,
+// with the profiler attached to the EVM. The state changes are discarded. It returns the number of
+// executed blocks, which is less than requested if ctx is cancelled.
+func ProfileBlocks(ctx context.Context, kv ethdb.KV, db ethdb.Getter, chainConfig *params.ChainConfig, from, to uint64, profiler *tracers.Profiler) (uint64, error) {
+	if from == 0 {
+		from = 1 // genesis has no transactions
+	}
+	chainContext := adapter.NewChainContext(db)
+	vmConfig := vm.Config{Debug: true, Tracer: profiler}
+	noop := state.NewNoopWriter()
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+
+	var blocks uint64
+	for blockNum := from; blockNum <= to; blockNum++ {
+		select {
+		case <-ctx.Done():
+			return blocks, ctx.Err()
+		case <-logEvery.C:
+			log.Info("Profiling blocks", "number", blockNum, "to", to)
+		default:
+		}
+		block := rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, blockNum), blockNum)
+		if block == nil {
+			return blocks, fmt.Errorf("block %d not found", blockNum)
+		}
+		header := block.Header()
+		ibs := state.New(state.NewPlainDBState(kv, blockNum-1))
+		if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(block.Number()) == 0 {
+			misc.ApplyDAOHardFork(ibs)
+		}
+		gp := new(core.GasPool).AddGas(block.GasLimit())
+		usedGas := new(uint64)
+		for i, tx := range block.Transactions() {
+			ibs.Prepare(tx.Hash(), block.Hash(), i)
+			if _, err := core.ApplyTransaction(chainConfig, chainContext, nil, gp, ibs, noop, header, tx, usedGas, vmConfig); err != nil {
+				return blocks, fmt.Errorf("block %d, tx %x failed: %w", blockNum, tx.Hash(), err)
+			}
+		}
+		blocks++
+	}
+	return blocks, nil
+}
@@ -0,0 +1,475 @@
+package transactions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/consensus/misc"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+)
+
+// ReplayedBlock is the outcome of the re-execution of a block by ReplayBlock
+type ReplayedBlock struct {
+	Number  uint64       `json:"number"`
+	Hash    common.Hash  `json:"hash"`
+	DAOFork StateDiff    `json:"daoFork,omitempty"`
+	Txs     []ReplayedTx `json:"txs"`
+	// Finalize is the state changed after the transactions, by the block and uncle rewards
+	Finalize StateDiff `json:"finalize,omitempty"`
+	// ChangeSetMismatches has a line per entry of the stored changesets which differs from the
+	// changesets of the re-execution
+	ChangeSetMismatches []string `json:"changeSetMismatches,omitempty"`
+}
+
+// ReplayedTx is the state changed by a transaction
+type ReplayedTx struct {
+	Index   int         `json:"index"`
+	Hash    common.Hash `json:"hash"`
+	GasUsed uint64      `json:"gasUsed"`
+	Failed  bool        `json:"failed"`
+	State   StateDiff   `json:"state"`
+}
+
+// StateDiff is the state changed by a transaction, by account
+type StateDiff map[common.Address]*AccountDiff
+
+// AccountDiff holds the values before and after of the fields of an account which changed. The storage
+// of a deleted account is not listed item by item.
+type AccountDiff struct {
+	Created bool                           `json:"created,omitempty"`
+	Deleted bool                           `json:"deleted,omitempty"`
+	Balance *BalanceChange                 `json:"balance,omitempty"`
+	Nonce   *NonceChange                   `json:"nonce,omitempty"`
+	Code    *CodeChange                    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageChange `json:"storage,omitempty"`
+}
+
+type BalanceChange struct {
+	Before *hexutil.Big `json:"before"`
+	After  *hexutil.Big `json:"after"`
+}
+
+type NonceChange struct {
+	Before hexutil.Uint64 `json:"before"`
+	After  hexutil.Uint64 `json:"after"`
+}
+
+type CodeChange struct {
+	Before hexutil.Bytes `json:"before"`
+	After  hexutil.Bytes `json:"after"`
+}
+
+type StorageChange struct {
+	Before common.Hash `json:"before"`
+	After  common.Hash `json:"after"`
+}
+
+func (d *AccountDiff) empty() bool {
+	return !d.Created && !d.Deleted && d.Balance == nil && d.Nonce == nil && d.Code == nil && len(d.Storage) == 0
+}
+
+// ReplayBlock re-executes the canonical block blockNum on top of the historical state after the block
+// blockNum-1 and returns the state changed by each of its transactions. The changesets of the
+// re-execution are compared with the changesets stored for the block, the database must have the history.
+func ReplayBlock(kv ethdb.KV, db ethdb.Getter, chainConfig *params.ChainConfig, blockNum uint64) (*ReplayedBlock, error) {
+	if blockNum == 0 {
+		return nil, fmt.Errorf("the genesis block cannot be replayed")
+	}
+	block := rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, blockNum), blockNum)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	header := block.Header()
+	replayed := &ReplayedBlock{Number: blockNum, Hash: block.Hash(), Txs: []ReplayedTx{}}
+
+	reader := state.NewPlainDBState(kv, blockNum-1)
+	ibs := state.New(reader)
+	writer := newReplayWriter(reader)
+	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
+	if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(ibs)
+		if err := ibs.FinalizeTx(ctx, writer); err != nil {
+			return nil, err
+		}
+		replayed.DAOFork = writer.takeDiff()
+	}
+
+	chainContext := adapter.NewChainContext(db)
+	gp := new(core.GasPool).AddGas(block.GasLimit())
+	usedGas := new(uint64)
+	for i, tx := range block.Transactions() {
+		ibs.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, err := core.ApplyTransaction(chainConfig, chainContext, nil, gp, ibs, writer, header, tx, usedGas, vm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("block %d, tx %x failed: %w", blockNum, tx.Hash(), err)
+		}
+		replayed.Txs = append(replayed.Txs, ReplayedTx{
+			Index:   i,
+			Hash:    tx.Hash(),
+			GasUsed: receipt.GasUsed,
+			Failed:  receipt.Status == types.ReceiptStatusFailed,
+			State:   writer.takeDiff(),
+		})
+	}
+
+	// The rewards are those of ethash, the proof of work is not verified. There are none with clique.
+	if chainConfig.Clique == nil {
+		ethash.NewFullFaker().Finalize(chainConfig, header, ibs, block.Transactions(), block.Uncles())
+	}
+	csw := state.NewChangeSetWriterPlain(blockNum)
+	if err := ibs.CommitBlock(ctx, teeWriter{writer, csw}); err != nil {
+		return nil, fmt.Errorf("committing block %d failed: %w", blockNum, err)
+	}
+	replayed.Finalize = writer.takeDiff()
+
+	mismatches, err := compareChangeSets(db, blockNum, csw)
+	if err != nil {
+		return nil, err
+	}
+	replayed.ChangeSetMismatches = mismatches
+	return replayed, nil
+}
+
+type replayStorageKey struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+// replayWriter keeps the state written in the block so far on top of the historical state, and the
+// values before the current transaction of what it writes, which makes a StateDiff per transaction
+type replayWriter struct {
+	reader   state.StateReader
+	accounts map[common.Address]*accounts.Account // nil for deleted accounts
+	storage  map[replayStorageKey]uint256.Int
+	code     map[common.Hash][]byte
+
+	accountsBefore map[common.Address]*accounts.Account
+	storageBefore  map[replayStorageKey]uint256.Int
+}
+
+func newReplayWriter(reader state.StateReader) *replayWriter {
+	return &replayWriter{
+		reader:         reader,
+		accounts:       make(map[common.Address]*accounts.Account),
+		storage:        make(map[replayStorageKey]uint256.Int),
+		code:           make(map[common.Hash][]byte),
+		accountsBefore: make(map[common.Address]*accounts.Account),
+		storageBefore:  make(map[replayStorageKey]uint256.Int),
+	}
+}
+
+func (w *replayWriter) account(address common.Address) (*accounts.Account, error) {
+	if a, ok := w.accounts[address]; ok {
+		return a, nil
+	}
+	return w.reader.ReadAccountData(address)
+}
+
+func (w *replayWriter) storageValue(k replayStorageKey) (uint256.Int, error) {
+	if v, ok := w.storage[k]; ok {
+		return v, nil
+	}
+	var v uint256.Int
+	enc, err := w.reader.ReadAccountStorage(k.address, k.incarnation, &k.key)
+	if err != nil {
+		return v, err
+	}
+	v.SetBytes(enc)
+	return v, nil
+}
+
+// accountCode returns the code of the account, the empty code hash is the zero hash
+func (w *replayWriter) accountCode(address common.Address, codeHash common.Hash) ([]byte, error) {
+	if codeHash == (common.Hash{}) {
+		return nil, nil
+	}
+	if code, ok := w.code[codeHash]; ok {
+		return code, nil
+	}
+	return w.reader.ReadAccountCode(address, codeHash)
+}
+
+// touch records the account as it is before the current transaction
+func (w *replayWriter) touch(address common.Address) error {
+	if _, ok := w.accountsBefore[address]; ok {
+		return nil
+	}
+	a, err := w.account(address)
+	if err != nil {
+		return err
+	}
+	w.accountsBefore[address] = a
+	return nil
+}
+
+func (w *replayWriter) UpdateAccountData(_ context.Context, address common.Address, original, account *accounts.Account) error {
+	if err := w.touch(address); err != nil {
+		return err
+	}
+	w.accounts[address] = account.SelfCopy()
+	return nil
+}
+
+func (w *replayWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	w.code[codeHash] = common.CopyBytes(code)
+	return w.touch(address)
+}
+
+func (w *replayWriter) DeleteAccount(_ context.Context, address common.Address, original *accounts.Account) error {
+	if err := w.touch(address); err != nil {
+		return err
+	}
+	w.accounts[address] = nil
+	return nil
+}
+
+func (w *replayWriter) WriteAccountStorage(_ context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	k := replayStorageKey{address: address, incarnation: incarnation, key: *key}
+	if _, ok := w.storageBefore[k]; !ok {
+		v, err := w.storageValue(k)
+		if err != nil {
+			return err
+		}
+		w.storageBefore[k] = v
+	}
+	w.storage[k] = *value
+	return w.touch(address)
+}
+
+func (w *replayWriter) CreateContract(address common.Address) error {
+	return nil
+}
+
+// takeDiff returns the changes since the previous call, the writes which restore the previous value
+// are left out
+func (w *replayWriter) takeDiff() StateDiff {
+	diff := make(StateDiff)
+	accountDiff := func(address common.Address) *AccountDiff {
+		d, ok := diff[address]
+		if !ok {
+			d = &AccountDiff{}
+			diff[address] = d
+		}
+		return d
+	}
+	for address, before := range w.accountsBefore {
+		after := w.accounts[address]
+		if _, ok := w.accounts[address]; !ok {
+			after = before // only the code or the storage were written
+		}
+		d := accountDiff(address)
+		d.Created = before == nil && after != nil
+		d.Deleted = before != nil && after == nil
+
+		var balanceBefore, balanceAfter uint256.Int
+		var nonceBefore, nonceAfter uint64
+		var codeHashBefore, codeHashAfter common.Hash
+		if before != nil {
+			balanceBefore, nonceBefore = before.Balance, before.Nonce
+			if !before.IsEmptyCodeHash() {
+				codeHashBefore = before.CodeHash
+			}
+		}
+		if after != nil {
+			balanceAfter, nonceAfter = after.Balance, after.Nonce
+			if !after.IsEmptyCodeHash() {
+				codeHashAfter = after.CodeHash
+			}
+		}
+		if balanceBefore != balanceAfter {
+			d.Balance = &BalanceChange{Before: (*hexutil.Big)(balanceBefore.ToBig()), After: (*hexutil.Big)(balanceAfter.ToBig())}
+		}
+		if nonceBefore != nonceAfter {
+			d.Nonce = &NonceChange{Before: hexutil.Uint64(nonceBefore), After: hexutil.Uint64(nonceAfter)}
+		}
+		if codeHashBefore != codeHashAfter {
+			// The code is in the database or in the cache, the errors are those of the database
+			codeBefore, _ := w.accountCode(address, codeHashBefore)
+			codeAfter, _ := w.accountCode(address, codeHashAfter)
+			d.Code = &CodeChange{Before: codeBefore, After: codeAfter}
+		}
+	}
+	for k, before := range w.storageBefore {
+		if after := w.storage[k]; after != before {
+			d := accountDiff(k.address)
+			if d.Storage == nil {
+				d.Storage = make(map[common.Hash]*StorageChange)
+			}
+			d.Storage[k.key] = &StorageChange{Before: before.Bytes32(), After: after.Bytes32()}
+		}
+	}
+	for address, d := range diff {
+		if d.empty() {
+			delete(diff, address)
+		}
+	}
+	w.accountsBefore = make(map[common.Address]*accounts.Account)
+	w.storageBefore = make(map[replayStorageKey]uint256.Int)
+	return diff
+}
+
+// teeWriter passes the writes to all its writers
+type teeWriter []state.StateWriter
+
+func (t teeWriter) UpdateAccountData(ctx context.Context, address common.Address, original, account *accounts.Account) error {
+	for _, w := range t {
+		if err := w.UpdateAccountData(ctx, address, original, account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	for _, w := range t {
+		if err := w.UpdateAccountCode(address, incarnation, codeHash, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeWriter) DeleteAccount(ctx context.Context, address common.Address, original *accounts.Account) error {
+	for _, w := range t {
+		if err := w.DeleteAccount(ctx, address, original); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeWriter) WriteAccountStorage(ctx context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	for _, w := range t {
+		if err := w.WriteAccountStorage(ctx, address, incarnation, key, original, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeWriter) CreateContract(address common.Address) error {
+	for _, w := range t {
+		if err := w.CreateContract(address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareChangeSets returns a line per entry of the changesets stored for the block which differs from
+// the entries of csw. The storage keys are compared without the incarnation, like the lookups in the
+// storage changesets.
+func compareChangeSets(db ethdb.Getter, blockNum uint64, csw *state.ChangeSetWriter) ([]string, error) {
+	var mismatches []string
+
+	accountChanges, err := csw.GetAccountChanges()
+	if err != nil {
+		return nil, err
+	}
+	replayedAccounts := make(map[string][]byte)
+	for _, c := range accountChanges.Changes {
+		replayedAccounts[string(c.Key)] = c.Value
+	}
+	enc, err := ethdb.GetChangeSetByBlock(db, false /* storage */, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	storedAccounts := make(map[string][]byte)
+	if err = changeset.AccountChangeSetPlainBytes(enc).Walk(func(k, v []byte) error {
+		storedAccounts[string(k)] = common.CopyBytes(v)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, k := range changeSetMismatches(storedAccounts, replayedAccounts) {
+		mismatches = append(mismatches, fmt.Sprintf("account %x: stored %s, replayed %s",
+			k, describeAccountChange(storedAccounts, k), describeAccountChange(replayedAccounts, k)))
+	}
+
+	storageKey := func(k []byte) string {
+		return string(k[:common.AddressLength]) + string(k[common.AddressLength+common.IncarnationLength:])
+	}
+	storageChanges, err := csw.GetStorageChanges()
+	if err != nil {
+		return nil, err
+	}
+	replayedStorage := make(map[string][]byte)
+	for _, c := range storageChanges.Changes {
+		replayedStorage[storageKey(c.Key)] = c.Value
+	}
+	enc, err = ethdb.GetChangeSetByBlock(db, true /* storage */, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	storedStorage := make(map[string][]byte)
+	if len(enc) > 0 {
+		if err = changeset.StorageChangeSetPlainBytes(enc).Walk(func(k, v []byte) error {
+			storedStorage[storageKey(k)] = common.CopyBytes(v)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range changeSetMismatches(storedStorage, replayedStorage) {
+		mismatches = append(mismatches, fmt.Sprintf("storage %x slot %x: stored %s, replayed %s",
+			k[:common.AddressLength], k[common.AddressLength:], describeStorageChange(storedStorage, k), describeStorageChange(replayedStorage, k)))
+	}
+	return mismatches, nil
+}
+
+// changeSetMismatches returns the sorted keys which are not in both changesets with the same value
+func changeSetMismatches(stored, replayed map[string][]byte) []string {
+	var keys []string
+	for k, v := range stored {
+		if r, ok := replayed[k]; !ok || !bytes.Equal(v, r) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range replayed {
+		if _, ok := stored[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func describeAccountChange(changes map[string][]byte, k string) string {
+	v, ok := changes[k]
+	switch {
+	case !ok:
+		return "no change"
+	case len(v) == 0:
+		return "non-existent account"
+	}
+	var a accounts.Account
+	if err := a.DecodeForStorage(v); err != nil {
+		return fmt.Sprintf("%x (%v)", v, err)
+	}
+	return fmt.Sprintf("{nonce: %d, balance: %d, incarnation: %d}", a.Nonce, a.Balance.ToBig(), a.Incarnation)
+}
+
+func describeStorageChange(changes map[string][]byte, k string) string {
+	v, ok := changes[k]
+	if !ok {
+		return "no change"
+	}
+	return fmt.Sprintf("%x", v)
+}
@@ -0,0 +1,123 @@
+package transactions
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+func TestReplayBlock(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xcc")
+		config   = params.AllEthashProtocolChanges
+		signer   = types.MakeSigner(config, big.NewInt(1))
+		gspec    = &core.Genesis{
+			Config: config,
+			Alloc: core.GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				// SSTORE(NUMBER, CALLVALUE)
+				contract: {Balance: new(big.Int), Code: []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE)}},
+			},
+		}
+	)
+	genDb := ethdb.NewMemDatabase()
+	defer genDb.Close()
+	blocks, _, err := core.GenerateChain(config, gspec.MustCommit(genDb), ethash.NewFaker(), genDb, 2, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), contract, uint256.NewInt().SetUint64(uint64(i+5)), 100000, uint256.NewInt().SetUint64(1), nil), signer, key)
+		gen.AddTx(tx)
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	if _, _, err = gspec.Commit(db, true /* history */); err != nil {
+		t.Fatal(err)
+	}
+	chain, err := core.NewBlockChain(db, nil, config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	for _, block := range blocks {
+		if err = stagedsync.InsertBlockInStages(db, config, ethash.NewFaker(), block, chain); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The second block is replayed on top of the state after the first one
+	replayed, err := ReplayBlock(db.KV(), db, config, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed.ChangeSetMismatches) != 0 {
+		t.Errorf("unexpected changeset mismatches %v", replayed.ChangeSetMismatches)
+	}
+	if len(replayed.Txs) != 1 || replayed.Txs[0].Failed {
+		t.Fatalf("expected a successful transaction, got %+v", replayed.Txs)
+	}
+	diff := replayed.Txs[0].State
+	if d := diff[address]; d == nil || d.Nonce == nil || d.Nonce.Before != 1 || d.Nonce.After != 2 {
+		t.Errorf("expected the sender nonce to go from 1 to 2, got %+v", d)
+	}
+	d := diff[contract]
+	if d == nil || d.Balance == nil || d.Balance.Before.ToInt().Int64() != 5 || d.Balance.After.ToInt().Int64() != 11 {
+		t.Fatalf("expected the contract balance to go from 5 to 11, got %+v", d)
+	}
+	if s := d.Storage[common.BigToHash(big.NewInt(2))]; s == nil || s.Before != (common.Hash{}) || s.After != common.BigToHash(big.NewInt(6)) {
+		t.Errorf("expected the storage item 2 to go from 0 to 6, got %+v", d.Storage)
+	}
+	if d.Storage[common.BigToHash(big.NewInt(1))] != nil || d.Nonce != nil || d.Code != nil {
+		t.Errorf("unexpected changes of the contract %+v", d)
+	}
+	if replayed.Finalize[blocks[1].Coinbase()] == nil {
+		t.Errorf("expected the block reward in %+v", replayed.Finalize)
+	}
+
+	// An entry added to the stored account changeset is reported
+	stored, err := ethdb.GetChangeSetByBlock(db, false /* storage */, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := changeset.NewAccountChangeSetPlain()
+	if err = changeset.AccountChangeSetPlainBytes(stored).Walk(func(k, v []byte) error {
+		return cs.Add(common.CopyBytes(k), common.CopyBytes(v))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	untouched := common.HexToAddress("0xdead")
+	if err = cs.Add(untouched.Bytes(), []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := changeset.EncodeAccountsPlain(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Put(dbutils.PlainAccountChangeSetBucket, dbutils.EncodeTimestamp(2), enc); err != nil {
+		t.Fatal(err)
+	}
+	if replayed, err = ReplayBlock(db.KV(), db, config, 2); err != nil {
+		t.Fatal(err)
+	}
+	expected := "account " + strings.ToLower(untouched.Hex()[2:]) + ": stored non-existent account, replayed no change"
+	if len(replayed.ChangeSetMismatches) != 1 || replayed.ChangeSetMismatches[0] != expected {
+		t.Errorf("expected the mismatch %q, got %v", expected, replayed.ChangeSetMismatches)
+	}
+}